@@ -0,0 +1,491 @@
+// Package agendador executa as tarefas periódicas de manutenção, como a política de retenção, em
+// uma única instância da API, eleita por um lock no banco. As execuções ficam registradas e podem
+// ser solicitadas pela API a qualquer momento.
+package agendador
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Gileno29/clientes-API/agendador/cron"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+)
+
+var (
+	ErrTarefaDesconhecida = errors.New("tarefa desconhecida")
+	ErrTarefaRepetida     = errors.New("tarefa já registrada")
+)
+
+// Funcao executa uma tarefa e retorna o resumo do que foi feito, guardado no histórico. O contexto
+// é cancelado quando o agendador é parado ou a instância deixa de ser a líder.
+type Funcao func(ctx context.Context) (string, error)
+
+// Tarefa é o estado de uma tarefa registrada no agendador
+type Tarefa struct {
+	Nome string
+	// Agenda é a expressão que define os horários; vazia, a tarefa só é executada pela API
+	Agenda string
+	// Proxima é o horário previsto da próxima execução agendada, já com o jitter ou a espera após
+	// uma falha. Somente a líder conhece o horário efetivo.
+	Proxima *time.Time
+	// Falhas é a quantidade de falhas seguidas no horário atual da agenda
+	Falhas     int
+	Executando bool
+}
+
+type tarefa struct {
+	nome      string
+	expressao string
+	agenda    cron.Agenda
+	funcao    Funcao
+
+	// slot é o horário da agenda atendido pela próxima execução, repetido nas novas tentativas
+	slot       time.Time
+	proxima    time.Time
+	falhas     int
+	executando bool
+}
+
+// Agendador executa as tarefas registradas quando esta instância é a líder
+type Agendador struct {
+	repo      repository.AgendadorRepository
+	lider     Lider
+	instancia string
+
+	// IntervaloVerificacao é a frequência com que a liderança, as agendas e as execuções
+	// solicitadas pela API são verificadas
+	IntervaloVerificacao time.Duration
+	// Jitter é o atraso máximo, sorteado a cada execução, somado ao horário da agenda
+	Jitter time.Duration
+	// EsperaMinima é a espera antes da nova tentativa após a primeira falha, dobrada a cada falha
+	// seguinte até EsperaMaxima. As tentativas param quando alcançam o próximo horário da agenda.
+	EsperaMinima time.Duration
+	EsperaMaxima time.Duration
+
+	mu            sync.Mutex
+	tarefas       map[string]*tarefa
+	ehLider       bool
+	ctxLider      context.Context
+	cancelarLider context.CancelFunc
+
+	agora   func() time.Time
+	sortear func(max time.Duration) time.Duration
+	avisar  chan struct{}
+	parar   context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewAgendador(repo repository.AgendadorRepository, lider Lider) *Agendador {
+	return &Agendador{
+		repo:                 repo,
+		lider:                lider,
+		instancia:            identificarInstancia(),
+		IntervaloVerificacao: 5 * time.Second,
+		Jitter:               30 * time.Second,
+		EsperaMinima:         30 * time.Second,
+		EsperaMaxima:         30 * time.Minute,
+		tarefas:              map[string]*tarefa{},
+		agora:                time.Now,
+		sortear:              sortear,
+		avisar:               make(chan struct{}, 1),
+	}
+}
+
+// Registrar adiciona uma tarefa executada nos horários da expressão (veja cron.NewAgenda). Com a
+// expressão vazia a tarefa só é executada quando solicitada pela API.
+func (a *Agendador) Registrar(nome, expressao string, funcao Funcao) error {
+	var agenda cron.Agenda
+	if expressao != "" {
+		var err error
+		if agenda, err = cron.NewAgenda(expressao); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.tarefas[nome]; ok {
+		return fmt.Errorf("%w: %s", ErrTarefaRepetida, nome)
+	}
+	a.tarefas[nome] = &tarefa{nome: nome, expressao: expressao, agenda: agenda, funcao: funcao}
+	return nil
+}
+
+// Tarefas retorna as tarefas registradas em ordem alfabética
+func (a *Agendador) Tarefas() []Tarefa {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	agora := a.agora()
+	tarefas := make([]Tarefa, 0, len(a.tarefas))
+	for _, t := range a.tarefas {
+		estado := Tarefa{Nome: t.nome, Agenda: t.expressao, Falhas: t.falhas, Executando: t.executando}
+		switch {
+		case t.agenda == nil:
+		case a.ehLider && !t.proxima.IsZero():
+			proxima := t.proxima
+			estado.Proxima = &proxima
+		default:
+			proxima := t.agenda.Proxima(agora)
+			estado.Proxima = &proxima
+		}
+		tarefas = append(tarefas, estado)
+	}
+	sort.Slice(tarefas, func(i, j int) bool { return tarefas[i].Nome < tarefas[j].Nome })
+	return tarefas
+}
+
+// Lider indica se esta instância é a líder que executa as tarefas
+func (a *Agendador) Lider() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.ehLider
+}
+
+// Disparar solicita a execução imediata da tarefa. A execução fica pendente até a instância líder,
+// que pode ser outra, iniciá-la; se a tarefa já estiver em execução ela aguarda o fim.
+func (a *Agendador) Disparar(nome string) (*models.ExecucaoAgendada, error) {
+	a.mu.Lock()
+	_, ok := a.tarefas[nome]
+	a.mu.Unlock()
+	if !ok {
+		return nil, ErrTarefaDesconhecida
+	}
+
+	execucao := &models.ExecucaoAgendada{
+		Tarefa: nome,
+		Origem: models.OrigemManual,
+		Status: models.ExecucaoPendente,
+	}
+	if err := a.repo.Create(execucao); err != nil {
+		return nil, err
+	}
+
+	select {
+	case a.avisar <- struct{}{}:
+	default:
+	}
+	return execucao, nil
+}
+
+// Iniciar verifica imediatamente a liderança e as tarefas e repete a verificação a cada
+// IntervaloVerificacao
+func (a *Agendador) Iniciar(ctx context.Context) {
+	ctx, a.parar = context.WithCancel(ctx)
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.IntervaloVerificacao)
+		defer ticker.Stop()
+		for {
+			a.verificar(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-a.avisar:
+			}
+		}
+	}()
+}
+
+// Parar interrompe as verificações e as tarefas em execução, aguarda o seu registro no histórico e
+// devolve a liderança
+func (a *Agendador) Parar() {
+	if a.parar != nil {
+		a.parar()
+	}
+	a.wg.Wait()
+
+	a.mu.Lock()
+	if a.ehLider {
+		a.ehLider = false
+		a.cancelarLider()
+	}
+	a.mu.Unlock()
+	a.lider.Liberar()
+	metrics.LiderAgendador(false)
+}
+
+// verificar confirma a liderança e inicia as execuções agendadas que chegaram ao horário e as
+// solicitadas pela API
+func (a *Agendador) verificar(ctx context.Context) {
+	lider, err := a.lider.Tentar(ctx)
+	if err != nil && ctx.Err() == nil {
+		slog.Error("Erro ao verificar a liderança do agendador", "erro", err)
+	}
+	metrics.LiderAgendador(lider)
+
+	a.mu.Lock()
+	eraLider := a.ehLider
+	a.ehLider = lider
+	if eraLider && !lider {
+		// Outra instância pode assumir a liderança, então as tarefas desta são interrompidas
+		a.cancelarLider()
+		a.mu.Unlock()
+		slog.Warn("Liderança do agendador perdida", "instancia", a.instancia)
+		return
+	}
+	a.mu.Unlock()
+	if !lider {
+		return
+	}
+
+	if !eraLider {
+		a.mu.Lock()
+		a.ctxLider, a.cancelarLider = context.WithCancel(ctx)
+		a.mu.Unlock()
+		if err := a.assumir(); err != nil {
+			slog.Error("Erro ao carregar o histórico do agendador", "erro", err)
+			a.mu.Lock()
+			a.ehLider = false
+			a.cancelarLider()
+			a.mu.Unlock()
+			a.lider.Liberar()
+			return
+		}
+	}
+
+	a.mu.Lock()
+	agora := a.agora()
+	var vencidas []*tarefa
+	for _, t := range a.tarefas {
+		if t.agenda != nil && !t.executando && !agora.Before(t.proxima) {
+			vencidas = append(vencidas, t)
+		}
+	}
+	a.mu.Unlock()
+	for _, t := range vencidas {
+		a.executarAgendada(t)
+	}
+
+	pendentes, err := a.repo.ListarPendentes()
+	if err != nil {
+		slog.Error("Erro ao listar as execuções solicitadas", "erro", err)
+		return
+	}
+	for i := range pendentes {
+		a.executarSolicitada(&pendentes[i])
+	}
+}
+
+// assumir carrega do histórico o horário de cada tarefa ao se tornar a líder, para que um horário
+// perdido durante a troca de líder seja executado e uma falha continue aguardando a nova tentativa
+func (a *Agendador) assumir() error {
+	slog.Info("Liderança do agendador assumida", "instancia", a.instancia)
+
+	// As execuções em andamento pertencem a uma líder anterior, que já perdeu o lock
+	interrompidas, err := a.repo.Interromper(a.instancia, "execução interrompida: a instância líder foi encerrada")
+	if err != nil {
+		return err
+	}
+	if interrompidas > 0 {
+		slog.Warn("Execuções da líder anterior marcadas como falhas", "quantidade", interrompidas)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	agora := a.agora()
+	for _, t := range a.tarefas {
+		if t.agenda == nil {
+			continue
+		}
+		ultima, err := a.repo.UltimaAgendada(t.nome)
+		if err != nil {
+			return err
+		}
+		switch {
+		case ultima == nil || ultima.AgendadoPara == nil:
+			t.slot, t.falhas = time.Time{}, 0
+			a.avancar(t, agora)
+		case ultima.Status == models.ExecucaoFalhou && ultima.FinalizadoEm != nil:
+			t.slot, t.falhas = *ultima.AgendadoPara, ultima.Tentativa
+			a.agendarNovaTentativa(t, *ultima.FinalizadoEm)
+		default:
+			t.slot, t.falhas = *ultima.AgendadoPara, 0
+			a.avancar(t, *ultima.AgendadoPara)
+		}
+	}
+	return nil
+}
+
+// avancar passa a tarefa para o primeiro horário da agenda posterior ao atual e à referência.
+// Deve ser chamado com a.mu bloqueado.
+func (a *Agendador) avancar(t *tarefa, referencia time.Time) {
+	if t.slot.After(referencia) {
+		referencia = t.slot
+	}
+	t.slot = t.agenda.Proxima(referencia)
+	t.falhas = 0
+	t.proxima = t.slot.Add(a.sortear(a.Jitter))
+}
+
+// agendarNovaTentativa calcula a espera exponencial após uma falha no horário atual, desistindo
+// dele quando a nova tentativa alcançaria o horário seguinte. Deve ser chamado com a.mu bloqueado.
+func (a *Agendador) agendarNovaTentativa(t *tarefa, falha time.Time) {
+	espera := a.EsperaMinima
+	for i := 1; i < t.falhas && espera < a.EsperaMaxima; i++ {
+		espera *= 2
+	}
+	if espera > a.EsperaMaxima {
+		espera = a.EsperaMaxima
+	}
+
+	proxima := falha.Add(espera + a.sortear(espera/2))
+	if !proxima.Before(t.agenda.Proxima(t.slot)) {
+		slog.Warn("Tarefa agendada não concluída no horário", "tarefa", t.nome, "agendado_para", t.slot, "falhas", t.falhas)
+		a.avancar(t, falha)
+		return
+	}
+	t.proxima = proxima
+}
+
+func (a *Agendador) executarAgendada(t *tarefa) {
+	a.mu.Lock()
+	if !a.ehLider || t.executando {
+		a.mu.Unlock()
+		return
+	}
+	ctx := a.ctxLider
+	agora := a.agora()
+	slot := t.slot
+	execucao := &models.ExecucaoAgendada{
+		Tarefa:       t.nome,
+		Origem:       models.OrigemAgendada,
+		Status:       models.ExecucaoExecutando,
+		AgendadoPara: &slot,
+		Tentativa:    t.falhas + 1,
+		Instancia:    a.instancia,
+		IniciadoEm:   &agora,
+	}
+	t.executando = true
+	a.mu.Unlock()
+
+	if err := a.repo.Create(execucao); err != nil {
+		slog.Error("Erro ao registrar a execução agendada", "tarefa", t.nome, "erro", err)
+		a.mu.Lock()
+		t.executando = false
+		a.mu.Unlock()
+		return
+	}
+	a.iniciar(ctx, t, execucao)
+}
+
+func (a *Agendador) executarSolicitada(execucao *models.ExecucaoAgendada) {
+	a.mu.Lock()
+	t, ok := a.tarefas[execucao.Tarefa]
+	if ok && t.executando {
+		// Aguarda o fim da execução em andamento para não executar a tarefa em paralelo
+		a.mu.Unlock()
+		return
+	}
+	if ok {
+		t.executando = true
+	}
+	ctx := a.ctxLider
+	a.mu.Unlock()
+
+	reservada, err := a.repo.Reservar(execucao, a.instancia)
+	if err != nil || !reservada || !ok {
+		if err != nil {
+			slog.Error("Erro ao reservar a execução solicitada", "id", execucao.ID, "erro", err)
+		}
+		if ok {
+			a.mu.Lock()
+			t.executando = false
+			a.mu.Unlock()
+		} else if reservada {
+			// Tarefa removida da configuração depois de solicitada
+			execucao.Status = models.ExecucaoFalhou
+			execucao.Erro = ErrTarefaDesconhecida.Error()
+			a.repo.Finalizar(execucao)
+		}
+		return
+	}
+	a.iniciar(ctx, t, execucao)
+}
+
+func (a *Agendador) iniciar(ctx context.Context, t *tarefa, execucao *models.ExecucaoAgendada) {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		inicio := time.Now()
+		resultado, err := executarComRecover(ctx, t)
+		metrics.ExecucaoAgendador(t.nome, execucao.Origem, time.Since(inicio), err)
+
+		execucao.Resultado = resultado
+		if err != nil {
+			execucao.Status = models.ExecucaoFalhou
+			execucao.Erro = err.Error()
+			slog.Error("Erro ao executar a tarefa agendada", "tarefa", t.nome, "origem", execucao.Origem, "tentativa", execucao.Tentativa, "erro", err)
+		} else {
+			execucao.Status = models.ExecucaoConcluida
+			slog.Info("Tarefa agendada executada", "tarefa", t.nome, "origem", execucao.Origem, "resultado", resultado, "duracao_ms", time.Since(inicio).Milliseconds())
+		}
+		if err := a.repo.Finalizar(execucao); err != nil {
+			slog.Error("Erro ao registrar o fim da execução", "tarefa", t.nome, "id", execucao.ID, "erro", err)
+		}
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		t.executando = false
+		// As execuções solicitadas pela API não alteram a agenda
+		if execucao.Origem != models.OrigemAgendada || ctx.Err() != nil {
+			return
+		}
+		fim := a.agora()
+		if err != nil {
+			t.falhas++
+			a.agendarNovaTentativa(t, fim)
+		} else {
+			a.avancar(t, fim)
+		}
+	}()
+}
+
+func executarComRecover(ctx context.Context, t *tarefa) (resultado string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("falha inesperada ao executar a tarefa")
+			slog.Error("Panic ao executar a tarefa agendada", "tarefa", t.nome, "panic", fmt.Sprint(r))
+		}
+	}()
+	return t.funcao(ctx)
+}
+
+// identificarInstancia combina o host com um sufixo aleatório, distinguindo também os processos
+// reiniciados no mesmo host
+func identificarInstancia() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "desconhecido"
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
+
+// sortear retorna uma duração aleatória entre zero e max
+func sortear(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n.Int64())
+}
@@ -0,0 +1,250 @@
+package agendador
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/migrations"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var bancos atomic.Int64
+
+// setupDB cria um banco SQLite em memória exclusivo, com o esquema das migrações
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:agendador%d?mode=memory&cache=shared", bancos.Add(1))), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrador, err := migrations.NewMigrador(db)
+	require.NoError(t, err)
+	_, err = migrador.Subir(context.Background(), 0)
+	require.NoError(t, err)
+	return db
+}
+
+// liderFalso permite decidir nos testes se a instância é a líder
+type liderFalso struct {
+	lider      atomic.Bool
+	liberacoes atomic.Int32
+}
+
+func (l *liderFalso) Tentar(context.Context) (bool, error) { return l.lider.Load(), nil }
+
+func (l *liderFalso) Liberar() { l.liberacoes.Add(1) }
+
+// relogio é o horário controlado pelos testes
+type relogio struct {
+	atual atomic.Int64
+}
+
+func (r *relogio) agora() time.Time { return time.Unix(0, r.atual.Load()) }
+
+func (r *relogio) definir(t time.Time) { r.atual.Store(t.UnixNano()) }
+
+func TestAgendador(t *testing.T) {
+	inicio := time.Now().Truncate(time.Hour)
+
+	novo := func(t *testing.T) (*Agendador, repository.AgendadorRepository, *liderFalso, *relogio) {
+		repo := repository.NewAgendadorRepository(setupDB(t))
+		lider := &liderFalso{}
+		lider.lider.Store(true)
+		r := &relogio{}
+		r.definir(inicio)
+		a := NewAgendador(repo, lider)
+		a.agora = r.agora
+		a.sortear = func(time.Duration) time.Duration { return 0 }
+		a.EsperaMinima = time.Minute
+		return a, repo, lider, r
+	}
+	// verificar executa um ciclo e aguarda as tarefas iniciadas por ele
+	verificar := func(a *Agendador) {
+		a.verificar(context.Background())
+		a.wg.Wait()
+	}
+
+	// Caso de sucesso: A tarefa roda somente quando chega o horário da agenda
+	t.Run("Executa no horário da agenda", func(t *testing.T) {
+		a, repo, _, r := novo(t)
+		var execucoes atomic.Int32
+		require.NoError(t, a.Registrar("limpeza", "@every 1h", func(context.Context) (string, error) {
+			execucoes.Add(1)
+			return "3 registros", nil
+		}))
+		assert.ErrorIs(t, a.Registrar("limpeza", "", nil), ErrTarefaRepetida)
+		assert.Error(t, a.Registrar("invalida", "* *", nil))
+
+		verificar(a)
+		assert.Zero(t, execucoes.Load())
+		tarefas := a.Tarefas()
+		require.Len(t, tarefas, 1)
+		assert.Equal(t, inicio.Add(time.Hour), *tarefas[0].Proxima)
+
+		r.definir(inicio.Add(time.Hour))
+		verificar(a)
+		verificar(a)
+		assert.Equal(t, int32(1), execucoes.Load())
+
+		historico, err := repo.Listar("limpeza", 10)
+		require.NoError(t, err)
+		require.Len(t, historico, 1)
+		assert.Equal(t, models.ExecucaoConcluida, historico[0].Status)
+		assert.Equal(t, models.OrigemAgendada, historico[0].Origem)
+		assert.Equal(t, "3 registros", historico[0].Resultado)
+		assert.True(t, inicio.Add(time.Hour).Equal(*historico[0].AgendadoPara))
+		assert.Equal(t, inicio.Add(2*time.Hour), *a.Tarefas()[0].Proxima)
+	})
+
+	// Caso de sucesso: As falhas são repetidas com espera crescente até o sucesso
+	t.Run("Repete a tarefa após a falha", func(t *testing.T) {
+		a, repo, _, r := novo(t)
+		var chamadas atomic.Int32
+		require.NoError(t, a.Registrar("revalidacao", "@every 1h", func(context.Context) (string, error) {
+			if chamadas.Add(1) <= 2 {
+				return "", errors.New("banco indisponível")
+			}
+			return "", nil
+		}))
+		verificar(a)
+
+		r.definir(inicio.Add(time.Hour))
+		verificar(a)
+		assert.Equal(t, 1, a.Tarefas()[0].Falhas)
+		r.definir(inicio.Add(time.Hour + 30*time.Second))
+		verificar(a)
+		assert.Equal(t, int32(1), chamadas.Load(), "Aguarda a espera mínima")
+
+		r.definir(inicio.Add(time.Hour + time.Minute))
+		verificar(a)
+		assert.Equal(t, int32(2), chamadas.Load())
+		r.definir(inicio.Add(time.Hour + 2*time.Minute))
+		verificar(a)
+		assert.Equal(t, int32(2), chamadas.Load(), "A espera dobra a cada falha")
+		r.definir(inicio.Add(time.Hour + 3*time.Minute))
+		verificar(a)
+		assert.Equal(t, int32(3), chamadas.Load())
+
+		historico, err := repo.Listar("", 10)
+		require.NoError(t, err)
+		require.Len(t, historico, 3)
+		assert.Equal(t, models.ExecucaoConcluida, historico[0].Status)
+		assert.Equal(t, 3, historico[0].Tentativa)
+		assert.Equal(t, models.ExecucaoFalhou, historico[2].Status)
+		assert.Equal(t, "banco indisponível", historico[2].Erro)
+		assert.True(t, historico[0].AgendadoPara.Equal(*historico[2].AgendadoPara), "As tentativas atendem ao mesmo horário")
+		assert.Zero(t, a.Tarefas()[0].Falhas)
+	})
+
+	// Caso de sucesso: A execução solicitada fica pendente até a líder iniciá-la
+	t.Run("Executa a tarefa solicitada pela API", func(t *testing.T) {
+		a, repo, lider, _ := novo(t)
+		lider.lider.Store(false)
+		require.NoError(t, a.Registrar("recriptografia", "", func(context.Context) (string, error) {
+			panic("chave ausente")
+		}))
+
+		_, err := a.Disparar("desconhecida")
+		assert.ErrorIs(t, err, ErrTarefaDesconhecida)
+		execucao, err := a.Disparar("recriptografia")
+		require.NoError(t, err)
+		assert.Equal(t, models.ExecucaoPendente, execucao.Status)
+
+		verificar(a)
+		assert.False(t, a.Lider())
+		pendente, err := repo.FindByID(execucao.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ExecucaoPendente, pendente.Status, "Somente a líder executa")
+
+		lider.lider.Store(true)
+		verificar(a)
+		assert.True(t, a.Lider())
+		finalizada, err := repo.FindByID(execucao.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ExecucaoFalhou, finalizada.Status)
+		assert.Equal(t, "falha inesperada ao executar a tarefa", finalizada.Erro)
+		assert.Equal(t, a.instancia, finalizada.Instancia)
+		assert.Nil(t, a.Tarefas()[0].Proxima, "Sem agenda a tarefa só roda pela API")
+
+		a.Parar()
+		assert.Equal(t, int32(1), lider.liberacoes.Load())
+		assert.False(t, a.Lider())
+	})
+
+	// Caso de sucesso: A nova líder executa o horário perdido e encerra as execuções da anterior
+	t.Run("Retoma a agenda da líder anterior", func(t *testing.T) {
+		a, repo, _, r := novo(t)
+		var execucoes atomic.Int32
+		require.NoError(t, a.Registrar("retencao", "@every 1h", func(context.Context) (string, error) {
+			execucoes.Add(1)
+			return "", nil
+		}))
+		anterior := inicio.Add(-3 * time.Hour)
+		require.NoError(t, repo.Create(&models.ExecucaoAgendada{Tarefa: "retencao", Origem: models.OrigemAgendada, Status: models.ExecucaoConcluida, AgendadoPara: &anterior, Tentativa: 1}))
+		interrompida := &models.ExecucaoAgendada{Tarefa: "retencao", Origem: models.OrigemManual, Status: models.ExecucaoExecutando, Instancia: "outra-instancia"}
+		require.NoError(t, repo.Create(interrompida))
+
+		verificar(a)
+		assert.Equal(t, int32(1), execucoes.Load(), "Executa uma vez o horário perdido")
+		verificar(a)
+		assert.Equal(t, int32(1), execucoes.Load())
+		assert.Equal(t, r.agora().Add(time.Hour), *a.Tarefas()[0].Proxima)
+
+		execucao, err := repo.FindByID(interrompida.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ExecucaoFalhou, execucao.Status)
+		assert.NotEmpty(t, execucao.Erro)
+	})
+
+	// Caso de sucesso: A perda da liderança interrompe as tarefas em execução
+	t.Run("Interrompe as tarefas ao perder a liderança", func(t *testing.T) {
+		a, repo, lider, _ := novo(t)
+		iniciada := make(chan struct{})
+		require.NoError(t, a.Registrar("lenta", "", func(ctx context.Context) (string, error) {
+			close(iniciada)
+			<-ctx.Done()
+			return "", ctx.Err()
+		}))
+		execucao, err := a.Disparar("lenta")
+		require.NoError(t, err)
+
+		a.verificar(context.Background())
+		<-iniciada
+		lider.lider.Store(false)
+		verificar(a)
+
+		finalizada, err := repo.FindByID(execucao.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ExecucaoFalhou, finalizada.Status)
+		assert.Equal(t, context.Canceled.Error(), finalizada.Erro)
+	})
+}
+
+func TestLider(t *testing.T) {
+	// Caso de sucesso: A conexão com o lock incerto é encerrada em vez de voltar ao pool
+	t.Run("Descarta a conexão do lock incerto", func(t *testing.T) {
+		sqlDB, err := setupDB(t).DB()
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		conn, err := sqlDB.Conn(ctx)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		assert.Equal(t, 1, sqlDB.Stats().Idle, "A conexão fechada normalmente volta ao pool")
+
+		conn, err = sqlDB.Conn(ctx)
+		require.NoError(t, err)
+		descartar(conn)
+		assert.Zero(t, sqlDB.Stats().Idle)
+		assert.Zero(t, sqlDB.Stats().OpenConnections, "A conexão descartada deve ser encerrada")
+	})
+}
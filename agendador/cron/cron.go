@@ -0,0 +1,181 @@
+// Package cron interpreta as expressões do cron usadas nas agendas do agendador
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Agenda calcula os horários de execução de uma tarefa
+type Agenda interface {
+	// Proxima retorna o primeiro horário da agenda posterior ao informado
+	Proxima(depois time.Time) time.Time
+}
+
+// descritores são os atalhos aceitos no lugar das cinco colunas
+var descritores = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	nomesMeses = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	nomesDias  = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// NewAgenda interpreta uma expressão no formato do cron, com as colunas minuto, hora, dia do mês,
+// mês e dia da semana, os descritores @hourly, @daily, @weekly, @monthly e @yearly, ou um intervalo
+// fixo como "@every 15m". Os horários seguem o fuso do horário informado a Proxima.
+func NewAgenda(expressao string) (Agenda, error) {
+	expressao = strings.TrimSpace(expressao)
+	if intervalo, ok := strings.CutPrefix(expressao, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(intervalo))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("agenda inválida %q: o intervalo deve ser uma duração de ao menos 1s", expressao)
+		}
+		return intervaloFixo(d), nil
+	}
+	if campos, ok := descritores[strings.ToLower(expressao)]; ok {
+		expressao = campos
+	}
+
+	campos := strings.Fields(expressao)
+	if len(campos) != 5 {
+		return nil, fmt.Errorf("agenda inválida %q: informe minuto, hora, dia do mês, mês e dia da semana", expressao)
+	}
+	var c expressaoCron
+	var err error
+	if c.minutos, err = interpretarCampo(campos[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("agenda inválida %q: minuto: %w", expressao, err)
+	}
+	if c.horas, err = interpretarCampo(campos[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("agenda inválida %q: hora: %w", expressao, err)
+	}
+	if c.dias, err = interpretarCampo(campos[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("agenda inválida %q: dia do mês: %w", expressao, err)
+	}
+	if c.meses, err = interpretarCampo(campos[3], 1, 12, nomesMeses); err != nil {
+		return nil, fmt.Errorf("agenda inválida %q: mês: %w", expressao, err)
+	}
+	// O domingo pode ser informado como 0 ou 7
+	if c.diasSemana, err = interpretarCampo(campos[4], 0, 7, nomesDias); err != nil {
+		return nil, fmt.Errorf("agenda inválida %q: dia da semana: %w", expressao, err)
+	}
+	if c.diasSemana&(1<<7) != 0 {
+		c.diasSemana |= 1
+	}
+	c.diaQualquer = campos[2] == "*"
+	c.diaSemanaQualquer = campos[4] == "*"
+	return c, nil
+}
+
+type intervaloFixo time.Duration
+
+func (i intervaloFixo) Proxima(depois time.Time) time.Time {
+	return depois.Add(time.Duration(i))
+}
+
+// expressaoCron guarda os valores aceitos em cada coluna como bits
+type expressaoCron struct {
+	minutos, horas, dias, meses, diasSemana uint64
+
+	diaQualquer, diaSemanaQualquer bool
+}
+
+func (c expressaoCron) Proxima(depois time.Time) time.Time {
+	t := depois.Truncate(time.Minute).Add(time.Minute)
+	// Uma agenda como "0 0 30 2 *" nunca acontece; a busca para após alguns anos
+	limite := t.AddDate(5, 0, 0)
+	for t.Before(limite) {
+		switch {
+		case c.meses&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.diaAceito(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.horas&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minutos&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// diaAceito segue o cron: com o dia do mês e o da semana restritos, basta um dos dois coincidir
+func (c expressaoCron) diaAceito(t time.Time) bool {
+	dia := c.dias&(1<<uint(t.Day())) != 0
+	diaSemana := c.diasSemana&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.diaQualquer:
+		return diaSemana
+	case c.diaSemanaQualquer:
+		return dia
+	default:
+		return dia || diaSemana
+	}
+}
+
+// interpretarCampo aceita *, valores, intervalos (1-5), listas (1,3) e passos (*/15, 10-20/5)
+func interpretarCampo(campo string, minimo, maximo int, nomes []string) (uint64, error) {
+	var bits uint64
+	for _, parte := range strings.Split(campo, ",") {
+		faixa, passo := parte, 1
+		if antes, depois, ok := strings.Cut(parte, "/"); ok {
+			n, err := strconv.Atoi(depois)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("passo inválido em %q", parte)
+			}
+			faixa, passo = antes, n
+		}
+
+		inicio, fim := minimo, maximo
+		if faixa != "*" {
+			antes, depois, intervalo := strings.Cut(faixa, "-")
+			var err error
+			if inicio, err = valorCampo(antes, nomes, minimo); err != nil {
+				return 0, err
+			}
+			fim = inicio
+			if intervalo {
+				if fim, err = valorCampo(depois, nomes, minimo); err != nil {
+					return 0, err
+				}
+			} else if passo > 1 {
+				fim = maximo
+			}
+		}
+		if inicio < minimo || fim > maximo || inicio > fim {
+			return 0, fmt.Errorf("%q fora do intervalo de %d a %d", parte, minimo, maximo)
+		}
+		for v := inicio; v <= fim; v += passo {
+			bits |= 1 << uint(v)
+		}
+	}
+	if bits == 0 {
+		return 0, errors.New("nenhum valor informado")
+	}
+	return bits, nil
+}
+
+func valorCampo(valor string, nomes []string, minimo int) (int, error) {
+	for i, nome := range nomes {
+		if strings.EqualFold(valor, nome) {
+			return i + minimo, nil
+		}
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido: %q", valor)
+	}
+	return n, nil
+}
@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAgenda(t *testing.T) {
+	// Quarta-feira, 15/01/2025 10:07
+	base := time.Date(2025, 1, 15, 10, 7, 30, 0, time.UTC)
+
+	// Caso de sucesso: Próximo horário de cada formato aceito
+	t.Run("Calcula o próximo horário", func(t *testing.T) {
+		casos := []struct {
+			expressao string
+			esperado  time.Time
+		}{
+			{"* * * * *", time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+			{"*/15 * * * *", time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+			{"0 3 * * *", time.Date(2025, 1, 16, 3, 0, 0, 0, time.UTC)},
+			{"30 8-18/2 * * mon-fri", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+			{"0 0 1,15 * *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+			{"0 0 * feb 7", time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)},
+			{"0 0 13 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+			{"@daily", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+			{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+			{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+			{"@every 90m", base.Add(90 * time.Minute)},
+		}
+		for _, caso := range casos {
+			agenda, err := NewAgenda(caso.expressao)
+			require.NoError(t, err, caso.expressao)
+			assert.Equal(t, caso.esperado, agenda.Proxima(base), caso.expressao)
+		}
+	})
+
+	// Caso de sucesso: Horário que nunca acontece não trava a busca
+	t.Run("Retorna zero para uma agenda impossível", func(t *testing.T) {
+		agenda, err := NewAgenda("0 0 30 2 *")
+		require.NoError(t, err)
+		assert.True(t, agenda.Proxima(base).IsZero())
+	})
+
+	// Caso de erro: Expressões fora do formato
+	t.Run("Recusa expressões inválidas", func(t *testing.T) {
+		for _, expressao := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "x * * * *", "@every 0s", "@every dia"} {
+			_, err := NewAgenda(expressao)
+			assert.Error(t, err, expressao)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package agendador
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// chaveLider identifica o lock consultivo que elege a instância líder no Postgres
+const chaveLider int64 = 72451874
+
+// Lider elege, entre as instâncias da API, a única que executa as tarefas do agendador
+type Lider interface {
+	// Tentar assume ou confirma a liderança, retornando se esta instância é a líder
+	Tentar(ctx context.Context) (bool, error)
+	// Liberar devolve a liderança para que outra instância a assuma
+	Liberar()
+}
+
+// NewLider retorna a eleição adequada ao banco. No Postgres a líder é a instância que detém um
+// lock consultivo de sessão; nos demais bancos a API roda em uma só instância, que é sempre a líder.
+func NewLider(db *gorm.DB) (Lider, error) {
+	if db.Dialector.Name() != "postgres" {
+		return liderUnico{}, nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return &liderPostgres{db: sqlDB}, nil
+}
+
+type liderUnico struct{}
+
+func (liderUnico) Tentar(context.Context) (bool, error) { return true, nil }
+
+func (liderUnico) Liberar() {}
+
+// liderPostgres mantém reservada a conexão que obteve o lock, que é liberado pelo Postgres quando
+// a sessão termina. Assim a liderança passa para outra instância se a líder cair.
+type liderPostgres struct {
+	db *sql.DB
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func (l *liderPostgres) Tentar(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// Com a conexão perdida o lock também foi liberado e pode já pertencer a outra instância. Se
+		// a sessão ainda existir, como em um ping que só excedeu o prazo, ela é encerrada junto.
+		slog.Warn("Conexão do lock do agendador perdida")
+		descartar(l.conn)
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var obtido bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", chaveLider).Scan(&obtido); err != nil {
+		// O lock pode ter sido obtido sem que a resposta chegasse
+		descartar(conn)
+		return false, err
+	}
+	if !obtido {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *liderPostgres) Liberar() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return
+	}
+	if _, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", chaveLider); err != nil {
+		slog.Error("Erro ao liberar o lock do agendador", "erro", err)
+		descartar(l.conn)
+	} else {
+		l.conn.Close()
+	}
+	l.conn = nil
+}
+
+// descartar encerra a conexão física em vez de devolvê-la ao pool, usado quando não se sabe se a
+// sessão ainda detém o lock. Uma sessão devolvida ao pool com o lock o manteria preso a ela, sem
+// líder, até que a conexão fosse fechada.
+func descartar(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
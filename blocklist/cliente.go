@@ -0,0 +1,61 @@
+package blocklist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+)
+
+// ErrDocumentoInvalido é retornado quando a API recusa o documento consultado
+var ErrDocumentoInvalido = errors.New("documento inválido")
+
+// ClienteHTTP consulta o endpoint GET /blocklist/{documento} de uma instância da API
+type ClienteHTTP struct {
+	urlBase string
+	http    *http.Client
+}
+
+// NewClienteHTTP cria o cliente para a API em urlBase, ex.: http://clientes-api:8080. Sem um
+// http.Client é usado um com timeout de 2 segundos.
+func NewClienteHTTP(urlBase string, cliente *http.Client) *ClienteHTTP {
+	if cliente == nil {
+		cliente = &http.Client{Timeout: 2 * time.Second}
+	}
+	return &ClienteHTTP{urlBase: strings.TrimRight(urlBase, "/"), http: cliente}
+}
+
+// Consultar informa se o documento está em blocklist, com a versão do índice que respondeu
+func (c *ClienteHTTP) Consultar(ctx context.Context, documento string) (dtos.BlocklistResponse, error) {
+	var resposta dtos.BlocklistResponse
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.urlBase+"/blocklist/"+url.PathEscape(documento), nil)
+	if err != nil {
+		return resposta, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return resposta, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		err = json.NewDecoder(res.Body).Decode(&resposta)
+		return resposta, err
+	case http.StatusBadRequest:
+		return resposta, ErrDocumentoInvalido
+	default:
+		var erro dtos.ResponseErro
+		json.NewDecoder(res.Body).Decode(&erro)
+		return resposta, fmt.Errorf("resposta inesperada da API: %d %s", res.StatusCode, erro.Mensagem)
+	}
+}
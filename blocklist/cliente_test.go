@@ -0,0 +1,52 @@
+package blocklist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClienteHTTP(t *testing.T) {
+	ctx := context.Background()
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/blocklist/52998224725":
+			w.Write([]byte(`{"documento":"52998224725","blocklist":true,"versao":7,"sincronizado_em":"2024-05-01T10:00:00Z"}`))
+		case "/blocklist/123":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"mensagem":"{'error': 'Documento inválido'}"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"mensagem":"{'error': 'falha'}"}`))
+		}
+	}))
+	defer servidor.Close()
+	cliente := NewClienteHTTP(servidor.URL+"/", nil)
+
+	// Caso de sucesso: Documento em blocklist com a versão do índice
+	t.Run("Consulta o documento", func(t *testing.T) {
+		resposta, err := cliente.Consultar(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.True(t, resposta.Blocklist)
+		assert.Equal(t, uint64(7), resposta.Versao)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), resposta.SincronizadoEm)
+	})
+
+	// Caso de erro: Documento recusado pela API
+	t.Run("Retorna ErrDocumentoInvalido", func(t *testing.T) {
+		_, err := cliente.Consultar(ctx, "123")
+		assert.ErrorIs(t, err, ErrDocumentoInvalido)
+	})
+
+	// Caso de erro: Falha da API
+	t.Run("Retorna erro para outros status", func(t *testing.T) {
+		_, err := cliente.Consultar(ctx, "86405508838")
+		assert.ErrorContains(t, err, "500")
+	})
+}
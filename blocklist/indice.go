@@ -0,0 +1,185 @@
+// Package blocklist mantém em memória os documentos em blocklist, para responder à triagem de
+// fraude sem consultar o banco, e o cliente Go do endpoint que expõe essa consulta.
+package blocklist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Indice guarda, para cada tenant, o conjunto de documentos em blocklist. É carregado do banco na
+// inicialização, atualizado pelas escritas feitas pela própria instância e recarregado
+// periodicamente para receber as alterações feitas por outras instâncias.
+type Indice struct {
+	tenants  []string
+	carregar func(ctx context.Context, tenant string) ([]string, error)
+
+	mu        sync.RWMutex
+	conjuntos map[string]*conjunto
+
+	parar     chan struct{}
+	pararOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// conjunto é o índice de um tenant; a versão é própria de cada tenant para que as alterações de
+// um não sejam percebidas pelos outros
+type conjunto struct {
+	documentos     map[string]struct{}
+	versao         uint64
+	sincronizadoEm time.Time
+}
+
+// Consulta é a resposta do índice para um documento. Versao aumenta a cada alteração do índice do
+// tenant e SincronizadoEm é o momento da última comparação completa com o banco.
+type Consulta struct {
+	Documento      string
+	Blocklist      bool
+	Versao         uint64
+	SincronizadoEm time.Time
+}
+
+// NewIndice cria um índice vazio dos tenants informados; carregar retorna todos os documentos em
+// blocklist do tenant no banco
+func NewIndice(tenants []string, carregar func(ctx context.Context, tenant string) ([]string, error)) *Indice {
+	i := &Indice{
+		tenants:   tenants,
+		carregar:  carregar,
+		conjuntos: map[string]*conjunto{},
+		parar:     make(chan struct{}),
+	}
+	for _, tenant := range tenants {
+		i.conjuntos[tenant] = &conjunto{documentos: map[string]struct{}{}}
+	}
+	return i
+}
+
+// Consultar informa se o documento está em blocklist no tenant, sem acessar o banco
+func (i *Indice) Consultar(tenant, documento string) Consulta {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	consulta := Consulta{Documento: documento}
+	if c, ok := i.conjuntos[tenant]; ok {
+		_, consulta.Blocklist = c.documentos[documento]
+		consulta.Versao, consulta.SincronizadoEm = c.versao, c.sincronizadoEm
+	}
+	return consulta
+}
+
+// Definir aplica ao índice uma alteração já confirmada no banco
+func (i *Indice) Definir(tenant, documento string, bloqueado bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	c, ok := i.conjuntos[tenant]
+	if !ok {
+		c = &conjunto{documentos: map[string]struct{}{}}
+		i.conjuntos[tenant] = c
+	}
+	_, atual := c.documentos[documento]
+	if atual == bloqueado {
+		return
+	}
+	if bloqueado {
+		c.documentos[documento] = struct{}{}
+	} else {
+		delete(c.documentos, documento)
+	}
+	c.versao++
+}
+
+// Tamanho retorna a quantidade de documentos em blocklist em todos os tenants
+func (i *Indice) Tamanho() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	total := 0
+	for _, c := range i.conjuntos {
+		total += len(c.documentos)
+	}
+	return total
+}
+
+// Sincronizar substitui o índice de cada tenant pelos documentos do banco. Se o índice de um tenant
+// for alterado durante a leitura, o resultado pode não conter a alteração e é descartado até a
+// próxima sincronização. A falha de um tenant não impede a sincronização dos demais.
+func (i *Indice) Sincronizar(ctx context.Context) error {
+	var erros []error
+	for _, tenant := range i.tenants {
+		if err := i.sincronizarTenant(ctx, tenant); err != nil {
+			erros = append(erros, fmt.Errorf("tenant %s: %w", tenant, err))
+		}
+	}
+	return errors.Join(erros...)
+}
+
+func (i *Indice) sincronizarTenant(ctx context.Context, tenant string) error {
+	i.mu.RLock()
+	versaoInicial := i.conjuntos[tenant].versao
+	i.mu.RUnlock()
+
+	documentos, err := i.carregar(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	novos := make(map[string]struct{}, len(documentos))
+	for _, documento := range documentos {
+		novos[documento] = struct{}{}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	c := i.conjuntos[tenant]
+	if c.versao != versaoInicial {
+		return nil
+	}
+	if !iguais(c.documentos, novos) {
+		c.documentos = novos
+		c.versao++
+	}
+	c.sincronizadoEm = time.Now()
+	return nil
+}
+
+// Iniciar sincroniza o índice com o banco a cada intervalo
+func (i *Indice) Iniciar(intervalo time.Duration) {
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-i.parar:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), intervalo)
+				if err := i.Sincronizar(ctx); err != nil {
+					slog.Warn("Erro ao sincronizar o índice de blocklist", "erro", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Encerrar interrompe as sincronizações periódicas
+func (i *Indice) Encerrar() {
+	i.pararOnce.Do(func() { close(i.parar) })
+	i.wg.Wait()
+}
+
+func iguais(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for documento := range a {
+		if _, ok := b[documento]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package blocklist
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fonte simula o banco consultado na sincronização
+type fonte struct {
+	mu         sync.Mutex
+	documentos []string
+	err        error
+	// durante é executada no meio da leitura, antes de os documentos serem retornados
+	durante func()
+}
+
+func (f *fonte) carregar(ctx context.Context, tenant string) ([]string, error) {
+	f.mu.Lock()
+	documentos, err, durante := append([]string(nil), f.documentos...), f.err, f.durante
+	f.mu.Unlock()
+	if durante != nil {
+		durante()
+	}
+	return documentos, err
+}
+
+func (f *fonte) definir(documentos ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.documentos = documentos
+}
+
+func TestIndice(t *testing.T) {
+	ctx := context.Background()
+
+	// Caso de sucesso: Carrega o banco e aplica as escritas
+	t.Run("Consulta e aplica as alterações", func(t *testing.T) {
+		indice := NewIndice([]string{"a"}, (&fonte{documentos: []string{"52998224725"}}).carregar)
+		require.NoError(t, indice.Sincronizar(ctx))
+
+		consulta := indice.Consultar("a", "52998224725")
+		assert.True(t, consulta.Blocklist)
+		assert.Equal(t, uint64(1), consulta.Versao)
+		assert.False(t, consulta.SincronizadoEm.IsZero())
+		assert.False(t, indice.Consultar("a", "86405508838").Blocklist)
+
+		indice.Definir("a", "86405508838", true)
+		indice.Definir("a", "52998224725", false)
+		assert.True(t, indice.Consultar("a", "86405508838").Blocklist)
+		assert.False(t, indice.Consultar("a", "52998224725").Blocklist)
+		assert.Equal(t, uint64(3), indice.Consultar("a", "86405508838").Versao)
+
+		indice.Definir("a", "86405508838", true)
+		assert.Equal(t, uint64(3), indice.Consultar("a", "86405508838").Versao, "Alteração sem efeito não muda a versão")
+		assert.Equal(t, 1, indice.Tamanho())
+	})
+
+	// Caso de sucesso: A versão só aumenta quando o banco diverge do índice
+	t.Run("Sincroniza com o banco", func(t *testing.T) {
+		origem := &fonte{documentos: []string{"52998224725"}}
+		indice := NewIndice([]string{"a"}, origem.carregar)
+		require.NoError(t, indice.Sincronizar(ctx))
+		anterior := indice.Consultar("a", "52998224725")
+
+		require.NoError(t, indice.Sincronizar(ctx))
+		atual := indice.Consultar("a", "52998224725")
+		assert.Equal(t, anterior.Versao, atual.Versao)
+		assert.False(t, atual.SincronizadoEm.Before(anterior.SincronizadoEm))
+
+		origem.definir("86405508838")
+		require.NoError(t, indice.Sincronizar(ctx))
+		assert.False(t, indice.Consultar("a", "52998224725").Blocklist)
+		assert.True(t, indice.Consultar("a", "86405508838").Blocklist)
+		assert.Equal(t, anterior.Versao+1, indice.Consultar("a", "86405508838").Versao)
+	})
+
+	// Caso de sucesso: Escrita durante a leitura do banco não é sobrescrita pela leitura antiga
+	t.Run("Descarta a sincronização concorrente com escrita", func(t *testing.T) {
+		origem := &fonte{}
+		indice := NewIndice([]string{"a"}, origem.carregar)
+		origem.durante = func() { indice.Definir("a", "52998224725", true) }
+
+		require.NoError(t, indice.Sincronizar(ctx))
+		assert.True(t, indice.Consultar("a", "52998224725").Blocklist)
+		assert.True(t, indice.Consultar("a", "52998224725").SincronizadoEm.IsZero())
+	})
+
+	// Caso de erro: Falha do banco mantém o índice atual
+	t.Run("Mantém o índice quando o banco falha", func(t *testing.T) {
+		origem := &fonte{documentos: []string{"52998224725"}}
+		indice := NewIndice([]string{"a"}, origem.carregar)
+		require.NoError(t, indice.Sincronizar(ctx))
+
+		origem.err = errors.New("banco fora do ar")
+		assert.Error(t, indice.Sincronizar(ctx))
+		assert.True(t, indice.Consultar("a", "52998224725").Blocklist)
+	})
+
+	// Caso de sucesso: Cada tenant tem seus documentos e sua versão
+	t.Run("Isola os tenants", func(t *testing.T) {
+		indice := NewIndice([]string{"a", "b"}, func(ctx context.Context, tenant string) ([]string, error) {
+			if tenant == "a" {
+				return []string{"52998224725"}, nil
+			}
+			return nil, nil
+		})
+		require.NoError(t, indice.Sincronizar(ctx))
+		assert.True(t, indice.Consultar("a", "52998224725").Blocklist)
+		assert.False(t, indice.Consultar("b", "52998224725").Blocklist)
+
+		versaoB := indice.Consultar("b", "52998224725").Versao
+		indice.Definir("a", "86405508838", true)
+		assert.False(t, indice.Consultar("b", "86405508838").Blocklist)
+		assert.Equal(t, versaoB, indice.Consultar("b", "86405508838").Versao, "Alteração de outro tenant não muda a versão")
+		assert.False(t, indice.Consultar("c", "52998224725").Blocklist)
+	})
+
+	// Caso de sucesso: Sincronização periódica
+	t.Run("Sincroniza a cada intervalo", func(t *testing.T) {
+		origem := &fonte{}
+		indice := NewIndice([]string{"a"}, origem.carregar)
+		indice.Iniciar(10 * time.Millisecond)
+		defer indice.Encerrar()
+
+		origem.definir("52998224725")
+		assert.Eventually(t, func() bool {
+			return indice.Consultar("a", "52998224725").Blocklist
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Backends de cache aceitos em cache.backend
+const (
+	BackendNenhum = "nenhum"
+	BackendLRU    = "lru"
+	BackendRedis  = "redis"
+)
+
+// Cache armazena valores por chave com prazo de validade. As implementações são seguras para
+// uso concorrente; uma falha do cache não deve impedir a consulta à fonte original.
+type Cache interface {
+	// Obter retorna o valor e true se a chave existir e não tiver expirado
+	Obter(ctx context.Context, chave string) ([]byte, bool, error)
+	// Definir armazena o valor pelo ttl informado
+	Definir(ctx context.Context, chave string, valor []byte, ttl time.Duration) error
+	// Remover apaga as chaves; chaves inexistentes são ignoradas
+	Remover(ctx context.Context, chaves ...string) error
+}
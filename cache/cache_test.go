@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contratoCache verifica o comportamento comum a todos os backends. avancar adianta o relógio
+// usado na expiração das entradas.
+func contratoCache(t *testing.T, c Cache, avancar func(time.Duration)) {
+	ctx := context.Background()
+
+	t.Run("Grava, lê e remove", func(t *testing.T) {
+		_, ok, err := c.Obter(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, c.Definir(ctx, "a", []byte("1"), time.Minute))
+		require.NoError(t, c.Definir(ctx, "b", []byte("2"), time.Minute))
+		valor, ok, err := c.Obter(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("1"), valor)
+
+		require.NoError(t, c.Remover(ctx, "a", "inexistente"))
+		_, ok, _ = c.Obter(ctx, "a")
+		assert.False(t, ok)
+		_, ok, _ = c.Obter(ctx, "b")
+		assert.True(t, ok, "As demais chaves são mantidas")
+		assert.NoError(t, c.Remover(ctx))
+	})
+
+	t.Run("Expira pelo ttl", func(t *testing.T) {
+		require.NoError(t, c.Definir(ctx, "curta", []byte("1"), time.Second))
+		require.NoError(t, c.Definir(ctx, "longa", []byte("2"), time.Hour))
+
+		avancar(2 * time.Second)
+		_, ok, _ := c.Obter(ctx, "curta")
+		assert.False(t, ok)
+		_, ok, _ = c.Obter(ctx, "longa")
+		assert.True(t, ok)
+	})
+}
+
+func TestLRU(t *testing.T) {
+	c := NewLRU(10)
+	agora := time.Now()
+	c.agora = func() time.Time { return agora }
+	contratoCache(t, c, func(d time.Duration) { agora = agora.Add(d) })
+
+	// Caso de sucesso: Ao atingir a capacidade a entrada usada há mais tempo é descartada
+	t.Run("Descarta a menos usada", func(t *testing.T) {
+		ctx := context.Background()
+		c := NewLRU(2)
+		c.Definir(ctx, "a", []byte("1"), time.Minute)
+		c.Definir(ctx, "b", []byte("2"), time.Minute)
+		c.Obter(ctx, "a")
+		c.Definir(ctx, "c", []byte("3"), time.Minute)
+
+		assert.Equal(t, 2, c.Tamanho())
+		_, ok, _ := c.Obter(ctx, "b")
+		assert.False(t, ok, "b era a menos usada")
+		_, ok, _ = c.Obter(ctx, "a")
+		assert.True(t, ok)
+	})
+}
+
+func TestRedis(t *testing.T) {
+	servidor := miniredis.RunT(t)
+	c, err := NewRedis("redis://"+servidor.Addr()+"/0", "clientes-api:")
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Fechar() })
+
+	require.NoError(t, c.Ping(context.Background()))
+	contratoCache(t, c, servidor.FastForward)
+	assert.True(t, servidor.Exists("clientes-api:b"), "As chaves recebem o prefixo")
+
+	// Caso de erro: Servidor fora do ar
+	t.Run("Retorna erro sem o servidor", func(t *testing.T) {
+		servidor.Close()
+		_, _, err := c.Obter(context.Background(), "b")
+		assert.Error(t, err)
+	})
+
+	// Caso de erro: URL inválida
+	_, err = NewRedis("http://localhost", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU é um cache em memória do processo que descarta as entradas menos usadas ao atingir a
+// capacidade. Cada instância da API tem o seu, então a invalidação só vale para a própria instância.
+type LRU struct {
+	mu         sync.Mutex
+	capacidade int
+	ordem      *list.List
+	entradas   map[string]*list.Element
+	agora      func() time.Time
+}
+
+type entradaLRU struct {
+	chave    string
+	valor    []byte
+	expiraEm time.Time
+}
+
+func NewLRU(capacidade int) *LRU {
+	return &LRU{
+		capacidade: capacidade,
+		ordem:      list.New(),
+		entradas:   map[string]*list.Element{},
+		agora:      time.Now,
+	}
+}
+
+func (c *LRU) Obter(ctx context.Context, chave string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elemento, ok := c.entradas[chave]
+	if !ok {
+		return nil, false, nil
+	}
+	entrada := elemento.Value.(*entradaLRU)
+	if !c.agora().Before(entrada.expiraEm) {
+		c.remover(elemento)
+		return nil, false, nil
+	}
+	c.ordem.MoveToFront(elemento)
+	return entrada.valor, true, nil
+}
+
+func (c *LRU) Definir(ctx context.Context, chave string, valor []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiraEm := c.agora().Add(ttl)
+	if elemento, ok := c.entradas[chave]; ok {
+		entrada := elemento.Value.(*entradaLRU)
+		entrada.valor = valor
+		entrada.expiraEm = expiraEm
+		c.ordem.MoveToFront(elemento)
+		return nil
+	}
+
+	c.entradas[chave] = c.ordem.PushFront(&entradaLRU{chave: chave, valor: valor, expiraEm: expiraEm})
+	for c.ordem.Len() > c.capacidade {
+		c.remover(c.ordem.Back())
+	}
+	return nil
+}
+
+func (c *LRU) Remover(ctx context.Context, chaves ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, chave := range chaves {
+		if elemento, ok := c.entradas[chave]; ok {
+			c.remover(elemento)
+		}
+	}
+	return nil
+}
+
+// Tamanho retorna a quantidade de entradas armazenadas, incluindo as expiradas ainda não descartadas
+func (c *LRU) Tamanho() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ordem.Len()
+}
+
+func (c *LRU) remover(elemento *list.Element) {
+	c.ordem.Remove(elemento)
+	delete(c.entradas, elemento.Value.(*entradaLRU).chave)
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Gileno29/clientes-API/cache"
+	"github.com/Gileno29/clientes-API/comandos"
+	"github.com/Gileno29/clientes-API/config"
+	"github.com/Gileno29/clientes-API/cripto"
+	"github.com/Gileno29/clientes-API/database"
+	"github.com/Gileno29/clientes-API/health"
+	"github.com/Gileno29/clientes-API/jobs"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/migrations"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"gorm.io/gorm"
+)
+
+// executarComando executa os subcomandos administrativos e retorna o código de saída do processo.
+// Os argumentos do comando vêm antes das flags, que incluem as flags de configuração.
+func executarComando(comando string, args []string) int {
+	var posicionais []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		posicionais = append(posicionais, args[0])
+		args = args[1:]
+	}
+
+	// Interrompe importações e expurgos longos ao receber SIGINT ou SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	flags := flag.NewFlagSet("apiclientes "+comando, flag.ContinueOnError)
+	err := executarSubcomando(ctx, comando, posicionais, flags, args)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, comandos.ErrUso), errors.Is(err, migrations.ErrUso):
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	case errors.Is(err, errConfiguracao):
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+	return 1
+}
+
+// errConfiguracao identifica os erros ao carregar a configuração, que encerram com código 2
+var errConfiguracao = errors.New("Configuração inválida")
+
+func executarSubcomando(ctx context.Context, comando string, posicionais []string, flags *flag.FlagSet, args []string) error {
+	saida := os.Stdout
+
+	switch comando {
+	case "help":
+		fmt.Fprintln(saida, comandos.Uso)
+		return nil
+	case "config", "migrate", "import", "export", "validate-doc", "generate-doc", "blocklist", "create-api-key", "purge":
+	default:
+		return comandos.ErrUso
+	}
+
+	// Os comandos sem acesso ao banco não dependem da configuração
+	switch comando {
+	case "validate-doc":
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		return comandos.ValidarDocumentos(posicionais, saida)
+	case "generate-doc":
+		tipo := flags.String("tipo", "", "tipo do documento: cpf ou cnpj; sem tipo alterna os dois")
+		quantidade := flags.Int("quantidade", 10, "quantidade de documentos")
+		formatado := flags.Bool("formatado", false, "aplica a máscara do CPF ou do CNPJ")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if len(posicionais) > 0 {
+			return comandos.ErrUso
+		}
+		return comandos.GerarDocumentos(rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())), *tipo, *quantidade, *formatado, saida)
+	}
+
+	// Flags dos comandos que operam sobre os clientes de um tenant
+	var tenantID *string
+	var filtro jobs.ParametrosFiltro
+	switch comando {
+	case "import", "export", "blocklist", "purge", "create-api-key":
+		tenantID = flags.String("tenant", tenant.Padrao, "tenant dos clientes")
+	}
+	switch comando {
+	case "export", "purge":
+		flags.StringVar(&filtro.RazaoSocial, "razao-social", "", "somente os clientes cuja razão social contém o texto")
+		flags.BoolVar(&filtro.SomenteBlocklist, "somente-blocklist", false, "somente os clientes em blocklist")
+	}
+	confirmar := false
+	if comando == "purge" {
+		flags.BoolVar(&confirmar, "confirmar", false, "remove os clientes; sem a flag somente informa quantos seriam removidos")
+	}
+	admin := false
+	if comando == "create-api-key" {
+		flags.BoolVar(&admin, "admin", false, "gera a chave das rotas /admin em vez da chave do tenant")
+	}
+
+	cfg, err := config.CarregarComFlags(flags, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w:\n%w", errConfiguracao, err)
+	}
+	// A saída padrão fica com o resultado do comando, como o CSV da exportação
+	logging.Configurar(cfg.Log.Nivel, cfg.Log.Formato, os.Stderr)
+
+	if tenantID != nil && !(comando == "create-api-key" && admin) {
+		if !slices.Contains(novoResolvedor(cfg.Tenancy).IDs(), *tenantID) {
+			return fmt.Errorf("tenant desconhecido: %s", *tenantID)
+		}
+		ctx = tenant.ComID(ctx, *tenantID)
+	}
+
+	switch comando {
+	case "config":
+		if len(posicionais) == 1 && posicionais[0] == "check" {
+			return verificarConfiguracao(ctx, cfg, saida)
+		}
+		if len(posicionais) > 0 {
+			return comandos.ErrUso
+		}
+		conteudo, err := cfg.YAML()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(saida, conteudo)
+		return nil
+	case "create-api-key":
+		if len(posicionais) > 0 {
+			return comandos.ErrUso
+		}
+		if !admin && len(cfg.Tenancy.Tenants) == 0 {
+			return errors.New("nenhum tenant configurado em tenancy.tenants, as chaves de API não são usadas")
+		}
+		return comandos.CriarChaveAPI(*tenantID, admin, saida)
+	case "migrate":
+		if len(posicionais) == 0 {
+			return migrations.ErrUso
+		}
+		db, err := conectar(cfg)
+		if err != nil {
+			return err
+		}
+		defer fecharBanco(db)
+		migrador, err := migrations.NewMigrador(db)
+		if err != nil {
+			return err
+		}
+		return migrations.ExecutarComando(ctx, migrador, posicionais, saida)
+	}
+
+	// Valida os argumentos antes de conectar ao banco
+	switch comando {
+	case "import":
+		if len(posicionais) != 1 {
+			return comandos.ErrUso
+		}
+	case "export":
+		if len(posicionais) > 1 {
+			return comandos.ErrUso
+		}
+	case "blocklist":
+		if len(posicionais) != 2 || (posicionais[0] != "add" && posicionais[0] != "remove") {
+			return comandos.ErrUso
+		}
+	case "purge":
+		if len(posicionais) > 0 {
+			return comandos.ErrUso
+		}
+	}
+
+	repo, encerrar, err := abrirClientes(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer encerrar()
+
+	switch comando {
+	case "import":
+		conteudo, err := os.ReadFile(posicionais[0])
+		if err != nil {
+			return err
+		}
+		return comandos.Importar(ctx, repo, posicionais[0], conteudo, saida)
+	case "export":
+		if len(posicionais) == 0 || posicionais[0] == "-" {
+			return comandos.Exportar(ctx, repo, filtro, saida)
+		}
+		arquivo, err := os.Create(posicionais[0])
+		if err != nil {
+			return err
+		}
+		if err := comandos.Exportar(ctx, repo, filtro, arquivo); err != nil {
+			arquivo.Close()
+			return err
+		}
+		return arquivo.Close()
+	case "blocklist":
+		return comandos.AlterarBlocklist(ctx, repo, posicionais[1], posicionais[0] == "add", saida)
+	default:
+		return comandos.Expurgar(ctx, repo, filtro, confirmar, saida)
+	}
+}
+
+// abrirClientes conecta ao banco com o esquema atualizado e monta o repositório de clientes da
+// mesma forma que a API, para que as escritas sejam cifradas e invalidem o cache compartilhado.
+// As instâncias em execução recebem as alterações da blocklist na próxima sincronização do índice.
+func abrirClientes(ctx context.Context, cfg config.Config) (repository.ClienteRepository, func(), error) {
+	if cfg.BancoDeDados.Driver == config.DriverMemoria {
+		return nil, nil, errors.New("o driver memoria não guarda os clientes entre execuções, use postgres ou sqlite")
+	}
+
+	db, err := conectar(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrador, err := migrations.NewMigrador(db)
+	if err == nil {
+		err = migrador.Verificar(ctx)
+	}
+	if err != nil {
+		fecharBanco(db)
+		return nil, nil, fmt.Errorf("esquema do banco de dados desatualizado, execute 'apiclientes migrate up': %w", err)
+	}
+
+	// Os comandos usam somente o primário
+	cifrador, err := novoCifrador(cfg)
+	if err != nil {
+		fecharBanco(db)
+		return nil, nil, err
+	}
+	repo, _, cacheRedis, err := novoRepositorioClientes(cfg, database.NewRoteador(db), cifrador)
+	if err != nil {
+		fecharBanco(db)
+		return nil, nil, err
+	}
+	return repo, func() {
+		if cacheRedis != nil {
+			cacheRedis.Fechar()
+		}
+		fecharBanco(db)
+	}, nil
+}
+
+// verificarConfiguracao confere as dependências da configuração carregada: o banco e o esquema,
+// o arquivo de chaves e o Redis, quando configurados
+func verificarConfiguracao(ctx context.Context, cfg config.Config, saida io.Writer) error {
+	registro := health.NewRegistro(5 * time.Second)
+
+	db, err := conectar(cfg)
+	if err != nil {
+		registro.Registrar(health.CheckerFunc{NomeChecker: "banco_de_dados", Fn: func(context.Context) error { return err }})
+	} else {
+		defer fecharBanco(db)
+		registro.Registrar(health.BancoDeDados(db))
+		// O banco em memória começa vazio, as migrações são aplicadas pela API ao iniciar
+		if cfg.BancoDeDados.Driver != config.DriverMemoria {
+			registro.Registrar(health.CheckerFunc{NomeChecker: "migracoes", Fn: func(ctx context.Context) error {
+				migrador, err := migrations.NewMigrador(db)
+				if err != nil {
+					return err
+				}
+				return migrador.Verificar(ctx)
+			}})
+		}
+	}
+
+	if cfg.Criptografia.ArquivoChaves != "" {
+		registro.Registrar(health.CheckerFunc{NomeChecker: "criptografia", Fn: func(context.Context) error {
+			chaves, err := cripto.CarregarArquivoChaves(cfg.Criptografia.ArquivoChaves)
+			if err != nil {
+				return err
+			}
+			_, err = cripto.NewCifrador(chaves, chaves.ChaveHash())
+			return err
+		}})
+	}
+
+	if cfg.Cache.Backend == cache.BackendRedis {
+		registro.Registrar(health.CheckerFunc{NomeChecker: "cache", Fn: func(ctx context.Context) error {
+			redis, err := cache.NewRedis(cfg.Cache.RedisURL, "clientes-api:")
+			if err != nil {
+				return err
+			}
+			defer redis.Fechar()
+			return redis.Ping(ctx)
+		}})
+	}
+
+	return comandos.VerificarConfiguracao(ctx, registro, saida)
+}
+
+// conectar abre o banco da configuração
+func conectar(cfg config.Config) (*gorm.DB, error) {
+	if err := database.Connect(cfg.BancoDeDados); err != nil {
+		return nil, err
+	}
+	return database.DB, nil
+}
+
+func fecharBanco(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// novoCifrador carrega as chaves de criptografia do arquivo configurado. Sem o arquivo retorna nil
+// e os dados pessoais são gravados em texto puro.
+func novoCifrador(cfg config.Config) (*cripto.Cifrador, error) {
+	if cfg.Criptografia.ArquivoChaves == "" {
+		return nil, nil
+	}
+	chaves, err := cripto.CarregarArquivoChaves(cfg.Criptografia.ArquivoChaves)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar as chaves de criptografia: %w", err)
+	}
+	cifrador, err := cripto.NewCifrador(chaves, chaves.ChaveHash())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao configurar a criptografia: %w", err)
+	}
+	return cifrador, nil
+}
+
+// novoRepositorioClientes monta o repositório de clientes com a criptografia e o cache
+// configurados. O repositório cifrado também é retornado para o job de recriptografia.
+func novoRepositorioClientes(cfg config.Config, roteador *database.Roteador, cifrador *cripto.Cifrador) (repository.ClienteRepository, repository.Recriptografavel, *cache.Redis, error) {
+	clienteRepo := repository.NewClienteRepositoryComReplicas(roteador)
+	if cfg.BancoDeDados.Driver == config.DriverMemoria {
+		clienteRepo = repository.NewClienteRepositoryMemoriaComEventos(roteador.Primario())
+	}
+
+	// Cifra o documento e a razão social dos clientes, e os eventos gerados por eles, quando configurado
+	var repoCifrado repository.Recriptografavel
+	if cifrador != nil {
+		cifrado := repository.NewClienteRepositoryCifrado(roteador, cifrador)
+		clienteRepo, repoCifrado = cifrado, cifrado.(repository.Recriptografavel)
+		slog.Info("Criptografia dos clientes habilitada", "chave_ativa", cifrador.ChaveAtiva())
+	}
+
+	// Guarda as buscas por documento no cache, quando configurado. Com a criptografia as entradas
+	// também são cifradas e as chaves usam o hash do documento.
+	var cacheRedis *cache.Redis
+	ttlCache, ttlNegativo := time.Duration(cfg.Cache.TTL), time.Duration(cfg.Cache.TTLNegativo)
+	comCache := func(c cache.Cache) repository.ClienteRepository {
+		if cifrador != nil {
+			return repository.NewClienteRepositoryComCacheCifrado(clienteRepo, c, ttlCache, ttlNegativo, cifrador)
+		}
+		return repository.NewClienteRepositoryComCache(clienteRepo, c, ttlCache, ttlNegativo)
+	}
+	switch cfg.Cache.Backend {
+	case cache.BackendLRU:
+		clienteRepo = comCache(cache.NewLRU(cfg.Cache.Capacidade))
+	case cache.BackendRedis:
+		var err error
+		cacheRedis, err = cache.NewRedis(cfg.Cache.RedisURL, "clientes-api:")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("erro ao configurar o Redis: %w", err)
+		}
+		// Sem o Redis as buscas continuam indo ao banco, então a API sobe mesmo assim
+		if err := cacheRedis.Ping(context.Background()); err != nil {
+			slog.Warn("Redis indisponível, buscas enviadas ao banco até ele voltar", "erro", err)
+		}
+		clienteRepo = comCache(cacheRedis)
+	}
+	return clienteRepo, repoCifrado, cacheRedis, nil
+}
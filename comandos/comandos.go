@@ -0,0 +1,264 @@
+package comandos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand/v2"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/health"
+	"github.com/Gileno29/clientes-API/jobs"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/utils"
+	"gorm.io/gorm"
+)
+
+// Uso descreve os subcomandos do binário
+const Uso = `uso: apiclientes [comando] [argumentos] [flags do comando] [flags de configuração]
+
+comandos:
+  serve                              inicia a API (padrão quando nenhum comando é informado)
+  config                             exibe a configuração efetiva, sem segredos
+  config check                       valida a configuração e verifica o banco, as chaves e o cache
+  migrate <comando>                  aplica, desfaz ou lista as migrações do esquema
+  import <arquivo>                   cadastra os clientes de um arquivo CSV ou JSON
+  export [arquivo]                   grava os clientes em CSV no arquivo ou na saída padrão
+  validate-doc <documento>...        valida CPFs e CNPJs
+  generate-doc                       gera CPFs e CNPJs válidos para testes
+  blocklist <add|remove> <documento> inclui ou retira um cliente da blocklist
+  create-api-key                     gera uma chave de API para um tenant ou para as rotas /admin
+  purge                              remove os clientes que atendem ao filtro
+
+Use "apiclientes <comando> -h" para ver as flags de cada comando.`
+
+var (
+	// ErrUso indica um comando desconhecido ou argumentos inválidos
+	ErrUso = errors.New(Uso)
+	// ErrDocumentoInvalido indica que algum dos documentos informados não é um CPF ou CNPJ válido
+	ErrDocumentoInvalido = errors.New("documento inválido")
+	// ErrClienteNaoEncontrado indica que o documento não está cadastrado no tenant
+	ErrClienteNaoEncontrado = errors.New("cliente não encontrado")
+)
+
+// Importar cadastra os clientes do arquivo pelo mesmo executor do job de importação. Arquivos .csv
+// usam as colunas da exportação; os demais são lidos como o JSON aceito por POST /jobs/importacao.
+// As linhas recusadas são listadas em saida e fazem o comando retornar erro.
+func Importar(ctx context.Context, repo repository.ClienteRepository, arquivo string, conteudo []byte, saida io.Writer) error {
+	var parametros jobs.ParametrosImportacao
+	if strings.EqualFold(filepath.Ext(arquivo), ".csv") {
+		clientes, err := jobs.LerCSVClientes(conteudo)
+		if err != nil {
+			return err
+		}
+		parametros.Clientes = clientes
+	} else if err := json.Unmarshal(conteudo, &parametros); err != nil {
+		return fmt.Errorf("arquivo inválido: %w", err)
+	}
+	if len(parametros.Clientes) == 0 {
+		return errors.New("nenhum cliente informado")
+	}
+
+	var resumo struct {
+		Total      int `json:"total"`
+		Importados int `json:"importados"`
+		Falhas     int `json:"falhas"`
+	}
+	if err := executarJob(ctx, repo, jobs.TipoImportacao, parametros, saida, &resumo); err != nil {
+		return err
+	}
+	fmt.Fprintf(saida, "%d de %d clientes importados\n", resumo.Importados, resumo.Total)
+	if resumo.Falhas > 0 {
+		return fmt.Errorf("%d clientes não importados", resumo.Falhas)
+	}
+	return nil
+}
+
+// Exportar escreve em saida o CSV dos clientes que atendem ao filtro, no formato do job de exportação
+func Exportar(ctx context.Context, repo repository.ClienteRepository, filtro jobs.ParametrosFiltro, saida io.Writer) error {
+	parametros, err := json.Marshal(filtro)
+	if err != nil {
+		return err
+	}
+	executor := jobs.ExecutoresClientes(repo)[jobs.TipoExportacao]
+	resultado, _, err := executor(ctx, &models.Job{Tipo: jobs.TipoExportacao, Parametros: string(parametros)}, progresso{saida: io.Discard})
+	if err != nil {
+		return err
+	}
+	_, err = saida.Write(resultado)
+	return err
+}
+
+// Expurgar remove os clientes que atendem ao filtro. Sem confirmar somente informa quantos seriam
+// removidos, pois sem filtro o expurgo remove todos os clientes do tenant.
+func Expurgar(ctx context.Context, repo repository.ClienteRepository, filtro jobs.ParametrosFiltro, confirmar bool, saida io.Writer) error {
+	if !confirmar {
+		total, err := jobs.ContarClientes(ctx, repo, filtro)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(saida, "%d clientes seriam removidos, repita o comando com -confirmar para removê-los\n", total)
+		return nil
+	}
+
+	var resumo struct {
+		Removidos int `json:"removidos"`
+	}
+	if err := executarJob(ctx, repo, jobs.TipoExpurgo, filtro, saida, &resumo); err != nil {
+		return err
+	}
+	fmt.Fprintf(saida, "%d clientes removidos\n", resumo.Removidos)
+	return nil
+}
+
+// AlterarBlocklist inclui ou retira o cliente da blocklist, registrando o evento como a API
+func AlterarBlocklist(ctx context.Context, repo repository.ClienteRepository, documento string, bloquear bool, saida io.Writer) error {
+	documento = utils.ClearNumber(documento)
+	if !utils.ValidaDocumento(documento) {
+		return ErrDocumentoInvalido
+	}
+
+	cliente, err := repo.FindByDocumento(ctx, documento)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrClienteNaoEncontrado
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := repo.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{Blocklist: &bloquear}); err != nil {
+		return err
+	}
+
+	if bloquear {
+		fmt.Fprintf(saida, "cliente %s incluído na blocklist\n", utils.FormatarDocumento(documento))
+	} else {
+		fmt.Fprintf(saida, "cliente %s retirado da blocklist\n", utils.FormatarDocumento(documento))
+	}
+	return nil
+}
+
+// ValidarDocumentos informa, para cada documento, se é um CPF ou CNPJ válido. Retorna
+// ErrDocumentoInvalido se algum deles for inválido.
+func ValidarDocumentos(documentos []string, saida io.Writer) error {
+	if len(documentos) == 0 {
+		return ErrUso
+	}
+
+	var err error
+	for _, documento := range documentos {
+		numero := utils.ClearNumber(documento)
+		switch {
+		case utils.ValidaDocumento(numero) && len(numero) == 11:
+			fmt.Fprintf(saida, "%s\tCPF válido\n", documento)
+		case utils.ValidaDocumento(numero):
+			fmt.Fprintf(saida, "%s\tCNPJ válido\n", documento)
+		default:
+			fmt.Fprintf(saida, "%s\tinválido\n", documento)
+			err = ErrDocumentoInvalido
+		}
+	}
+	return err
+}
+
+// GerarDocumentos escreve em saida a quantidade de documentos válidos do tipo: cpf, cnpj ou,
+// vazio, os dois tipos alternados
+func GerarDocumentos(r *mrand.Rand, tipo string, quantidade int, formatado bool, saida io.Writer) error {
+	if quantidade < 1 {
+		return errors.New("a quantidade deve ser maior que zero")
+	}
+	if tipo != "" && tipo != "cpf" && tipo != "cnpj" {
+		return fmt.Errorf("tipo inválido: %s, use cpf ou cnpj", tipo)
+	}
+
+	for i := 0; i < quantidade; i++ {
+		documento := utils.GerarCPF(r)
+		if tipo == "cnpj" || (tipo == "" && i%2 == 1) {
+			documento = utils.GerarCNPJ(r)
+		}
+		if formatado {
+			documento = utils.FormatarDocumento(documento)
+		}
+		fmt.Fprintln(saida, documento)
+	}
+	return nil
+}
+
+// CriarChaveAPI gera uma chave aleatória e explica onde configurá-la. As chaves ficam somente na
+// configuração, então a chave passa a valer quando as instâncias forem reiniciadas com ela.
+func CriarChaveAPI(tenantID string, admin bool, saida io.Writer) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	chave := hex.EncodeToString(b)
+
+	fmt.Fprintln(saida, chave)
+	if admin {
+		fmt.Fprintln(saida, "\nInclua a chave em admin.chaves_api (ou ADMIN_API_KEYS) e reinicie a API.")
+	} else {
+		fmt.Fprintf(saida, "\nInclua a chave nas chaves_api do tenant %s em tenancy.tenants e reinicie a API.\n", tenantID)
+	}
+	return nil
+}
+
+// VerificarConfiguracao executa as verificações do registro e lista o resultado de cada
+// dependência, retornando erro se alguma falhou
+func VerificarConfiguracao(ctx context.Context, registro *health.Registro, saida io.Writer) error {
+	resultado := registro.Verificar(ctx)
+
+	nomes := make([]string, 0, len(resultado.Componentes))
+	for nome := range resultado.Componentes {
+		nomes = append(nomes, nome)
+	}
+	sort.Strings(nomes)
+
+	var falhas []string
+	for _, nome := range nomes {
+		componente := resultado.Componentes[nome]
+		if componente.Status == health.StatusOK {
+			fmt.Fprintf(saida, "ok     %s\n", nome)
+			continue
+		}
+		fmt.Fprintf(saida, "falha  %s: %s\n", nome, componente.Erro)
+		falhas = append(falhas, nome)
+	}
+	if len(falhas) > 0 {
+		return fmt.Errorf("verificação falhou: %s", strings.Join(falhas, ", "))
+	}
+	fmt.Fprintln(saida, "configuração válida")
+	return nil
+}
+
+// executarJob executa diretamente o executor do job do tipo, listando em saida os erros de cada
+// item, e decodifica o resultado em resumo
+func executarJob(ctx context.Context, repo repository.ClienteRepository, tipo string, parametros interface{}, saida io.Writer, resumo interface{}) error {
+	conteudo, err := json.Marshal(parametros)
+	if err != nil {
+		return err
+	}
+	executor := jobs.ExecutoresClientes(repo)[tipo]
+	resultado, _, err := executor(ctx, &models.Job{Tipo: tipo, Parametros: string(conteudo)}, progresso{saida: saida})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resultado, resumo)
+}
+
+// progresso implementa jobs.Progresso escrevendo os erros dos itens no terminal
+type progresso struct {
+	saida io.Writer
+}
+
+func (p progresso) Atualizar(int, int) {}
+
+func (p progresso) Erro(mensagem string) {
+	fmt.Fprintln(p.saida, mensagem)
+}
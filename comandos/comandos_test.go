@@ -0,0 +1,182 @@
+package comandos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/health"
+	"github.com/Gileno29/clientes-API/jobs"
+	"github.com/Gileno29/clientes-API/migrations"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/utils"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var bancos atomic.Int64
+
+// setupRepo cria um banco SQLite em memória exclusivo, com o esquema das migrações
+func setupRepo(t *testing.T) repository.ClienteRepository {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:comandos%d?mode=memory&cache=shared", bancos.Add(1))), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrador, err := migrations.NewMigrador(db)
+	require.NoError(t, err)
+	_, err = migrador.Subir(context.Background(), 0)
+	require.NoError(t, err)
+	return repository.NewClienteRepository(db)
+}
+
+func TestClientes(t *testing.T) {
+	ctx := tenant.ComID(context.Background(), tenant.Padrao)
+	csv := "documento,razaosocial,blocklist\n529.982.247-25,Ana,false\n33000167000101,Empresa,true\n"
+
+	// Caso de sucesso: Importa o CSV e exporta no mesmo formato
+	t.Run("Importa e exporta os clientes", func(t *testing.T) {
+		repo := setupRepo(t)
+		var saida bytes.Buffer
+		require.NoError(t, Importar(ctx, repo, "clientes.CSV", []byte(csv), &saida))
+		assert.Equal(t, "2 de 2 clientes importados\n", saida.String())
+
+		saida.Reset()
+		require.NoError(t, Exportar(ctx, repo, jobs.ParametrosFiltro{}, &saida))
+		assert.Equal(t, "documento,razaosocial,blocklist\n52998224725,Ana,false\n33000167000101,Empresa,true\n", saida.String())
+
+		saida.Reset()
+		require.NoError(t, Exportar(ctx, repo, jobs.ParametrosFiltro{SomenteBlocklist: true}, &saida))
+		assert.Equal(t, "documento,razaosocial,blocklist\n33000167000101,Empresa,true\n", saida.String())
+	})
+
+	// Caso de erro: As linhas recusadas são listadas e o comando falha
+	t.Run("Lista as linhas recusadas", func(t *testing.T) {
+		repo := setupRepo(t)
+		var saida bytes.Buffer
+		json := `{"clientes": [{"documento": "52998224725", "razaosocial": "Ana"}, {"documento": "123", "razaosocial": "Ruim"}, {"documento": "52998224725", "razaosocial": "Ana"}]}`
+		err := Importar(ctx, repo, "clientes.json", []byte(json), &saida)
+		assert.EqualError(t, err, "2 clientes não importados")
+		assert.Equal(t, "linha 2: documento inválido\nlinha 3: cliente já cadastrado\n1 de 3 clientes importados\n", saida.String())
+
+		assert.Error(t, Importar(ctx, repo, "clientes.json", []byte(`{"clientes": []}`), &saida))
+		assert.Error(t, Importar(ctx, repo, "clientes.csv", []byte("cpf,nome\n"), &saida))
+	})
+
+	// Caso de sucesso: Inclui e retira o cliente da blocklist
+	t.Run("Altera a blocklist", func(t *testing.T) {
+		repo := setupRepo(t)
+		require.NoError(t, Importar(ctx, repo, "clientes.csv", []byte(csv), &bytes.Buffer{}))
+
+		var saida bytes.Buffer
+		require.NoError(t, AlterarBlocklist(ctx, repo, "529.982.247-25", true, &saida))
+		assert.Equal(t, "cliente 529.982.247-25 incluído na blocklist\n", saida.String())
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.True(t, cliente.Blocklist)
+
+		require.NoError(t, AlterarBlocklist(ctx, repo, "52998224725", false, &saida))
+		cliente, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.False(t, cliente.Blocklist)
+
+		// Caso de erro: Documento inválido ou não cadastrado
+		assert.ErrorIs(t, AlterarBlocklist(ctx, repo, "123", true, &saida), ErrDocumentoInvalido)
+		assert.ErrorIs(t, AlterarBlocklist(ctx, repo, "11144477735", true, &saida), ErrClienteNaoEncontrado)
+	})
+
+	// Caso de sucesso: O expurgo só remove os clientes com a confirmação
+	t.Run("Expurga somente com confirmação", func(t *testing.T) {
+		repo := setupRepo(t)
+		require.NoError(t, Importar(ctx, repo, "clientes.csv", []byte(csv), &bytes.Buffer{}))
+		filtro := jobs.ParametrosFiltro{SomenteBlocklist: true}
+
+		var saida bytes.Buffer
+		require.NoError(t, Expurgar(ctx, repo, filtro, false, &saida))
+		assert.Equal(t, "1 clientes seriam removidos, repita o comando com -confirmar para removê-los\n", saida.String())
+		_, err := repo.FindByDocumento(ctx, "33000167000101")
+		assert.NoError(t, err)
+
+		saida.Reset()
+		require.NoError(t, Expurgar(ctx, repo, filtro, true, &saida))
+		assert.Equal(t, "1 clientes removidos\n", saida.String())
+		_, err = repo.FindByDocumento(ctx, "33000167000101")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.NoError(t, err, "Clientes fora do filtro são mantidos")
+	})
+}
+
+func TestDocumentos(t *testing.T) {
+	// Caso de sucesso: Identifica CPFs e CNPJs válidos e falha com algum inválido
+	t.Run("Valida os documentos", func(t *testing.T) {
+		var saida bytes.Buffer
+		assert.NoError(t, ValidarDocumentos([]string{"529.982.247-25", "33000167000101"}, &saida))
+		assert.Equal(t, "529.982.247-25\tCPF válido\n33000167000101\tCNPJ válido\n", saida.String())
+
+		saida.Reset()
+		assert.ErrorIs(t, ValidarDocumentos([]string{"123", "52998224725"}, &saida), ErrDocumentoInvalido)
+		assert.Equal(t, "123\tinválido\n52998224725\tCPF válido\n", saida.String())
+
+		assert.ErrorIs(t, ValidarDocumentos(nil, &saida), ErrUso)
+	})
+
+	// Caso de sucesso: Gera documentos válidos do tipo pedido
+	t.Run("Gera os documentos", func(t *testing.T) {
+		r := rand.New(rand.NewPCG(1, 2))
+		var saida bytes.Buffer
+		require.NoError(t, GerarDocumentos(r, "", 4, false, &saida))
+		linhas := strings.Fields(saida.String())
+		require.Len(t, linhas, 4)
+		for i, documento := range linhas {
+			assert.True(t, utils.ValidaDocumento(documento))
+			assert.Len(t, documento, []int{11, 14}[i%2], "Alterna CPF e CNPJ")
+		}
+
+		saida.Reset()
+		require.NoError(t, GerarDocumentos(r, "cnpj", 1, true, &saida))
+		assert.Regexp(t, `^\d{2}\.\d{3}\.\d{3}/0001-\d{2}\n$`, saida.String())
+
+		// Caso de erro: Tipo ou quantidade inválidos
+		assert.Error(t, GerarDocumentos(r, "rg", 1, false, &saida))
+		assert.Error(t, GerarDocumentos(r, "cpf", 0, false, &saida))
+	})
+}
+
+func TestCriarChaveAPI(t *testing.T) {
+	var saida bytes.Buffer
+	require.NoError(t, CriarChaveAPI("loja-a", false, &saida))
+	linhas := strings.Split(saida.String(), "\n")
+	assert.Regexp(t, `^[0-9a-f]{64}$`, linhas[0])
+	assert.Contains(t, saida.String(), "tenant loja-a")
+
+	var outra bytes.Buffer
+	require.NoError(t, CriarChaveAPI("", true, &outra))
+	assert.NotEqual(t, linhas[0], strings.Split(outra.String(), "\n")[0], "Cada chave é aleatória")
+	assert.Contains(t, outra.String(), "admin.chaves_api")
+}
+
+func TestVerificarConfiguracao(t *testing.T) {
+	registro := health.NewRegistro(time.Second)
+	registro.Registrar(health.CheckerFunc{NomeChecker: "banco_de_dados", Fn: func(context.Context) error { return nil }})
+
+	// Caso de sucesso: Todas as dependências respondem
+	var saida bytes.Buffer
+	require.NoError(t, VerificarConfiguracao(context.Background(), registro, &saida))
+	assert.Equal(t, "ok     banco_de_dados\nconfiguração válida\n", saida.String())
+
+	// Caso de erro: Uma dependência falhou
+	registro.Registrar(health.CheckerFunc{NomeChecker: "cache", Fn: func(context.Context) error { return errors.New("conexão recusada") }})
+	saida.Reset()
+	assert.EqualError(t, VerificarConfiguracao(context.Background(), registro, &saida), "verificação falhou: cache")
+	assert.Equal(t, "ok     banco_de_dados\nfalha  cache: conexão recusada\n", saida.String())
+}
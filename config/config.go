@@ -0,0 +1,770 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gileno29/clientes-API/agendador/cron"
+	"github.com/Gileno29/clientes-API/cache"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/tracing"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config reúne a configuração da aplicação. Os valores são aplicados na ordem: padrões,
+// arquivo YAML/TOML, variáveis de ambiente e flags, cada etapa sobrescrevendo a anterior.
+type Config struct {
+	Servidor     Servidor     `yaml:"servidor" toml:"servidor"`
+	GRPC         GRPC         `yaml:"grpc" toml:"grpc"`
+	BancoDeDados BancoDeDados `yaml:"banco_de_dados" toml:"banco_de_dados"`
+	Log          Log          `yaml:"log" toml:"log"`
+	Tracing      Tracing      `yaml:"tracing" toml:"tracing"`
+	Cache        Cache        `yaml:"cache" toml:"cache"`
+	Blocklist    Blocklist    `yaml:"blocklist" toml:"blocklist"`
+	Tenancy      Tenancy      `yaml:"tenancy" toml:"tenancy"`
+	LGPD         LGPD         `yaml:"lgpd" toml:"lgpd"`
+	Criptografia Criptografia `yaml:"criptografia" toml:"criptografia"`
+	Retencao     Retencao     `yaml:"retencao" toml:"retencao"`
+	Agendador    Agendador    `yaml:"agendador" toml:"agendador"`
+	Admin        Admin        `yaml:"admin" toml:"admin"`
+}
+
+type Servidor struct {
+	Porta int `yaml:"porta" toml:"porta"`
+
+	TempoLeituraCabecalho Duracao `yaml:"tempo_leitura_cabecalho" toml:"tempo_leitura_cabecalho"`
+	TempoLeitura          Duracao `yaml:"tempo_leitura" toml:"tempo_leitura"`
+	TempoEscrita          Duracao `yaml:"tempo_escrita" toml:"tempo_escrita"`
+	TempoOcioso           Duracao `yaml:"tempo_ocioso" toml:"tempo_ocioso"`
+	// TempoEncerramento é o prazo para drenar as requisições após o sinal de término
+	TempoEncerramento Duracao `yaml:"tempo_encerramento" toml:"tempo_encerramento"`
+	// TempoFinalizador é o prazo de cada finalizador, como a parada dos workers e o fechamento do
+	// pool do banco, contado a partir do início dele e não consumido pela drenagem
+	TempoFinalizador Duracao `yaml:"tempo_finalizador" toml:"tempo_finalizador"`
+	// AtrasoEncerramento é a espera, com a prontidão já falhando, antes de parar de aceitar
+	// conexões, para que o balanceador retire a instância
+	AtrasoEncerramento Duracao `yaml:"atraso_encerramento" toml:"atraso_encerramento"`
+}
+
+// GRPC configura a API gRPC, servida em uma porta própria
+type GRPC struct {
+	// Porta do servidor gRPC; 0 desabilita a API gRPC
+	Porta int `yaml:"porta" toml:"porta"`
+}
+
+// Drivers de armazenamento aceitos em banco_de_dados.driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	// DriverMemoria mantém os clientes em memória, sem persistência, para testes e demonstrações
+	DriverMemoria = "memoria"
+)
+
+type BancoDeDados struct {
+	// Driver seleciona o armazenamento: postgres, sqlite ou memoria
+	Driver string `yaml:"driver" toml:"driver"`
+	// Arquivo é o caminho do banco quando o driver é sqlite
+	Arquivo string `yaml:"arquivo" toml:"arquivo"`
+
+	// DSN é a string de conexão completa; quando informada os demais campos de conexão são ignorados
+	DSN     string `yaml:"dsn" toml:"dsn"`
+	Host    string `yaml:"host" toml:"host"`
+	Porta   int    `yaml:"porta" toml:"porta"`
+	Usuario string `yaml:"usuario" toml:"usuario"`
+	Senha   string `yaml:"senha" toml:"senha"`
+	Nome    string `yaml:"nome" toml:"nome"`
+	SSLMode string `yaml:"sslmode" toml:"sslmode"`
+
+	// Replicas são as strings de conexão das réplicas de leitura do Postgres
+	Replicas []string `yaml:"replicas" toml:"replicas"`
+	// IntervaloVerificacaoReplicas é o intervalo do ping que retira e recoloca as réplicas em uso
+	IntervaloVerificacaoReplicas Duracao `yaml:"intervalo_verificacao_replicas" toml:"intervalo_verificacao_replicas"`
+
+	MaxConexoesAbertas int     `yaml:"max_conexoes_abertas" toml:"max_conexoes_abertas"`
+	MaxConexoesOciosas int     `yaml:"max_conexoes_ociosas" toml:"max_conexoes_ociosas"`
+	TempoVidaConexao   Duracao `yaml:"tempo_vida_conexao" toml:"tempo_vida_conexao"`
+	TempoOciosoConexao Duracao `yaml:"tempo_ocioso_conexao" toml:"tempo_ocioso_conexao"`
+
+	// Prazos de cada operação feita pelos handlers; ao expirar a consulta é cancelada e a API responde 504
+	TimeoutConsulta Duracao `yaml:"timeout_consulta" toml:"timeout_consulta"`
+	TimeoutEscrita  Duracao `yaml:"timeout_escrita" toml:"timeout_escrita"`
+	TimeoutLote     Duracao `yaml:"timeout_lote" toml:"timeout_lote"`
+}
+
+type Log struct {
+	Nivel   string `yaml:"nivel" toml:"nivel"`
+	Formato string `yaml:"formato" toml:"formato"`
+}
+
+type Tracing struct {
+	Exportador string `yaml:"exportador" toml:"exportador"`
+}
+
+// Cache configura o cache das buscas de clientes por documento
+type Cache struct {
+	// Backend seleciona o cache: nenhum, lru (em memória do processo) ou redis (compartilhado)
+	Backend string `yaml:"backend" toml:"backend"`
+	// Capacidade é o máximo de documentos mantidos pelo backend lru
+	Capacidade int `yaml:"capacidade" toml:"capacidade"`
+	// TTL é a validade dos clientes encontrados e TTLNegativo a dos documentos não cadastrados;
+	// TTLNegativo zero desativa o cache de documentos não cadastrados
+	TTL         Duracao `yaml:"ttl" toml:"ttl"`
+	TTLNegativo Duracao `yaml:"ttl_negativo" toml:"ttl_negativo"`
+	// RedisURL é o endereço do servidor quando o backend é redis, ex.: redis://:senha@redis:6379/0
+	RedisURL string `yaml:"redis_url" toml:"redis_url"`
+}
+
+// Blocklist configura o índice em memória consultado em GET /blocklist/{documento}
+type Blocklist struct {
+	// IntervaloSincronizacao é o intervalo em que o índice é comparado com o banco, recebendo as
+	// alterações feitas por outras instâncias
+	IntervaloSincronizacao Duracao `yaml:"intervalo_sincronizacao" toml:"intervalo_sincronizacao"`
+}
+
+// Tenancy configura a multi-tenancy. Sem tenants ela fica desligada e todos os dados pertencem ao
+// tenant padrao.
+type Tenancy struct {
+	// SegredoJWT valida os tokens HS256 do cabeçalho Authorization, que trazem o tenant na claim
+	// tenant_id; vazio desativa a identificação por JWT
+	SegredoJWT string `yaml:"segredo_jwt" toml:"segredo_jwt"`
+	// AceitarCabecalho aceita o tenant informado no cabeçalho X-Tenant-ID. Só deve ser ligado atrás
+	// de um gateway que autentica a requisição e define o cabeçalho.
+	AceitarCabecalho bool                `yaml:"aceitar_cabecalho" toml:"aceitar_cabecalho"`
+	Tenants          []TenantConfigurado `yaml:"tenants" toml:"tenants"`
+}
+
+// TenantConfigurado é um tenant com as chaves de API que o identificam e os prazos próprios dele;
+// prazos não informados usam os de banco_de_dados
+type TenantConfigurado struct {
+	ID              string   `yaml:"id" toml:"id"`
+	ChavesAPI       []string `yaml:"chaves_api" toml:"chaves_api"`
+	TimeoutConsulta Duracao  `yaml:"timeout_consulta" toml:"timeout_consulta"`
+	TimeoutEscrita  Duracao  `yaml:"timeout_escrita" toml:"timeout_escrita"`
+	TimeoutLote     Duracao  `yaml:"timeout_lote" toml:"timeout_lote"`
+}
+
+// LGPD configura o atendimento aos titulares
+type LGPD struct {
+	// Sal é a chave do hash que substitui o documento dos clientes anonimizados. Vazio desabilita a
+	// anonimização; alterá-lo faz com que os anonimizados deixem de ser reconhecidos na blocklist.
+	Sal string `yaml:"sal" toml:"sal"`
+}
+
+// Criptografia configura a cifragem dos dados pessoais dos clientes no banco
+type Criptografia struct {
+	// ArquivoChaves é o caminho do arquivo JSON com as chaves mestras e a chave do hash de busca.
+	// Vazio mantém os clientes em texto puro.
+	ArquivoChaves string `yaml:"arquivo_chaves" toml:"arquivo_chaves"`
+}
+
+// Retencao configura a política de retenção, executada pela tarefa retencao do agendador; cada
+// prazo zerado desabilita a regra
+type Retencao struct {
+	// Simulacao faz as rodadas somente registrarem o que seria apagado ou anonimizado
+	Simulacao bool `yaml:"simulacao" toml:"simulacao"`
+	// ClientesRemovidos é o prazo, a partir da remoção, para apagar o histórico do cliente
+	ClientesRemovidos Duracao `yaml:"clientes_removidos" toml:"clientes_removidos"`
+	// ClientesInativos é o tempo sem alterações após o qual o cliente é anonimizado
+	ClientesInativos Duracao `yaml:"clientes_inativos" toml:"clientes_inativos"`
+	// Eventos é o prazo de guarda dos eventos de auditoria já entregues aos webhooks
+	Eventos Duracao `yaml:"eventos" toml:"eventos"`
+	// Jobs é o prazo de guarda dos jobs finalizados e dos seus resultados
+	Jobs Duracao `yaml:"jobs" toml:"jobs"`
+}
+
+// Agendador configura as tarefas periódicas, executadas somente pela instância líder
+type Agendador struct {
+	// Habilitado permite que a instância assuma a liderança e execute as tarefas
+	Habilitado bool `yaml:"habilitado" toml:"habilitado"`
+	// IntervaloVerificacao é a frequência com que a liderança, as agendas e as execuções
+	// solicitadas pela API são verificadas
+	IntervaloVerificacao Duracao `yaml:"intervalo_verificacao" toml:"intervalo_verificacao"`
+	// Jitter é o atraso máximo sorteado a cada execução, para espalhar a carga das tarefas
+	Jitter Duracao `yaml:"jitter" toml:"jitter"`
+	// EsperaMinima e EsperaMaxima limitam a espera exponencial antes de repetir uma tarefa que falhou
+	EsperaMinima Duracao `yaml:"espera_minima" toml:"espera_minima"`
+	EsperaMaxima Duracao `yaml:"espera_maxima" toml:"espera_maxima"`
+	// Agendas associa cada tarefa à expressão do cron que a executa; a tarefa sem agenda só é
+	// executada pela API
+	Agendas map[string]string `yaml:"agendas" toml:"agendas"`
+}
+
+// Admin configura o acesso às rotas administrativas, que operam sobre todos os tenants
+type Admin struct {
+	// ChavesAPI são as chaves aceitas no cabeçalho X-API-Key das rotas /admin; vazio as desabilita
+	ChavesAPI []string `yaml:"chaves_api" toml:"chaves_api"`
+}
+
+// Duracao permite escrever durações como "5m" ou "30s" nos arquivos de configuração. Os prazos
+// longos aceitam também dias, como "90d".
+type Duracao time.Duration
+
+func (d *Duracao) UnmarshalText(texto []byte) error {
+	if dias, ok := strings.CutSuffix(string(texto), "d"); ok {
+		n, err := strconv.Atoi(dias)
+		if err != nil {
+			return fmt.Errorf("duração inválida: %q", texto)
+		}
+		*d = Duracao(time.Duration(n) * 24 * time.Hour)
+		return nil
+	}
+	valor, err := time.ParseDuration(string(texto))
+	if err != nil {
+		return err
+	}
+	*d = Duracao(valor)
+	return nil
+}
+
+func (d Duracao) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+var modosSSL = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Padrao retorna a configuração usada quando nada é informado
+func Padrao() Config {
+	return Config{
+		Servidor: Servidor{
+			Porta:                 8080,
+			TempoLeituraCabecalho: Duracao(5 * time.Second),
+			TempoLeitura:          Duracao(30 * time.Second),
+			TempoEscrita:          Duracao(60 * time.Second),
+			TempoOcioso:           Duracao(2 * time.Minute),
+			TempoEncerramento:     Duracao(30 * time.Second),
+			TempoFinalizador:      Duracao(5 * time.Second),
+		},
+		GRPC: GRPC{Porta: 9090},
+		BancoDeDados: BancoDeDados{
+			Driver:                       DriverPostgres,
+			Arquivo:                      "clientes.db",
+			Host:                         "localhost",
+			Porta:                        5432,
+			SSLMode:                      "disable",
+			IntervaloVerificacaoReplicas: Duracao(5 * time.Second),
+			MaxConexoesAbertas:           25,
+			MaxConexoesOciosas:           5,
+			TempoVidaConexao:             Duracao(30 * time.Minute),
+			TempoOciosoConexao:           Duracao(5 * time.Minute),
+			TimeoutConsulta:              Duracao(5 * time.Second),
+			TimeoutEscrita:               Duracao(10 * time.Second),
+			TimeoutLote:                  Duracao(time.Minute),
+		},
+		Log:     Log{Nivel: "info", Formato: logging.FormatoJSON},
+		Tracing: Tracing{Exportador: tracing.ExportadorNenhum},
+		Cache: Cache{
+			Backend:     cache.BackendNenhum,
+			Capacidade:  10000,
+			TTL:         Duracao(time.Minute),
+			TTLNegativo: Duracao(10 * time.Second),
+		},
+		Blocklist: Blocklist{IntervaloSincronizacao: Duracao(time.Minute)},
+		Agendador: Agendador{
+			Habilitado:           true,
+			IntervaloVerificacao: Duracao(5 * time.Second),
+			Jitter:               Duracao(30 * time.Second),
+			EsperaMinima:         Duracao(30 * time.Second),
+			EsperaMaxima:         Duracao(30 * time.Minute),
+			Agendas:              map[string]string{"retencao": "0 3 * * *"},
+		},
+	}
+}
+
+// Carregar monta a configuração a partir dos padrões, do arquivo indicado pela flag -config
+// ou pela variável CONFIG_FILE, das variáveis de ambiente e das flags em args
+func Carregar(args []string) (Config, error) {
+	return CarregarComFlags(flag.NewFlagSet("clientes-api", flag.ContinueOnError), args)
+}
+
+// CarregarComFlags é o Carregar dos subcomandos, que registram as próprias flags em flags antes
+// da chamada e as leem depois dela. As flags de configuração são adicionadas ao mesmo conjunto.
+func CarregarComFlags(flags *flag.FlagSet, args []string) (Config, error) {
+	cfg := Padrao()
+
+	arquivo := flags.String("config", os.Getenv("CONFIG_FILE"), "arquivo de configuração YAML ou TOML")
+	porta := flags.Int("port", 0, "porta HTTP")
+	portaGRPC := flags.Int("grpc-port", 0, "porta gRPC, 0 desabilita a API gRPC")
+	tempoEncerramento := flags.Duration("shutdown-timeout", 0, "prazo para drenar requisições e workers no encerramento")
+	driver := flags.String("db-driver", "", "armazenamento: postgres, sqlite ou memoria")
+	arquivoBanco := flags.String("db-file", "", "arquivo do banco quando o driver é sqlite")
+	replicas := flags.String("database-replica-urls", "", "strings de conexão das réplicas de leitura, separadas por vírgula")
+	dsn := flags.String("database-url", "", "string de conexão completa com o banco")
+	host := flags.String("db-host", "", "host do banco")
+	portaBanco := flags.Int("db-port", 0, "porta do banco")
+	usuario := flags.String("db-user", "", "usuário do banco")
+	nome := flags.String("db-name", "", "nome do banco")
+	sslmode := flags.String("db-sslmode", "", "modo SSL da conexão: "+strings.Join(modosSSL, ", "))
+	maxAbertas := flags.Int("db-max-open-conns", 0, "máximo de conexões abertas")
+	maxOciosas := flags.Int("db-max-idle-conns", 0, "máximo de conexões ociosas")
+	nivel := flags.String("log-level", "", "nível de log: debug, info, warn ou error")
+	formato := flags.String("log-format", "", "formato de log: json ou text")
+	exportador := flags.String("tracing-exporter", "", "exportador de traces: none, stdout ou otlp")
+	backendCache := flags.String("cache-backend", "", "cache das buscas por documento: nenhum, lru ou redis")
+	redisURL := flags.String("redis-url", "", "endereço do Redis quando o cache é redis")
+	if err := flags.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *arquivo != "" {
+		if err := lerArquivo(*arquivo, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	errAmbiente := aplicarAmbiente(&cfg)
+
+	// Somente as flags informadas sobrescrevem os valores anteriores
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Servidor.Porta = *porta
+		case "grpc-port":
+			cfg.GRPC.Porta = *portaGRPC
+		case "shutdown-timeout":
+			cfg.Servidor.TempoEncerramento = Duracao(*tempoEncerramento)
+		case "db-driver":
+			cfg.BancoDeDados.Driver = *driver
+		case "db-file":
+			cfg.BancoDeDados.Arquivo = *arquivoBanco
+		case "database-replica-urls":
+			cfg.BancoDeDados.Replicas = separarLista(*replicas)
+		case "database-url":
+			cfg.BancoDeDados.DSN = *dsn
+		case "db-host":
+			cfg.BancoDeDados.Host = *host
+		case "db-port":
+			cfg.BancoDeDados.Porta = *portaBanco
+		case "db-user":
+			cfg.BancoDeDados.Usuario = *usuario
+		case "db-name":
+			cfg.BancoDeDados.Nome = *nome
+		case "db-sslmode":
+			cfg.BancoDeDados.SSLMode = *sslmode
+		case "db-max-open-conns":
+			cfg.BancoDeDados.MaxConexoesAbertas = *maxAbertas
+		case "db-max-idle-conns":
+			cfg.BancoDeDados.MaxConexoesOciosas = *maxOciosas
+		case "log-level":
+			cfg.Log.Nivel = *nivel
+		case "log-format":
+			cfg.Log.Formato = *formato
+		case "tracing-exporter":
+			cfg.Tracing.Exportador = *exportador
+		case "cache-backend":
+			cfg.Cache.Backend = *backendCache
+		case "redis-url":
+			cfg.Cache.RedisURL = *redisURL
+		}
+	})
+
+	return cfg, errors.Join(errAmbiente, cfg.Validar())
+}
+
+func lerArquivo(caminho string, cfg *Config) error {
+	conteudo, err := os.ReadFile(caminho)
+	if err != nil {
+		return fmt.Errorf("erro ao ler o arquivo de configuração: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(caminho)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(conteudo, cfg)
+	case ".toml":
+		err = toml.Unmarshal(conteudo, cfg)
+	default:
+		return fmt.Errorf("formato de arquivo de configuração não suportado: %s", caminho)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao interpretar o arquivo de configuração %s: %w", caminho, err)
+	}
+	return nil
+}
+
+// aplicarAmbiente lê as variáveis de ambiente. As variáveis DEV_, TEST_ e PROD_ selecionadas por
+// ENVIRONMENT continuam aceitas para os arquivos .env existentes, com precedência menor que as
+// variáveis sem prefixo.
+func aplicarAmbiente(cfg *Config) error {
+	var erros []error
+
+	texto := func(destino *string, nomes ...string) {
+		for _, nome := range nomes {
+			if valor, ok := os.LookupEnv(nome); ok {
+				*destino = valor
+			}
+		}
+	}
+	inteiro := func(destino *int, nomes ...string) {
+		for _, nome := range nomes {
+			if valor, ok := os.LookupEnv(nome); ok {
+				n, err := strconv.Atoi(valor)
+				if err != nil {
+					erros = append(erros, fmt.Errorf("%s deve ser um número inteiro: %q", nome, valor))
+					continue
+				}
+				*destino = n
+			}
+		}
+	}
+	duracao := func(destino *Duracao, nomes ...string) {
+		for _, nome := range nomes {
+			if valor, ok := os.LookupEnv(nome); ok {
+				if err := destino.UnmarshalText([]byte(valor)); err != nil {
+					erros = append(erros, fmt.Errorf("%s deve ser uma duração (ex.: 30s, 5m): %q", nome, valor))
+				}
+			}
+		}
+	}
+
+	booleano := func(destino *bool, nome string) {
+		if valor, ok := os.LookupEnv(nome); ok {
+			b, err := strconv.ParseBool(valor)
+			if err != nil {
+				erros = append(erros, fmt.Errorf("%s deve ser true ou false: %q", nome, valor))
+				return
+			}
+			*destino = b
+		}
+	}
+
+	if prefixo, ok := prefixosAmbiente[os.Getenv("ENVIRONMENT")]; ok {
+		texto(&cfg.BancoDeDados.Host, prefixo+"DATABASE_HOST")
+		inteiro(&cfg.BancoDeDados.Porta, prefixo+"DATABASE_PORT")
+		texto(&cfg.BancoDeDados.Usuario, prefixo+"POSTGRES_USER")
+		texto(&cfg.BancoDeDados.Senha, prefixo+"POSTGRES_PASSWORD")
+		texto(&cfg.BancoDeDados.Nome, prefixo+"POSTGRES_DB")
+	} else if ambiente := os.Getenv("ENVIRONMENT"); ambiente != "" {
+		erros = append(erros, fmt.Errorf("ENVIRONMENT desconhecido: %s", ambiente))
+	}
+
+	inteiro(&cfg.Servidor.Porta, "PORT")
+	duracao(&cfg.Servidor.TempoLeituraCabecalho, "SERVER_READ_HEADER_TIMEOUT")
+	duracao(&cfg.Servidor.TempoLeitura, "SERVER_READ_TIMEOUT")
+	duracao(&cfg.Servidor.TempoEscrita, "SERVER_WRITE_TIMEOUT")
+	duracao(&cfg.Servidor.TempoOcioso, "SERVER_IDLE_TIMEOUT")
+	duracao(&cfg.Servidor.TempoEncerramento, "SERVER_SHUTDOWN_TIMEOUT")
+	duracao(&cfg.Servidor.AtrasoEncerramento, "SERVER_SHUTDOWN_DELAY")
+	duracao(&cfg.Servidor.TempoFinalizador, "SERVER_SHUTDOWN_FINALIZER_TIMEOUT")
+	inteiro(&cfg.GRPC.Porta, "GRPC_PORT")
+	texto(&cfg.BancoDeDados.Driver, "DATABASE_DRIVER")
+	texto(&cfg.BancoDeDados.Arquivo, "DATABASE_FILE")
+	texto(&cfg.BancoDeDados.DSN, "DATABASE_URL")
+	if valor, ok := os.LookupEnv("DATABASE_REPLICA_URLS"); ok {
+		cfg.BancoDeDados.Replicas = separarLista(valor)
+	}
+	duracao(&cfg.BancoDeDados.IntervaloVerificacaoReplicas, "DATABASE_REPLICA_CHECK_INTERVAL")
+	texto(&cfg.BancoDeDados.Host, "DATABASE_HOST")
+	inteiro(&cfg.BancoDeDados.Porta, "DATABASE_PORT")
+	texto(&cfg.BancoDeDados.Usuario, "POSTGRES_USER")
+	texto(&cfg.BancoDeDados.Senha, "POSTGRES_PASSWORD")
+	texto(&cfg.BancoDeDados.Nome, "POSTGRES_DB")
+	texto(&cfg.BancoDeDados.SSLMode, "DATABASE_SSLMODE")
+	inteiro(&cfg.BancoDeDados.MaxConexoesAbertas, "DATABASE_MAX_OPEN_CONNS")
+	inteiro(&cfg.BancoDeDados.MaxConexoesOciosas, "DATABASE_MAX_IDLE_CONNS")
+	duracao(&cfg.BancoDeDados.TempoVidaConexao, "DATABASE_CONN_MAX_LIFETIME")
+	duracao(&cfg.BancoDeDados.TempoOciosoConexao, "DATABASE_CONN_MAX_IDLE_TIME")
+	duracao(&cfg.BancoDeDados.TimeoutConsulta, "DATABASE_QUERY_TIMEOUT")
+	duracao(&cfg.BancoDeDados.TimeoutEscrita, "DATABASE_WRITE_TIMEOUT")
+	duracao(&cfg.BancoDeDados.TimeoutLote, "DATABASE_BATCH_TIMEOUT")
+	texto(&cfg.Log.Nivel, "LOG_LEVEL")
+	texto(&cfg.Log.Formato, "LOG_FORMAT")
+	texto(&cfg.Tracing.Exportador, "TRACING_EXPORTER")
+	texto(&cfg.Cache.Backend, "CACHE_BACKEND")
+	inteiro(&cfg.Cache.Capacidade, "CACHE_CAPACITY")
+	duracao(&cfg.Cache.TTL, "CACHE_TTL")
+	duracao(&cfg.Cache.TTLNegativo, "CACHE_NEGATIVE_TTL")
+	texto(&cfg.Cache.RedisURL, "REDIS_URL")
+	duracao(&cfg.Blocklist.IntervaloSincronizacao, "BLOCKLIST_SYNC_INTERVAL")
+	texto(&cfg.Tenancy.SegredoJWT, "TENANCY_JWT_SECRET")
+	texto(&cfg.LGPD.Sal, "LGPD_HASH_SALT")
+	texto(&cfg.Criptografia.ArquivoChaves, "ENCRYPTION_KEYFILE")
+	booleano(&cfg.Tenancy.AceitarCabecalho, "TENANCY_TRUST_HEADER")
+	booleano(&cfg.Retencao.Simulacao, "RETENTION_DRY_RUN")
+	duracao(&cfg.Retencao.ClientesRemovidos, "RETENTION_REMOVED_CLIENTS")
+	duracao(&cfg.Retencao.ClientesInativos, "RETENTION_INACTIVE_CLIENTS")
+	duracao(&cfg.Retencao.Eventos, "RETENTION_EVENTS")
+	duracao(&cfg.Retencao.Jobs, "RETENTION_JOBS")
+	booleano(&cfg.Agendador.Habilitado, "SCHEDULER_ENABLED")
+	duracao(&cfg.Agendador.IntervaloVerificacao, "SCHEDULER_POLL_INTERVAL")
+	duracao(&cfg.Agendador.Jitter, "SCHEDULER_JITTER")
+	duracao(&cfg.Agendador.EsperaMinima, "SCHEDULER_BACKOFF_MIN")
+	duracao(&cfg.Agendador.EsperaMaxima, "SCHEDULER_BACKOFF_MAX")
+	// SCHEDULER_SCHEDULE_<TAREFA> define a agenda de uma tarefa, como SCHEDULER_SCHEDULE_RETENCAO
+	for _, variavel := range os.Environ() {
+		nome, valor, _ := strings.Cut(variavel, "=")
+		if tarefa, ok := strings.CutPrefix(nome, "SCHEDULER_SCHEDULE_"); ok && tarefa != "" {
+			if cfg.Agendador.Agendas == nil {
+				cfg.Agendador.Agendas = map[string]string{}
+			}
+			cfg.Agendador.Agendas[strings.ToLower(tarefa)] = valor
+		}
+	}
+	if valor, ok := os.LookupEnv("ADMIN_API_KEYS"); ok {
+		cfg.Admin.ChavesAPI = separarLista(valor)
+	}
+
+	return errors.Join(erros...)
+}
+
+var prefixosAmbiente = map[string]string{
+	"development": "DEV_",
+	"test":        "TEST_",
+	"production":  "PROD_",
+}
+
+// Validar verifica todos os campos e retorna os problemas encontrados de uma só vez
+func (c Config) Validar() error {
+	var erros []error
+	invalido := func(formato string, args ...interface{}) {
+		erros = append(erros, fmt.Errorf(formato, args...))
+	}
+
+	if c.Servidor.Porta < 1 || c.Servidor.Porta > 65535 {
+		invalido("servidor.porta inválida: %d", c.Servidor.Porta)
+	}
+	servidor := c.Servidor
+	if servidor.TempoLeituraCabecalho < 0 || servidor.TempoLeitura < 0 || servidor.TempoEscrita < 0 || servidor.TempoOcioso < 0 || servidor.AtrasoEncerramento < 0 {
+		invalido("servidor: os timeouts não podem ser negativos")
+	}
+	if servidor.TempoEncerramento <= 0 {
+		invalido("servidor.tempo_encerramento deve ser maior que zero")
+	}
+	if servidor.TempoFinalizador <= 0 {
+		invalido("servidor.tempo_finalizador deve ser maior que zero")
+	}
+	if c.GRPC.Porta < 0 || c.GRPC.Porta > 65535 {
+		invalido("grpc.porta inválida: %d", c.GRPC.Porta)
+	} else if c.GRPC.Porta == servidor.Porta {
+		invalido("grpc.porta deve ser diferente de servidor.porta")
+	}
+
+	banco := c.BancoDeDados
+	drivers := []string{DriverPostgres, DriverSQLite, DriverMemoria}
+	if !contem(drivers, banco.Driver) {
+		invalido("banco_de_dados.driver inválido: %s, utilize %s", banco.Driver, strings.Join(drivers, ", "))
+	}
+	if banco.Driver == DriverSQLite && banco.Arquivo == "" {
+		invalido("banco_de_dados.arquivo obrigatório quando o driver é sqlite")
+	}
+	if banco.Driver == DriverPostgres && banco.DSN == "" {
+		if banco.Host == "" {
+			invalido("banco_de_dados.host obrigatório quando dsn não é informado")
+		}
+		if banco.Usuario == "" {
+			invalido("banco_de_dados.usuario obrigatório quando dsn não é informado")
+		}
+		if banco.Nome == "" {
+			invalido("banco_de_dados.nome obrigatório quando dsn não é informado")
+		}
+		if banco.Porta < 1 || banco.Porta > 65535 {
+			invalido("banco_de_dados.porta inválida: %d", banco.Porta)
+		}
+		if !contem(modosSSL, banco.SSLMode) {
+			invalido("banco_de_dados.sslmode inválido: %s, utilize %s", banco.SSLMode, strings.Join(modosSSL, ", "))
+		}
+	}
+	if len(banco.Replicas) > 0 && banco.Driver != DriverPostgres {
+		invalido("banco_de_dados.replicas só é suportado com o driver postgres")
+	}
+	for i, replica := range banco.Replicas {
+		if replica == "" {
+			invalido("banco_de_dados.replicas: a réplica %d está vazia", i+1)
+		}
+	}
+	if banco.IntervaloVerificacaoReplicas <= 0 {
+		invalido("banco_de_dados.intervalo_verificacao_replicas deve ser maior que zero")
+	}
+	if banco.MaxConexoesAbertas < 0 || banco.MaxConexoesOciosas < 0 {
+		invalido("banco_de_dados: o tamanho do pool não pode ser negativo")
+	}
+	if banco.MaxConexoesAbertas > 0 && banco.MaxConexoesOciosas > banco.MaxConexoesAbertas {
+		invalido("banco_de_dados.max_conexoes_ociosas não pode ser maior que max_conexoes_abertas")
+	}
+	if banco.TempoVidaConexao < 0 || banco.TempoOciosoConexao < 0 {
+		invalido("banco_de_dados: os tempos de conexão não podem ser negativos")
+	}
+	if banco.TimeoutConsulta <= 0 || banco.TimeoutEscrita <= 0 || banco.TimeoutLote <= 0 {
+		invalido("banco_de_dados: os timeouts de consulta, escrita e lote devem ser maiores que zero")
+	}
+
+	if !contem([]string{"debug", "info", "warn", "error"}, strings.ToLower(c.Log.Nivel)) {
+		invalido("log.nivel inválido: %s", c.Log.Nivel)
+	}
+	if !contem([]string{logging.FormatoJSON, logging.FormatoTexto}, strings.ToLower(c.Log.Formato)) {
+		invalido("log.formato inválido: %s", c.Log.Formato)
+	}
+	if !contem([]string{tracing.ExportadorNenhum, tracing.ExportadorStdout, tracing.ExportadorOTLP}, strings.ToLower(c.Tracing.Exportador)) {
+		invalido("tracing.exportador inválido: %s", c.Tracing.Exportador)
+	}
+
+	backends := []string{cache.BackendNenhum, cache.BackendLRU, cache.BackendRedis}
+	if !contem(backends, c.Cache.Backend) {
+		invalido("cache.backend inválido: %s, utilize %s", c.Cache.Backend, strings.Join(backends, ", "))
+	}
+	if c.Cache.Backend == cache.BackendLRU && c.Cache.Capacidade <= 0 {
+		invalido("cache.capacidade deve ser maior que zero")
+	}
+	if c.Cache.Backend == cache.BackendRedis && c.Cache.RedisURL == "" {
+		invalido("cache.redis_url obrigatório quando o backend é redis")
+	}
+	if c.Cache.Backend != cache.BackendNenhum && (c.Cache.TTL <= 0 || c.Cache.TTLNegativo < 0) {
+		invalido("cache: o ttl deve ser maior que zero e o ttl_negativo não pode ser negativo")
+	}
+	if c.Blocklist.IntervaloSincronizacao <= 0 {
+		invalido("blocklist.intervalo_sincronizacao deve ser maior que zero")
+	}
+
+	ids := map[string]bool{}
+	chaves := map[string]bool{}
+	for i, t := range c.Tenancy.Tenants {
+		if !tenant.IDValido(t.ID) {
+			invalido("tenancy.tenants: id inválido no tenant %d: %q, utilize letras minúsculas, números, - e _", i+1, t.ID)
+		} else if ids[t.ID] {
+			invalido("tenancy.tenants: id repetido: %s", t.ID)
+		}
+		ids[t.ID] = true
+		for _, chave := range t.ChavesAPI {
+			if chave == "" || chaves[chave] {
+				invalido("tenancy.tenants: as chaves de API do tenant %s devem ser preenchidas e únicas", t.ID)
+			}
+			chaves[chave] = true
+		}
+		if t.TimeoutConsulta < 0 || t.TimeoutEscrita < 0 || t.TimeoutLote < 0 {
+			invalido("tenancy.tenants: os timeouts do tenant %s não podem ser negativos", t.ID)
+		}
+	}
+	if len(c.Tenancy.Tenants) > 0 && len(chaves) == 0 && c.Tenancy.SegredoJWT == "" && !c.Tenancy.AceitarCabecalho {
+		invalido("tenancy: informe chaves_api, segredo_jwt ou aceitar_cabecalho para identificar os tenants")
+	}
+	if c.LGPD.Sal != "" && len(c.LGPD.Sal) < 16 {
+		invalido("lgpd.sal deve ter ao menos 16 caracteres")
+	}
+	if c.Criptografia.ArquivoChaves != "" && banco.Driver == DriverMemoria {
+		invalido("criptografia.arquivo_chaves não se aplica ao banco_de_dados.driver memoria")
+	}
+	retencao := c.Retencao
+	if retencao.ClientesRemovidos < 0 || retencao.ClientesInativos < 0 || retencao.Eventos < 0 || retencao.Jobs < 0 {
+		invalido("retencao: os prazos não podem ser negativos")
+	}
+	if retencao.ClientesInativos > 0 && c.LGPD.Sal == "" {
+		invalido("retencao.clientes_inativos exige o lgpd.sal, usado na anonimização")
+	}
+	agenda := c.Agendador
+	if agenda.IntervaloVerificacao <= 0 || agenda.EsperaMinima <= 0 || agenda.EsperaMaxima < agenda.EsperaMinima || agenda.Jitter < 0 {
+		invalido("agendador: intervalo_verificacao e espera_minima devem ser maiores que zero, espera_maxima não pode ser menor que espera_minima e jitter não pode ser negativo")
+	}
+	for _, tarefa := range ordenarChaves(agenda.Agendas) {
+		if expressao := agenda.Agendas[tarefa]; expressao != "" {
+			if _, err := cron.NewAgenda(expressao); err != nil {
+				invalido("agendador.agendas.%s: %v", tarefa, err)
+			}
+		}
+	}
+	for _, chave := range c.Admin.ChavesAPI {
+		if chave == "" || chaves[chave] {
+			invalido("admin.chaves_api: as chaves devem ser preenchidas e diferentes das chaves dos tenants")
+			break
+		}
+	}
+
+	return errors.Join(erros...)
+}
+
+// StringConexao retorna o DSN informado ou o monta a partir dos campos de conexão
+func (b BancoDeDados) StringConexao() string {
+	if b.DSN != "" {
+		return b.DSN
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		valorDSN(b.Host), b.Porta, valorDSN(b.Usuario), valorDSN(b.Senha), valorDSN(b.Nome), b.SSLMode)
+}
+
+// valorDSN coloca entre aspas os valores vazios ou com espaços, como exige o formato chave=valor
+func valorDSN(valor string) string {
+	if valor != "" && !strings.ContainsAny(valor, ` '\`) {
+		return valor
+	}
+	valor = strings.ReplaceAll(valor, `\`, `\\`)
+	valor = strings.ReplaceAll(valor, `'`, `\'`)
+	return "'" + valor + "'"
+}
+
+// Redigida retorna uma cópia da configuração sem a senha e as credenciais, própria para ser exibida
+func (c Config) Redigida() Config {
+	if c.BancoDeDados.Senha != "" {
+		c.BancoDeDados.Senha = logging.Redigido
+	}
+	c.BancoDeDados.DSN = logging.RedigirTexto(c.BancoDeDados.DSN)
+	replicas := make([]string, len(c.BancoDeDados.Replicas))
+	for i, replica := range c.BancoDeDados.Replicas {
+		replicas[i] = logging.RedigirTexto(replica)
+	}
+	c.BancoDeDados.Replicas = replicas
+	c.Cache.RedisURL = logging.RedigirTexto(c.Cache.RedisURL)
+	if c.Tenancy.SegredoJWT != "" {
+		c.Tenancy.SegredoJWT = logging.Redigido
+	}
+	tenants := make([]TenantConfigurado, len(c.Tenancy.Tenants))
+	for i, t := range c.Tenancy.Tenants {
+		chaves := make([]string, len(t.ChavesAPI))
+		for j := range chaves {
+			chaves[j] = logging.Redigido
+		}
+		t.ChavesAPI = chaves
+		tenants[i] = t
+	}
+	c.Tenancy.Tenants = tenants
+	if c.LGPD.Sal != "" {
+		c.LGPD.Sal = logging.Redigido
+	}
+	chavesAdmin := make([]string, len(c.Admin.ChavesAPI))
+	for i := range chavesAdmin {
+		chavesAdmin[i] = logging.Redigido
+	}
+	c.Admin.ChavesAPI = chavesAdmin
+	return c
+}
+
+// YAML serializa a configuração redigida no mesmo formato aceito pelo arquivo de configuração
+func (c Config) YAML() (string, error) {
+	conteudo, err := yaml.Marshal(c.Redigida())
+	return string(conteudo), err
+}
+
+// separarLista divide os valores separados por vírgula, ignorando os espaços e os itens vazios
+func separarLista(valor string) []string {
+	var itens []string
+	for _, item := range strings.Split(valor, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			itens = append(itens, item)
+		}
+	}
+	return itens
+}
+
+// ordenarChaves retorna as chaves do mapa em ordem, para que os erros saiam sempre na mesma ordem
+func ordenarChaves(valores map[string]string) []string {
+	chaves := make([]string, 0, len(valores))
+	for chave := range valores {
+		chaves = append(chaves, chave)
+	}
+	sort.Strings(chaves)
+	return chaves
+}
+
+func contem(valores []string, valor string) bool {
+	for _, v := range valores {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
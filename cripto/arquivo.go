@@ -0,0 +1,105 @@
+package cripto
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ArquivoChaves é o KMS local, com as chaves mestras lidas de um arquivo JSON:
+//
+//	{
+//	  "ativa": "2026-10",
+//	  "chaves": {"2026-10": "<32 bytes em base64>", "2026-01": "<32 bytes em base64>"},
+//	  "hash": "<32 bytes ou mais em base64>"
+//	}
+//
+// As chaves antigas continuam no arquivo até que o job de recriptografia converta os registros
+// cifrados com elas.
+type ArquivoChaves struct {
+	ativa  string
+	chaves map[string][]byte
+	hash   []byte
+}
+
+type conteudoArquivo struct {
+	Ativa  string            `json:"ativa"`
+	Chaves map[string]string `json:"chaves"`
+	Hash   string            `json:"hash"`
+}
+
+// CarregarArquivoChaves lê e valida o arquivo de chaves
+func CarregarArquivoChaves(caminho string) (*ArquivoChaves, error) {
+	dados, err := os.ReadFile(caminho)
+	if err != nil {
+		return nil, err
+	}
+	var conteudo conteudoArquivo
+	if err := json.Unmarshal(dados, &conteudo); err != nil {
+		return nil, fmt.Errorf("arquivo de chaves %s inválido: %w", caminho, err)
+	}
+
+	var erros []error
+	arquivo := &ArquivoChaves{ativa: conteudo.Ativa, chaves: map[string][]byte{}}
+	ids := make([]string, 0, len(conteudo.Chaves))
+	for id := range conteudo.Chaves {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		chave, err := base64.StdEncoding.DecodeString(conteudo.Chaves[id])
+		if err != nil || len(chave) != tamanhoChave {
+			erros = append(erros, fmt.Errorf("a chave %s deve ter %d bytes em base64", id, tamanhoChave))
+			continue
+		}
+		arquivo.chaves[id] = chave
+	}
+	if _, ok := conteudo.Chaves[conteudo.Ativa]; !ok {
+		erros = append(erros, fmt.Errorf("a chave ativa %q não está entre as chaves", conteudo.Ativa))
+	}
+	arquivo.hash, err = base64.StdEncoding.DecodeString(conteudo.Hash)
+	if err != nil || len(arquivo.hash) < tamanhoChave {
+		erros = append(erros, fmt.Errorf("a chave do hash deve ter ao menos %d bytes em base64", tamanhoChave))
+	}
+	if err := errors.Join(erros...); err != nil {
+		return nil, fmt.Errorf("arquivo de chaves %s inválido: %w", caminho, err)
+	}
+	return arquivo, nil
+}
+
+// ChaveHash retorna a chave do hash de busca
+func (a *ArquivoChaves) ChaveHash() []byte {
+	return a.hash
+}
+
+func (a *ArquivoChaves) ChaveAtiva() string {
+	return a.ativa
+}
+
+func (a *ArquivoChaves) CifrarChave(ctx context.Context, chaveDados []byte) (string, []byte, error) {
+	aead, err := novoAEAD(a.chaves[a.ativa])
+	if err != nil {
+		return "", nil, err
+	}
+	cifrada, err := selar(aead, chaveDados, []byte(a.ativa))
+	if err != nil {
+		return "", nil, err
+	}
+	return a.ativa, cifrada, nil
+}
+
+func (a *ArquivoChaves) DecifrarChave(ctx context.Context, chaveID string, cifrada []byte) ([]byte, error) {
+	chave, ok := a.chaves[chaveID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrChaveDesconhecida, chaveID)
+	}
+	aead, err := novoAEAD(chave)
+	if err != nil {
+		return nil, err
+	}
+	return abrir(aead, cifrada, []byte(chaveID))
+}
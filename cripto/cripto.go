@@ -0,0 +1,161 @@
+// Package cripto cifra os dados pessoais antes de gravá-los no banco. Cada registro é cifrado com
+// uma chave de dados própria (envelope), que por sua vez é cifrada pela chave mestra ativa do KMS.
+// Um hash com chave, determinístico, permite buscar o registro sem decifrar a base.
+package cripto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// tamanhoChave é o tamanho, em bytes, das chaves AES-256 usadas nos dados e nas chaves mestras
+const tamanhoChave = 32
+
+// ErrChaveDesconhecida é retornado quando o registro foi cifrado com uma chave mestra que não
+// está mais disponível no KMS
+var ErrChaveDesconhecida = errors.New("chave mestra desconhecida")
+
+// KMS guarda as chaves mestras e cifra as chaves de dados com elas, sem expô-las. ArquivoChaves
+// atende ao desenvolvimento e a instalações simples; um serviço de KMS pode implementar a mesma
+// interface.
+type KMS interface {
+	// ChaveAtiva retorna o identificador da chave mestra usada nas novas cifragens
+	ChaveAtiva() string
+	// CifrarChave cifra a chave de dados com a chave mestra ativa, retornando o identificador dela
+	CifrarChave(ctx context.Context, chaveDados []byte) (chaveID string, cifrada []byte, err error)
+	// DecifrarChave recupera a chave de dados cifrada pela chave mestra chaveID
+	DecifrarChave(ctx context.Context, chaveID string, cifrada []byte) ([]byte, error)
+}
+
+// Envelope são os valores de um registro cifrados com uma chave de dados própria, guardada
+// cifrada pela chave mestra ChaveID
+type Envelope struct {
+	ChaveID      string
+	ChaveCifrada string
+	Valores      []string
+}
+
+// Cifrador cifra e decifra os envelopes e calcula o hash de busca
+type Cifrador struct {
+	kms       KMS
+	chaveHash []byte
+}
+
+// NewCifrador cria o cifrador. A chave do hash não é rotacionada: trocá-la impede que os
+// registros existentes sejam encontrados.
+func NewCifrador(kms KMS, chaveHash []byte) (*Cifrador, error) {
+	if len(chaveHash) < tamanhoChave {
+		return nil, fmt.Errorf("a chave do hash deve ter ao menos %d bytes", tamanhoChave)
+	}
+	return &Cifrador{kms: kms, chaveHash: chaveHash}, nil
+}
+
+// ChaveAtiva retorna a chave mestra usada nas novas cifragens
+func (c *Cifrador) ChaveAtiva() string {
+	return c.kms.ChaveAtiva()
+}
+
+// Hash retorna o HMAC-SHA256 do valor no tenant, em hexadecimal, usado para buscar e garantir a
+// unicidade do valor cifrado
+func (c *Cifrador) Hash(tenantID, valor string) string {
+	mac := hmac.New(sha256.New, c.chaveHash)
+	mac.Write([]byte(tenantID + ":" + valor))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Cifrar cifra os valores com uma nova chave de dados. vinculo entra como dado autenticado, de
+// forma que o envelope só pode ser decifrado no registro para o qual foi gerado.
+func (c *Cifrador) Cifrar(ctx context.Context, vinculo string, valores ...string) (Envelope, error) {
+	chaveDados := make([]byte, tamanhoChave)
+	if _, err := rand.Read(chaveDados); err != nil {
+		return Envelope{}, err
+	}
+	aead, err := novoAEAD(chaveDados)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	envelope := Envelope{Valores: make([]string, len(valores))}
+	for i, valor := range valores {
+		cifrado, err := selar(aead, []byte(valor), []byte(vinculo))
+		if err != nil {
+			return Envelope{}, err
+		}
+		envelope.Valores[i] = base64.StdEncoding.EncodeToString(cifrado)
+	}
+
+	chaveID, chaveCifrada, err := c.kms.CifrarChave(ctx, chaveDados)
+	if err != nil {
+		return Envelope{}, err
+	}
+	envelope.ChaveID = chaveID
+	envelope.ChaveCifrada = base64.StdEncoding.EncodeToString(chaveCifrada)
+	return envelope, nil
+}
+
+// Decifrar recupera os valores do envelope gerado para o mesmo vinculo
+func (c *Cifrador) Decifrar(ctx context.Context, vinculo string, envelope Envelope) ([]string, error) {
+	chaveCifrada, err := base64.StdEncoding.DecodeString(envelope.ChaveCifrada)
+	if err != nil {
+		return nil, fmt.Errorf("chave de dados inválida: %w", err)
+	}
+	chaveDados, err := c.kms.DecifrarChave(ctx, envelope.ChaveID, chaveCifrada)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := novoAEAD(chaveDados)
+	if err != nil {
+		return nil, err
+	}
+
+	valores := make([]string, len(envelope.Valores))
+	for i, valor := range envelope.Valores {
+		cifrado, err := base64.StdEncoding.DecodeString(valor)
+		if err != nil {
+			return nil, fmt.Errorf("valor cifrado inválido: %w", err)
+		}
+		aberto, err := abrir(aead, cifrado, []byte(vinculo))
+		if err != nil {
+			return nil, err
+		}
+		valores[i] = string(aberto)
+	}
+	return valores, nil
+}
+
+func novoAEAD(chave []byte) (cipher.AEAD, error) {
+	bloco, err := aes.NewCipher(chave)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(bloco)
+}
+
+// selar cifra com AES-GCM e um nonce aleatório, colocado antes do texto cifrado
+func selar(aead cipher.AEAD, texto, dadosAutenticados []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, texto, dadosAutenticados), nil
+}
+
+func abrir(aead cipher.AEAD, cifrado, dadosAutenticados []byte) ([]byte, error) {
+	if len(cifrado) < aead.NonceSize() {
+		return nil, errors.New("valor cifrado truncado")
+	}
+	nonce, conteudo := cifrado[:aead.NonceSize()], cifrado[aead.NonceSize():]
+	aberto, err := aead.Open(nil, nonce, conteudo, dadosAutenticados)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao decifrar: %w", err)
+	}
+	return aberto, nil
+}
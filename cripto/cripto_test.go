@@ -0,0 +1,118 @@
+package cripto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chave(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, tamanhoChave))
+}
+
+func escreverArquivo(t *testing.T, conteudo string) string {
+	caminho := filepath.Join(t.TempDir(), "chaves.json")
+	require.NoError(t, os.WriteFile(caminho, []byte(conteudo), 0o600))
+	return caminho
+}
+
+func novoCifrador(t *testing.T, ativa string) *Cifrador {
+	arquivo, err := CarregarArquivoChaves(escreverArquivo(t, `{
+		"ativa": "`+ativa+`",
+		"chaves": {"k1": "`+chave(1)+`", "k2": "`+chave(2)+`"},
+		"hash": "`+chave(9)+`"
+	}`))
+	require.NoError(t, err)
+	cifrador, err := NewCifrador(arquivo, arquivo.ChaveHash())
+	require.NoError(t, err)
+	return cifrador
+}
+
+func TestCifrador(t *testing.T) {
+	ctx := context.Background()
+	cifrador := novoCifrador(t, "k1")
+
+	// Caso de sucesso: Os valores voltam iguais e não aparecem no envelope
+	envelope, err := cifrador.Cifrar(ctx, "registro", "52998224725", "João Silva")
+	require.NoError(t, err)
+	assert.Equal(t, "k1", envelope.ChaveID)
+	assert.NotContains(t, envelope.Valores[0], "52998224725")
+	valores, err := cifrador.Decifrar(ctx, "registro", envelope)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"52998224725", "João Silva"}, valores)
+
+	// Caso de sucesso: Cada cifragem usa uma chave de dados e um nonce novos
+	outro, err := cifrador.Cifrar(ctx, "registro", "52998224725", "João Silva")
+	require.NoError(t, err)
+	assert.NotEqual(t, envelope.Valores, outro.Valores)
+	assert.NotEqual(t, envelope.ChaveCifrada, outro.ChaveCifrada)
+
+	// Caso de erro: O envelope não pode ser movido para outro registro
+	_, err = cifrador.Decifrar(ctx, "outro-registro", envelope)
+	assert.Error(t, err)
+
+	// Caso de erro: Valor adulterado
+	adulterado := envelope
+	adulterado.Valores = []string{outro.Valores[1], envelope.Valores[1]}
+	_, err = cifrador.Decifrar(ctx, "registro", adulterado)
+	assert.Error(t, err)
+}
+
+func TestCifradorRotacao(t *testing.T) {
+	ctx := context.Background()
+	antigo, err := novoCifrador(t, "k1").Cifrar(ctx, "registro", "52998224725")
+	require.NoError(t, err)
+
+	// Caso de sucesso: Com a nova chave ativa, os envelopes da anterior continuam legíveis
+	rotacionado := novoCifrador(t, "k2")
+	assert.Equal(t, "k2", rotacionado.ChaveAtiva())
+	valores, err := rotacionado.Decifrar(ctx, "registro", antigo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"52998224725"}, valores)
+	novo, err := rotacionado.Cifrar(ctx, "registro", "52998224725")
+	require.NoError(t, err)
+	assert.Equal(t, "k2", novo.ChaveID)
+
+	// Caso de erro: Chave removida do arquivo
+	arquivo, err := CarregarArquivoChaves(escreverArquivo(t, `{"ativa": "k2", "chaves": {"k2": "`+chave(2)+`"}, "hash": "`+chave(9)+`"}`))
+	require.NoError(t, err)
+	semChave, err := NewCifrador(arquivo, arquivo.ChaveHash())
+	require.NoError(t, err)
+	_, err = semChave.Decifrar(ctx, "registro", antigo)
+	assert.ErrorIs(t, err, ErrChaveDesconhecida)
+}
+
+func TestHash(t *testing.T) {
+	// Caso de sucesso: Hash determinístico, separado por tenant
+	cifrador := novoCifrador(t, "k1")
+	hash := cifrador.Hash("a", "52998224725")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, novoCifrador(t, "k2").Hash("a", "52998224725"), "A rotação não altera o hash")
+	assert.NotEqual(t, hash, cifrador.Hash("b", "52998224725"))
+
+	// Caso de erro: Chave do hash curta
+	_, err := NewCifrador(nil, []byte("curta"))
+	assert.Error(t, err)
+}
+
+func TestCarregarArquivoChaves(t *testing.T) {
+	// Caso de erro: Arquivo inexistente
+	_, err := CarregarArquivoChaves(filepath.Join(t.TempDir(), "nao-existe.json"))
+	assert.Error(t, err)
+
+	// Caso de erro: Todos os problemas do arquivo informados de uma vez
+	_, err = CarregarArquivoChaves(escreverArquivo(t, `{"ativa": "k3", "chaves": {"k1": "curta"}, "hash": ""}`))
+	assert.ErrorContains(t, err, "a chave k1 deve ter 32 bytes")
+	assert.ErrorContains(t, err, `a chave ativa "k3" não está entre as chaves`)
+	assert.ErrorContains(t, err, "a chave do hash deve ter ao menos 32 bytes")
+
+	// Caso de erro: JSON inválido
+	_, err = CarregarArquivoChaves(escreverArquivo(t, `ativa: k1`))
+	assert.ErrorContains(t, err, "inválido")
+}
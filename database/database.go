@@ -1,67 +1,122 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
+	"time"
 
-	"github.com/Gileno29/clientes-API/utils"
-	"github.com/joho/godotenv"
+	"github.com/Gileno29/clientes-API/config"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/tracing"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func Connect() {
-	var (
-		user     string
-		password string
-		dbname   string
-		host     string
-	)
+// arquivoMemoria é o banco SQLite em memória usado pelos jobs, eventos e webhooks no driver memoria
+const arquivoMemoria = "file:clientes-api?mode=memory&cache=shared"
 
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Erro ao carregar o arquivo .env: ", err)
+// Connect abre o pool de conexões com a configuração informada. O esquema é mantido pelas
+// migrações, veja o pacote migrations.
+func Connect(cfg config.BancoDeDados) error {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		slog.Info("Conectando ao banco de dados", "driver", cfg.Driver, "arquivo", cfg.Arquivo)
+		dialector = sqlite.Open(arquivoSQLite(cfg.Arquivo))
+	case config.DriverMemoria:
+		slog.Info("Conectando ao banco de dados", "driver", cfg.Driver)
+		dialector = sqlite.Open(arquivoMemoria)
+	default:
+		if cfg.DSN != "" {
+			slog.Info("Conectando ao banco de dados", "conexao", cfg.DSN)
+		} else {
+			slog.Info("Conectando ao banco de dados", "host", cfg.Host, "porta", cfg.Porta, "banco", cfg.Nome, "usuario", cfg.Usuario, "sslmode", cfg.SSLMode)
+		}
+		dialector = postgres.Open(cfg.StringConexao())
 	}
 
-	env := os.Getenv("ENVIRONMENT")
-	if env == "" {
-		log.Fatal("Variável de ambiente 'ENVIRONMENT' não definida")
+	db, err := abrir(dialector, cfg, true)
+	if err != nil {
+		return err
 	}
 
-	switch env {
-	case "development":
-		user = os.Getenv("DEV_POSTGRES_USER")
-		password = os.Getenv("DEV_POSTGRES_PASSWORD")
-		dbname = os.Getenv("DEV_POSTGRES_DB")
-		host = os.Getenv("DEV_DATABASE_HOST")
-	case "test":
-		user = os.Getenv("TEST_POSTGRES_USER")
-		password = os.Getenv("TEST_POSTGRES_PASSWORD")
-		dbname = os.Getenv("TEST_POSTGRES_DB")
-		host = os.Getenv("TEST_DATABASE_HOST")
-	case "production":
-		user = os.Getenv("PROD_POSTGRES_USER")
-		password = os.Getenv("PROD_POSTGRES_PASSWORD")
-		dbname = os.Getenv("PROD_POSTGRES_DB")
-		host = os.Getenv("PROD_DATABASE_HOST")
-	default:
-		log.Fatalf("Ambiente desconhecido: %s", env)
+	DB = db
+	return nil
+}
+
+// ConectarReplicas abre um pool para cada réplica configurada e retorna o roteador das leituras.
+// Sem réplicas todas as consultas usam o primário. Uma réplica fora do ar não impede a
+// inicialização: ela começa indisponível e volta a ser usada quando responder à verificação.
+func ConectarReplicas(cfg config.BancoDeDados, primario *gorm.DB) (*Roteador, error) {
+	var replicas []*gorm.DB
+	for i, dsn := range cfg.Replicas {
+		slog.Info("Conectando à réplica", "replica", i+1, "conexao", logging.RedigirTexto(dsn))
+		db, err := abrir(postgres.Open(dsn), cfg, false)
+		if err != nil {
+			for _, aberta := range replicas {
+				if sqlDB, err := aberta.DB(); err == nil {
+					sqlDB.Close()
+				}
+			}
+			return nil, fmt.Errorf("réplica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, db)
 	}
 
-	conectioString := "user=" + user + " dbname=" + dbname + " password=" + password + " host=" + host + " sslmode=disable"
-	fmt.Println(conectioString)
-	db, err := gorm.Open(postgres.Open(conectioString), &gorm.Config{})
+	roteador := NewRoteador(primario, replicas...)
+	roteador.Verificar(context.Background(), time.Duration(cfg.IntervaloVerificacaoReplicas))
+	return roteador, nil
+}
 
+// abrir cria o pool com o logger, o tracing e os limites de conexão configurados. Com
+// exigirConexao falso o banco pode estar fora do ar, a conexão é feita na primeira consulta.
+func abrir(dialector gorm.Dialector, cfg config.BancoDeDados, exigirConexao bool) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logging.NewGormLogger(200 * time.Millisecond),
+		DisableAutomaticPing: !exigirConexao,
+	})
 	if err != nil {
-		panic("Falha ao conectar ao banco de dados")
+		return nil, fmt.Errorf("falha ao conectar ao banco de dados: %w", err)
 	}
-	err = utils.VerificarTabelaClientes(db)
+	// Cada comando executado vira um span filho da operação que o originou
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("falha ao configurar o tracing do banco de dados: %w", err)
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
-		panic("Falha ao criar tabela de clientes")
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxConexoesAbertas)
+	sqlDB.SetMaxIdleConns(cfg.MaxConexoesOciosas)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.TempoVidaConexao))
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.TempoOciosoConexao))
+	if cfg.Driver == config.DriverMemoria {
+		// O banco em memória deixa de existir quando a última conexão é fechada
+		sqlDB.SetMaxIdleConns(max(cfg.MaxConexoesOciosas, 1))
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+	if !exigirConexao {
+		return db, nil
 	}
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("falha ao conectar ao banco de dados: %w", err)
+	}
+	return db, nil
+}
 
-	DB = db
+// arquivoSQLite aguarda os locks de escrita em vez de falhar imediatamente com "database is locked",
+// a não ser que o caminho já traga os próprios parâmetros
+func arquivoSQLite(arquivo string) string {
+	if strings.Contains(arquivo, "?") {
+		return arquivo
+	}
+	return arquivo + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
 }
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// Roteador distribui as consultas somente leitura entre as réplicas saudáveis em round-robin e
+// usa o primário para as escritas, para as leituras sem réplica disponível e para as leituras
+// de uma requisição que já escreveu.
+type Roteador struct {
+	primario *gorm.DB
+	replicas []*replica
+	proxima  atomic.Uint64
+
+	parar     chan struct{}
+	pararOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type replica struct {
+	nome     string
+	db       *gorm.DB
+	saudavel atomic.Bool
+}
+
+// NewRoteador cria o roteador com as réplicas inicialmente consideradas saudáveis
+func NewRoteador(primario *gorm.DB, replicas ...*gorm.DB) *Roteador {
+	r := &Roteador{primario: primario, parar: make(chan struct{})}
+	for i, db := range replicas {
+		rep := &replica{nome: fmt.Sprintf("replica-%d", i+1), db: db}
+		rep.saudavel.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	return r
+}
+
+// Primario retorna a conexão usada para as escritas
+func (r *Roteador) Primario() *gorm.DB {
+	return r.primario
+}
+
+// Ler executa a consulta somente leitura fn em uma réplica. Se a réplica falhar por outro motivo
+// que não o cancelamento do contexto ou um registro não encontrado, ela é marcada como indisponível
+// e a consulta é repetida no primário.
+func (r *Roteador) Ler(ctx context.Context, fn func(db *gorm.DB) error) error {
+	rep := r.escolher(ctx)
+	if rep == nil {
+		registrarDestino(ctx, "primario")
+		return fn(r.primario.WithContext(ctx))
+	}
+
+	registrarDestino(ctx, rep.nome)
+	err := fn(rep.db.WithContext(ctx))
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) || ctx.Err() != nil {
+		return err
+	}
+
+	r.marcar(rep, err)
+	registrarDestino(ctx, "primario")
+	return fn(r.primario.WithContext(ctx))
+}
+
+// escolher retorna a próxima réplica saudável, ou nil se a leitura deve ir para o primário
+func (r *Roteador) escolher(ctx context.Context) *replica {
+	if len(r.replicas) == 0 || Escreveu(ctx) {
+		return nil
+	}
+	inicio := r.proxima.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(inicio+uint64(i))%uint64(len(r.replicas))]
+		if rep.saudavel.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// marcar registra o estado da réplica, logando somente as mudanças
+func (r *Roteador) marcar(rep *replica, err error) {
+	saudavel := err == nil
+	if rep.saudavel.Swap(saudavel) == saudavel {
+		return
+	}
+	if saudavel {
+		slog.Info("Réplica disponível novamente", "replica", rep.nome)
+	} else {
+		slog.Warn("Réplica indisponível, leituras enviadas ao primário", "replica", rep.nome, "erro", err)
+	}
+}
+
+// Iniciar verifica as réplicas a cada intervalo, recolocando em uso as que voltarem a responder
+func (r *Roteador) Iniciar(intervalo time.Duration) {
+	if len(r.replicas) == 0 {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.parar:
+				return
+			case <-ticker.C:
+				r.Verificar(context.Background(), intervalo)
+			}
+		}
+	}()
+}
+
+// Verificar faz o ping de todas as réplicas, cada uma limitada pelo timeout
+func (r *Roteador) Verificar(ctx context.Context, timeout time.Duration) {
+	for _, rep := range r.replicas {
+		r.marcar(rep, ping(ctx, rep.db, timeout))
+	}
+}
+
+func ping(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Encerrar interrompe as verificações e fecha os pools das réplicas
+func (r *Roteador) Encerrar() error {
+	r.pararOnce.Do(func() { close(r.parar) })
+	r.wg.Wait()
+
+	var erros []error
+	for _, rep := range r.replicas {
+		sqlDB, err := rep.db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil {
+			erros = append(erros, fmt.Errorf("%s: %w", rep.nome, err))
+		}
+	}
+	return errors.Join(erros...)
+}
+
+func registrarDestino(ctx context.Context, destino string) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.destino", destino))
+}
+
+type chaveEscrita struct{}
+
+// ComLeituraAposEscrita prepara o contexto da requisição para lembrar se ela já escreveu no banco.
+// A partir da primeira escrita as leituras feitas com o contexto vão para o primário, para que a
+// requisição enxergue as próprias alterações mesmo com atraso na replicação.
+func ComLeituraAposEscrita(ctx context.Context) context.Context {
+	if ctx.Value(chaveEscrita{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, chaveEscrita{}, &atomic.Bool{})
+}
+
+// RegistrarEscrita marca que a requisição do contexto escreveu no banco
+func RegistrarEscrita(ctx context.Context) {
+	if escreveu, ok := ctx.Value(chaveEscrita{}).(*atomic.Bool); ok {
+		escreveu.Store(true)
+	}
+}
+
+// NoPrimario retorna um contexto cujas leituras vão para o primário, sem marcar a requisição de
+// ctx como tendo escrito. Serve para leituras que não podem ver uma réplica atrasada.
+func NoPrimario(ctx context.Context) context.Context {
+	escreveu := &atomic.Bool{}
+	escreveu.Store(true)
+	return context.WithValue(ctx, chaveEscrita{}, escreveu)
+}
+
+// Escreveu indica se a requisição do contexto já escreveu no banco
+func Escreveu(ctx context.Context) bool {
+	escreveu, ok := ctx.Value(chaveEscrita{}).(*atomic.Bool)
+	return ok && escreveu.Load()
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var bancos atomic.Int64
+
+// origem identifica em qual banco a consulta foi executada
+type origem struct {
+	Nome string
+}
+
+// setupBanco cria um banco SQLite em memória exclusivo que responde com o nome informado
+func setupBanco(t *testing.T, nome string) *gorm.DB {
+	arquivo := fmt.Sprintf("file:replicas%d?mode=memory&cache=shared", bancos.Add(1))
+	db, err := gorm.Open(sqlite.Open(arquivo), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&origem{}))
+	require.NoError(t, db.Create(&origem{Nome: nome}).Error)
+	return db
+}
+
+// lerOrigem retorna o nome do banco que atendeu a leitura
+func lerOrigem(t *testing.T, r *Roteador, ctx context.Context) string {
+	t.Helper()
+	var resultado origem
+	require.NoError(t, r.Ler(ctx, func(db *gorm.DB) error {
+		return db.First(&resultado).Error
+	}))
+	return resultado.Nome
+}
+
+func TestRoteador(t *testing.T) {
+	ctx := context.Background()
+
+	// Caso de sucesso: Sem réplicas as leituras vão para o primário
+	t.Run("Usa o primário sem réplicas", func(t *testing.T) {
+		r := NewRoteador(setupBanco(t, "primario"))
+		assert.Equal(t, "primario", lerOrigem(t, r, ctx))
+	})
+
+	// Caso de sucesso: As leituras alternam entre as réplicas
+	t.Run("Distribui as leituras em round-robin", func(t *testing.T) {
+		r := NewRoteador(setupBanco(t, "primario"), setupBanco(t, "replica-1"), setupBanco(t, "replica-2"))
+
+		contagem := map[string]int{}
+		for i := 0; i < 4; i++ {
+			contagem[lerOrigem(t, r, ctx)]++
+		}
+		assert.Equal(t, map[string]int{"replica-1": 2, "replica-2": 2}, contagem)
+	})
+
+	// Caso de sucesso: Depois de escrever a requisição lê do primário
+	t.Run("Lê as próprias escritas no primário", func(t *testing.T) {
+		r := NewRoteador(setupBanco(t, "primario"), setupBanco(t, "replica-1"))
+
+		requisicao := ComLeituraAposEscrita(ctx)
+		assert.Equal(t, "replica-1", lerOrigem(t, r, requisicao))
+
+		RegistrarEscrita(requisicao)
+		assert.True(t, Escreveu(requisicao))
+		assert.Equal(t, "primario", lerOrigem(t, r, requisicao))
+		assert.Equal(t, "replica-1", lerOrigem(t, r, ComLeituraAposEscrita(ctx)), "Outras requisições continuam nas réplicas")
+
+		// Sem o contexto preparado a escrita não é lembrada
+		RegistrarEscrita(ctx)
+		assert.False(t, Escreveu(ctx))
+	})
+
+	// Caso de sucesso: A leitura marcada vai para o primário sem afetar a requisição
+	t.Run("Lê do primário quando pedido", func(t *testing.T) {
+		r := NewRoteador(setupBanco(t, "primario"), setupBanco(t, "replica-1"))
+
+		requisicao := ComLeituraAposEscrita(ctx)
+		assert.Equal(t, "primario", lerOrigem(t, r, NoPrimario(requisicao)))
+		assert.False(t, Escreveu(requisicao))
+		assert.Equal(t, "replica-1", lerOrigem(t, r, requisicao))
+	})
+
+	// Caso de erro: Réplica que falha é retirada de uso e a leitura vai para o primário
+	t.Run("Usa o primário quando a réplica falha", func(t *testing.T) {
+		replica1 := setupBanco(t, "replica-1")
+		r := NewRoteador(setupBanco(t, "primario"), replica1, setupBanco(t, "replica-2"))
+
+		sqlDB, _ := replica1.DB()
+		sqlDB.Close()
+
+		contagem := map[string]int{}
+		for i := 0; i < 4; i++ {
+			contagem[lerOrigem(t, r, ctx)]++
+		}
+		assert.Equal(t, 1, contagem["primario"], "Somente a leitura que falhou deve ir para o primário")
+		assert.Equal(t, 3, contagem["replica-2"])
+		assert.False(t, r.replicas[0].saudavel.Load())
+
+		r.Verificar(ctx, time.Second)
+		assert.False(t, r.replicas[0].saudavel.Load(), "Réplica fechada continua indisponível")
+	})
+
+	// Caso de sucesso: Registro não encontrado não é falha da réplica
+	t.Run("Não troca de banco para registro não encontrado", func(t *testing.T) {
+		r := NewRoteador(setupBanco(t, "primario"), setupBanco(t, "replica-1"))
+
+		chamadas := 0
+		err := r.Ler(ctx, func(db *gorm.DB) error {
+			chamadas++
+			return db.Where("nome = ?", "inexistente").First(&origem{}).Error
+		})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Equal(t, 1, chamadas)
+		assert.True(t, r.replicas[0].saudavel.Load())
+	})
+
+	// Caso de sucesso: A verificação recoloca em uso a réplica que voltou a responder
+	t.Run("Recoloca a réplica que voltou", func(t *testing.T) {
+		r := NewRoteador(setupBanco(t, "primario"), setupBanco(t, "replica-1"))
+		r.replicas[0].saudavel.Store(false)
+		assert.Equal(t, "primario", lerOrigem(t, r, ctx))
+
+		r.Iniciar(10 * time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return r.replicas[0].saudavel.Load()
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "replica-1", lerOrigem(t, r, ctx))
+
+		require.NoError(t, r.Encerrar())
+	})
+}
@@ -15,106 +15,223 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/clientes": {
+        "/admin/agendador/execucoes": {
             "get": {
-                "description": "Retorna uma lista de clientes com suporte a paginação e filtro por nome/razão social",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retorna as execuções das tarefas, as mais recentes primeiro, com a origem (agendada ou manual), a tentativa, a instância que executou, o resultado e o erro.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "clientes"
+                    "agendador"
                 ],
-                "summary": "Lista todos os clientes com paginação",
+                "summary": "Lista o histórico do agendador",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filtrar por nome/razão social",
-                        "name": "razao_social",
-                        "in": "query"
+                        "description": "Chave de API administrativa",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Número da página",
-                        "name": "page",
+                        "type": "string",
+                        "description": "Filtra pelo nome da tarefa",
+                        "name": "tarefa",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "default": 10,
-                        "description": "Número de itens por página",
+                        "default": 50,
+                        "description": "Número máximo de registros",
                         "name": "limit",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Resposta com clientes paginados",
+                        "description": "Execuções",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ListarClientesResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.ExecucaoAgendadaResponse"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Erro na requisição",
+                    "401": {
+                        "description": "Credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "403": {
+                        "description": "Acesso administrativo desabilitado",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
                     "500": {
-                        "description": "Erro interno do servidor",
+                        "description": "Erro ao listar o histórico",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Cadastra um novo cliente no sistema com base nos dados fornecidos.",
-                "consumes": [
+            }
+        },
+        "/admin/agendador/execucoes/{id}": {
+            "get": {
+                "description": "Retorna o status de uma execução, usado para acompanhar a execução solicitada pela API.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "agendador"
+                ],
+                "summary": "Consulta uma execução do agendador",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chave de API administrativa",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Identificador da execução",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Execução encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ExecucaoAgendadaResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "403": {
+                        "description": "Acesso administrativo desabilitado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Execução não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao consultar a execução",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/agendador/tarefas": {
+            "get": {
+                "description": "Retorna as tarefas periódicas registradas com a agenda e o horário previsto da próxima execução. O horário efetivo, com o jitter e a espera após falhas, só é conhecido pela instância líder.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "clientes"
+                    "agendador"
                 ],
-                "summary": "Cadastra um novo cliente",
+                "summary": "Lista as tarefas do agendador",
                 "parameters": [
                     {
-                        "description": "Dados do cliente a ser cadastrado",
-                        "name": "cliente",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Chave de API administrativa",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tarefas registradas",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ClienteResponse"
+                            "$ref": "#/definitions/dtos.TarefasAgendadasResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "403": {
+                        "description": "Acesso administrativo desabilitado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     }
+                }
+            }
+        },
+        "/admin/agendador/tarefas/{nome}/execucoes": {
+            "post": {
+                "description": "Registra a execução como pendente e retorna imediatamente. A instância líder a inicia na próxima verificação, depois do fim de uma execução da mesma tarefa já em andamento. A execução manual não altera a agenda.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "agendador"
+                ],
+                "summary": "Solicita a execução imediata de uma tarefa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chave de API administrativa",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nome da tarefa",
+                        "name": "nome",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Cliente cadastrado com sucesso",
+                    "202": {
+                        "description": "Execução solicitada",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ClienteResponse"
+                            "$ref": "#/definitions/dtos.ExecucaoAgendadaResponse"
                         }
                     },
-                    "400": {
-                        "description": "Erro ao processar a requisição (ex: documento inválido ou JSON inválido)",
+                    "401": {
+                        "description": "Credencial inválida",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
-                    "409": {
-                        "description": "Cliente já cadastrado",
+                    "403": {
+                        "description": "Acesso administrativo desabilitado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Tarefa desconhecida",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
                     "500": {
-                        "description": "Erro interno ao cadastrar o cliente",
+                        "description": "Erro ao solicitar a execução",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
@@ -122,19 +239,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/clientes/{documento}": {
+        "/blocklist/{documento}": {
             "get": {
-                "description": "Verifica se um cliente com o documento (CPF/CNPJ) fornecido está cadastrado na base de dados.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Responde a partir do índice em memória, sem consultar o banco de dados. Documentos não cadastrados retornam blocklist false. Clientes anonimizados continuam em blocklist pelo hash do documento. A versão aumenta a cada alteração do índice e sincronizado_em indica a última comparação completa com o banco.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "clientes"
+                    "blocklist"
                 ],
-                "summary": "Verifica se um cliente está cadastrado",
+                "summary": "Verifica se um documento está em blocklist",
                 "parameters": [
                     {
                         "type": "string",
@@ -146,9 +260,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cliente encontrado",
+                        "description": "Situação do documento",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ClienteResponse"
+                            "$ref": "#/definitions/dtos.BlocklistResponse"
                         }
                     },
                     "400": {
@@ -157,16 +271,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
-                    "404": {
-                        "description": "Cliente não encontrado",
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Atualiza a razão social e/ou o status de blocklist de um cliente com base no documento (CPF/CNPJ) fornecido.",
+            }
+        },
+        "/clientes": {
+            "get": {
+                "description": "Retorna uma lista de clientes com suporte a paginação e filtro por nome/razão social. Com a criptografia habilitada, o filtro por razão social fica indisponível nos tenants acima do limite de busca cifrada.",
                 "consumes": [
                     "application/json"
                 ],
@@ -176,60 +292,64 @@ const docTemplate = `{
                 "tags": [
                     "clientes"
                 ],
-                "summary": "Atualiza os dados de um cliente",
+                "summary": "Lista todos os clientes com paginação",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Documento do cliente (CPF/CNPJ)",
-                        "name": "documento",
-                        "in": "path",
-                        "required": true
+                        "description": "Filtrar por nome/razão social",
+                        "name": "razao_social",
+                        "in": "query"
                     },
                     {
-                        "description": "Dados para atualização",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.AtualizaClienteRequest"
-                        }
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Número da página",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Número de itens por página",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cliente atualizado com sucesso",
+                        "description": "Resposta com clientes paginados",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ClienteResponse"
+                            "$ref": "#/definitions/dtos.ListarClientesResponse"
                         }
                     },
                     "400": {
-                        "description": "Dados inválidos ou parâmetros vazios",
+                        "description": "Erro na requisição ou filtro indisponível",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
-                    "404": {
-                        "description": "Cliente não encontrado",
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
-                    "409": {
-                        "description": "Cliente não identificado",
+                    "500": {
+                        "description": "Erro interno do servidor",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
-                    "500": {
-                        "description": "Erro ao atualizar cliente",
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Deleta um cliente com base no documento (CPF/CNPJ) fornecido.",
+            "post": {
+                "description": "Cadastra um novo cliente no sistema com base nos dados fornecidos.",
                 "consumes": [
                     "application/json"
                 ],
@@ -239,37 +359,51 @@ const docTemplate = `{
                 "tags": [
                     "clientes"
                 ],
-                "summary": "Deleta um cliente",
+                "summary": "Cadastra um novo cliente",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Documento do cliente (CPF/CNPJ)",
-                        "name": "documento",
-                        "in": "path",
-                        "required": true
+                        "description": "Dados do cliente a ser cadastrado",
+                        "name": "cliente",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ClienteResponse"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Cliente deletado com sucesso",
+                    "201": {
+                        "description": "Cliente cadastrado com sucesso",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ResponseSucesso"
+                            "$ref": "#/definitions/dtos.ClienteResponse"
                         }
                     },
                     "400": {
-                        "description": "Documento inválido",
+                        "description": "Erro ao processar a requisição (ex: documento inválido ou JSON inválido)",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
-                    "404": {
-                        "description": "Cliente não encontrado",
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "409": {
+                        "description": "Cliente já cadastrado",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
                     },
                     "500": {
-                        "description": "Erro ao deletar cliente",
+                        "description": "Erro interno ao cadastrar o cliente",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseErro"
                         }
@@ -277,9 +411,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/status": {
-            "get": {
-                "description": "Retorna informações sobre o tempo de atividade (uptime) e o número de requisições atendidas.",
+        "/clientes/lote": {
+            "post": {
+                "description": "Executa em lote operações de cadastro (criar), substituição (atualizar), remoção (remover) e alteração de blocklist (blocklist).\nNo modo tudo_ou_nada todas as operações são executadas em uma única transação e desfeitas se alguma falhar.\nNo modo melhor_esforco cada operação é executada em sua própria transação e o resultado de cada uma é retornado.",
                 "consumes": [
                     "application/json"
                 ],
@@ -287,43 +421,1418 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "suporte"
+                    "clientes"
+                ],
+                "summary": "Processa um lote de operações de clientes",
+                "parameters": [
+                    {
+                        "description": "Operações do lote",
+                        "name": "lote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoteRequest"
+                        }
+                    }
                 ],
-                "summary": "Retorna o status do servidor",
                 "responses": {
                     "200": {
-                        "description": "Status do servidor",
+                        "description": "Lote processado",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ResponseStatus"
+                            "$ref": "#/definitions/dtos.LoteResponse"
                         }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "dtos.AtualizaClienteRequest": {
-            "type": "object",
-            "properties": {
-                "blocklist": {
-                    "type": "boolean"
-                },
-                "razaosocial": {
-                    "type": "string"
-                }
-            }
-        },
-        "dtos.ClienteResponse": {
+                    },
+                    "400": {
+                        "description": "Requisição inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "413": {
+                        "description": "Lote excede o número máximo de operações",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "422": {
+                        "description": "Lote tudo_ou_nada desfeito por falha em uma operação",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoteResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao processar o lote",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/clientes/{documento}": {
+            "get": {
+                "description": "Verifica se um cliente com o documento (CPF/CNPJ) fornecido está cadastrado na base de dados.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Verifica se um cliente está cadastrado",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Documento do cliente (CPF/CNPJ)",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cliente encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ClienteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Documento inválido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Substitui a razão social e o status de blocklist de um cliente com base no documento (CPF/CNPJ) fornecido. Todos os campos são obrigatórios.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Substitui os dados de um cliente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Documento do cliente (CPF/CNPJ)",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dados para atualização",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AtualizaClienteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cliente atualizado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ClienteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos ou campos ausentes",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao atualizar cliente",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deleta um cliente com base no documento (CPF/CNPJ) fornecido.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Deleta um cliente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Documento do cliente (CPF/CNPJ)",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cliente deletado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseSucesso"
+                        }
+                    },
+                    "400": {
+                        "description": "Documento inválido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao deletar cliente",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Aplica um JSON Merge Patch (RFC 7396) ou JSON Patch (RFC 6902) sobre o cliente. O documento resultante é validado com as mesmas regras do cadastro e o documento (CPF/CNPJ) não pode ser alterado.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Atualiza parcialmente os dados de um cliente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Documento do cliente (CPF/CNPJ)",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch ou lista de operações JSON Patch",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cliente atualizado com sucesso",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ClienteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Patch inválido ou documento resultante inválido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "409": {
+                        "description": "Operação test do JSON Patch não confere",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type não suportado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao atualizar cliente",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/eventos": {
+            "get": {
+                "description": "Envia em tempo real os eventos cliente.criado, cliente.atualizado, cliente.removido e blocklist.alterado.\nPara retomar após uma desconexão, informe o id do último evento recebido no header Last-Event-ID ou no parâmetro last_event_id.\nSem esse id somente eventos novos são enviados. Os eventos saem na ordem dos ids: um evento cuja transação ainda não foi confirmada retém os seguintes por até um minuto.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "eventos"
+                ],
+                "summary": "Stream de eventos de clientes (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tipos de evento separados por vírgula",
+                        "name": "tipo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Documento do cliente (CPF/CNPJ)",
+                        "name": "documento",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id do último evento recebido",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id do último evento recebido",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream de eventos",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Filtro inválido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao consultar eventos",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "Consulta os clientes e o histórico de eventos de cada um, com os mesmos filtros e validações da API REST, e cadastra, atualiza ou altera a blocklist dos clientes. O esquema está em graphqlapi/schema.graphql e pode ser obtido por introspecção. Erros de execução são respondidos com status 200 e listados em errors, com o código e o status HTTP equivalente da API REST em extensions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Executa uma consulta ou mutation GraphQL",
+                "parameters": [
+                    {
+                        "description": "Consulta, nome da operação e variáveis",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resultado da operação",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Requisição inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Retorna 200 enquanto o processo estiver respondendo, sem verificar dependências.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suporte"
+                ],
+                "summary": "Verifica se a aplicação está viva",
+                "responses": {
+                    "200": {
+                        "description": "Aplicação viva",
+                        "schema": {
+                            "$ref": "#/definitions/health.Resultado"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Verifica cada dependência registrada (banco de dados, migrações, etc.) e retorna o status e a latência de cada uma.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suporte"
+                ],
+                "summary": "Verifica se a aplicação está pronta para receber tráfego",
+                "responses": {
+                    "200": {
+                        "description": "Aplicação pronta",
+                        "schema": {
+                            "$ref": "#/definitions/health.Resultado"
+                        }
+                    },
+                    "503": {
+                        "description": "Alguma dependência falhou",
+                        "schema": {
+                            "$ref": "#/definitions/health.Resultado"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Retorna o status, o progresso e os erros registrados de um job.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Consulta o andamento de um job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador do job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.JobResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Job não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancela um job pendente ou interrompe um job em execução.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Cancela um job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador do job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Cancelamento solicitado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseSucesso"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Job não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "409": {
+                        "description": "Job já finalizado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao cancelar o job",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/resultado": {
+            "get": {
+                "description": "Retorna o conteúdo gerado por um job concluído, como o CSV de uma exportação.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Baixa o resultado de um job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador do job",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resultado do job",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Job não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "409": {
+                        "description": "Job ainda não concluído",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{tipo}": {
+            "post": {
+                "description": "Cria um job de importacao, exportacao, revalidacao, expurgo ou recriptografia e retorna imediatamente o seu identificador.\nA importação aceita um JSON com a lista de clientes ou um CSV (text/csv) com as colunas documento, razaosocial e blocklist.\nOs demais jobs aceitam os filtros razao_social e somente_blocklist. O expurgo sem filtro remove todos os clientes e exige confirmar: true.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Submete um job para execução em segundo plano",
+                "parameters": [
+                    {
+                        "enum": [
+                            "importacao",
+                            "exportacao",
+                            "revalidacao",
+                            "expurgo",
+                            "recriptografia"
+                        ],
+                        "type": "string",
+                        "description": "Tipo do job",
+                        "name": "tipo",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parâmetros do job",
+                        "name": "parametros",
+                        "in": "body",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job aceito",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Parâmetros inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Tipo de job desconhecido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao submeter o job",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Expõe contadores de requisições por rota, método e status, histogramas de latência, requisições em andamento, estatísticas do pool de conexões do banco e contadores de domínio.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "suporte"
+                ],
+                "summary": "Métricas no formato do Prometheus",
+                "responses": {
+                    "200": {
+                        "description": "Métricas",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/retencao/execucoes": {
+            "get": {
+                "description": "Retorna as regras de retenção aplicadas no tenant, as mais recentes primeiro, com os registros alcançados e os erros. As regras de uma mesma rodada compartilham o campo execucao.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retencao"
+                ],
+                "summary": "Lista a auditoria da política de retenção",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Número máximo de registros",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Regras aplicadas",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.RetencaoRegraResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao listar a auditoria",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/retencao/simulacao": {
+            "post": {
+                "description": "Conta, sem alterar nada, os registros que cada regra da política de retenção alcançaria agora. A simulação é registrada na auditoria como as rodadas agendadas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retencao"
+                ],
+                "summary": "Simula a política de retenção no tenant",
+                "responses": {
+                    "200": {
+                        "description": "Registros alcançados por regra",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RelatorioRetencaoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "503": {
+                        "description": "Política de retenção não configurada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Retorna informações sobre o tempo de atividade (uptime) e o número de requisições atendidas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suporte"
+                ],
+                "summary": "Retorna o status do servidor",
+                "responses": {
+                    "200": {
+                        "description": "Status do servidor",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/titulares/{documento}/anonimizar": {
+            "post": {
+                "description": "Remove de forma irreversível os dados pessoais do cliente. Resta somente o hash do documento, com sal, e a situação da blocklist, que continua valendo em GET /blocklist/{documento}. Os eventos do cliente passam a referenciar o hash e perdem o conteúdo, e o evento cliente.anonimizado registra a operação.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "titulares"
+                ],
+                "summary": "Anonimiza um cliente a pedido do titular",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Documento do titular (CPF/CNPJ)",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cliente anonimizado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AnonimizacaoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Documento inválido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Cliente não encontrado",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao anonimizar cliente",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "503": {
+                        "description": "Anonimização não configurada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/titulares/{documento}/relatorio": {
+            "get": {
+                "description": "Reúne os dados cadastrais, a anonimização, se houver, o histórico de eventos e os envios a webhooks do documento, em JSON ou PDF. Cada relatório gerado é registrado no histórico como o evento titular.relatorio_gerado, que não é enviado aos webhooks nem ao stream de eventos.",
+                "produces": [
+                    "application/json",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "titulares"
+                ],
+                "summary": "Gera o relatório dos dados mantidos sobre um titular",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Documento do titular (CPF/CNPJ)",
+                        "name": "documento",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Formato do relatório",
+                        "name": "formato",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Relatório do titular",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RelatorioTitularResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Documento ou formato inválido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao gerar o relatório",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "504": {
+                        "description": "Tempo limite da operação excedido",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Lista as assinaturas de webhook",
+                "responses": {
+                    "200": {
+                        "description": "Assinaturas cadastradas",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.WebhookResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao listar as assinaturas",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Cadastra uma URL que passa a receber os eventos informados (cliente.criado, cliente.atualizado, cliente.removido, blocklist.alterado). Sem eventos, todos são enviados.\nAs entregas são assinadas com HMAC-SHA256 no header X-Webhook-Assinatura. Se o segredo não for informado ele é gerado e retornado somente nesta resposta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Cadastra uma assinatura de webhook",
+                "parameters": [
+                    {
+                        "description": "Dados da assinatura",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Assinatura cadastrada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Dados inválidos",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao cadastrar a assinatura",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Consulta uma assinatura de webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador da assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Assinatura encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WebhookResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Assinatura não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a assinatura e encerra as entregas pendentes para ela.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Remove uma assinatura de webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador da assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Assinatura removida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseSucesso"
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Assinatura não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao remover a assinatura",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/entregas": {
+            "get": {
+                "description": "Retorna as entregas mais recentes de uma assinatura com o registro de cada tentativa.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Lista as entregas de uma assinatura",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identificador da assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Número máximo de entregas",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entregas da assinatura",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.WebhookEntregaResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Tenant não identificado ou credencial inválida",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "404": {
+                        "description": "Assinatura não encontrada",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    },
+                    "500": {
+                        "description": "Erro ao listar as entregas",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseErro"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dtos.AnonimizacaoResponse": {
+            "type": "object",
+            "properties": {
+                "anonimizado_em": {
+                    "type": "string"
+                },
+                "blocklist": {
+                    "type": "boolean"
+                },
+                "hash_documento": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AtualizaClienteRequest": {
+            "type": "object",
+            "properties": {
+                "blocklist": {
+                    "type": "boolean"
+                },
+                "razaosocial": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.BlocklistResponse": {
+            "type": "object",
+            "properties": {
+                "blocklist": {
+                    "type": "boolean"
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "sincronizado_em": {
+                    "type": "string"
+                },
+                "versao": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.ClienteResponse": {
+            "type": "object",
+            "properties": {
+                "blocklist": {
+                    "type": "boolean"
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "razaosocial": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ClienteTitularResponse": {
+            "type": "object",
+            "properties": {
+                "atualizado_em": {
+                    "type": "string"
+                },
+                "blocklist": {
+                    "type": "boolean"
+                },
+                "criado_em": {
+                    "type": "string"
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "razaosocial": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CompartilhamentoResponse": {
+            "type": "object",
+            "properties": {
+                "assinatura_id": {
+                    "type": "string"
+                },
+                "entregue_em": {
+                    "type": "string"
+                },
+                "evento_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.EventoResponse": {
+            "type": "object",
+            "properties": {
+                "criado_em": {
+                    "type": "string"
+                },
+                "dados": {
+                    "type": "object"
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ExecucaoAgendadaResponse": {
+            "type": "object",
+            "properties": {
+                "agendado_para": {
+                    "type": "string"
+                },
+                "criado_em": {
+                    "type": "string"
+                },
+                "erro": {
+                    "type": "string"
+                },
+                "finalizado_em": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "iniciado_em": {
+                    "type": "string"
+                },
+                "instancia": {
+                    "type": "string"
+                },
+                "origem": {
+                    "type": "string"
+                },
+                "resultado": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tarefa": {
+                    "type": "string"
+                },
+                "tentativa": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.GraphQLErro": {
+            "type": "object",
+            "properties": {
+                "extensions": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "dtos.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "dtos.GraphQLResponse": {
             "type": "object",
             "properties": {
-                "blocklist": {
-                    "type": "boolean"
+                "data": {
+                    "type": "object"
                 },
-                "documento": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.GraphQLErro"
+                    }
+                }
+            }
+        },
+        "dtos.JobResponse": {
+            "type": "object",
+            "properties": {
+                "criado_em": {
                     "type": "string"
                 },
-                "razaosocial": {
+                "erros": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finalizado_em": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "iniciado_em": {
+                    "type": "string"
+                },
+                "processados": {
+                    "type": "integer"
+                },
+                "progresso": {
+                    "type": "number"
+                },
+                "resultado_url": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tipo": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
@@ -347,11 +1856,125 @@ const docTemplate = `{
                 }
             }
         },
+        "dtos.LoteRequest": {
+            "type": "object",
+            "properties": {
+                "modo": {
+                    "type": "string",
+                    "enum": [
+                        "tudo_ou_nada",
+                        "melhor_esforco"
+                    ]
+                },
+                "operacoes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.OperacaoLoteRequest"
+                    }
+                }
+            }
+        },
+        "dtos.LoteResponse": {
+            "type": "object",
+            "properties": {
+                "falhas": {
+                    "type": "integer"
+                },
+                "modo": {
+                    "type": "string"
+                },
+                "resultados": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ResultadoOperacaoLote"
+                    }
+                },
+                "sucesso": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.OperacaoLoteRequest": {
+            "type": "object",
+            "properties": {
+                "blocklist": {
+                    "type": "boolean"
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "operacao": {
+                    "type": "string",
+                    "enum": [
+                        "criar",
+                        "atualizar",
+                        "remover",
+                        "blocklist"
+                    ]
+                },
+                "razaosocial": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.RelatorioRetencaoResponse": {
+            "type": "object",
+            "properties": {
+                "execucao": {
+                    "type": "string"
+                },
+                "regras": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.RetencaoRegraResponse"
+                    }
+                },
+                "simulacao": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.RelatorioTitularResponse": {
+            "type": "object",
+            "properties": {
+                "anonimizacao": {
+                    "$ref": "#/definitions/dtos.AnonimizacaoResponse"
+                },
+                "cliente": {
+                    "$ref": "#/definitions/dtos.ClienteTitularResponse"
+                },
+                "compartilhamentos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.CompartilhamentoResponse"
+                    }
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "eventos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.EventoResponse"
+                    }
+                },
+                "gerado_em": {
+                    "type": "string"
+                }
+            }
+        },
         "dtos.ResponseErro": {
             "type": "object",
             "properties": {
                 "mensagem": {
                     "type": "string"
+                },
+                "trace_id": {
+                    "description": "TraceID identifica o trace das falhas internas, para ser informado em chamados",
+                    "type": "string"
                 }
             }
         },
@@ -373,6 +1996,213 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "dtos.ResultadoOperacaoLote": {
+            "type": "object",
+            "properties": {
+                "cliente": {
+                    "$ref": "#/definitions/dtos.ClienteResponse"
+                },
+                "documento": {
+                    "type": "string"
+                },
+                "indice": {
+                    "type": "integer"
+                },
+                "mensagem": {
+                    "type": "string"
+                },
+                "operacao": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.RetencaoRegraResponse": {
+            "type": "object",
+            "properties": {
+                "afetados": {
+                    "type": "integer"
+                },
+                "erro": {
+                    "type": "string"
+                },
+                "execucao": {
+                    "type": "string"
+                },
+                "finalizado_em": {
+                    "type": "string"
+                },
+                "iniciado_em": {
+                    "type": "string"
+                },
+                "limite": {
+                    "type": "string"
+                },
+                "regra": {
+                    "type": "string"
+                },
+                "simulacao": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.TarefaAgendadaResponse": {
+            "type": "object",
+            "properties": {
+                "agenda": {
+                    "type": "string"
+                },
+                "executando": {
+                    "type": "boolean"
+                },
+                "falhas": {
+                    "type": "integer"
+                },
+                "nome": {
+                    "type": "string"
+                },
+                "proxima_execucao": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.TarefasAgendadasResponse": {
+            "type": "object",
+            "properties": {
+                "lider": {
+                    "description": "Lider indica se a instância que respondeu é a que executa as tarefas",
+                    "type": "boolean"
+                },
+                "tarefas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.TarefaAgendadaResponse"
+                    }
+                }
+            }
+        },
+        "dtos.WebhookEntregaResponse": {
+            "type": "object",
+            "properties": {
+                "entregue_em": {
+                    "type": "string"
+                },
+                "evento_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "proxima_tentativa_em": {
+                    "type": "string"
+                },
+                "registros": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.WebhookTentativaResponse"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tentativas": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.WebhookRequest": {
+            "type": "object",
+            "properties": {
+                "eventos": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "segredo": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "ativo": {
+                    "type": "boolean"
+                },
+                "criado_em": {
+                    "type": "string"
+                },
+                "eventos": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "segredo": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.WebhookTentativaResponse": {
+            "type": "object",
+            "properties": {
+                "criado_em": {
+                    "type": "string"
+                },
+                "duracao_ms": {
+                    "type": "integer"
+                },
+                "erro": {
+                    "type": "string"
+                },
+                "numero": {
+                    "type": "integer"
+                },
+                "status_http": {
+                    "type": "integer"
+                }
+            }
+        },
+        "health.Componente": {
+            "type": "object",
+            "properties": {
+                "erro": {
+                    "type": "string"
+                },
+                "latencia_ms": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Resultado": {
+            "type": "object",
+            "properties": {
+                "componentes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Componente"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -1,11 +1,38 @@
 package dtos
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type ClienteResponse struct {
 	Documento   string `json:"documento"`
 	RazaoSocial string `json:"razaosocial"`
 	Blocklist   bool   `json:"blocklist"`
 }
 
+type BlocklistAlteradoPayload struct {
+	Documento         string `json:"documento"`
+	Blocklist         bool   `json:"blocklist"`
+	BlocklistAnterior bool   `json:"blocklist_anterior"`
+}
+
+type ClienteAnonimizadoPayload struct {
+	HashDocumento string `json:"hash_documento"`
+	Blocklist     bool   `json:"blocklist"`
+}
+
+type RelatorioTitularPayload struct {
+	Formato string `json:"formato"`
+}
+
+type BlocklistResponse struct {
+	Documento      string    `json:"documento"`
+	Blocklist      bool      `json:"blocklist"`
+	Versao         uint64    `json:"versao"`
+	SincronizadoEm time.Time `json:"sincronizado_em"`
+}
+
 type ListarClientesResponse struct {
 	Page     int               `json:"page"`
 	Limit    int               `json:"limit"`
@@ -20,6 +47,8 @@ type ResponseStatus struct {
 
 type ResponseErro struct {
 	Mensagem string `json:"mensagem"`
+	// TraceID identifica o trace das falhas internas, para ser informado em chamados
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 type ResponseSucesso struct {
@@ -30,3 +59,184 @@ type AtualizaClienteRequest struct {
 	RazaoSocial *string `json:"razaosocial"`
 	Blocklist   *bool   `json:"blocklist"`
 }
+
+type OperacaoLoteRequest struct {
+	Operacao    string  `json:"operacao" enums:"criar,atualizar,remover,blocklist"`
+	Documento   string  `json:"documento"`
+	RazaoSocial *string `json:"razaosocial,omitempty"`
+	Blocklist   *bool   `json:"blocklist,omitempty"`
+}
+
+type LoteRequest struct {
+	Modo      string                `json:"modo" enums:"tudo_ou_nada,melhor_esforco"`
+	Operacoes []OperacaoLoteRequest `json:"operacoes"`
+}
+
+type ResultadoOperacaoLote struct {
+	Indice    int              `json:"indice"`
+	Operacao  string           `json:"operacao"`
+	Documento string           `json:"documento"`
+	Status    int              `json:"status"`
+	Mensagem  string           `json:"mensagem,omitempty"`
+	Cliente   *ClienteResponse `json:"cliente,omitempty"`
+}
+
+type LoteResponse struct {
+	Modo       string                  `json:"modo"`
+	Total      int                     `json:"total"`
+	Sucesso    int                     `json:"sucesso"`
+	Falhas     int                     `json:"falhas"`
+	Resultados []ResultadoOperacaoLote `json:"resultados"`
+}
+
+type JobResponse struct {
+	ID           string     `json:"id"`
+	Tipo         string     `json:"tipo"`
+	Status       string     `json:"status"`
+	Progresso    float64    `json:"progresso"`
+	Processados  int        `json:"processados"`
+	Total        int        `json:"total"`
+	Erros        []string   `json:"erros,omitempty"`
+	ResultadoURL string     `json:"resultado_url,omitempty"`
+	CriadoEm     time.Time  `json:"criado_em"`
+	IniciadoEm   *time.Time `json:"iniciado_em,omitempty"`
+	FinalizadoEm *time.Time `json:"finalizado_em,omitempty"`
+}
+
+type EventoResponse struct {
+	ID        uint            `json:"id"`
+	Tipo      string          `json:"tipo"`
+	Documento string          `json:"documento"`
+	CriadoEm  time.Time       `json:"criado_em"`
+	Dados     json.RawMessage `json:"dados" swaggertype:"object"`
+}
+
+type WebhookRequest struct {
+	URL     string   `json:"url"`
+	Eventos []string `json:"eventos"`
+	Segredo string   `json:"segredo,omitempty"`
+}
+
+type WebhookResponse struct {
+	ID       string    `json:"id"`
+	URL      string    `json:"url"`
+	Eventos  []string  `json:"eventos"`
+	Segredo  string    `json:"segredo,omitempty"`
+	Ativo    bool      `json:"ativo"`
+	CriadoEm time.Time `json:"criado_em"`
+}
+
+type WebhookTentativaResponse struct {
+	Numero     int       `json:"numero"`
+	StatusHTTP int       `json:"status_http,omitempty"`
+	Erro       string    `json:"erro,omitempty"`
+	DuracaoMs  int64     `json:"duracao_ms"`
+	CriadoEm   time.Time `json:"criado_em"`
+}
+
+type WebhookEntregaResponse struct {
+	ID                 uint                       `json:"id"`
+	EventoID           uint                       `json:"evento_id"`
+	Status             string                     `json:"status"`
+	Tentativas         int                        `json:"tentativas"`
+	ProximaTentativaEm *time.Time                 `json:"proxima_tentativa_em,omitempty"`
+	EntregueEm         *time.Time                 `json:"entregue_em,omitempty"`
+	Registros          []WebhookTentativaResponse `json:"registros"`
+}
+
+type ClienteTitularResponse struct {
+	Documento    string    `json:"documento"`
+	RazaoSocial  string    `json:"razaosocial"`
+	Blocklist    bool      `json:"blocklist"`
+	CriadoEm     time.Time `json:"criado_em"`
+	AtualizadoEm time.Time `json:"atualizado_em"`
+}
+
+type AnonimizacaoResponse struct {
+	HashDocumento string    `json:"hash_documento"`
+	Blocklist     bool      `json:"blocklist"`
+	AnonimizadoEm time.Time `json:"anonimizado_em"`
+}
+
+type CompartilhamentoResponse struct {
+	EventoID     uint       `json:"evento_id"`
+	AssinaturaID string     `json:"assinatura_id"`
+	URL          string     `json:"url,omitempty"`
+	Status       string     `json:"status"`
+	EntregueEm   *time.Time `json:"entregue_em,omitempty"`
+}
+
+type RelatorioTitularResponse struct {
+	Documento         string                     `json:"documento"`
+	GeradoEm          time.Time                  `json:"gerado_em"`
+	Cliente           *ClienteTitularResponse    `json:"cliente"`
+	Anonimizacao      *AnonimizacaoResponse      `json:"anonimizacao,omitempty"`
+	Eventos           []EventoResponse           `json:"eventos"`
+	Compartilhamentos []CompartilhamentoResponse `json:"compartilhamentos"`
+}
+
+type RetencaoRegraResponse struct {
+	Execucao     string    `json:"execucao"`
+	Regra        string    `json:"regra"`
+	Simulacao    bool      `json:"simulacao"`
+	Limite       time.Time `json:"limite"`
+	Afetados     int64     `json:"afetados"`
+	Erro         string    `json:"erro,omitempty"`
+	IniciadoEm   time.Time `json:"iniciado_em"`
+	FinalizadoEm time.Time `json:"finalizado_em"`
+}
+
+type RelatorioRetencaoResponse struct {
+	Execucao  string                  `json:"execucao"`
+	Simulacao bool                    `json:"simulacao"`
+	Regras    []RetencaoRegraResponse `json:"regras"`
+}
+
+type TarefaAgendadaResponse struct {
+	Nome            string     `json:"nome"`
+	Agenda          string     `json:"agenda,omitempty"`
+	ProximaExecucao *time.Time `json:"proxima_execucao,omitempty"`
+	Falhas          int        `json:"falhas"`
+	Executando      bool       `json:"executando"`
+}
+
+type TarefasAgendadasResponse struct {
+	// Lider indica se a instância que respondeu é a que executa as tarefas
+	Lider   bool                     `json:"lider"`
+	Tarefas []TarefaAgendadaResponse `json:"tarefas"`
+}
+
+type ExecucaoAgendadaResponse struct {
+	ID           uint       `json:"id"`
+	Tarefa       string     `json:"tarefa"`
+	Origem       string     `json:"origem"`
+	Status       string     `json:"status"`
+	AgendadoPara *time.Time `json:"agendado_para,omitempty"`
+	Tentativa    int        `json:"tentativa"`
+	Instancia    string     `json:"instancia,omitempty"`
+	Resultado    string     `json:"resultado,omitempty"`
+	Erro         string     `json:"erro,omitempty"`
+	CriadoEm     time.Time  `json:"criado_em"`
+	IniciadoEm   *time.Time `json:"iniciado_em,omitempty"`
+	FinalizadoEm *time.Time `json:"finalizado_em,omitempty"`
+}
+
+// GraphQLRequest segue o formato usual das requisições GraphQL por HTTP
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse documenta a resposta GraphQL. Os erros trazem em extensions o codigo e o status
+// HTTP que a API REST responderia.
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	Errors []GraphQLErro   `json:"errors,omitempty"`
+}
+
+type GraphQLErro struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
@@ -0,0 +1,94 @@
+package graphqlapi
+
+import (
+	"context"
+
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// carregadores agrupa as buscas dos resolvers de uma requisição. O cache dos dataloaders vale
+// somente durante a requisição e as mutations limpam as chaves que alteram.
+type carregadores struct {
+	// clientes carrega o cliente do documento, ou nil se ele não estiver cadastrado
+	clientes *dataloader.Loader[string, *models.Cliente]
+	// historico carrega os eventos do documento em ordem
+	historico *dataloader.Loader[string, []models.Evento]
+}
+
+type chaveCarregadores struct{}
+
+func comCarregadores(ctx context.Context, c *carregadores) context.Context {
+	return context.WithValue(ctx, chaveCarregadores{}, c)
+}
+
+// carregadoresDe retorna os dataloaders da requisição, criados por Servico.Executar
+func carregadoresDe(ctx context.Context) *carregadores {
+	return ctx.Value(chaveCarregadores{}).(*carregadores)
+}
+
+func (s *Servico) novosCarregadores() *carregadores {
+	return &carregadores{
+		clientes:  dataloader.NewBatchedLoader(s.carregarClientes, dataloader.WithBatchCapacity[string, *models.Cliente](LimiteMaximo)),
+		historico: dataloader.NewBatchedLoader(s.carregarHistorico, dataloader.WithBatchCapacity[string, []models.Evento](LimiteMaximo)),
+	}
+}
+
+// limpar descarta o que foi carregado do documento, que acabou de ser alterado
+func (c *carregadores) limpar(ctx context.Context, documento string) {
+	c.clientes.Clear(ctx, documento)
+	c.historico.Clear(ctx, documento)
+}
+
+// carregarClientes busca os clientes de todos os documentos do lote em uma consulta
+func (s *Servico) carregarClientes(ctx context.Context, documentos []string) []*dataloader.Result[*models.Cliente] {
+	resultados := make([]*dataloader.Result[*models.Cliente], len(documentos))
+
+	ctx, cancel := s.prazoConsulta(ctx)
+	defer cancel()
+	clientes, err := s.clientes.FindByDocumentos(ctx, documentos)
+	if err != nil {
+		err = erroBanco(ctx, err, "Erro ao buscar clientes")
+		for i := range resultados {
+			resultados[i] = &dataloader.Result[*models.Cliente]{Error: err}
+		}
+		return resultados
+	}
+
+	porDocumento := make(map[string]*models.Cliente, len(clientes))
+	for i := range clientes {
+		porDocumento[clientes[i].Documento] = &clientes[i]
+	}
+	for i, documento := range documentos {
+		resultados[i] = &dataloader.Result[*models.Cliente]{Data: porDocumento[documento]}
+	}
+	return resultados
+}
+
+// carregarHistorico busca os eventos de todos os documentos do lote em uma consulta
+func (s *Servico) carregarHistorico(ctx context.Context, documentos []string) []*dataloader.Result[[]models.Evento] {
+	resultados := make([]*dataloader.Result[[]models.Evento], len(documentos))
+
+	tenantID, err := tenant.ID(ctx)
+	var eventos []models.Evento
+	if err == nil {
+		eventos, err = s.eventos.ListarPorDocumentos(tenantID, documentos)
+	}
+	if err != nil {
+		err = erroBanco(ctx, err, "Erro ao buscar o histórico")
+		for i := range resultados {
+			resultados[i] = &dataloader.Result[[]models.Evento]{Error: err}
+		}
+		return resultados
+	}
+
+	porDocumento := make(map[string][]models.Evento, len(documentos))
+	for _, evento := range eventos {
+		porDocumento[evento.Documento] = append(porDocumento[evento.Documento], evento)
+	}
+	for i, documento := range documentos {
+		resultados[i] = &dataloader.Result[[]models.Evento]{Data: porDocumento[documento]}
+	}
+	return resultados
+}
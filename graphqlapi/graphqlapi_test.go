@@ -0,0 +1,250 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Gileno29/clientes-API/migrations"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var bancos atomic.Int64
+
+// clientesContador conta as consultas que chegam ao repositório de clientes
+type clientesContador struct {
+	repository.ClienteRepository
+	buscas atomic.Int64
+}
+
+func (r *clientesContador) FindByDocumento(ctx context.Context, documento string) (*models.Cliente, error) {
+	r.buscas.Add(1)
+	return r.ClienteRepository.FindByDocumento(ctx, documento)
+}
+
+func (r *clientesContador) FindByDocumentos(ctx context.Context, documentos []string) ([]models.Cliente, error) {
+	r.buscas.Add(1)
+	return r.ClienteRepository.FindByDocumentos(ctx, documentos)
+}
+
+// eventosContador conta as consultas que chegam ao repositório de eventos
+type eventosContador struct {
+	repository.EventoRepository
+	buscas atomic.Int64
+}
+
+func (r *eventosContador) ListarPorDocumentos(tenantID string, documentos []string) ([]models.Evento, error) {
+	r.buscas.Add(1)
+	return r.EventoRepository.ListarPorDocumentos(tenantID, documentos)
+}
+
+// setupServico cria o serviço sobre um banco SQLite exclusivo, com o esquema das migrações
+func setupServico(t *testing.T) (*Servico, *clientesContador, *eventosContador) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:graphqlapi%d?mode=memory&cache=shared", bancos.Add(1))), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	migrador, err := migrations.NewMigrador(db)
+	require.NoError(t, err)
+	_, err = migrador.Subir(context.Background(), 0)
+	require.NoError(t, err)
+
+	clientes := &clientesContador{ClienteRepository: repository.NewClienteRepository(db)}
+	eventos := &eventosContador{EventoRepository: repository.NewEventoRepository(db)}
+	return NewServico(clientes, eventos), clientes, eventos
+}
+
+// executar executa a operação no tenant padrão e decodifica os dados em resultado
+func executar(t *testing.T, s *Servico, consulta string, variaveis map[string]interface{}, resultado interface{}) []map[string]interface{} {
+	t.Helper()
+	resposta := s.Executar(tenant.ComID(context.Background(), tenant.Padrao), consulta, "", variaveis)
+	conteudo, err := json.Marshal(resposta)
+	require.NoError(t, err)
+
+	var decodificada struct {
+		Data   json.RawMessage          `json:"data"`
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(conteudo, &decodificada))
+	if resultado != nil && len(decodificada.Data) > 0 && string(decodificada.Data) != "null" {
+		require.NoError(t, json.Unmarshal(decodificada.Data, resultado))
+	}
+	return decodificada.Errors
+}
+
+func TestMutations(t *testing.T) {
+	s, _, _ := setupServico(t)
+
+	// Caso de sucesso: Cadastra, atualiza e altera a blocklist do cliente
+	t.Run("Cadastra e altera o cliente", func(t *testing.T) {
+		var criado struct {
+			CriarCliente struct {
+				Documento   string `json:"documento"`
+				RazaoSocial string `json:"razaoSocial"`
+				Blocklist   bool   `json:"blocklist"`
+			} `json:"criarCliente"`
+		}
+		erros := executar(t, s, `mutation { criarCliente(input: {documento: "529.982.247-25", razaoSocial: "João Silva"}) { documento razaoSocial blocklist } }`, nil, &criado)
+		require.Empty(t, erros)
+		assert.Equal(t, "52998224725", criado.CriarCliente.Documento)
+		assert.False(t, criado.CriarCliente.Blocklist)
+
+		var atualizado struct {
+			AtualizarCliente struct {
+				RazaoSocial string `json:"razaoSocial"`
+				Blocklist   bool   `json:"blocklist"`
+			} `json:"atualizarCliente"`
+		}
+		erros = executar(t, s, `mutation($doc: String!) { atualizarCliente(documento: $doc, input: {razaoSocial: "João da Silva"}) { razaoSocial blocklist } }`,
+			map[string]interface{}{"doc": "52998224725"}, &atualizado)
+		require.Empty(t, erros)
+		assert.Equal(t, "João da Silva", atualizado.AtualizarCliente.RazaoSocial)
+		assert.False(t, atualizado.AtualizarCliente.Blocklist, "Campos não informados são mantidos")
+
+		var bloqueado struct {
+			AlterarBlocklist struct {
+				Blocklist bool `json:"blocklist"`
+			} `json:"alterarBlocklist"`
+		}
+		erros = executar(t, s, `mutation { alterarBlocklist(documento: "52998224725", blocklist: true) { blocklist } }`, nil, &bloqueado)
+		require.Empty(t, erros)
+		assert.True(t, bloqueado.AlterarBlocklist.Blocklist)
+	})
+
+	// Caso de erro: Os erros trazem o código e o status da API REST
+	t.Run("Mapeia os erros", func(t *testing.T) {
+		casos := []struct {
+			operacao string
+			mensagem string
+			codigo   string
+			status   float64
+		}{
+			{`mutation { criarCliente(input: {documento: "123", razaoSocial: "Empresa"}) { documento } }`, "Documento inválido", CodigoEntradaInvalida, 400},
+			{`mutation { criarCliente(input: {documento: "33000167000101", razaoSocial: ""}) { documento } }`, "Razão social inválida", CodigoEntradaInvalida, 400},
+			{`mutation { criarCliente(input: {documento: "52998224725", razaoSocial: "Outro"}) { documento } }`, "Cliente já cadastrado", CodigoConflito, 409},
+			{`mutation { atualizarCliente(documento: "52998224725", input: {}) { documento } }`, "Nenhum campo informado", CodigoEntradaInvalida, 400},
+			{`mutation { alterarBlocklist(documento: "11144477735", blocklist: true) { documento } }`, "Cliente não encontrado", CodigoNaoEncontrado, 404},
+		}
+		for _, caso := range casos {
+			erros := executar(t, s, caso.operacao, nil, nil)
+			require.Len(t, erros, 1, caso.operacao)
+			assert.Equal(t, caso.mensagem, erros[0]["message"])
+			extensoes, _ := erros[0]["extensions"].(map[string]interface{})
+			assert.Equal(t, caso.codigo, extensoes["codigo"])
+			assert.Equal(t, caso.status, extensoes["status"])
+		}
+	})
+}
+
+func TestConsultas(t *testing.T) {
+	s, contadorClientes, contadorEventos := setupServico(t)
+	documentos := []string{"52998224725", "11144477735", "33000167000101"}
+	for _, documento := range documentos {
+		erros := executar(t, s, `mutation($doc: String!) { criarCliente(input: {documento: $doc, razaoSocial: "Cliente"}) { documento } }`,
+			map[string]interface{}{"doc": documento}, nil)
+		require.Empty(t, erros)
+	}
+	erros := executar(t, s, `mutation { alterarBlocklist(documento: "52998224725", blocklist: true) { documento } }`, nil, nil)
+	require.Empty(t, erros)
+
+	// Caso de sucesso: O histórico de todos os clientes da página é buscado em uma única consulta
+	t.Run("Lista os clientes com o histórico", func(t *testing.T) {
+		contadorEventos.buscas.Store(0)
+		var resultado struct {
+			Clientes struct {
+				Total    int `json:"total"`
+				Clientes []struct {
+					Documento string `json:"documento"`
+					Historico []struct {
+						Tipo  string `json:"tipo"`
+						Dados string `json:"dados"`
+					} `json:"historico"`
+				} `json:"clientes"`
+			} `json:"clientes"`
+		}
+		erros := executar(t, s, `{ clientes(limit: 10) { total clientes { documento historico { tipo dados } } } }`, nil, &resultado)
+		require.Empty(t, erros)
+		assert.Equal(t, 3, resultado.Clientes.Total)
+		require.Len(t, resultado.Clientes.Clientes, 3)
+		for _, cliente := range resultado.Clientes.Clientes {
+			if cliente.Documento == "52998224725" {
+				require.Len(t, cliente.Historico, 3)
+				assert.Equal(t, models.EventoClienteCriado, cliente.Historico[0].Tipo, "Do mais antigo ao mais recente")
+				assert.Equal(t, models.EventoBlocklistAlterado, cliente.Historico[2].Tipo)
+				continue
+			}
+			require.Len(t, cliente.Historico, 1)
+			assert.Equal(t, models.EventoClienteCriado, cliente.Historico[0].Tipo)
+			assert.Contains(t, cliente.Historico[0].Dados, cliente.Documento)
+		}
+		assert.Equal(t, int64(1), contadorEventos.buscas.Load(), "O histórico deve ser buscado em lote")
+
+		var filtrado struct {
+			Clientes struct {
+				Clientes []struct {
+					Historico []struct {
+						Tipo string `json:"tipo"`
+					} `json:"historico"`
+				} `json:"clientes"`
+			} `json:"clientes"`
+		}
+		erros = executar(t, s, `{ clientes { clientes { historico(tipos: ["blocklist.alterado"]) { tipo } } } }`, nil, &filtrado)
+		require.Empty(t, erros)
+		var tipos []string
+		for _, cliente := range filtrado.Clientes.Clientes {
+			for _, evento := range cliente.Historico {
+				tipos = append(tipos, evento.Tipo)
+			}
+		}
+		assert.Equal(t, []string{models.EventoBlocklistAlterado}, tipos, "Somente os eventos dos tipos informados")
+	})
+
+	// Caso de sucesso: Buscas de vários documentos na mesma operação viram uma consulta
+	t.Run("Agrupa as buscas por documento", func(t *testing.T) {
+		contadorClientes.buscas.Store(0)
+		var resultado struct {
+			A *struct {
+				Blocklist bool `json:"blocklist"`
+			} `json:"a"`
+			B *struct {
+				Documento string `json:"documento"`
+			} `json:"b"`
+			Lote []*struct {
+				Documento string `json:"documento"`
+			} `json:"lote"`
+		}
+		erros := executar(t, s, `{
+			a: cliente(documento: "529.982.247-25") { blocklist }
+			b: cliente(documento: "86405508838") { documento }
+			lote: clientesPorDocumento(documentos: ["11144477735", "86405508838", "33000167000101"]) { documento }
+		}`, nil, &resultado)
+		require.Empty(t, erros)
+		require.NotNil(t, resultado.A)
+		assert.True(t, resultado.A.Blocklist)
+		assert.Nil(t, resultado.B, "Documento não cadastrado resulta em null")
+		require.Len(t, resultado.Lote, 3)
+		assert.Equal(t, "11144477735", resultado.Lote[0].Documento)
+		assert.Nil(t, resultado.Lote[1])
+		assert.Equal(t, "33000167000101", resultado.Lote[2].Documento)
+		assert.Equal(t, int64(1), contadorClientes.buscas.Load(), "As buscas devem ser feitas em lote")
+	})
+
+	// Caso de erro: Paginação ou documento inválidos
+	t.Run("Valida os argumentos", func(t *testing.T) {
+		erros := executar(t, s, `{ clientes(limit: 1000) { total } }`, nil, nil)
+		require.Len(t, erros, 1)
+		assert.Contains(t, erros[0]["message"], "Paginação inválida")
+
+		erros = executar(t, s, `{ cliente(documento: "123") { documento } }`, nil, nil)
+		require.Len(t, erros, 1)
+		assert.Equal(t, "Documento inválido", erros[0]["message"])
+	})
+}
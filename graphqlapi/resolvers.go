@@ -0,0 +1,292 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/utils"
+	"github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+// Códigos informados na extensão codigo dos erros, acompanhados do status HTTP equivalente
+const (
+	CodigoEntradaInvalida = "ENTRADA_INVALIDA"
+	CodigoNaoEncontrado   = "NAO_ENCONTRADO"
+	CodigoConflito        = "CONFLITO"
+	CodigoErroInterno     = "ERRO_INTERNO"
+	CodigoTempoEsgotado   = "TEMPO_ESGOTADO"
+)
+
+// erroResolver é o erro de um resolver, com a mensagem da API REST e, nas extensões, o código e
+// o status HTTP que a API REST responderia
+type erroResolver struct {
+	mensagem string
+	codigo   string
+	status   int
+}
+
+func (e *erroResolver) Error() string {
+	return e.mensagem
+}
+
+func (e *erroResolver) Extensions() map[string]interface{} {
+	return map[string]interface{}{"codigo": e.codigo, "status": e.status}
+}
+
+func entradaInvalida(mensagem, tipo string) error {
+	metrics.FalhaValidacao(tipo)
+	return &erroResolver{mensagem: mensagem, codigo: CodigoEntradaInvalida, status: http.StatusBadRequest}
+}
+
+var (
+	errNaoEncontrado = &erroResolver{mensagem: "Cliente não encontrado", codigo: CodigoNaoEncontrado, status: http.StatusNotFound}
+	errJaCadastrado  = &erroResolver{mensagem: "Cliente já cadastrado", codigo: CodigoConflito, status: http.StatusConflict}
+)
+
+// erroBanco registra o erro e retorna TEMPO_ESGOTADO para operações interrompidas pelo prazo ou
+// cancelamento, como o 504 da API REST, e ERRO_INTERNO nos demais casos
+func erroBanco(ctx context.Context, err error, mensagem string) error {
+	logging.FromContext(ctx).Error(mensagem, "erro", err)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return &erroResolver{mensagem: "Tempo limite da operação excedido", codigo: CodigoTempoEsgotado, status: http.StatusGatewayTimeout}
+	}
+	return &erroResolver{mensagem: mensagem, codigo: CodigoErroInterno, status: http.StatusInternalServerError}
+}
+
+// resolvedor é a raiz das queries e mutations
+type resolvedor struct {
+	s *Servico
+}
+
+func (r *resolvedor) Cliente(ctx context.Context, args struct{ Documento string }) (*clienteResolver, error) {
+	documento := utils.ClearNumber(args.Documento)
+	if !utils.ValidaDocumento(documento) {
+		return nil, entradaInvalida("Documento inválido", metrics.ValidacaoDocumento)
+	}
+	cliente, err := carregadoresDe(ctx).clientes.Load(ctx, documento)()
+	if err != nil || cliente == nil {
+		return nil, err
+	}
+	return r.novoCliente(cliente), nil
+}
+
+func (r *resolvedor) ClientesPorDocumento(ctx context.Context, args struct{ Documentos []string }) ([]*clienteResolver, error) {
+	if len(args.Documentos) > LimiteMaximo {
+		return nil, entradaInvalida("No máximo "+strconv.Itoa(LimiteMaximo)+" documentos por busca", metrics.ValidacaoDados)
+	}
+	documentos := make([]string, len(args.Documentos))
+	for i, documento := range args.Documentos {
+		documentos[i] = utils.ClearNumber(documento)
+		if !utils.ValidaDocumento(documentos[i]) {
+			return nil, entradaInvalida("Documento inválido: "+documento, metrics.ValidacaoDocumento)
+		}
+	}
+
+	clientes, erros := carregadoresDe(ctx).clientes.LoadMany(ctx, documentos)()
+	resolvers := make([]*clienteResolver, len(clientes))
+	for i, cliente := range clientes {
+		if len(erros) > i && erros[i] != nil {
+			return nil, erros[i]
+		}
+		if cliente != nil {
+			resolvers[i] = r.novoCliente(cliente)
+		}
+	}
+	return resolvers, nil
+}
+
+type argumentosListagem struct {
+	RazaoSocial *string
+	Page        int32
+	Limit       int32
+}
+
+func (r *resolvedor) Clientes(ctx context.Context, args argumentosListagem) (*paginaResolver, error) {
+	pagina := paginaResolver{page: args.Page, limit: args.Limit}
+	if pagina.page < 1 || pagina.limit < 1 || pagina.limit > LimiteMaximo {
+		return nil, entradaInvalida("Paginação inválida: page deve ser maior que zero e limit entre 1 e "+strconv.Itoa(LimiteMaximo), metrics.ValidacaoDados)
+	}
+	razaoSocial := ""
+	if args.RazaoSocial != nil {
+		razaoSocial = *args.RazaoSocial
+	}
+
+	ctxConsulta, cancel := r.s.prazoConsulta(ctx)
+	defer cancel()
+	clientes, total, err := r.s.clientes.ListarClientes(ctxConsulta, razaoSocial, int(pagina.page), int(pagina.limit))
+	if errors.Is(err, repository.ErrBuscaIndisponivel) {
+		return nil, entradaInvalida("Filtro por razão social indisponível para os clientes cifrados, liste sem o filtro ou busque pelo documento", metrics.ValidacaoDados)
+	}
+	if err != nil {
+		return nil, erroBanco(ctxConsulta, err, "Erro ao listar clientes")
+	}
+
+	pagina.total = int32(total)
+	pagina.clientes = make([]*clienteResolver, len(clientes))
+	for i := range clientes {
+		pagina.clientes[i] = r.novoCliente(&clientes[i])
+	}
+	return &pagina, nil
+}
+
+type entradaNovoCliente struct {
+	Documento   string
+	RazaoSocial string
+	Blocklist   bool
+}
+
+func (r *resolvedor) CriarCliente(ctx context.Context, args struct{ Input entradaNovoCliente }) (*clienteResolver, error) {
+	documento := utils.ClearNumber(args.Input.Documento)
+	if !utils.ValidaDocumento(documento) {
+		return nil, entradaInvalida("Documento inválido", metrics.ValidacaoDocumento)
+	}
+	if !utils.ValidaRazaoSocial(args.Input.RazaoSocial) {
+		return nil, entradaInvalida("Razão social inválida", metrics.ValidacaoRazaoSocial)
+	}
+
+	ctxConsulta, cancel := r.s.prazoConsulta(ctx)
+	defer cancel()
+	existente, err := r.s.clientes.FindByDocumento(ctxConsulta, documento)
+	if err == nil && existente != nil {
+		return nil, errJaCadastrado
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, erroBanco(ctxConsulta, err, "Erro ao cadastrar cliente")
+	}
+
+	cliente := models.Cliente{
+		Documento:   documento,
+		RazaoSocial: args.Input.RazaoSocial,
+		Blocklist:   args.Input.Blocklist,
+	}
+	ctxEscrita, cancel := r.s.prazoEscrita(ctx)
+	defer cancel()
+	if err := r.s.clientes.Create(ctxEscrita, &cliente); err != nil {
+		return nil, erroBanco(ctxEscrita, err, "Erro ao cadastrar cliente")
+	}
+	carregadoresDe(ctx).limpar(ctx, documento)
+	return r.novoCliente(&cliente), nil
+}
+
+type entradaAtualizacao struct {
+	RazaoSocial *string
+	Blocklist   *bool
+}
+
+func (r *resolvedor) AtualizarCliente(ctx context.Context, args struct {
+	Documento string
+	Input     entradaAtualizacao
+}) (*clienteResolver, error) {
+	if args.Input.RazaoSocial == nil && args.Input.Blocklist == nil {
+		return nil, entradaInvalida("Nenhum campo informado", metrics.ValidacaoDados)
+	}
+	if args.Input.RazaoSocial != nil && !utils.ValidaRazaoSocial(*args.Input.RazaoSocial) {
+		return nil, entradaInvalida("Razão social inválida", metrics.ValidacaoRazaoSocial)
+	}
+	return r.atualizar(ctx, args.Documento, &dtos.AtualizaClienteRequest{
+		RazaoSocial: args.Input.RazaoSocial,
+		Blocklist:   args.Input.Blocklist,
+	})
+}
+
+func (r *resolvedor) AlterarBlocklist(ctx context.Context, args struct {
+	Documento string
+	Blocklist bool
+}) (*clienteResolver, error) {
+	return r.atualizar(ctx, args.Documento, &dtos.AtualizaClienteRequest{Blocklist: &args.Blocklist})
+}
+
+// atualizar busca o cliente e aplica os campos informados em dados
+func (r *resolvedor) atualizar(ctx context.Context, documento string, dados *dtos.AtualizaClienteRequest) (*clienteResolver, error) {
+	documento = utils.ClearNumber(documento)
+	if !utils.ValidaDocumento(documento) {
+		return nil, entradaInvalida("Documento inválido", metrics.ValidacaoDocumento)
+	}
+
+	ctxConsulta, cancel := r.s.prazoConsulta(ctx)
+	defer cancel()
+	cliente, err := r.s.clientes.FindByDocumento(ctxConsulta, documento)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errNaoEncontrado
+	}
+	if err != nil {
+		return nil, erroBanco(ctxConsulta, err, "Erro ao buscar cliente")
+	}
+
+	ctxEscrita, cancel := r.s.prazoEscrita(ctx)
+	defer cancel()
+	atualizado, err := r.s.clientes.UpdateByDocumento(ctxEscrita, cliente, dados)
+	if err != nil {
+		return nil, erroBanco(ctxEscrita, err, "Erro ao atualizar cliente")
+	}
+	carregadoresDe(ctx).limpar(ctx, documento)
+	return r.novoCliente(atualizado), nil
+}
+
+func (r *resolvedor) novoCliente(cliente *models.Cliente) *clienteResolver {
+	return &clienteResolver{cliente: cliente}
+}
+
+type paginaResolver struct {
+	page     int32
+	limit    int32
+	total    int32
+	clientes []*clienteResolver
+}
+
+func (p *paginaResolver) Page() int32                  { return p.page }
+func (p *paginaResolver) Limit() int32                 { return p.limit }
+func (p *paginaResolver) Total() int32                 { return p.total }
+func (p *paginaResolver) Clientes() []*clienteResolver { return p.clientes }
+
+type clienteResolver struct {
+	cliente *models.Cliente
+}
+
+func (c *clienteResolver) Documento() string          { return c.cliente.Documento }
+func (c *clienteResolver) RazaoSocial() string        { return c.cliente.RazaoSocial }
+func (c *clienteResolver) Blocklist() bool            { return c.cliente.Blocklist }
+func (c *clienteResolver) CriadoEm() graphql.Time     { return graphql.Time{Time: c.cliente.CreatedAt} }
+func (c *clienteResolver) AtualizadoEm() graphql.Time { return graphql.Time{Time: c.cliente.UpdatedAt} }
+
+// Historico carrega os eventos pelo dataloader, para que o histórico de todos os clientes de uma
+// lista seja buscado em uma única consulta
+func (c *clienteResolver) Historico(ctx context.Context, args struct{ Tipos *[]string }) ([]*eventoResolver, error) {
+	eventos, err := carregadoresDe(ctx).historico.Load(ctx, c.cliente.Documento)()
+	if err != nil {
+		return nil, err
+	}
+
+	tipos := map[string]bool{}
+	if args.Tipos != nil {
+		for _, tipo := range *args.Tipos {
+			tipos[tipo] = true
+		}
+	}
+	resolvers := make([]*eventoResolver, 0, len(eventos))
+	for i := range eventos {
+		if len(tipos) == 0 || tipos[eventos[i].Tipo] {
+			resolvers = append(resolvers, &eventoResolver{evento: &eventos[i]})
+		}
+	}
+	return resolvers, nil
+}
+
+type eventoResolver struct {
+	evento *models.Evento
+}
+
+func (e *eventoResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(e.evento.ID), 10))
+}
+func (e *eventoResolver) Tipo() string           { return e.evento.Tipo }
+func (e *eventoResolver) CriadoEm() graphql.Time { return graphql.Time{Time: e.evento.CreatedAt} }
+func (e *eventoResolver) Dados() string          { return e.evento.Payload }
@@ -0,0 +1,80 @@
+// Package graphqlapi expõe os clientes por GraphQL, para que o back-office busque em uma única
+// requisição somente os campos de que precisa. As buscas de clientes e do histórico de eventos
+// passam por dataloaders criados a cada requisição, que agrupam em uma consulta as buscas feitas
+// pelos resolvers de uma mesma lista.
+package graphqlapi
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	// LimiteMaximo é o maior limit da listagem e a maior quantidade de documentos por busca
+	LimiteMaximo = 100
+	// ProfundidadeMaxima limita o aninhamento dos campos de uma consulta
+	ProfundidadeMaxima = 8
+)
+
+//go:embed schema.graphql
+var esquema string
+
+// Servico executa as operações GraphQL sobre os mesmos repositórios e validações da API REST
+type Servico struct {
+	schema   *graphql.Schema
+	clientes repository.ClienteRepository
+	eventos  repository.EventoRepository
+
+	// TimeoutConsulta é o prazo de cada leitura no banco
+	TimeoutConsulta time.Duration
+	// TimeoutEscrita é o prazo de cada cadastro ou atualização
+	TimeoutEscrita time.Duration
+}
+
+func NewServico(clientes repository.ClienteRepository, eventos repository.EventoRepository) *Servico {
+	s := &Servico{
+		clientes:        clientes,
+		eventos:         eventos,
+		TimeoutConsulta: 5 * time.Second,
+		TimeoutEscrita:  10 * time.Second,
+	}
+	// O paralelismo cobre uma página inteira, para que os resolvers de todos os clientes da lista
+	// cheguem juntos aos dataloaders
+	s.schema = graphql.MustParseSchema(esquema, &resolvedor{s: s},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(ProfundidadeMaxima),
+		graphql.MaxParallelism(LimiteMaximo),
+	)
+	return s
+}
+
+// Executar executa a operação no tenant do contexto com dataloaders exclusivos dela, de forma que
+// nada do que foi carregado é reaproveitado por outra requisição
+func (s *Servico) Executar(ctx context.Context, consulta, operacao string, variaveis map[string]interface{}) *graphql.Response {
+	ctx = comCarregadores(ctx, s.novosCarregadores())
+	return s.schema.Exec(ctx, consulta, operacao, variaveis)
+}
+
+// comPrazo aplica o prazo configurado para o tenant, ou o padrão
+func (s *Servico) comPrazo(ctx context.Context, padrao time.Duration, prazo func(tenant.Tenant) time.Duration) (context.Context, context.CancelFunc) {
+	if t, ok := tenant.DoContexto(ctx); ok && prazo(t) > 0 {
+		padrao = prazo(t)
+	}
+	if padrao <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, padrao)
+}
+
+func (s *Servico) prazoConsulta(ctx context.Context) (context.Context, context.CancelFunc) {
+	return s.comPrazo(ctx, s.TimeoutConsulta, func(t tenant.Tenant) time.Duration { return t.TimeoutConsulta })
+}
+
+func (s *Servico) prazoEscrita(ctx context.Context) (context.Context, context.CancelFunc) {
+	return s.comPrazo(ctx, s.TimeoutEscrita, func(t tenant.Tenant) time.Duration { return t.TimeoutEscrita })
+}
@@ -0,0 +1,239 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/blocklist"
+	"github.com/Gileno29/clientes-API/health"
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/migrations"
+	clientesv1 "github.com/Gileno29/clientes-API/proto/clientes/v1"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+var bancos atomic.Int64
+
+// setupServidor sobe o servidor em memória sobre um banco SQLite exclusivo e retorna a conexão
+// de um cliente. Com resolvedor nil a multi-tenancy fica desligada.
+func setupServidor(t *testing.T, resolvedor *tenant.Resolvedor, registro *health.Registro) *grpc.ClientConn {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:grpcapi%d?mode=memory&cache=shared", bancos.Add(1))), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	migrador, err := migrations.NewMigrador(db)
+	require.NoError(t, err)
+	_, err = migrador.Subir(context.Background(), 0)
+	require.NoError(t, err)
+
+	if resolvedor == nil {
+		resolvedor = tenant.NewResolvedor(nil, nil, "", false)
+	}
+	base := repository.NewClienteRepository(db)
+	indice := blocklist.NewIndice(resolvedor.IDs(), func(ctx context.Context, t string) ([]string, error) {
+		return base.ListarBloqueados(tenant.ComID(ctx, t))
+	})
+	require.NoError(t, indice.Sincronizar(context.Background()))
+	servico := NewServicoClientes(repository.NewClienteRepositoryComIndice(base, indice), indice, lgpd.NewPseudonimizador("sal"))
+	servidor := NewServidor(servico, resolvedor, registro)
+
+	listener := bufconn.Listen(1 << 20)
+	go servidor.Servir(listener)
+	t.Cleanup(func() { servidor.Encerrar(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestClienteService(t *testing.T) {
+	cliente := clientesv1.NewClienteServiceClient(setupServidor(t, nil, nil))
+	ctx := context.Background()
+
+	// Caso de sucesso: Cadastra, busca, atualiza e remove o cliente
+	t.Run("Ciclo de vida do cliente", func(t *testing.T) {
+		criado, err := cliente.CriarCliente(ctx, &clientesv1.CriarClienteRequest{Documento: "529.982.247-25", RazaoSocial: "João Silva"})
+		require.NoError(t, err)
+		assert.Equal(t, "52998224725", criado.Documento)
+
+		buscado, err := cliente.BuscarCliente(ctx, &clientesv1.BuscarClienteRequest{Documento: "52998224725"})
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", buscado.RazaoSocial)
+		assert.False(t, buscado.Blocklist)
+
+		atualizado, err := cliente.AtualizarCliente(ctx, &clientesv1.AtualizarClienteRequest{
+			Documento:   "52998224725",
+			RazaoSocial: proto.String("João da Silva"),
+			Blocklist:   proto.Bool(true),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "João da Silva", atualizado.RazaoSocial)
+		assert.True(t, atualizado.Blocklist)
+
+		_, err = cliente.RemoverCliente(ctx, &clientesv1.RemoverClienteRequest{Documento: "52998224725"})
+		require.NoError(t, err)
+		_, err = cliente.BuscarCliente(ctx, &clientesv1.BuscarClienteRequest{Documento: "52998224725"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	// Caso de erro: Os erros usam os códigos equivalentes aos status da API REST
+	t.Run("Mapeia os erros", func(t *testing.T) {
+		_, err := cliente.CriarCliente(ctx, &clientesv1.CriarClienteRequest{Documento: "123", RazaoSocial: "Empresa"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "Documento inválido", status.Convert(err).Message())
+
+		_, err = cliente.CriarCliente(ctx, &clientesv1.CriarClienteRequest{Documento: "33000167000101", RazaoSocial: ""})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "Razão social inválida", status.Convert(err).Message())
+
+		_, err = cliente.CriarCliente(ctx, &clientesv1.CriarClienteRequest{Documento: "33000167000101", RazaoSocial: "Empresa"})
+		require.NoError(t, err)
+		_, err = cliente.CriarCliente(ctx, &clientesv1.CriarClienteRequest{Documento: "33000167000101", RazaoSocial: "Empresa"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		_, err = cliente.AtualizarCliente(ctx, &clientesv1.AtualizarClienteRequest{Documento: "33000167000101", RazaoSocial: proto.String("Empresa")})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "Campo blocklist obrigatório", status.Convert(err).Message())
+
+		_, err = cliente.RemoverCliente(ctx, &clientesv1.RemoverClienteRequest{Documento: "11144477735"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestListarClientes(t *testing.T) {
+	cliente := clientesv1.NewClienteServiceClient(setupServidor(t, nil, nil))
+	ctx := context.Background()
+	for _, documento := range []string{"52998224725", "11144477735", "33000167000101"} {
+		_, err := cliente.CriarCliente(ctx, &clientesv1.CriarClienteRequest{Documento: documento, RazaoSocial: "Cliente " + documento})
+		require.NoError(t, err)
+	}
+
+	receber := func(req *clientesv1.ListarClientesRequest) ([]string, error) {
+		stream, err := cliente.ListarClientes(ctx, req)
+		require.NoError(t, err)
+		var documentos []string
+		for {
+			c, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return documentos, nil
+			}
+			if err != nil {
+				return documentos, err
+			}
+			documentos = append(documentos, c.Documento)
+		}
+	}
+
+	// Caso de sucesso: Envia todos os clientes, os filtrados ou até o limite
+	documentos, err := receber(&clientesv1.ListarClientesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, documentos, 3)
+
+	documentos, err = receber(&clientesv1.ListarClientesRequest{RazaoSocial: "1114"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"11144477735"}, documentos)
+
+	documentos, err = receber(&clientesv1.ListarClientesRequest{Limite: 2})
+	require.NoError(t, err)
+	assert.Len(t, documentos, 2)
+
+	// Caso de erro: Limite negativo
+	_, err = receber(&clientesv1.ListarClientesRequest{Limite: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestVerificarBlocklist(t *testing.T) {
+	cliente := clientesv1.NewClienteServiceClient(setupServidor(t, nil, nil))
+	ctx := context.Background()
+	_, err := cliente.CriarCliente(ctx, &clientesv1.CriarClienteRequest{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true})
+	require.NoError(t, err)
+
+	// Caso de sucesso: Consulta os documentos no índice, marcando os inválidos
+	resposta, err := cliente.VerificarBlocklist(ctx, &clientesv1.VerificarBlocklistRequest{
+		Documentos: []string{"529.982.247-25", "11144477735", "123"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resposta.Resultados, 3)
+	assert.True(t, resposta.Resultados[0].Valido)
+	assert.True(t, resposta.Resultados[0].Blocklist)
+	assert.Equal(t, "529.982.247-25", resposta.Resultados[0].Documento)
+	assert.True(t, resposta.Resultados[1].Valido)
+	assert.False(t, resposta.Resultados[1].Blocklist)
+	assert.False(t, resposta.Resultados[2].Valido)
+	assert.NotZero(t, resposta.Versao)
+	assert.NotNil(t, resposta.SincronizadoEm)
+
+	// Caso de erro: Nenhum documento ou documentos demais
+	_, err = cliente.VerificarBlocklist(ctx, &clientesv1.VerificarBlocklistRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = cliente.VerificarBlocklist(ctx, &clientesv1.VerificarBlocklistRequest{Documentos: make([]string, maxDocumentosBlocklist+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestTenant(t *testing.T) {
+	resolvedor := tenant.NewResolvedor(
+		[]tenant.Tenant{{ID: "loja-a"}, {ID: "loja-b"}},
+		map[string]string{"chave-a": "loja-a", "chave-b": "loja-b"},
+		"", false,
+	)
+	cliente := clientesv1.NewClienteServiceClient(setupServidor(t, resolvedor, nil))
+	comChave := func(chave string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", chave)
+	}
+
+	// Caso de sucesso: Cada tenant vê somente os próprios clientes
+	_, err := cliente.CriarCliente(comChave("chave-a"), &clientesv1.CriarClienteRequest{Documento: "52998224725", RazaoSocial: "João Silva"})
+	require.NoError(t, err)
+	_, err = cliente.BuscarCliente(comChave("chave-a"), &clientesv1.BuscarClienteRequest{Documento: "52998224725"})
+	assert.NoError(t, err)
+	_, err = cliente.BuscarCliente(comChave("chave-b"), &clientesv1.BuscarClienteRequest{Documento: "52998224725"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Caso de erro: Chamadas sem tenant ou com credencial inválida são recusadas
+	_, err = cliente.BuscarCliente(context.Background(), &clientesv1.BuscarClienteRequest{Documento: "52998224725"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "Tenant não identificado", status.Convert(err).Message())
+
+	_, err = cliente.BuscarCliente(comChave("outra"), &clientesv1.BuscarClienteRequest{Documento: "52998224725"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "Credencial inválida", status.Convert(err).Message())
+}
+
+func TestHealth(t *testing.T) {
+	registro := health.NewRegistro(time.Second)
+	conn := setupServidor(t, nil, registro)
+	saude := grpc_health_v1.NewHealthClient(conn)
+
+	// Caso de sucesso: O health não exige tenant e acompanha a prontidão
+	require.Eventually(t, func() bool {
+		resposta, err := saude.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "clientes.v1.ClienteService"})
+		return err == nil && resposta.Status == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	// Caso de erro: Serviço desconhecido
+	_, err := saude.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "outro"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,158 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetadadoRequestID é o metadado equivalente ao cabeçalho X-Request-ID
+	MetadadoRequestID = "x-request-id"
+
+	// prefixoServico identifica os métodos que exigem tenant; health e reflection ficam abertos
+	prefixoServico = "/clientes.v1.ClienteService/"
+)
+
+// requestIDValido limita o id recebido do cliente para que ele não polua os logs
+var requestIDValido = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// interceptadorUnario aplica a cada chamada unária o id da requisição, o log de acesso, as
+// métricas, a recuperação de pânicos e a identificação do tenant, como os middlewares da API REST
+func interceptadorUnario(resolvedor *tenant.Resolvedor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resposta any, err error) {
+		ctx, fim := iniciarChamada(ctx, info.FullMethod)
+		defer func() { fim(err) }()
+		defer recuperar(ctx, &err)
+
+		ctx, err = identificarTenant(ctx, resolvedor, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// interceptadorStream é o interceptadorUnario das chamadas com stream
+func interceptadorStream(resolvedor *tenant.Resolvedor) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, fim := iniciarChamada(ss.Context(), info.FullMethod)
+		defer func() { fim(err) }()
+		defer recuperar(ctx, &err)
+
+		ctx, err = identificarTenant(ctx, resolvedor, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &streamComContexto{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// iniciarChamada associa ao contexto um logger com o id da requisição, recebido no metadado
+// x-request-id ou gerado, e retorna a função que registra o acesso e as métricas ao final
+func iniciarChamada(ctx context.Context, metodo string) (context.Context, func(error)) {
+	inicio := time.Now()
+
+	id := primeiroMetadado(ctx, MetadadoRequestID)
+	if !requestIDValido.MatchString(id) {
+		id = novoRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(MetadadoRequestID, id))
+	logger := slog.Default().With(slog.String("request_id", id))
+	ctx = logging.ComLogger(ctx, logger)
+
+	return ctx, func(err error) {
+		codigo := status.Code(err)
+		duracao := time.Since(inicio)
+		metrics.ObservarRequisicaoGRPC(metodo, codigo.String(), duracao)
+
+		nivel := slog.LevelInfo
+		switch codigo {
+		case codes.OK:
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+			nivel = slog.LevelError
+		default:
+			nivel = slog.LevelWarn
+		}
+		logging.FromContext(ctx).LogAttrs(ctx, nivel, "Chamada gRPC atendida",
+			slog.String("metodo", metodo),
+			slog.String("codigo", codigo.String()),
+			slog.Float64("latencia_ms", float64(duracao.Microseconds())/1000),
+		)
+	}
+}
+
+// recuperar converte o pânico de um método em INTERNAL, registrando-o no logger da chamada
+func recuperar(ctx context.Context, err *error) {
+	if recuperado := recover(); recuperado != nil {
+		logging.FromContext(ctx).Error("Panic ao atender a chamada",
+			"panic", fmt.Sprint(recuperado),
+			"stack", string(debug.Stack()),
+		)
+		*err = status.Error(codes.Internal, "Erro interno")
+	}
+}
+
+// identificarTenant resolve o tenant pelos metadados x-api-key, authorization ou x-tenant-id.
+// Chamadas sem tenant identificado recebem UNAUTHENTICATED com as mensagens da API REST.
+func identificarTenant(ctx context.Context, resolvedor *tenant.Resolvedor, metodo string) (context.Context, error) {
+	if !strings.HasPrefix(metodo, prefixoServico) {
+		return ctx, nil
+	}
+
+	t, err := resolvedor.ResolverCabecalhos(func(nome string) string {
+		return primeiroMetadado(ctx, strings.ToLower(nome))
+	})
+	if err != nil {
+		mensagem := "Tenant não identificado"
+		switch {
+		case errors.Is(err, tenant.ErrCredencial):
+			mensagem = "Credencial inválida"
+		case errors.Is(err, tenant.ErrDesconhecido):
+			mensagem = "Tenant desconhecido"
+		}
+		return ctx, status.Error(codes.Unauthenticated, mensagem)
+	}
+
+	logger := logging.FromContext(ctx).With(slog.String("tenant", t.ID))
+	return logging.ComLogger(tenant.ComTenant(ctx, t), logger), nil
+}
+
+func primeiroMetadado(ctx context.Context, chave string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if valores := md.Get(chave); len(valores) > 0 {
+		return valores[0]
+	}
+	return ""
+}
+
+func novoRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// streamComContexto troca o contexto do stream pelo contexto com o tenant e o logger
+type streamComContexto struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *streamComContexto) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,280 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Gileno29/clientes-API/blocklist"
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	clientesv1 "github.com/Gileno29/clientes-API/proto/clientes/v1"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	// tamanhoPagina é a quantidade de clientes lida do repositório a cada consulta da listagem
+	tamanhoPagina = 500
+	// maxDocumentosBlocklist limita os documentos de uma chamada de VerificarBlocklist
+	maxDocumentosBlocklist = 1000
+)
+
+// ServicoClientes implementa o ClienteService sobre o mesmo repositório e as mesmas validações
+// do ClienteHandler. Os erros usam os códigos gRPC equivalentes aos status da API REST.
+type ServicoClientes struct {
+	clientesv1.UnimplementedClienteServiceServer
+
+	repo   repository.ClienteRepository
+	indice *blocklist.Indice
+	// pseudonimizador reconhece os clientes anonimizados, que ficam no índice pelo hash do documento
+	pseudonimizador *lgpd.Pseudonimizador
+
+	// TimeoutConsulta é o prazo de cada leitura no banco
+	TimeoutConsulta time.Duration
+	// TimeoutEscrita é o prazo de cada cadastro, atualização ou remoção
+	TimeoutEscrita time.Duration
+}
+
+func NewServicoClientes(repo repository.ClienteRepository, indice *blocklist.Indice, pseudonimizador *lgpd.Pseudonimizador) *ServicoClientes {
+	return &ServicoClientes{
+		repo:            repo,
+		indice:          indice,
+		pseudonimizador: pseudonimizador,
+		TimeoutConsulta: 5 * time.Second,
+		TimeoutEscrita:  10 * time.Second,
+	}
+}
+
+func (s *ServicoClientes) CriarCliente(ctx context.Context, req *clientesv1.CriarClienteRequest) (*clientesv1.Cliente, error) {
+	documento := utils.ClearNumber(req.GetDocumento())
+	if !utils.ValidaDocumento(documento) {
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		return nil, status.Error(codes.InvalidArgument, "Documento inválido")
+	}
+	if !utils.ValidaRazaoSocial(req.GetRazaoSocial()) {
+		metrics.FalhaValidacao(metrics.ValidacaoRazaoSocial)
+		return nil, status.Error(codes.InvalidArgument, "Razão social inválida")
+	}
+
+	ctxConsulta, cancel := s.comPrazo(ctx, s.TimeoutConsulta, func(t tenant.Tenant) time.Duration { return t.TimeoutConsulta })
+	defer cancel()
+	existente, err := s.repo.FindByDocumento(ctxConsulta, documento)
+	if err == nil && existente != nil {
+		return nil, status.Error(codes.AlreadyExists, "Cliente já cadastrado")
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, erroBanco(ctxConsulta, err, "Erro ao cadastrar cliente")
+	}
+
+	cliente := models.Cliente{
+		Documento:   documento,
+		RazaoSocial: req.GetRazaoSocial(),
+		Blocklist:   req.GetBlocklist(),
+	}
+	ctxEscrita, cancel := s.comPrazo(ctx, s.TimeoutEscrita, func(t tenant.Tenant) time.Duration { return t.TimeoutEscrita })
+	defer cancel()
+	if err := s.repo.Create(ctxEscrita, &cliente); err != nil {
+		return nil, erroBanco(ctxEscrita, err, "Erro ao cadastrar cliente")
+	}
+	return clienteProto(&cliente), nil
+}
+
+func (s *ServicoClientes) BuscarCliente(ctx context.Context, req *clientesv1.BuscarClienteRequest) (*clientesv1.Cliente, error) {
+	cliente, err := s.buscarCliente(ctx, req.GetDocumento())
+	if err != nil {
+		return nil, err
+	}
+	return clienteProto(cliente), nil
+}
+
+func (s *ServicoClientes) ListarClientes(req *clientesv1.ListarClientesRequest, stream grpc.ServerStreamingServer[clientesv1.Cliente]) error {
+	if req.GetLimite() < 0 {
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		return status.Error(codes.InvalidArgument, "Limite inválido")
+	}
+
+	enviados := 0
+	for pagina := 1; ; pagina++ {
+		ctx, cancel := s.comPrazo(stream.Context(), s.TimeoutConsulta, func(t tenant.Tenant) time.Duration { return t.TimeoutConsulta })
+		clientes, _, err := s.repo.ListarClientes(ctx, req.GetRazaoSocial(), pagina, tamanhoPagina)
+		if errors.Is(err, repository.ErrBuscaIndisponivel) {
+			cancel()
+			return status.Error(codes.InvalidArgument, "Filtro por razão social indisponível para os clientes cifrados, liste sem o filtro ou busque pelo documento")
+		}
+		if err != nil {
+			err = erroBanco(ctx, err, "Erro ao listar clientes")
+			cancel()
+			return err
+		}
+		cancel()
+
+		for i := range clientes {
+			if err := stream.Send(clienteProto(&clientes[i])); err != nil {
+				return err
+			}
+			enviados++
+			if req.GetLimite() > 0 && enviados == int(req.GetLimite()) {
+				return nil
+			}
+		}
+		if len(clientes) < tamanhoPagina {
+			return nil
+		}
+	}
+}
+
+func (s *ServicoClientes) AtualizarCliente(ctx context.Context, req *clientesv1.AtualizarClienteRequest) (*clientesv1.Cliente, error) {
+	cliente, err := s.buscarCliente(ctx, req.GetDocumento())
+	if err != nil {
+		return nil, err
+	}
+
+	// A atualização substitui o cliente por completo, como o PUT da API REST
+	if req.RazaoSocial == nil {
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		return nil, status.Error(codes.InvalidArgument, "Campo razao_social obrigatório")
+	}
+	if !utils.ValidaRazaoSocial(req.GetRazaoSocial()) {
+		metrics.FalhaValidacao(metrics.ValidacaoRazaoSocial)
+		return nil, status.Error(codes.InvalidArgument, "Razão social inválida")
+	}
+	if req.Blocklist == nil {
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		return nil, status.Error(codes.InvalidArgument, "Campo blocklist obrigatório")
+	}
+
+	ctxEscrita, cancel := s.comPrazo(ctx, s.TimeoutEscrita, func(t tenant.Tenant) time.Duration { return t.TimeoutEscrita })
+	defer cancel()
+	atualizado, err := s.repo.UpdateByDocumento(ctxEscrita, cliente, &dtos.AtualizaClienteRequest{
+		RazaoSocial: req.RazaoSocial,
+		Blocklist:   req.Blocklist,
+	})
+	if err != nil {
+		return nil, erroBanco(ctxEscrita, err, "Erro ao atualizar cliente")
+	}
+	return clienteProto(atualizado), nil
+}
+
+func (s *ServicoClientes) RemoverCliente(ctx context.Context, req *clientesv1.RemoverClienteRequest) (*clientesv1.RemoverClienteResponse, error) {
+	cliente, err := s.buscarCliente(ctx, req.GetDocumento())
+	if err != nil {
+		return nil, err
+	}
+
+	ctxEscrita, cancel := s.comPrazo(ctx, s.TimeoutEscrita, func(t tenant.Tenant) time.Duration { return t.TimeoutEscrita })
+	defer cancel()
+	if err := s.repo.DeleteByDocumento(ctxEscrita, cliente.Documento); err != nil {
+		return nil, erroBanco(ctxEscrita, err, "Erro ao deletar cliente")
+	}
+	return &clientesv1.RemoverClienteResponse{}, nil
+}
+
+// VerificarBlocklist responde a partir do índice em memória, como GET /blocklist/{documento}.
+// Documentos inválidos não falham a chamada: são retornados com valido falso.
+func (s *ServicoClientes) VerificarBlocklist(ctx context.Context, req *clientesv1.VerificarBlocklistRequest) (*clientesv1.VerificarBlocklistResponse, error) {
+	t, ok := tenant.DoContexto(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Tenant não identificado")
+	}
+	if len(req.GetDocumentos()) == 0 {
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		return nil, status.Error(codes.InvalidArgument, "Nenhum documento informado")
+	}
+	if len(req.GetDocumentos()) > maxDocumentosBlocklist {
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		return nil, status.Errorf(codes.InvalidArgument, "No máximo %d documentos por chamada", maxDocumentosBlocklist)
+	}
+
+	resposta := &clientesv1.VerificarBlocklistResponse{
+		Resultados: make([]*clientesv1.SituacaoBlocklist, 0, len(req.GetDocumentos())),
+	}
+	for _, informado := range req.GetDocumentos() {
+		situacao := &clientesv1.SituacaoBlocklist{Documento: informado}
+		resposta.Resultados = append(resposta.Resultados, situacao)
+
+		documento := utils.ClearNumber(informado)
+		if !utils.ValidaDocumento(documento) {
+			metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+			continue
+		}
+		situacao.Valido = true
+
+		consulta := s.indice.Consultar(t.ID, documento)
+		if !consulta.Blocklist && s.pseudonimizador != nil && s.pseudonimizador.Habilitado() {
+			hash, _ := s.pseudonimizador.Hash(t.ID, documento)
+			consulta.Blocklist = s.indice.Consultar(t.ID, hash).Blocklist
+		}
+		situacao.Blocklist = consulta.Blocklist
+		resposta.Versao = consulta.Versao
+		if !consulta.SincronizadoEm.IsZero() {
+			resposta.SincronizadoEm = timestamppb.New(consulta.SincronizadoEm)
+		}
+	}
+	return resposta, nil
+}
+
+// buscarCliente valida o documento e busca o cliente, retornando INVALID_ARGUMENT, NOT_FOUND ou o
+// erro do banco
+func (s *ServicoClientes) buscarCliente(ctx context.Context, documento string) (*models.Cliente, error) {
+	documento = utils.ClearNumber(documento)
+	if !utils.ValidaDocumento(documento) {
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		return nil, status.Error(codes.InvalidArgument, "Documento inválido")
+	}
+
+	ctx, cancel := s.comPrazo(ctx, s.TimeoutConsulta, func(t tenant.Tenant) time.Duration { return t.TimeoutConsulta })
+	defer cancel()
+	cliente, err := s.repo.FindByDocumento(ctx, documento)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "Cliente não encontrado")
+	}
+	if err != nil {
+		return nil, erroBanco(ctx, err, "Erro ao buscar cliente")
+	}
+	return cliente, nil
+}
+
+// comPrazo aplica o prazo configurado para o tenant, ou o padrão. O prazo informado pelo cliente
+// gRPC continua valendo quando é menor.
+func (s *ServicoClientes) comPrazo(ctx context.Context, padrao time.Duration, prazo func(tenant.Tenant) time.Duration) (context.Context, context.CancelFunc) {
+	if t, ok := tenant.DoContexto(ctx); ok && prazo(t) > 0 {
+		padrao = prazo(t)
+	}
+	if padrao <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, padrao)
+}
+
+// erroBanco registra o erro e retorna DEADLINE_EXCEEDED para operações interrompidas pelo prazo
+// ou cancelamento, como o 504 da API REST, e INTERNAL nos demais casos
+func erroBanco(ctx context.Context, err error, mensagem string) error {
+	logging.FromContext(ctx).Error(mensagem, "erro", err)
+	if tempoEsgotado(ctx, err) {
+		return status.Error(codes.DeadlineExceeded, "Tempo limite da operação excedido")
+	}
+	return status.Error(codes.Internal, mensagem)
+}
+
+// tempoEsgotado indica se a operação falhou por ter excedido o prazo ou por ter sido cancelada
+func tempoEsgotado(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || ctx.Err() != nil
+}
+
+func clienteProto(cliente *models.Cliente) *clientesv1.Cliente {
+	return &clientesv1.Cliente{
+		Documento:   cliente.Documento,
+		RazaoSocial: cliente.RazaoSocial,
+		Blocklist:   cliente.Blocklist,
+	}
+}
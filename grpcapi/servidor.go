@@ -0,0 +1,101 @@
+// Package grpcapi expõe as operações de clientes da API REST por gRPC, em uma porta própria, com
+// o protocolo padrão de health checking e reflection para ferramentas como grpcurl.
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/Gileno29/clientes-API/health"
+	clientesv1 "github.com/Gileno29/clientes-API/proto/clientes/v1"
+	"github.com/Gileno29/clientes-API/tenant"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Servidor atende o ClienteService e os serviços de health e reflection
+type Servidor struct {
+	// GRPC é o servidor subjacente
+	GRPC *grpc.Server
+	// IntervaloHealth é o intervalo entre as verificações de prontidão refletidas no health do gRPC
+	IntervaloHealth time.Duration
+
+	saude    *grpchealth.Server
+	registro *health.Registro
+}
+
+// NewServidor cria o servidor do serviço. O tenant de cada chamada é identificado pelo resolvedor,
+// e o estado do health acompanha a prontidão do registro, como GET /health/ready.
+func NewServidor(servico *ServicoClientes, resolvedor *tenant.Resolvedor, registro *health.Registro) *Servidor {
+	s := &Servidor{
+		GRPC: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(interceptadorUnario(resolvedor)),
+			grpc.ChainStreamInterceptor(interceptadorStream(resolvedor)),
+		),
+		IntervaloHealth: 10 * time.Second,
+		saude:           grpchealth.NewServer(),
+		registro:        registro,
+	}
+	clientesv1.RegisterClienteServiceServer(s.GRPC, servico)
+	grpc_health_v1.RegisterHealthServer(s.GRPC, s.saude)
+	reflection.Register(s.GRPC)
+	return s
+}
+
+// Servir atende as conexões do listener até o Encerrar
+func (s *Servidor) Servir(listener net.Listener) error {
+	parar := make(chan struct{})
+	defer close(parar)
+	go s.acompanharProntidao(parar)
+
+	slog.Info("Servidor gRPC iniciado", "endereco", listener.Addr().String())
+	return s.GRPC.Serve(listener)
+}
+
+// Encerrar passa o health para NOT_SERVING e aguarda as chamadas em andamento. Esgotado o prazo
+// de ctx, as conexões restantes são fechadas à força.
+func (s *Servidor) Encerrar(ctx context.Context) error {
+	s.saude.Shutdown()
+
+	concluido := make(chan struct{})
+	go func() {
+		s.GRPC.GracefulStop()
+		close(concluido)
+	}()
+
+	select {
+	case <-concluido:
+		return nil
+	case <-ctx.Done():
+		s.GRPC.Stop()
+		return ctx.Err()
+	}
+}
+
+// acompanharProntidao atualiza o health a cada IntervaloHealth com o resultado do registro
+func (s *Servidor) acompanharProntidao(parar <-chan struct{}) {
+	ticker := time.NewTicker(s.IntervaloHealth)
+	defer ticker.Stop()
+	for {
+		s.atualizarHealth()
+		select {
+		case <-parar:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Servidor) atualizarHealth() {
+	situacao := grpc_health_v1.HealthCheckResponse_SERVING
+	if s.registro != nil && s.registro.Verificar(context.Background()).Status != health.StatusOK {
+		situacao = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	// O serviço vazio representa o servidor como um todo
+	s.saude.SetServingStatus("", situacao)
+	s.saude.SetServingStatus(clientesv1.ClienteService_ServiceDesc.ServiceName, situacao)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gileno29/clientes-API/agendador"
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AgendadorHandler expõe as tarefas periódicas, o histórico das execuções e a execução manual.
+// As rotas são administrativas, pois as tarefas operam sobre todos os tenants.
+type AgendadorHandler struct {
+	agendador *agendador.Agendador
+	repo      repository.AgendadorRepository
+}
+
+func NewAgendadorHandler(a *agendador.Agendador, repo repository.AgendadorRepository) *AgendadorHandler {
+	return &AgendadorHandler{agendador: a, repo: repo}
+}
+
+// ListarTarefas godoc
+// @Summary Lista as tarefas do agendador
+// @Description Retorna as tarefas periódicas registradas com a agenda e o horário previsto da próxima execução. O horário efetivo, com o jitter e a espera após falhas, só é conhecido pela instância líder.
+// @Tags agendador
+// @Produce json
+// @Param X-API-Key header string true "Chave de API administrativa"
+// @Success 200 {object} dtos.TarefasAgendadasResponse "Tarefas registradas"
+// @Failure 401 {object} dtos.ResponseErro "Credencial inválida"
+// @Failure 403 {object} dtos.ResponseErro "Acesso administrativo desabilitado"
+// @Router /admin/agendador/tarefas [get]
+func (h *AgendadorHandler) ListarTarefas(c *gin.Context) {
+	tarefas := h.agendador.Tarefas()
+	resposta := dtos.TarefasAgendadasResponse{
+		Lider:   h.agendador.Lider(),
+		Tarefas: make([]dtos.TarefaAgendadaResponse, 0, len(tarefas)),
+	}
+	for _, tarefa := range tarefas {
+		resposta.Tarefas = append(resposta.Tarefas, dtos.TarefaAgendadaResponse{
+			Nome:            tarefa.Nome,
+			Agenda:          tarefa.Agenda,
+			ProximaExecucao: tarefa.Proxima,
+			Falhas:          tarefa.Falhas,
+			Executando:      tarefa.Executando,
+		})
+	}
+	c.JSON(http.StatusOK, resposta)
+}
+
+// DispararTarefa godoc
+// @Summary Solicita a execução imediata de uma tarefa
+// @Description Registra a execução como pendente e retorna imediatamente. A instância líder a inicia na próxima verificação, depois do fim de uma execução da mesma tarefa já em andamento. A execução manual não altera a agenda.
+// @Tags agendador
+// @Produce json
+// @Param X-API-Key header string true "Chave de API administrativa"
+// @Param nome path string true "Nome da tarefa"
+// @Success 202 {object} dtos.ExecucaoAgendadaResponse "Execução solicitada"
+// @Failure 401 {object} dtos.ResponseErro "Credencial inválida"
+// @Failure 403 {object} dtos.ResponseErro "Acesso administrativo desabilitado"
+// @Failure 404 {object} dtos.ResponseErro "Tarefa desconhecida"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao solicitar a execução"
+// @Router /admin/agendador/tarefas/{nome}/execucoes [post]
+func (h *AgendadorHandler) DispararTarefa(c *gin.Context) {
+	execucao, err := h.agendador.Disparar(c.Param("nome"))
+	if errors.Is(err, agendador.ErrTarefaDesconhecida) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Tarefa desconhecida'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao solicitar a execução'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	c.Header("Location", "/admin/agendador/execucoes/"+strconv.FormatUint(uint64(execucao.ID), 10))
+	c.JSON(http.StatusAccepted, execucaoAgendadaResponse(execucao))
+}
+
+// ListarExecucoesAgendador godoc
+// @Summary Lista o histórico do agendador
+// @Description Retorna as execuções das tarefas, as mais recentes primeiro, com a origem (agendada ou manual), a tentativa, a instância que executou, o resultado e o erro.
+// @Tags agendador
+// @Produce json
+// @Param X-API-Key header string true "Chave de API administrativa"
+// @Param tarefa query string false "Filtra pelo nome da tarefa"
+// @Param limit query int false "Número máximo de registros" default(50)
+// @Success 200 {array} dtos.ExecucaoAgendadaResponse "Execuções"
+// @Failure 401 {object} dtos.ResponseErro "Credencial inválida"
+// @Failure 403 {object} dtos.ResponseErro "Acesso administrativo desabilitado"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao listar o histórico"
+// @Router /admin/agendador/execucoes [get]
+func (h *AgendadorHandler) ListarExecucoesAgendador(c *gin.Context) {
+	limite, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limite < 1 || limite > 500 {
+		limite = 50
+	}
+
+	execucoes, err := h.repo.Listar(c.Query("tarefa"), limite)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao listar o histórico'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	resposta := make([]dtos.ExecucaoAgendadaResponse, 0, len(execucoes))
+	for i := range execucoes {
+		resposta = append(resposta, execucaoAgendadaResponse(&execucoes[i]))
+	}
+	c.JSON(http.StatusOK, resposta)
+}
+
+// ConsultarExecucaoAgendador godoc
+// @Summary Consulta uma execução do agendador
+// @Description Retorna o status de uma execução, usado para acompanhar a execução solicitada pela API.
+// @Tags agendador
+// @Produce json
+// @Param X-API-Key header string true "Chave de API administrativa"
+// @Param id path int true "Identificador da execução"
+// @Success 200 {object} dtos.ExecucaoAgendadaResponse "Execução encontrada"
+// @Failure 401 {object} dtos.ResponseErro "Credencial inválida"
+// @Failure 403 {object} dtos.ResponseErro "Acesso administrativo desabilitado"
+// @Failure 404 {object} dtos.ResponseErro "Execução não encontrada"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao consultar a execução"
+// @Router /admin/agendador/execucoes/{id} [get]
+func (h *AgendadorHandler) ConsultarExecucaoAgendador(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	var execucao *models.ExecucaoAgendada
+	if err == nil {
+		execucao, err = h.repo.FindByID(uint(id))
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, strconv.ErrSyntax) || errors.Is(err, strconv.ErrRange) {
+			erro := dtos.ResponseErro{
+				Mensagem: "{'error': 'Execução não encontrada'}",
+			}
+			c.JSON(http.StatusNotFound, erro)
+			return
+		}
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao consultar a execução'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	c.JSON(http.StatusOK, execucaoAgendadaResponse(execucao))
+}
+
+func execucaoAgendadaResponse(execucao *models.ExecucaoAgendada) dtos.ExecucaoAgendadaResponse {
+	return dtos.ExecucaoAgendadaResponse{
+		ID:           execucao.ID,
+		Tarefa:       execucao.Tarefa,
+		Origem:       execucao.Origem,
+		Status:       execucao.Status,
+		AgendadoPara: execucao.AgendadoPara,
+		Tentativa:    execucao.Tentativa,
+		Instancia:    execucao.Instancia,
+		Resultado:    execucao.Resultado,
+		Erro:         execucao.Erro,
+		CriadoEm:     execucao.CreatedAt,
+		IniciadoEm:   execucao.IniciadoEm,
+		FinalizadoEm: execucao.FinalizadoEm,
+	}
+}
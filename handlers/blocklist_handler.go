@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Gileno29/clientes-API/blocklist"
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type BlocklistHandler struct {
+	indice *blocklist.Indice
+	// pseudonimizador reconhece os clientes anonimizados, que ficam no índice pelo hash do documento
+	pseudonimizador *lgpd.Pseudonimizador
+}
+
+func NewBlocklistHandler(indice *blocklist.Indice, pseudonimizador *lgpd.Pseudonimizador) *BlocklistHandler {
+	return &BlocklistHandler{indice: indice, pseudonimizador: pseudonimizador}
+}
+
+// ConsultarBlocklist godoc
+// @Summary Verifica se um documento está em blocklist
+// @Description Responde a partir do índice em memória, sem consultar o banco de dados. Documentos não cadastrados retornam blocklist false. Clientes anonimizados continuam em blocklist pelo hash do documento. A versão aumenta a cada alteração do índice e sincronizado_em indica a última comparação completa com o banco.
+// @Tags blocklist
+// @Produce json
+// @Param documento path string true "Documento do cliente (CPF/CNPJ)"
+// @Success 200 {object} dtos.BlocklistResponse "Situação do documento"
+// @Failure 400 {object} dtos.ResponseErro "Documento inválido"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Router /blocklist/{documento} [get]
+func (h *BlocklistHandler) ConsultarBlocklist(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+
+	documento := utils.ClearNumber(c.Param("documento"))
+
+	if !utils.ValidaDocumento(documento) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Documento inválido'}",
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	consulta := h.indice.Consultar(t.ID, documento)
+	if !consulta.Blocklist && h.pseudonimizador.Habilitado() {
+		hash, _ := h.pseudonimizador.Hash(t.ID, documento)
+		consulta.Blocklist = h.indice.Consultar(t.ID, hash).Blocklist
+	}
+	c.JSON(http.StatusOK, dtos.BlocklistResponse{
+		Documento:      consulta.Documento,
+		Blocklist:      consulta.Blocklist,
+		Versao:         consulta.Versao,
+		SincronizadoEm: consulta.SincronizadoEm,
+	})
+}
@@ -1,25 +1,66 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/metrics"
 
 	"github.com/Gileno29/clientes-API/models"
 	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
 	"github.com/Gileno29/clientes-API/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type ClienteHandler struct {
 	repo repository.ClienteRepository
+
+	// TimeoutConsulta é o prazo de cada leitura no banco
+	TimeoutConsulta time.Duration
+	// TimeoutEscrita é o prazo de cada cadastro, atualização ou remoção
+	TimeoutEscrita time.Duration
+	// TimeoutLote é o prazo para processar um lote inteiro
+	TimeoutLote time.Duration
 }
 
 func NewClienteHandler(repo repository.ClienteRepository) *ClienteHandler {
-	return &ClienteHandler{repo: repo}
+	return &ClienteHandler{
+		repo:            repo,
+		TimeoutConsulta: 5 * time.Second,
+		TimeoutEscrita:  10 * time.Second,
+		TimeoutLote:     time.Minute,
+	}
+}
+
+// prazoConsulta, prazoEscrita e prazoLote retornam o prazo configurado para o tenant da requisição
+// ou, sem ele, o do handler
+func (h *ClienteHandler) prazoConsulta(c *gin.Context) time.Duration {
+	return prazoDoTenant(c, h.TimeoutConsulta, func(t tenant.Tenant) time.Duration { return t.TimeoutConsulta })
+}
+
+func (h *ClienteHandler) prazoEscrita(c *gin.Context) time.Duration {
+	return prazoDoTenant(c, h.TimeoutEscrita, func(t tenant.Tenant) time.Duration { return t.TimeoutEscrita })
+}
+
+func (h *ClienteHandler) prazoLote(c *gin.Context) time.Duration {
+	return prazoDoTenant(c, h.TimeoutLote, func(t tenant.Tenant) time.Duration { return t.TimeoutLote })
+}
+
+func prazoDoTenant(c *gin.Context, padrao time.Duration, prazo func(tenant.Tenant) time.Duration) time.Duration {
+	if t, ok := tenant.DoContexto(c.Request.Context()); ok && prazo(t) > 0 {
+		return prazo(t)
+	}
+	return padrao
 }
 
 // @Summary Cadastra um novo cliente
@@ -30,8 +71,10 @@ func NewClienteHandler(repo repository.ClienteRepository) *ClienteHandler {
 // @Param cliente body dtos.ClienteResponse true "Dados do cliente a ser cadastrado"
 // @Success 201 {object} dtos.ClienteResponse "Cliente cadastrado com sucesso"
 // @Failure 400 {object} dtos.ResponseErro "Erro ao processar a requisição (ex: documento inválido ou JSON inválido)"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
 // @Failure 409 {object} dtos.ResponseErro "Cliente já cadastrado"
 // @Failure 500 {object} dtos.ResponseErro "Erro interno ao cadastrar o cliente"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
 // @Router /clientes [post]
 func (h *ClienteHandler) CadastrarCliente(c *gin.Context) {
 	var cliente models.Cliente
@@ -39,6 +82,7 @@ func (h *ClienteHandler) CadastrarCliente(c *gin.Context) {
 		erro := dtos.ResponseErro{
 			Mensagem: "{'error':" + err.Error() + "}",
 		}
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
 		c.JSON(http.StatusBadRequest, erro)
 		return
 	}
@@ -49,12 +93,24 @@ func (h *ClienteHandler) CadastrarCliente(c *gin.Context) {
 		erro := dtos.ResponseErro{
 			Mensagem: "{'error': 'Documento inválido'}",
 		}
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	if !utils.ValidaRazaoSocial(cliente.RazaoSocial) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Razão social inválida'}",
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoRazaoSocial)
 		c.JSON(http.StatusBadRequest, erro)
 		return
 	}
 
 	// Verifica se o cliente já existe
-	existingCliente, err := h.repo.FindByDocumento(cliente.Documento)
+	ctx, cancel := comPrazo(c, h.prazoConsulta(c))
+	defer cancel()
+	existingCliente, err := h.repo.FindByDocumento(ctx, cliente.Documento)
 
 	if err == nil && existingCliente != nil {
 		erro := dtos.ResponseErro{
@@ -63,12 +119,15 @@ func (h *ClienteHandler) CadastrarCliente(c *gin.Context) {
 		c.JSON(http.StatusConflict, erro)
 		return
 	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		responderErroBanco(c, ctx, err, "Erro ao cadastrar cliente")
+		return
+	}
 
-	if err := h.repo.Create(&cliente); err != nil {
-		erro := dtos.ResponseErro{
-			Mensagem: "{'error': 'Erro ao cadastrar cliente'}",
-		}
-		c.JSON(http.StatusInternalServerError, erro)
+	ctx, cancel = comPrazo(c, h.prazoEscrita(c))
+	defer cancel()
+	if err := h.repo.Create(ctx, &cliente); err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao cadastrar cliente")
 		return
 	}
 
@@ -83,7 +142,7 @@ func (h *ClienteHandler) CadastrarCliente(c *gin.Context) {
 
 // ListarClientes godoc
 // @Summary Lista todos os clientes com paginação
-// @Description Retorna uma lista de clientes com suporte a paginação e filtro por nome/razão social
+// @Description Retorna uma lista de clientes com suporte a paginação e filtro por nome/razão social. Com a criptografia habilitada, o filtro por razão social fica indisponível nos tenants acima do limite de busca cifrada.
 // @Tags clientes
 // @Accept json
 // @Produce json
@@ -91,21 +150,29 @@ func (h *ClienteHandler) CadastrarCliente(c *gin.Context) {
 // @Param page query int false "Número da página" default(1)
 // @Param limit query int false "Número de itens por página" default(10)
 // @Success 200 {object} dtos.ListarClientesResponse "Resposta com clientes paginados"
-// @Failure 400 {object} dtos.ResponseErro "Erro na requisição"
+// @Failure 400 {object} dtos.ResponseErro "Erro na requisição ou filtro indisponível"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
 // @Failure 500 {object} dtos.ResponseErro "Erro interno do servidor"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
 // @Router /clientes [get]
 func (h *ClienteHandler) ListarClientes(c *gin.Context) {
 	razaoSocial := c.Query("razao_social")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	clientes, total, err := h.repo.ListarClientes(razaoSocial, page, limit)
+	ctx, cancel := comPrazo(c, h.prazoConsulta(c))
+	defer cancel()
+	clientes, total, err := h.repo.ListarClientes(ctx, razaoSocial, page, limit)
 
-	if err != nil {
+	if errors.Is(err, repository.ErrBuscaIndisponivel) {
 		erro := dtos.ResponseErro{
-			Mensagem: "{'error': 'Erro ao listar clientes'}",
+			Mensagem: "{'error': 'Filtro por razão social indisponível para os clientes cifrados, liste sem o filtro ou busque pelo documento'}",
 		}
-		c.JSON(http.StatusInternalServerError, erro)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+	if err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao listar clientes")
 		return
 	}
 
@@ -146,7 +213,9 @@ func (h *ClienteHandler) ListarClientes(c *gin.Context) {
 // @Param documento path string true "Documento do cliente (CPF/CNPJ)"
 // @Success 200 {object} dtos.ClienteResponse "Cliente encontrado"
 // @Failure 400 {object} dtos.ResponseErro "Documento inválido"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
 // @Failure 404 {object} dtos.ResponseErro "Cliente não encontrado"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
 // @Router /clientes/{documento} [get]
 func (h *ClienteHandler) VerificarCliente(c *gin.Context) {
 	documento := utils.ClearNumber(c.Param("documento"))
@@ -155,17 +224,13 @@ func (h *ClienteHandler) VerificarCliente(c *gin.Context) {
 		erro := dtos.ResponseErro{
 			Mensagem: "{'error': 'Documento inválido'}",
 		}
-
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
 		c.JSON(http.StatusBadRequest, erro)
 		return
 	}
 
-	cliente, err := h.repo.FindByDocumento(documento)
-	if err != nil {
-		erro := dtos.ResponseErro{
-			Mensagem: "{'error': 'Cliente não encontrado'}",
-		}
-		c.JSON(http.StatusNotFound, erro)
+	cliente, ok := h.buscarCliente(c, documento)
+	if !ok {
 		return
 	}
 
@@ -179,8 +244,8 @@ func (h *ClienteHandler) VerificarCliente(c *gin.Context) {
 }
 
 // AtualizaCliente godoc
-// @Summary Atualiza os dados de um cliente
-// @Description Atualiza a razão social e/ou o status de blocklist de um cliente com base no documento (CPF/CNPJ) fornecido.
+// @Summary Substitui os dados de um cliente
+// @Description Substitui a razão social e o status de blocklist de um cliente com base no documento (CPF/CNPJ) fornecido. Todos os campos são obrigatórios.
 // @Tags clientes
 // @Accept json
 // @Produce json
@@ -188,10 +253,11 @@ func (h *ClienteHandler) VerificarCliente(c *gin.Context) {
 //
 // @Param body body dtos.AtualizaClienteRequest true "Dados para atualização"
 // @Success 200 {object} dtos.ClienteResponse "Cliente atualizado com sucesso"
-// @Failure 400 {object} dtos.ResponseErro "Dados inválidos ou parâmetros vazios"
+// @Failure 400 {object} dtos.ResponseErro "Dados inválidos ou campos ausentes"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
 // @Failure 404 {object} dtos.ResponseErro "Cliente não encontrado"
-// @Failure 409 {object} dtos.ResponseErro "Cliente não identificado"
 // @Failure 500 {object} dtos.ResponseErro "Erro ao atualizar cliente"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
 // @Router /clientes/{documento} [put]
 func (h *ClienteHandler) AtualizaCliente(c *gin.Context) {
 
@@ -201,31 +267,119 @@ func (h *ClienteHandler) AtualizaCliente(c *gin.Context) {
 		erro := dtos.ResponseErro{
 			Mensagem: "{'error': 'Documento inválido'}",
 		}
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
 
+	cliente, ok := h.buscarCliente(c, documento)
+	if !ok {
+		return
+	}
+
+	var dadosAtualizados dtos.AtualizaClienteRequest
+	if err := c.ShouldBindJSON(&dadosAtualizados); err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Dados inválidos: %v'}", err.Error()),
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	if mensagem, tipo := validarAtualizacao(&dadosAtualizados); mensagem != "" {
+		erro := dtos.ResponseErro{
+			Mensagem: mensagem,
+		}
+		metrics.FalhaValidacao(tipo)
 		c.JSON(http.StatusBadRequest, erro)
 		return
 	}
 
-	cliente, err := h.repo.FindByDocumento(documento)
+	ctx, cancel := comPrazo(c, h.prazoEscrita(c))
+	defer cancel()
+	clienteAtualizado, err := h.repo.UpdateByDocumento(ctx, cliente, &dadosAtualizados)
 	if err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao atualizar cliente")
+		return
+	}
+
+	response := dtos.ClienteResponse{
+		Documento:   clienteAtualizado.Documento,
+		RazaoSocial: clienteAtualizado.RazaoSocial,
+		Blocklist:   clienteAtualizado.Blocklist,
+	}
+
+	c.JSON(http.StatusOK, response)
+
+}
+
+// AtualizaParcialCliente godoc
+// @Summary Atualiza parcialmente os dados de um cliente
+// @Description Aplica um JSON Merge Patch (RFC 7396) ou JSON Patch (RFC 6902) sobre o cliente. O documento resultante é validado com as mesmas regras do cadastro e o documento (CPF/CNPJ) não pode ser alterado.
+// @Tags clientes
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param documento path string true "Documento do cliente (CPF/CNPJ)"
+// @Param body body object true "Merge patch ou lista de operações JSON Patch"
+// @Success 200 {object} dtos.ClienteResponse "Cliente atualizado com sucesso"
+// @Failure 400 {object} dtos.ResponseErro "Patch inválido ou documento resultante inválido"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Cliente não encontrado"
+// @Failure 409 {object} dtos.ResponseErro "Operação test do JSON Patch não confere"
+// @Failure 415 {object} dtos.ResponseErro "Content-Type não suportado"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao atualizar cliente"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
+// @Router /clientes/{documento} [patch]
+func (h *ClienteHandler) AtualizaParcialCliente(c *gin.Context) {
+	documento := utils.ClearNumber(c.Param("documento"))
+
+	if !utils.ValidaDocumento(documento) {
 		erro := dtos.ResponseErro{
-			Mensagem: "{'error': 'Cliente não encontrado'}",
+			Mensagem: "{'error': 'Documento inválido'}",
 		}
-		c.JSON(http.StatusNotFound, erro)
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		c.JSON(http.StatusBadRequest, erro)
 		return
 	}
 
-	var dadosAtualizados dtos.AtualizaClienteRequest
-	if err := c.ShouldBindJSON(&dadosAtualizados); err != nil {
+	var aplicarPatch func(documento, patch []byte) ([]byte, error)
+	switch c.ContentType() {
+	case "application/merge-patch+json":
+		aplicarPatch = utils.AplicarMergePatch
+	case "application/json-patch+json":
+		aplicarPatch = utils.AplicarJSONPatch
+	default:
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Content-Type não suportado, utilize application/merge-patch+json ou application/json-patch+json'}",
+		}
+		c.JSON(http.StatusUnsupportedMediaType, erro)
+		return
+	}
+
+	cliente, ok := h.buscarCliente(c, documento)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
 		erro := dtos.ResponseErro{
 			Mensagem: fmt.Sprintf("{'error': 'Dados inválidos: %v'}", err.Error()),
 		}
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
 		c.JSON(http.StatusBadRequest, erro)
 		return
 	}
 
-	clienteAtualizado, err := h.repo.UpdateByDocumento(cliente, &dadosAtualizados)
+	atual, err := json.Marshal(dtos.ClienteResponse{
+		Documento:   cliente.Documento,
+		RazaoSocial: cliente.RazaoSocial,
+		Blocklist:   cliente.Blocklist,
+	})
 	if err != nil {
+		c.Error(err)
 		erro := dtos.ResponseErro{
 			Mensagem: "{'error': 'Erro ao atualizar cliente'}",
 		}
@@ -233,6 +387,61 @@ func (h *ClienteHandler) AtualizaCliente(c *gin.Context) {
 		return
 	}
 
+	resultado, err := aplicarPatch(atual, patch)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, utils.ErrTesteFalhou) {
+			status = http.StatusConflict
+		}
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Patch inválido: %v'}", err.Error()),
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoPatch)
+		c.JSON(status, erro)
+		return
+	}
+
+	var clienteResultante struct {
+		Documento *string `json:"documento"`
+		dtos.AtualizaClienteRequest
+	}
+	decoder := json.NewDecoder(bytes.NewReader(resultado))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&clienteResultante); err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Dados inválidos: %v'}", err.Error()),
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	if clienteResultante.Documento == nil || utils.ClearNumber(*clienteResultante.Documento) != cliente.Documento {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'O documento do cliente não pode ser alterado'}",
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	if mensagem, tipo := validarAtualizacao(&clienteResultante.AtualizaClienteRequest); mensagem != "" {
+		erro := dtos.ResponseErro{
+			Mensagem: mensagem,
+		}
+		metrics.FalhaValidacao(tipo)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	ctx, cancel := comPrazo(c, h.prazoEscrita(c))
+	defer cancel()
+	clienteAtualizado, err := h.repo.UpdateByDocumento(ctx, cliente, &clienteResultante.AtualizaClienteRequest)
+	if err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao atualizar cliente")
+		return
+	}
+
 	response := dtos.ClienteResponse{
 		Documento:   clienteAtualizado.Documento,
 		RazaoSocial: clienteAtualizado.RazaoSocial,
@@ -240,7 +449,42 @@ func (h *ClienteHandler) AtualizaCliente(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, response)
+}
+
+// buscarCliente busca o cliente pelo documento e, se não conseguir, responde 404, 504 ou 500
+func (h *ClienteHandler) buscarCliente(c *gin.Context, documento string) (*models.Cliente, bool) {
+	ctx, cancel := comPrazo(c, h.prazoConsulta(c))
+	defer cancel()
+
+	cliente, err := h.repo.FindByDocumento(ctx, documento)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Cliente não encontrado'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return nil, false
+	}
+	if err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao buscar cliente")
+		return nil, false
+	}
+	return cliente, true
+}
 
+// validarAtualizacao aplica aos dados de atualização as mesmas regras usadas no cadastro,
+// exigindo todos os campos já que a atualização substitui o cliente por completo.
+// Retorna a mensagem de erro e o tipo da falha de validação.
+func validarAtualizacao(dados *dtos.AtualizaClienteRequest) (string, string) {
+	if dados.RazaoSocial == nil {
+		return "{'error': 'Campo razaosocial obrigatório'}", metrics.ValidacaoDados
+	}
+	if !utils.ValidaRazaoSocial(*dados.RazaoSocial) {
+		return "{'error': 'Razão social inválida'}", metrics.ValidacaoRazaoSocial
+	}
+	if dados.Blocklist == nil {
+		return "{'error': 'Campo blocklist obrigatório'}", metrics.ValidacaoDados
+	}
+	return "", ""
 }
 
 // DeletarCliente godoc
@@ -252,8 +496,10 @@ func (h *ClienteHandler) AtualizaCliente(c *gin.Context) {
 // @Param documento path string true "Documento do cliente (CPF/CNPJ)"
 // @Success 200 {object} dtos.ResponseSucesso "Cliente deletado com sucesso"
 // @Failure 400 {object} dtos.ResponseErro "Documento inválido"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
 // @Failure 404 {object} dtos.ResponseErro "Cliente não encontrado"
 // @Failure 500 {object} dtos.ResponseErro "Erro ao deletar cliente"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
 // @Router /clientes/{documento} [delete]
 func (h *ClienteHandler) DeletarCliente(c *gin.Context) {
 	documento := utils.ClearNumber(c.Param("documento"))
@@ -262,25 +508,19 @@ func (h *ClienteHandler) DeletarCliente(c *gin.Context) {
 		erro := dtos.ResponseErro{
 			Mensagem: "{'error': 'Documento inválido'}",
 		}
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
 		c.JSON(http.StatusBadRequest, erro)
 		return
 	}
 
-	_, err := h.repo.FindByDocumento(documento)
-	if err != nil {
-		erro := dtos.ResponseErro{
-			Mensagem: "{'error': 'Cliente não encontrado'}",
-		}
-		c.JSON(http.StatusNotFound, erro)
+	if _, ok := h.buscarCliente(c, documento); !ok {
 		return
 	}
 
-	if err := h.repo.DeleteByDocumento(documento); err != nil {
-
-		erro := dtos.ResponseErro{
-			Mensagem: "{'error': 'Erro ao deletar cliente'}",
-		}
-		c.JSON(http.StatusInternalServerError, erro)
+	ctx, cancel := comPrazo(c, h.prazoEscrita(c))
+	defer cancel()
+	if err := h.repo.DeleteByDocumento(ctx, documento); err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao deletar cliente")
 		return
 	}
 
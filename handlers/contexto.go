@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// comPrazo deriva do contexto da requisição um contexto limitado ao prazo da operação,
+// cancelado também quando o cliente desconecta
+func comPrazo(c *gin.Context, prazo time.Duration) (context.Context, context.CancelFunc) {
+	if prazo <= 0 {
+		return context.WithCancel(c.Request.Context())
+	}
+	return context.WithTimeout(c.Request.Context(), prazo)
+}
+
+// tempoEsgotado indica se a operação falhou por ter excedido o prazo ou por ter sido cancelada
+func tempoEsgotado(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || ctx.Err() != nil
+}
+
+// statusErroBanco retorna 504 para operações interrompidas pelo prazo ou cancelamento e 500 nos demais casos
+func statusErroBanco(ctx context.Context, err error) int {
+	if tempoEsgotado(ctx, err) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// responderErroBanco registra o erro e responde 504 ou 500 conforme a causa da falha, com o id do
+// trace da requisição
+func responderErroBanco(c *gin.Context, ctx context.Context, err error, mensagem string) {
+	c.Error(err)
+	status := statusErroBanco(ctx, err)
+	if status == http.StatusGatewayTimeout {
+		mensagem = "Tempo limite da operação excedido"
+	}
+	erro := dtos.ResponseErro{
+		Mensagem: "{'error': '" + mensagem + "'}",
+		TraceID:  tracing.TraceID(c.Request.Context()),
+	}
+	c.JSON(status, erro)
+}
+
+// tenantDaRequisicao retorna o tenant identificado pelo TenantMiddleware. Sem ele a requisição é
+// recusada com 401, para que nenhum handler opere fora do escopo de um tenant.
+func tenantDaRequisicao(c *gin.Context) (tenant.Tenant, bool) {
+	t, ok := tenant.DoContexto(c.Request.Context())
+	if !ok {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Tenant não identificado'}",
+		}
+		c.JSON(http.StatusUnauthorized, erro)
+	}
+	return t, ok
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tamanhoLoteEventos é a quantidade máxima de eventos lida do log a cada consulta
+const tamanhoLoteEventos = 100
+
+// tamanhoLoteSequencia é a quantidade máxima de ids verificada a cada consulta em busca de lacunas
+const tamanhoLoteSequencia = 1000
+
+type EventoHandler struct {
+	repo repository.EventoRepository
+
+	// IntervaloConsulta é a frequência com que o log de eventos é consultado
+	IntervaloConsulta time.Duration
+	// IntervaloHeartbeat é a frequência dos comentários enviados para manter a conexão aberta
+	IntervaloHeartbeat time.Duration
+	// TempoLacuna é quanto o stream aguarda um id ausente antes de seguir sem ele. Os ids são
+	// reservados na inserção e as transações podem ser confirmadas fora de ordem, então os eventos
+	// após um id ausente ficam retidos até que ele apareça; caso contrário o evento confirmado
+	// depois ficaria para trás do Last-Event-ID. Ids de transações desfeitas e de eventos expurgados
+	// nunca aparecem, por isso a lacuna é abandonada após esse tempo ou quando o evento seguinte a
+	// ela foi gravado há mais tempo que isso.
+	TempoLacuna time.Duration
+
+	encerrando chan struct{}
+	encerrar   sync.Once
+}
+
+func NewEventoHandler(repo repository.EventoRepository) *EventoHandler {
+	return &EventoHandler{
+		repo:               repo,
+		IntervaloConsulta:  time.Second,
+		IntervaloHeartbeat: 15 * time.Second,
+		TempoLacuna:        time.Minute,
+		encerrando:         make(chan struct{}),
+	}
+}
+
+// Encerrar finaliza os streams abertos. Como nunca ficam ociosos, sem isso eles impediriam
+// o servidor de concluir o encerramento; os clientes reconectam em outra instância.
+func (h *EventoHandler) Encerrar() {
+	h.encerrar.Do(func() { close(h.encerrando) })
+}
+
+// StreamEventos godoc
+// @Summary Stream de eventos de clientes (Server-Sent Events)
+// @Description Envia em tempo real os eventos cliente.criado, cliente.atualizado, cliente.removido e blocklist.alterado.
+// @Description Para retomar após uma desconexão, informe o id do último evento recebido no header Last-Event-ID ou no parâmetro last_event_id.
+// @Description Sem esse id somente eventos novos são enviados. Os eventos saem na ordem dos ids: um evento cuja transação ainda não foi confirmada retém os seguintes por até um minuto.
+// @Tags eventos
+// @Produce text/event-stream
+// @Param tipo query string false "Tipos de evento separados por vírgula"
+// @Param documento query string false "Documento do cliente (CPF/CNPJ)"
+// @Param last_event_id query int false "Id do último evento recebido"
+// @Param Last-Event-ID header int false "Id do último evento recebido"
+// @Success 200 {string} string "Stream de eventos"
+// @Failure 400 {object} dtos.ResponseErro "Filtro inválido"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao consultar eventos"
+// @Router /eventos [get]
+func (h *EventoHandler) StreamEventos(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	var tipos []string
+	if filtro := c.Query("tipo"); filtro != "" {
+		for _, tipo := range strings.Split(filtro, ",") {
+			tipo = strings.TrimSpace(tipo)
+			if !eventoValido(tipo) {
+				erro := dtos.ResponseErro{
+					Mensagem: fmt.Sprintf("{'error': 'Evento desconhecido: %s'}", tipo),
+				}
+				c.JSON(http.StatusBadRequest, erro)
+				return
+			}
+			tipos = append(tipos, tipo)
+		}
+	}
+
+	documento := utils.ClearNumber(c.Query("documento"))
+
+	ultimoID, err := h.ultimoEventoRecebido(c)
+	if err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Last-Event-ID inválido'}",
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+	if ultimoID == nil {
+		atual, err := h.repo.UltimoID()
+		if err != nil {
+			c.Error(err)
+			erro := dtos.ResponseErro{
+				Mensagem: "{'error': 'Erro ao consultar eventos'}",
+			}
+			c.JSON(http.StatusInternalServerError, erro)
+			return
+		}
+		ultimoID = &atual
+	}
+
+	// O stream é longo por natureza e não deve ser interrompido pelo timeout de escrita do servidor
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	consulta := time.NewTicker(h.IntervaloConsulta)
+	defer consulta.Stop()
+	heartbeat := time.NewTicker(h.IntervaloHeartbeat)
+	defer heartbeat.Stop()
+
+	var pendente *lacuna
+	for {
+		sequencia, err := h.repo.ListarSequencia(*ultimoID, tamanhoLoteSequencia)
+		if err != nil {
+			// O cliente reconecta automaticamente e retoma a partir do último id recebido
+			return
+		}
+		var fronteira uint
+		fronteira, pendente = h.fronteira(*ultimoID, sequencia, pendente, time.Now())
+
+		enviados := 0
+		if fronteira > *ultimoID {
+			eventos, err := h.repo.ListarApos(t.ID, *ultimoID, tipos, documento, tamanhoLoteEventos)
+			if err != nil {
+				return
+			}
+			for i := range eventos {
+				if eventos[i].ID > fronteira {
+					break
+				}
+				if err := escreverEvento(c.Writer, &eventos[i]); err != nil {
+					return
+				}
+				*ultimoID = eventos[i].ID
+				enviados++
+			}
+			if enviados > 0 {
+				c.Writer.Flush()
+			}
+			// Os eventos do tenant até a fronteira foram todos enviados
+			if enviados < tamanhoLoteEventos {
+				*ultimoID = fronteira
+			}
+		}
+		if enviados == tamanhoLoteEventos || (pendente == nil && len(sequencia) == tamanhoLoteSequencia) {
+			continue
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.encerrando:
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-consulta.C:
+		}
+	}
+}
+
+// lacuna é o intervalo de ids inicio..fim-1 ausente do log, que retém os eventos a partir de fim
+type lacuna struct {
+	inicio, fim uint
+	desde       time.Time
+}
+
+// fronteira retorna o maior id até o qual todos os eventos do log já foram confirmados, ou tiveram
+// a lacuna abandonada, e a lacuna que impede o stream de avançar além dele. pendente é a lacuna da
+// consulta anterior, que mantém a espera quando continua sem ser preenchida.
+func (h *EventoHandler) fronteira(ultimoID uint, sequencia []models.Evento, pendente *lacuna, agora time.Time) (uint, *lacuna) {
+	fronteira := ultimoID
+	for i := range sequencia {
+		if sequencia[i].ID > fronteira+1 {
+			atual := lacuna{inicio: fronteira + 1, fim: sequencia[i].ID, desde: agora}
+			if pendente != nil && atual.inicio >= pendente.inicio && atual.inicio < pendente.fim {
+				atual.desde = pendente.desde
+			}
+			// A transação que reservou o id ausente começou antes da gravação do evento seguinte
+			if agora.Sub(atual.desde) < h.TempoLacuna && agora.Sub(sequencia[i].CreatedAt) < h.TempoLacuna {
+				return fronteira, &atual
+			}
+		}
+		fronteira = sequencia[i].ID
+	}
+	return fronteira, nil
+}
+
+func (h *EventoHandler) ultimoEventoRecebido(c *gin.Context) (*uint, error) {
+	valor := c.GetHeader("Last-Event-ID")
+	if valor == "" {
+		valor = c.Query("last_event_id")
+	}
+	if valor == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(valor, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	ultimo := uint(id)
+	return &ultimo, nil
+}
+
+func escreverEvento(w io.Writer, evento *models.Evento) error {
+	dados, err := json.Marshal(dtos.EventoResponse{
+		ID:        evento.ID,
+		Tipo:      evento.Tipo,
+		Documento: evento.Documento,
+		CriadoEm:  evento.CreatedAt,
+		Dados:     json.RawMessage(evento.Payload),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evento.ID, evento.Tipo, dados)
+	return err
+}
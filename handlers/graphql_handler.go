@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/graphqlapi"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler struct {
+	servico *graphqlapi.Servico
+}
+
+func NewGraphQLHandler(servico *graphqlapi.Servico) *GraphQLHandler {
+	return &GraphQLHandler{servico: servico}
+}
+
+// ExecutarGraphQL godoc
+// @Summary Executa uma consulta ou mutation GraphQL
+// @Description Consulta os clientes e o histórico de eventos de cada um, com os mesmos filtros e validações da API REST, e cadastra, atualiza ou altera a blocklist dos clientes. O esquema está em graphqlapi/schema.graphql e pode ser obtido por introspecção. Erros de execução são respondidos com status 200 e listados em errors, com o código e o status HTTP equivalente da API REST em extensions.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param body body dtos.GraphQLRequest true "Consulta, nome da operação e variáveis"
+// @Success 200 {object} dtos.GraphQLResponse "Resultado da operação"
+// @Failure 400 {object} dtos.ResponseErro "Requisição inválida"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Router /graphql [post]
+func (h *GraphQLHandler) ExecutarGraphQL(c *gin.Context) {
+	if _, ok := tenantDaRequisicao(c); !ok {
+		return
+	}
+
+	var requisicao dtos.GraphQLRequest
+	if err := c.ShouldBindJSON(&requisicao); err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Dados inválidos: " + err.Error() + "'}",
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+	if requisicao.Query == "" {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Campo query obrigatório'}",
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoDados)
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	resposta := h.servico.Executar(c.Request.Context(), requisicao.Query, requisicao.OperationName, requisicao.Variables)
+	c.JSON(http.StatusOK, resposta)
+}
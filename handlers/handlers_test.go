@@ -1,31 +1,67 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"errors"
+	"time"
 
+	"github.com/Gileno29/clientes-API/agendador"
+	"github.com/Gileno29/clientes-API/blocklist"
+	"github.com/Gileno29/clientes-API/config"
 	"github.com/Gileno29/clientes-API/database"
 	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/graphqlapi"
+	"github.com/Gileno29/clientes-API/health"
+	"github.com/Gileno29/clientes-API/jobs"
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/middlewares"
+	"github.com/Gileno29/clientes-API/migrations"
 
 	"github.com/Gileno29/clientes-API/models"
 	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/retencao"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"gorm.io/gorm"
 )
 
 // setupDB inicializa um banco de dados SQLite em memória para testes
 func setupDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
+	cfg := config.Padrao().BancoDeDados
+	cfg.Driver = config.DriverSQLite
+	cfg.Arquivo = "file::memory:?cache=shared"
+	if err := database.Connect(cfg); err != nil {
 		panic("Falha ao conectar ao banco de dados")
 	}
-	// Cria a tabela de clientes
-	db.AutoMigrate(&models.Cliente{})
+	db := database.DB
+	// Cria as tabelas utilizadas pelos handlers com as mesmas migrações da aplicação
+	migrador, err := migrations.NewMigrador(db)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := migrador.Subir(context.Background(), 0); err != nil {
+		panic(err)
+	}
 	return db
 }
 
@@ -36,14 +72,30 @@ func setupRouter(db *gorm.DB) *gin.Engine {
 	clienteHandler := NewClienteHandler(clienteRepo)
 
 	suporteHandler := NewSuporteHandler()
+	webhookHandler := NewWebhookHandler(repository.NewWebhookRepository(db))
 
 	router := gin.Default()
+	router.Use(otelgin.Middleware(tracing.NomeServico))
+	router.Use(middlewares.RequestIDMiddleware())
+	router.Use(middlewares.LeituraAposEscritaMiddleware())
+	router.Use(middlewares.MetricsMiddleware())
+	router.Use(middlewares.HandlerSpanMiddleware())
+	// Sem tenants configurados todas as requisições pertencem ao tenant padrão
+	router.Use(middlewares.TenantMiddleware(tenant.NewResolvedor(nil, nil, "", false)))
 	router.POST("/clientes", clienteHandler.CadastrarCliente)
+	router.POST("/clientes/lote", clienteHandler.ProcessarLote)
 	router.GET("/clientes", clienteHandler.ListarClientes)
 	router.GET("/clientes/:documento", clienteHandler.VerificarCliente)
 	router.PUT("/clientes/:documento", clienteHandler.AtualizaCliente)
+	router.PATCH("/clientes/:documento", clienteHandler.AtualizaParcialCliente)
 	router.DELETE("/clientes/:documento", clienteHandler.DeletarCliente)
 	router.GET("/status", suporteHandler.Status)
+	router.GET("/metrics", suporteHandler.Metricas)
+	router.POST("/webhooks", webhookHandler.CadastrarWebhook)
+	router.GET("/webhooks", webhookHandler.ListarWebhooks)
+	router.GET("/webhooks/:id", webhookHandler.ConsultarWebhook)
+	router.DELETE("/webhooks/:id", webhookHandler.DeletarWebhook)
+	router.GET("/webhooks/:id/entregas", webhookHandler.ListarEntregasWebhook)
 	return router
 }
 
@@ -58,7 +110,7 @@ func TestCadastrarCliente(t *testing.T) {
 
 	// Caso de sucesso: Cliente válido
 	t.Run("Cadastra cliente com sucesso", func(t *testing.T) {
-		body := `{"documento": "52998224725", "razao_social": "João Silva", "blocklist": false}`
+		body := `{"documento": "52998224725", "razaosocial": "João Silva", "blocklist": false}`
 		req, _ := http.NewRequest("POST", "/clientes", strings.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 
@@ -70,7 +122,7 @@ func TestCadastrarCliente(t *testing.T) {
 
 	// Caso de erro: Documento inválido
 	t.Run("Retorna erro para documento inválido", func(t *testing.T) {
-		body := `{"documento": "123", "razao_social": "João Silva", "blocklist": false}`
+		body := `{"documento": "123", "razaosocial": "João Silva", "blocklist": false}`
 		req, _ := http.NewRequest("POST", "/clientes", strings.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 
@@ -83,9 +135,9 @@ func TestCadastrarCliente(t *testing.T) {
 	// Caso de erro: Cliente já cadastrado
 	t.Run("Retorna erro para cliente já cadastrado", func(t *testing.T) {
 		// Insere um cliente no banco de dados
-		db.Create(&models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
+		db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
 
-		body := `{"documento": "52998224725", "razao_social": "João Silva", "blocklist": false}`
+		body := `{"documento": "52998224725", "razaosocial": "João Silva", "blocklist": false}`
 		req, _ := http.NewRequest("POST", "/clientes", strings.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 
@@ -94,6 +146,18 @@ func TestCadastrarCliente(t *testing.T) {
 
 		assert.Equal(t, http.StatusConflict, resp.Code, "Status code deve ser 409")
 	})
+
+	// Caso de erro: Razão social em branco
+	t.Run("Retorna erro para razão social em branco", func(t *testing.T) {
+		body := `{"documento": "33000167000101", "razaosocial": " ", "blocklist": false}`
+		req, _ := http.NewRequest("POST", "/clientes", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
 }
 
 func TestListarClientes(t *testing.T) {
@@ -102,8 +166,8 @@ func TestListarClientes(t *testing.T) {
 	clearTable(db)
 
 	// Insere alguns clientes no banco de dados
-	db.Create(&models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
-	db.Create(&models.Cliente{Documento: "33000167000101", RazaoSocial: "Empresa XYZ", Blocklist: true})
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "33000167000101", RazaoSocial: "Empresa XYZ", Blocklist: true})
 
 	// Caso de sucesso: Listar clientes sem filtro
 	t.Run("Lista clientes sem filtro", func(t *testing.T) {
@@ -139,7 +203,7 @@ func TestVerificarCliente(t *testing.T) {
 	clearTable(db)
 
 	// Insere um cliente no banco de dados
-	db.Create(&models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
 
 	// Caso de sucesso: Cliente encontrado
 	t.Run("Retorna cliente encontrado", func(t *testing.T) {
@@ -169,13 +233,79 @@ func TestVerificarCliente(t *testing.T) {
 	})
 }
 
+func TestConsultarBlocklist(t *testing.T) {
+	db := setupDB()
+	clearTable(db)
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true})
+
+	base := repository.NewClienteRepository(db)
+	indice := blocklist.NewIndice([]string{tenant.Padrao}, func(ctx context.Context, t string) ([]string, error) {
+		return base.ListarBloqueados(tenant.ComID(ctx, t))
+	})
+	assert.NoError(t, indice.Sincronizar(context.Background()))
+	clienteHandler := NewClienteHandler(repository.NewClienteRepositoryComIndice(base, indice))
+	blocklistHandler := NewBlocklistHandler(indice, lgpd.NewPseudonimizador(""))
+
+	router := gin.New()
+	router.Use(middlewares.TenantMiddleware(tenant.NewResolvedor(nil, nil, "", false)))
+	router.PUT("/clientes/:documento", clienteHandler.AtualizaCliente)
+	router.GET("/blocklist/:documento", blocklistHandler.ConsultarBlocklist)
+
+	consultar := func(documento string) (int, dtos.BlocklistResponse) {
+		req, _ := http.NewRequest("GET", "/blocklist/"+documento, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var resposta dtos.BlocklistResponse
+		json.Unmarshal(resp.Body.Bytes(), &resposta)
+		return resp.Code, resposta
+	}
+
+	// Caso de sucesso: Documento carregado do banco na inicialização
+	t.Run("Retorna o documento em blocklist", func(t *testing.T) {
+		status, resposta := consultar("529.982.247-25")
+		assert.Equal(t, http.StatusOK, status, "Status code deve ser 200")
+		assert.True(t, resposta.Blocklist)
+		assert.Equal(t, "52998224725", resposta.Documento)
+		assert.False(t, resposta.SincronizadoEm.IsZero())
+	})
+
+	// Caso de sucesso: Documento não cadastrado não está em blocklist
+	t.Run("Retorna false para documento não cadastrado", func(t *testing.T) {
+		status, resposta := consultar("86405508838")
+		assert.Equal(t, http.StatusOK, status, "Status code deve ser 200")
+		assert.False(t, resposta.Blocklist)
+	})
+
+	// Caso de sucesso: A atualização pela API chega ao índice com nova versão
+	t.Run("Reflete a atualização do cliente", func(t *testing.T) {
+		_, antes := consultar("52998224725")
+
+		body := `{"razaosocial": "João Silva", "blocklist": false}`
+		req, _ := http.NewRequest("PUT", "/clientes/52998224725", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		_, depois := consultar("52998224725")
+		assert.False(t, depois.Blocklist)
+		assert.Greater(t, depois.Versao, antes.Versao)
+	})
+
+	// Caso de erro: Documento inválido
+	t.Run("Retorna erro para documento inválido", func(t *testing.T) {
+		status, _ := consultar("123")
+		assert.Equal(t, http.StatusBadRequest, status, "Status code deve ser 400")
+	})
+}
+
 func TestAtualizaCliente(t *testing.T) {
 	db := setupDB()
 	router := setupRouter(db)
 	clearTable(db)
 
 	// Insere um cliente no banco de dados
-	db.Create(&models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
 
 	// Verifica se o cliente foi criado corretamente
 	var cliente models.Cliente
@@ -184,7 +314,7 @@ func TestAtualizaCliente(t *testing.T) {
 
 	// Caso de sucesso: Atualiza razão social e blocklist
 	t.Run("Atualiza cliente com sucesso", func(t *testing.T) {
-		body := `{"razao_social": "João da Silva", "blocklist": true}`
+		body := `{"razaosocial": "João da Silva", "blocklist": true}`
 		req, _ := http.NewRequest("PUT", "/clientes/52998224725", strings.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 
@@ -222,6 +352,95 @@ func TestAtualizaCliente(t *testing.T) {
 		assert.Contains(t, erroResponse.Mensagem, "Dados inválidos", "Mensagem de erro deve indicar dados inválidos")
 	})
 
+	// Caso de erro: PUT substitui o cliente por completo, então todos os campos são obrigatórios
+	t.Run("Retorna erro quando falta algum campo", func(t *testing.T) {
+		body := `{"blocklist": false}`
+		req, _ := http.NewRequest("PUT", "/clientes/52998224725", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
+
+	// Caso de erro: Razão social em branco não é mais ignorada silenciosamente
+	t.Run("Retorna erro para razão social em branco", func(t *testing.T) {
+		body := `{"razaosocial": " ", "blocklist": false}`
+		req, _ := http.NewRequest("PUT", "/clientes/52998224725", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
+
+}
+
+func TestAtualizaParcialCliente(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+	clearTable(db)
+
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
+
+	patch := func(contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", "/clientes/52998224725", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Caso de sucesso: Merge patch altera somente os campos informados
+	t.Run("Aplica merge patch", func(t *testing.T) {
+		resp := patch("application/merge-patch+json", `{"blocklist": true}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		var cliente dtos.ClienteResponse
+		json.Unmarshal(resp.Body.Bytes(), &cliente)
+		assert.True(t, cliente.Blocklist)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial)
+	})
+
+	// Caso de sucesso: JSON Patch com operações test e replace
+	t.Run("Aplica JSON patch", func(t *testing.T) {
+		body := `[{"op": "test", "path": "/blocklist", "value": true}, {"op": "replace", "path": "/razaosocial", "value": "João da Silva"}]`
+		resp := patch("application/json-patch+json", body)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		var cliente models.Cliente
+		db.Where("documento = ?", "52998224725").First(&cliente)
+		assert.Equal(t, "João da Silva", cliente.RazaoSocial)
+	})
+
+	// Caso de erro: Operação test que não confere
+	t.Run("Retorna conflito quando test falha", func(t *testing.T) {
+		resp := patch("application/json-patch+json", `[{"op": "test", "path": "/blocklist", "value": false}]`)
+		assert.Equal(t, http.StatusConflict, resp.Code, "Status code deve ser 409")
+	})
+
+	// Caso de erro: Remover a razão social gera um documento inválido
+	t.Run("Retorna erro quando o resultado é inválido", func(t *testing.T) {
+		resp := patch("application/merge-patch+json", `{"razaosocial": null}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+
+		resp = patch("application/merge-patch+json", `{"razaosocial": " "}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
+
+	// Caso de erro: O documento não pode ser alterado
+	t.Run("Retorna erro ao alterar o documento", func(t *testing.T) {
+		resp := patch("application/merge-patch+json", `{"documento": "33000167000101"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
+
+	// Caso de erro: Content-Type não suportado
+	t.Run("Retorna erro para Content-Type não suportado", func(t *testing.T) {
+		resp := patch("application/json", `{"blocklist": false}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code, "Status code deve ser 415")
+	})
 }
 
 func TestDeletarCliente(t *testing.T) {
@@ -229,7 +448,7 @@ func TestDeletarCliente(t *testing.T) {
 	router := setupRouter(db)
 
 	// Insere um cliente no banco de dados
-	db.Create(&models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
 
 	// Caso de sucesso: Deleta cliente
 	t.Run("Deleta cliente com sucesso", func(t *testing.T) {
@@ -260,15 +479,1345 @@ func TestDeletarCliente(t *testing.T) {
 
 }
 
-func TestStatus(t *testing.T) {
-	router := setupRouter(setupDB())
+func TestProcessarLote(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+	clearTable(db)
 
-	// Caso de sucesso: Retorna status do servidor
-	t.Run("Retorna status do servidor", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/status", nil)
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: false})
+
+	enviar := func(body string) (*httptest.ResponseRecorder, dtos.LoteResponse) {
+		req, _ := http.NewRequest("POST", "/clientes/lote", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var lote dtos.LoteResponse
+		json.Unmarshal(resp.Body.Bytes(), &lote)
+		return resp, lote
+	}
+
+	// Caso de erro: No modo tudo_ou_nada uma falha desfaz todas as operações
+	t.Run("Desfaz o lote tudo_ou_nada quando uma operação falha", func(t *testing.T) {
+		body := `{"modo": "tudo_ou_nada", "operacoes": [
+			{"operacao": "criar", "documento": "33000167000101", "razaosocial": "Empresa XYZ"},
+			{"operacao": "blocklist", "documento": "52998224725", "blocklist": true},
+			{"operacao": "remover", "documento": "12345678909"}
+		]}`
+		resp, lote := enviar(body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, "Status code deve ser 422")
+		assert.Equal(t, 0, lote.Sucesso)
+		assert.Equal(t, http.StatusNotFound, lote.Resultados[2].Status)
+		assert.Equal(t, http.StatusFailedDependency, lote.Resultados[0].Status)
+
+		var total int64
+		db.Model(&models.Cliente{}).Where("documento = ?", "33000167000101").Count(&total)
+		assert.Equal(t, int64(0), total, "Cadastro deve ter sido desfeito")
+
+		var cliente models.Cliente
+		db.Where("documento = ?", "52998224725").First(&cliente)
+		assert.False(t, cliente.Blocklist, "Alteração de blocklist deve ter sido desfeita")
+	})
+
+	// Caso de sucesso: No modo melhor_esforco as operações válidas são aplicadas
+	t.Run("Aplica as operações válidas no modo melhor_esforco", func(t *testing.T) {
+		body := `{"modo": "melhor_esforco", "operacoes": [
+			{"operacao": "criar", "documento": "33.000.167/0001-01", "razaosocial": "Empresa XYZ", "blocklist": true},
+			{"operacao": "atualizar", "documento": "52998224725", "razaosocial": " ", "blocklist": true},
+			{"operacao": "blocklist", "documento": "52998224725", "blocklist": true}
+		]}`
+		resp, lote := enviar(body)
+
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		assert.Equal(t, 2, lote.Sucesso)
+		assert.Equal(t, 1, lote.Falhas)
+		assert.Equal(t, http.StatusCreated, lote.Resultados[0].Status)
+		assert.Equal(t, http.StatusBadRequest, lote.Resultados[1].Status)
+
+		var cliente models.Cliente
+		db.Where("documento = ?", "52998224725").First(&cliente)
+		assert.True(t, cliente.Blocklist)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial)
+	})
+
+	// Caso de erro: Lote sem operações ou com modo inválido
+	t.Run("Retorna erro para lote inválido", func(t *testing.T) {
+		resp, _ := enviar(`{"modo": "tudo_ou_nada", "operacoes": []}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+
+		resp, _ = enviar(`{"modo": "parcial", "operacoes": [{"operacao": "remover", "documento": "52998224725"}]}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
+
+	// Caso de erro: Lote acima do limite de operações
+	t.Run("Retorna erro para lote acima do limite", func(t *testing.T) {
+		operacoes := make([]dtos.OperacaoLoteRequest, MaxOperacoesLote+1)
+		for i := range operacoes {
+			operacoes[i] = dtos.OperacaoLoteRequest{Operacao: "remover", Documento: "52998224725"}
+		}
+		body, _ := json.Marshal(dtos.LoteRequest{Modo: ModoMelhorEsforco, Operacoes: operacoes})
+		resp, _ := enviar(string(body))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code, "Status code deve ser 413")
+	})
+}
+
+func TestJobs(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+	clearTable(db)
+	db.Exec("DELETE FROM jobs")
+
+	manager := jobs.NewManager(repository.NewJobRepository(db), 2)
+	manager.IntervaloFila = 10 * time.Millisecond
+	manager.IntervaloHeartbeat = 10 * time.Millisecond
+	jobs.RegistrarExecutoresClientes(manager, repository.NewClienteRepository(db))
+	jobHandler := NewJobHandler(manager)
+	router.POST("/jobs/:tipo", jobHandler.SubmeterJob)
+	router.GET("/jobs/:id", jobHandler.ConsultarJob)
+	router.GET("/jobs/:id/resultado", jobHandler.BaixarResultadoJob)
+	router.DELETE("/jobs/:id", jobHandler.CancelarJob)
+
+	submeter := func(tipo, contentType, body string) (*httptest.ResponseRecorder, dtos.JobResponse) {
+		req, _ := http.NewRequest("POST", "/jobs/"+tipo, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
 		resp := httptest.NewRecorder()
 		router.ServeHTTP(resp, req)
 
+		var job dtos.JobResponse
+		json.Unmarshal(resp.Body.Bytes(), &job)
+		return resp, job
+	}
+
+	consultar := func(id string) dtos.JobResponse {
+		req, _ := http.NewRequest("GET", "/jobs/"+id, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var job dtos.JobResponse
+		json.Unmarshal(resp.Body.Bytes(), &job)
+		return job
+	}
+
+	// Caso de sucesso: Job pendente pode ser cancelado antes dos workers iniciarem
+	t.Run("Cancela job pendente", func(t *testing.T) {
+		_, job := submeter(jobs.TipoRevalidacao, "application/json", "")
+
+		req, _ := http.NewRequest("DELETE", "/jobs/"+job.ID, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusAccepted, resp.Code, "Status code deve ser 202")
+		assert.Equal(t, models.JobCancelado, consultar(job.ID).Status)
+
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusConflict, resp.Code, "Status code deve ser 409")
+	})
+
+	manager.Iniciar(context.Background())
+	defer manager.Parar()
+
+	// Caso de sucesso: Importa um CSV e exporta o resultado
+	t.Run("Importa e exporta clientes", func(t *testing.T) {
+		csv := "documento,razaosocial,blocklist\n529.982.247-25,João Silva,false\n123,Inválido,false\n33000167000101,Empresa XYZ,true\n"
+		resp, job := submeter(jobs.TipoImportacao, "text/csv", csv)
+		assert.Equal(t, http.StatusAccepted, resp.Code, "Status code deve ser 202")
+		assert.Equal(t, "/jobs/"+job.ID, resp.Header().Get("Location"))
+
+		assert.Eventually(t, func() bool {
+			return consultar(job.ID).Status == models.JobConcluido
+		}, 5*time.Second, 10*time.Millisecond)
+		importacao := consultar(job.ID)
+		assert.Equal(t, 3, importacao.Processados)
+		assert.Len(t, importacao.Erros, 1, "A linha com documento inválido deve ser registrada")
+
+		_, job = submeter(jobs.TipoExportacao, "application/json", `{"somente_blocklist": true}`)
+		assert.Eventually(t, func() bool {
+			return consultar(job.ID).Status == models.JobConcluido
+		}, 5*time.Second, 10*time.Millisecond)
+
+		req, _ := http.NewRequest("GET", "/jobs/"+job.ID+"/resultado", nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		assert.Equal(t, "documento,razaosocial,blocklist\n33000167000101,Empresa XYZ,true\n", resp.Body.String())
+	})
+
+	// Caso de erro: Expurgo sem filtro e sem confirmação removeria todos os clientes
+	t.Run("Exige confirmação para o expurgo sem filtro", func(t *testing.T) {
+		resp, _ := submeter(jobs.TipoExpurgo, "application/json", "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+
+		resp, _ = submeter(jobs.TipoExpurgo, "application/json", `{"somente_blocklist": false}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+
+		resp, job := submeter(jobs.TipoExpurgo, "application/json", `{"confirmar": true}`)
+		assert.Equal(t, http.StatusAccepted, resp.Code, "Status code deve ser 202")
+		assert.Eventually(t, func() bool {
+			return consultar(job.ID).Status == models.JobConcluido
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	// Caso de erro: Tipo de job desconhecido e job inexistente
+	t.Run("Retorna erro para job inexistente", func(t *testing.T) {
+		resp, _ := submeter("desconhecido", "application/json", "")
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Status code deve ser 404")
+
+		req, _ := http.NewRequest("GET", "/jobs/inexistente", nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Status code deve ser 404")
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+	db.Exec("DELETE FROM webhook_assinaturas")
+
+	cadastrar := func(body string) (*httptest.ResponseRecorder, dtos.WebhookResponse) {
+		req, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var webhook dtos.WebhookResponse
+		json.Unmarshal(resp.Body.Bytes(), &webhook)
+		return resp, webhook
+	}
+
+	// Caso de sucesso: Cadastra assinatura e retorna o segredo gerado somente no cadastro
+	t.Run("Cadastra, consulta e remove assinatura", func(t *testing.T) {
+		resp, webhook := cadastrar(`{"url": "https://exemplo.com/hook", "eventos": ["blocklist.alterado"]}`)
+		assert.Equal(t, http.StatusCreated, resp.Code, "Status code deve ser 201")
+		assert.NotEmpty(t, webhook.Segredo)
+		assert.Equal(t, []string{"blocklist.alterado"}, webhook.Eventos)
+
+		req, _ := http.NewRequest("GET", "/webhooks/"+webhook.ID, nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		assert.NotContains(t, resp.Body.String(), webhook.Segredo, "O segredo não deve ser exposto na consulta")
+
+		req, _ = http.NewRequest("GET", "/webhooks/"+webhook.ID+"/entregas", nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		req, _ = http.NewRequest("DELETE", "/webhooks/"+webhook.ID, nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Status code deve ser 404")
+	})
+
+	// Caso de erro: URL ou evento inválidos
+	t.Run("Retorna erro para dados inválidos", func(t *testing.T) {
+		resp, _ := cadastrar(`{"url": "ftp://exemplo.com"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+
+		resp, _ = cadastrar(`{"url": "https://exemplo.com/hook", "eventos": ["cliente.inexistente"]}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
+}
+
+func TestTitulares(t *testing.T) {
+	db := setupDB()
+	clearTable(db)
+	for _, tabela := range []string{"clientes_anonimizados", "eventos", "webhook_assinaturas", "webhook_entregas"} {
+		db.Exec("DELETE FROM " + tabela)
+	}
+
+	pseudonimizador := lgpd.NewPseudonimizador("sal-dos-testes-lgpd")
+	base := repository.NewClienteRepository(db)
+	indice := blocklist.NewIndice([]string{tenant.Padrao}, func(ctx context.Context, t string) ([]string, error) {
+		return base.ListarBloqueados(tenant.ComID(ctx, t))
+	})
+	clientes := repository.NewClienteRepositoryComIndice(base, indice)
+	eventos := repository.NewEventoRepository(db)
+	webhooks := repository.NewWebhookRepository(db)
+	titularHandler := NewTitularHandler(clientes, eventos, webhooks, pseudonimizador)
+	blocklistHandler := NewBlocklistHandler(indice, pseudonimizador)
+	semSal := NewTitularHandler(clientes, eventos, webhooks, lgpd.NewPseudonimizador(""))
+
+	router := gin.New()
+	router.Use(middlewares.TenantMiddleware(tenant.NewResolvedor(nil, nil, "", false)))
+	router.GET("/titulares/:documento/relatorio", titularHandler.RelatorioTitular)
+	router.POST("/titulares/:documento/anonimizar", titularHandler.AnonimizarTitular)
+	router.POST("/sem-sal/:documento/anonimizar", semSal.AnonimizarTitular)
+	router.GET("/blocklist/:documento", blocklistHandler.ConsultarBlocklist)
+
+	ctx := tenant.ComID(context.Background(), tenant.Padrao)
+	bloqueado := true
+	cliente := &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}
+	assert.NoError(t, clientes.Create(ctx, cliente))
+	_, err := clientes.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{Blocklist: &bloqueado})
+	assert.NoError(t, err)
+
+	// Uma assinatura recebe os eventos do titular
+	webhooks.Create(&models.WebhookAssinatura{ID: "crm", TenantID: tenant.Padrao, URL: "https://crm.exemplo.com/hook", Segredo: "segredo", Ativo: true})
+	naoPublicados, _ := eventos.ListarNaoPublicados(10)
+	assinaturas, _ := webhooks.ListarAtivas()
+	for i := range naoPublicados {
+		webhooks.DistribuirEvento(&naoPublicados[i], assinaturas)
+	}
+
+	relatorio := func(documento string) dtos.RelatorioTitularResponse {
+		req, _ := http.NewRequest("GET", "/titulares/"+documento+"/relatorio", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		var resposta dtos.RelatorioTitularResponse
+		json.Unmarshal(resp.Body.Bytes(), &resposta)
+		return resposta
+	}
+	anonimizar := func(caminho string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", caminho, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Caso de sucesso: Relatório com o cadastro, o histórico e os envios aos webhooks
+	t.Run("Gera o relatório do titular", func(t *testing.T) {
+		resposta := relatorio("529.982.247-25")
+		if assert.NotNil(t, resposta.Cliente) {
+			assert.Equal(t, "João Silva", resposta.Cliente.RazaoSocial)
+			assert.True(t, resposta.Cliente.Blocklist)
+		}
+		assert.Nil(t, resposta.Anonimizacao)
+		assert.Len(t, resposta.Eventos, 3, "Criação, atualização e alteração da blocklist")
+		assert.Len(t, resposta.Compartilhamentos, 3)
+		assert.Equal(t, "https://crm.exemplo.com/hook", resposta.Compartilhamentos[0].URL)
+
+		req, _ := http.NewRequest("GET", "/titulares/52998224725/relatorio?formato=pdf", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		assert.Equal(t, "application/pdf", resp.Header().Get("Content-Type"))
+		assert.True(t, bytes.HasPrefix(resp.Body.Bytes(), []byte("%PDF-")))
+
+		// Cada relatório gerado fica registrado no histórico
+		resposta = relatorio("52998224725")
+		var auditorias int
+		for _, evento := range resposta.Eventos {
+			if evento.Tipo == models.EventoRelatorioTitular {
+				auditorias++
+				assert.NotEqual(t, "52998224725", evento.Documento, "A auditoria referencia o hash do documento")
+			}
+		}
+		assert.Equal(t, 2, auditorias)
+	})
+
+	// Caso de sucesso: A anonimização apaga os dados pessoais e mantém a blocklist
+	t.Run("Anonimiza o titular", func(t *testing.T) {
+		resp := anonimizar("/titulares/52998224725/anonimizar")
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		var anonimizacao dtos.AnonimizacaoResponse
+		json.Unmarshal(resp.Body.Bytes(), &anonimizacao)
+		hash, _ := pseudonimizador.Hash(tenant.Padrao, "52998224725")
+		assert.Equal(t, hash, anonimizacao.HashDocumento)
+		assert.True(t, anonimizacao.Blocklist)
+
+		_, err := base.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		var restantes int64
+		db.Model(&models.Evento{}).Where("documento = ? OR payload LIKE ?", "52998224725", "%João Silva%").Count(&restantes)
+		assert.Zero(t, restantes, "Nenhum evento guarda o documento ou a razão social")
+
+		req, _ := http.NewRequest("GET", "/blocklist/52998224725", nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var consulta dtos.BlocklistResponse
+		json.Unmarshal(resp.Body.Bytes(), &consulta)
+		assert.True(t, consulta.Blocklist, "A blocklist continua valendo pelo hash")
+
+		resposta := relatorio("52998224725")
+		assert.Nil(t, resposta.Cliente)
+		if assert.NotNil(t, resposta.Anonimizacao) {
+			assert.Equal(t, hash, resposta.Anonimizacao.HashDocumento)
+		}
+		assert.Equal(t, models.EventoClienteAnonimizado, resposta.Eventos[len(resposta.Eventos)-1].Tipo)
+	})
+
+	// Caso de erro: Cliente inexistente, documento inválido ou sal não configurado
+	t.Run("Retorna erro ao anonimizar", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, anonimizar("/titulares/52998224725/anonimizar").Code, "Status code deve ser 404")
+		assert.Equal(t, http.StatusBadRequest, anonimizar("/titulares/123/anonimizar").Code, "Status code deve ser 400")
+		assert.Equal(t, http.StatusServiceUnavailable, anonimizar("/sem-sal/86405508838/anonimizar").Code, "Status code deve ser 503")
+
+		req, _ := http.NewRequest("GET", "/titulares/52998224725/relatorio?formato=xml", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+	})
+}
+
+func TestRetencao(t *testing.T) {
+	db := setupDB()
+	for _, tabela := range []string{"jobs", "retencao_execucoes"} {
+		db.Exec("DELETE FROM " + tabela)
+	}
+
+	retencaoRepo := repository.NewRetencaoRepository(db)
+	clientes := repository.NewClienteRepository(db)
+	pseudonimizador := lgpd.NewPseudonimizador("sal-dos-testes-lgpd")
+	tenants := []string{tenant.Padrao}
+	retencaoHandler := NewRetencaoHandler(retencao.NewExecutor(clientes, retencaoRepo, pseudonimizador, retencao.Politica{Jobs: time.Nanosecond}, tenants), retencaoRepo)
+	desabilitado := NewRetencaoHandler(retencao.NewExecutor(clientes, retencaoRepo, pseudonimizador, retencao.Politica{}, tenants), retencaoRepo)
+
+	router := gin.New()
+	router.Use(middlewares.TenantMiddleware(tenant.NewResolvedor(nil, nil, "", false)))
+	router.POST("/retencao/simulacao", retencaoHandler.SimularRetencao)
+	router.GET("/retencao/execucoes", retencaoHandler.ListarExecucoesRetencao)
+	router.POST("/desabilitada/simulacao", desabilitado.SimularRetencao)
+
+	finalizado := time.Now().Add(-time.Minute)
+	db.Create(&models.Job{ID: "retencao-j1", TenantID: tenant.Padrao, Tipo: jobs.TipoExportacao, Status: models.JobConcluido, FinalizadoEm: &finalizado})
+
+	// Caso de sucesso: A simulação conta os jobs alcançados sem apagá-los
+	t.Run("Simula a política", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/retencao/simulacao", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		var relatorio dtos.RelatorioRetencaoResponse
+		json.Unmarshal(resp.Body.Bytes(), &relatorio)
+		assert.True(t, relatorio.Simulacao)
+		assert.NotEmpty(t, relatorio.Execucao)
+		if assert.Len(t, relatorio.Regras, 1) {
+			assert.Equal(t, models.RetencaoJobs, relatorio.Regras[0].Regra)
+			assert.Equal(t, int64(1), relatorio.Regras[0].Afetados)
+		}
+
+		var restantes int64
+		db.Model(&models.Job{}).Count(&restantes)
+		assert.Equal(t, int64(1), restantes, "A simulação não apaga")
+	})
+
+	// Caso de sucesso: A simulação fica registrada na auditoria
+	t.Run("Lista a auditoria", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/retencao/execucoes?limit=10", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
 		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		var regras []dtos.RetencaoRegraResponse
+		json.Unmarshal(resp.Body.Bytes(), &regras)
+		if assert.Len(t, regras, 1) {
+			assert.True(t, regras[0].Simulacao)
+			assert.Equal(t, int64(1), regras[0].Afetados)
+		}
+	})
+
+	// Caso de erro: Nenhuma regra configurada
+	t.Run("Retorna erro sem política", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/desabilitada/simulacao", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code, "Status code deve ser 503")
+	})
+}
+
+func TestAgendador(t *testing.T) {
+	db := setupDB()
+	db.Exec("DELETE FROM agendador_execucoes")
+
+	lider, err := agendador.NewLider(db)
+	assert.NoError(t, err)
+	agendadorRepo := repository.NewAgendadorRepository(db)
+	agenda := agendador.NewAgendador(agendadorRepo, lider)
+	assert.NoError(t, agenda.Registrar("retencao", "0 3 * * *", func(context.Context) (string, error) { return "", nil }))
+	assert.NoError(t, agenda.Registrar("revalidacao", "", func(context.Context) (string, error) { return "", nil }))
+	agendadorHandler := NewAgendadorHandler(agenda, agendadorRepo)
+
+	router := gin.New()
+	admin := router.Group("/admin", middlewares.AdminMiddleware([]string{"chave-admin"}))
+	admin.GET("/agendador/tarefas", agendadorHandler.ListarTarefas)
+	admin.POST("/agendador/tarefas/:nome/execucoes", agendadorHandler.DispararTarefa)
+	admin.GET("/agendador/execucoes", agendadorHandler.ListarExecucoesAgendador)
+	admin.GET("/agendador/execucoes/:id", agendadorHandler.ConsultarExecucaoAgendador)
+	router.GET("/desabilitado/agendador/tarefas", middlewares.AdminMiddleware(nil), agendadorHandler.ListarTarefas)
+
+	requisicao := func(metodo, caminho string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(metodo, caminho, nil)
+		req.Header.Set(tenant.CabecalhoAPIKey, "chave-admin")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Caso de sucesso: Lista as tarefas com o próximo horário da agenda
+	t.Run("Lista as tarefas", func(t *testing.T) {
+		resp := requisicao("GET", "/admin/agendador/tarefas")
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		var resposta dtos.TarefasAgendadasResponse
+		json.Unmarshal(resp.Body.Bytes(), &resposta)
+		if assert.Len(t, resposta.Tarefas, 2) {
+			assert.Equal(t, "retencao", resposta.Tarefas[0].Nome)
+			assert.Equal(t, "0 3 * * *", resposta.Tarefas[0].Agenda)
+			if assert.NotNil(t, resposta.Tarefas[0].ProximaExecucao) {
+				assert.Equal(t, 3, resposta.Tarefas[0].ProximaExecucao.Hour())
+			}
+			assert.Nil(t, resposta.Tarefas[1].ProximaExecucao, "Tarefa executada somente pela API")
+		}
+	})
+
+	// Caso de sucesso: A execução solicitada fica pendente e aparece no histórico
+	t.Run("Solicita a execução de uma tarefa", func(t *testing.T) {
+		resp := requisicao("POST", "/admin/agendador/tarefas/revalidacao/execucoes")
+		assert.Equal(t, http.StatusAccepted, resp.Code, "Status code deve ser 202")
+		var execucao dtos.ExecucaoAgendadaResponse
+		json.Unmarshal(resp.Body.Bytes(), &execucao)
+		assert.Equal(t, models.ExecucaoPendente, execucao.Status)
+		assert.Equal(t, models.OrigemManual, execucao.Origem)
+		assert.Equal(t, "/admin/agendador/execucoes/"+strconv.FormatUint(uint64(execucao.ID), 10), resp.Header().Get("Location"))
+
+		resp = requisicao("GET", resp.Header().Get("Location"))
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		resp = requisicao("GET", "/admin/agendador/execucoes?tarefa=revalidacao")
+		var historico []dtos.ExecucaoAgendadaResponse
+		json.Unmarshal(resp.Body.Bytes(), &historico)
+		if assert.Len(t, historico, 1) {
+			assert.Equal(t, execucao.ID, historico[0].ID)
+		}
+		resp = requisicao("GET", "/admin/agendador/execucoes?tarefa=retencao")
+		assert.Equal(t, "[]", resp.Body.String())
+	})
+
+	// Caso de erro: Tarefa ou execução inexistente
+	t.Run("Retorna 404 para tarefa desconhecida", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, requisicao("POST", "/admin/agendador/tarefas/expurgo/execucoes").Code, "Status code deve ser 404")
+		assert.Equal(t, http.StatusNotFound, requisicao("GET", "/admin/agendador/execucoes/999999").Code, "Status code deve ser 404")
+		assert.Equal(t, http.StatusNotFound, requisicao("GET", "/admin/agendador/execucoes/abc").Code, "Status code deve ser 404")
+	})
+
+	// Caso de erro: Rotas administrativas sem a chave ou sem chaves configuradas
+	t.Run("Exige a chave administrativa", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/admin/agendador/tarefas", nil)
+		req.Header.Set(tenant.CabecalhoAPIKey, "chave-de-tenant")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "Status code deve ser 401")
+
+		assert.Equal(t, http.StatusForbidden, requisicao("GET", "/desabilitado/agendador/tarefas").Code, "Status code deve ser 403")
+	})
+}
+
+func TestStreamEventos(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+	clearTable(db)
+	db.Exec("DELETE FROM eventos")
+
+	eventoHandler := NewEventoHandler(repository.NewEventoRepository(db))
+	eventoHandler.IntervaloConsulta = 10 * time.Millisecond
+	eventoHandler.IntervaloHeartbeat = 20 * time.Millisecond
+	eventoHandler.TempoLacuna = 50 * time.Millisecond
+	router.GET("/eventos", eventoHandler.StreamEventos)
+
+	servidor := httptest.NewServer(router)
+	defer servidor.Close()
+
+	clienteRepo := repository.NewClienteRepository(db)
+	padrao := tenant.ComID(context.Background(), tenant.Padrao)
+	cliente := &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}
+	clienteRepo.Create(padrao, cliente)
+	clienteRepo.Create(padrao, &models.Cliente{Documento: "33000167000101", RazaoSocial: "Empresa XYZ"})
+	// Os eventos de outro tenant não chegam ao stream, mesmo com o mesmo documento
+	clienteRepo.Create(tenant.ComID(context.Background(), "outro"), &models.Cliente{Documento: "52998224725", RazaoSocial: "Maria Oliveira"})
+	blocklist := true
+	clienteRepo.UpdateByDocumento(padrao, cliente, &dtos.AtualizaClienteRequest{Blocklist: &blocklist})
+
+	// lerEventos lê o stream até receber a quantidade de eventos esperada
+	lerEventos := func(t *testing.T, url string, header http.Header, quantidade int) ([]dtos.EventoResponse, []string, bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+		for chave, valores := range header {
+			req.Header[chave] = valores
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			return nil, nil, false
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		var eventos []dtos.EventoResponse
+		var ids []string
+		heartbeat := false
+		leitor := bufio.NewScanner(resp.Body)
+		for len(eventos) < quantidade && leitor.Scan() {
+			linha := leitor.Text()
+			switch {
+			case strings.HasPrefix(linha, "id: "):
+				ids = append(ids, strings.TrimPrefix(linha, "id: "))
+			case strings.HasPrefix(linha, "data: "):
+				var evento dtos.EventoResponse
+				json.Unmarshal([]byte(strings.TrimPrefix(linha, "data: ")), &evento)
+				eventos = append(eventos, evento)
+			case strings.HasPrefix(linha, ": heartbeat"):
+				heartbeat = true
+			}
+		}
+		return eventos, ids, heartbeat
+	}
+
+	// Caso de sucesso: Retoma a partir do Last-Event-ID aplicando os filtros
+	t.Run("Retoma o stream a partir do Last-Event-ID", func(t *testing.T) {
+		header := http.Header{"Last-Event-Id": []string{"0"}}
+		eventos, ids, _ := lerEventos(t, servidor.URL+"/eventos?documento=529.982.247-25", header, 3)
+
+		assert.Len(t, eventos, 3)
+		assert.Equal(t, models.EventoClienteCriado, eventos[0].Tipo)
+		assert.Equal(t, models.EventoClienteAtualizado, eventos[1].Tipo, "O cadastro do outro tenant não deve aparecer")
+		assert.Equal(t, models.EventoBlocklistAlterado, eventos[2].Tipo)
+		for _, evento := range eventos {
+			assert.Equal(t, "52998224725", evento.Documento)
+		}
+
+		proximos, _, _ := lerEventos(t, servidor.URL+"/eventos?tipo=blocklist.alterado&last_event_id="+ids[0], nil, 1)
+		assert.Len(t, proximos, 1)
+		assert.Equal(t, models.EventoBlocklistAlterado, proximos[0].Tipo)
+	})
+
+	// Caso de sucesso: Sem Last-Event-ID somente os eventos novos são enviados, com heartbeats.
+	// A auditoria do relatório do titular não é enviada.
+	t.Run("Envia somente eventos novos", func(t *testing.T) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			repository.NewEventoRepository(db).Registrar(tenant.Padrao, models.EventoRelatorioTitular, "33000167000101", dtos.RelatorioTitularPayload{Formato: "json"})
+			clienteRepo.DeleteByDocumento(padrao, "33000167000101")
+		}()
+
+		eventos, _, heartbeat := lerEventos(t, servidor.URL+"/eventos", nil, 1)
+		assert.Len(t, eventos, 1)
+		assert.Equal(t, models.EventoClienteRemovido, eventos[0].Tipo)
+		assert.True(t, heartbeat, "Heartbeat deve ser enviado enquanto não há eventos")
+	})
+
+	// Caso de sucesso: O evento de id menor confirmado depois de um maior não fica para trás
+	t.Run("Aguarda a transação que reservou um id menor", func(t *testing.T) {
+		// Banco em arquivo com WAL, em que as consultas não enxergam a transação aberta
+		arquivo := filepath.Join(t.TempDir(), "eventos.db")
+		banco, err := gorm.Open(sqlite.Open(arquivo+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"), &gorm.Config{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		migrador, err := migrations.NewMigrador(banco)
+		assert.NoError(t, err)
+		_, err = migrador.Subir(context.Background(), 0)
+		assert.NoError(t, err)
+
+		lento := NewEventoHandler(repository.NewEventoRepository(banco))
+		lento.IntervaloConsulta = 10 * time.Millisecond
+		lentoRouter := gin.New()
+		lentoRouter.Use(middlewares.TenantMiddleware(tenant.NewResolvedor(nil, nil, "", false)))
+		lentoRouter.GET("/eventos", lento.StreamEventos)
+		lentoServidor := httptest.NewServer(lentoRouter)
+		defer lentoServidor.Close()
+		defer lento.Encerrar()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			banco.Create(&models.Evento{ID: 2, TenantID: tenant.Padrao, Tipo: models.EventoClienteAtualizado, Documento: "52998224725", Payload: "{}"})
+			// A transação que reservou o id 1 continua aberta por dezenas de consultas do stream
+			tx := banco.Begin()
+			tx.Create(&models.Evento{ID: 1, TenantID: tenant.Padrao, Tipo: models.EventoClienteCriado, Documento: "52998224725", Payload: "{}"})
+			time.Sleep(500 * time.Millisecond)
+			tx.Commit()
+		}()
+
+		eventos, _, _ := lerEventos(t, lentoServidor.URL+"/eventos?last_event_id=0", nil, 2)
+		if assert.Len(t, eventos, 2) {
+			assert.Equal(t, uint(1), eventos[0].ID)
+			assert.Equal(t, uint(2), eventos[1].ID)
+		}
+	})
+
+	// Caso de sucesso: O id que nunca aparece, como o de uma transação desfeita, é abandonado
+	t.Run("Abandona a lacuna após o tempo limite", func(t *testing.T) {
+		ultimo, err := repository.NewEventoRepository(db).UltimoID()
+		assert.NoError(t, err)
+		db.Create(&models.Evento{ID: ultimo + 5, TenantID: tenant.Padrao, Tipo: models.EventoClienteAtualizado, Documento: "52998224725", Payload: "{}"})
+
+		inicio := time.Now()
+		eventos, _, _ := lerEventos(t, servidor.URL+"/eventos?last_event_id="+strconv.Itoa(int(ultimo)), nil, 1)
+		if assert.Len(t, eventos, 1) {
+			assert.Equal(t, ultimo+5, eventos[0].ID)
+		}
+		assert.GreaterOrEqual(t, time.Since(inicio), eventoHandler.TempoLacuna, "O evento após a lacuna deve aguardar o tempo limite")
+	})
+
+	// Caso de erro: Filtro com tipo de evento desconhecido
+	t.Run("Retorna erro para tipo desconhecido", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/eventos?tipo=cliente.inexistente", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+
+		req, _ = http.NewRequest("GET", "/eventos?tipo=titular.relatorio_gerado", nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "A auditoria do titular não pode ser assinada")
+	})
+
+	// Caso de sucesso: Streams abertos são finalizados no encerramento do servidor
+	t.Run("Finaliza o stream no encerramento", func(t *testing.T) {
+		resp, err := http.Get(servidor.URL + "/eventos")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		eventoHandler.Encerrar()
+
+		finalizado := make(chan struct{})
+		go func() {
+			io.Copy(io.Discard, resp.Body)
+			close(finalizado)
+		}()
+		select {
+		case <-finalizado:
+		case <-time.After(2 * time.Second):
+			t.Fatal("O stream deveria ter sido finalizado")
+		}
+	})
+}
+
+func TestMultiTenancy(t *testing.T) {
+	db := setupDB()
+	clearTable(db)
+	db.Exec("DELETE FROM webhook_assinaturas")
+
+	resolvedor := tenant.NewResolvedor(
+		[]tenant.Tenant{{ID: "a"}, {ID: "b"}},
+		map[string]string{"chave-a": "a", "chave-b": "b"},
+		"", false,
+	)
+	clienteHandler := NewClienteHandler(repository.NewClienteRepository(db))
+	webhookHandler := NewWebhookHandler(repository.NewWebhookRepository(db))
+	router := gin.New()
+	router.Use(middlewares.TenantMiddleware(resolvedor))
+	router.POST("/clientes", clienteHandler.CadastrarCliente)
+	router.GET("/clientes", clienteHandler.ListarClientes)
+	router.GET("/clientes/:documento", clienteHandler.VerificarCliente)
+	router.PATCH("/clientes/:documento", clienteHandler.AtualizaParcialCliente)
+	router.DELETE("/clientes/:documento", clienteHandler.DeletarCliente)
+	router.POST("/webhooks", webhookHandler.CadastrarWebhook)
+	router.GET("/webhooks", webhookHandler.ListarWebhooks)
+	router.GET("/webhooks/:id", webhookHandler.ConsultarWebhook)
+	router.DELETE("/webhooks/:id", webhookHandler.DeletarWebhook)
+
+	requisitar := func(chave, metodo, url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(metodo, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if metodo == "PATCH" {
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+		}
+		if chave != "" {
+			req.Header.Set(tenant.CabecalhoAPIKey, chave)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Caso de erro: Requisição sem credencial ou com credencial desconhecida
+	t.Run("Retorna 401 sem tenant identificado", func(t *testing.T) {
+		resp := requisitar("", "GET", "/clientes", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "Status code deve ser 401")
+		assert.Contains(t, resp.Body.String(), "Tenant não identificado")
+
+		resp = requisitar("chave-invalida", "GET", "/clientes", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "Status code deve ser 401")
+		assert.Contains(t, resp.Body.String(), "Credencial inválida")
+	})
+
+	// Caso de sucesso: Um tenant não lê nem altera os clientes de outro
+	t.Run("Isola os clientes entre tenants", func(t *testing.T) {
+		resp := requisitar("chave-a", "POST", "/clientes", `{"documento": "52998224725", "razaosocial": "João Silva", "blocklist": false}`)
+		assert.Equal(t, http.StatusCreated, resp.Code, "Status code deve ser 201")
+
+		assert.Equal(t, http.StatusNotFound, requisitar("chave-b", "GET", "/clientes/52998224725", "").Code)
+		assert.Equal(t, http.StatusNotFound, requisitar("chave-b", "PATCH", "/clientes/52998224725", `{"blocklist": true}`).Code)
+		requisitar("chave-b", "DELETE", "/clientes/52998224725", "")
+
+		var lista dtos.ListarClientesResponse
+		json.Unmarshal(requisitar("chave-b", "GET", "/clientes", "").Body.Bytes(), &lista)
+		assert.Zero(t, lista.Total, "O tenant b não deve listar os clientes do a")
+
+		resp = requisitar("chave-a", "GET", "/clientes/52998224725", "")
+		assert.Equal(t, http.StatusOK, resp.Code, "O cliente do tenant a deve continuar cadastrado")
+		assert.Contains(t, resp.Body.String(), `"blocklist":false`)
+
+		// O mesmo documento pode ser cadastrado pelo outro tenant
+		resp = requisitar("chave-b", "POST", "/clientes", `{"documento": "52998224725", "razaosocial": "Maria Oliveira", "blocklist": true}`)
+		assert.Equal(t, http.StatusCreated, resp.Code, "Status code deve ser 201")
+		assert.Contains(t, requisitar("chave-a", "GET", "/clientes/52998224725", "").Body.String(), "João Silva")
+		assert.Contains(t, requisitar("chave-b", "GET", "/clientes/52998224725", "").Body.String(), "Maria Oliveira")
+	})
+
+	// Caso de sucesso: As assinaturas de webhook também pertencem ao tenant
+	t.Run("Isola os webhooks entre tenants", func(t *testing.T) {
+		resp := requisitar("chave-a", "POST", "/webhooks", `{"url": "https://exemplo.com/hook"}`)
+		assert.Equal(t, http.StatusCreated, resp.Code, "Status code deve ser 201")
+		var webhook dtos.WebhookResponse
+		json.Unmarshal(resp.Body.Bytes(), &webhook)
+
+		assert.Equal(t, http.StatusNotFound, requisitar("chave-b", "GET", "/webhooks/"+webhook.ID, "").Code)
+		assert.Equal(t, http.StatusNotFound, requisitar("chave-b", "DELETE", "/webhooks/"+webhook.ID, "").Code)
+		assert.Equal(t, "[]", requisitar("chave-b", "GET", "/webhooks", "").Body.String())
+		assert.Equal(t, http.StatusOK, requisitar("chave-a", "GET", "/webhooks/"+webhook.ID, "").Code)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	router := setupRouter(setupDB())
+
+	// Caso de sucesso: Retorna status do servidor
+	t.Run("Retorna status do servidor", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/status", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+	})
+}
+
+func TestMetricas(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+	clearTable(db)
+
+	metricas := func() string {
+		req, _ := http.NewRequest("GET", "/metrics", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		return resp.Body.String()
+	}
+
+	// valor retorna o valor de uma série das métricas, zero se ela não existir
+	valor := func(serie string) float64 {
+		for _, linha := range strings.Split(metricas(), "\n") {
+			if strings.HasPrefix(linha, serie+" ") {
+				v, _ := strconv.ParseFloat(strings.TrimPrefix(linha, serie+" "), 64)
+				return v
+			}
+		}
+		return 0
+	}
+
+	enviar := func(metodo, url, body string) {
+		req, _ := http.NewRequest(metodo, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Caso de sucesso: Requisições são contadas pelo template da rota
+	t.Run("Conta requisições por rota e status", func(t *testing.T) {
+		serie := `clientes_api_http_requests_total{method="GET",route="/clientes/:documento",status="400"}`
+		antes := valor(serie)
+		enviar("GET", "/clientes/123", "")
+		enviar("GET", "/clientes/456", "")
+		assert.Equal(t, antes+2, valor(serie))
+		assert.Contains(t, metricas(), "clientes_api_http_request_duration_seconds_bucket")
+		assert.Contains(t, metricas(), "clientes_api_http_requests_in_flight")
+	})
+
+	// Caso de sucesso: Contadores de domínio e de falhas de validação
+	t.Run("Conta clientes criados e falhas de validação", func(t *testing.T) {
+		criados := valor("clientes_api_clientes_criados_total")
+		falhas := valor(`clientes_api_validacao_falhas_total{tipo="documento"}`)
+		blocklist := valor(`clientes_api_clientes_blocklist_alteracoes_total{blocklist="true"}`)
+
+		enviar("POST", "/clientes", `{"documento": "52998224725", "razaosocial": "João Silva", "blocklist": false}`)
+		enviar("POST", "/clientes", `{"documento": "123", "razaosocial": "João Silva"}`)
+		enviar("PUT", "/clientes/52998224725", `{"razaosocial": "João Silva", "blocklist": true}`)
+
+		assert.Equal(t, criados+1, valor("clientes_api_clientes_criados_total"))
+		assert.Equal(t, falhas+1, valor(`clientes_api_validacao_falhas_total{tipo="documento"}`))
+		assert.Equal(t, blocklist+1, valor(`clientes_api_clientes_blocklist_alteracoes_total{blocklist="true"}`))
+	})
+
+	// Caso de sucesso: Operações desfeitas em um lote não são contabilizadas
+	t.Run("Não conta operações de lote desfeito", func(t *testing.T) {
+		criados := valor("clientes_api_clientes_criados_total")
+		enviar("POST", "/clientes/lote", `{"operacoes": [
+			{"operacao": "criar", "documento": "33000167000101", "razaosocial": "Empresa XYZ"},
+			{"operacao": "remover", "documento": "12345678909"}
+		]}`)
+		assert.Equal(t, criados, valor("clientes_api_clientes_criados_total"))
+	})
+}
+
+func TestHealth(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+
+	registro := health.NewRegistro(50 * time.Millisecond)
+	registro.Registrar(health.BancoDeDados(db))
+	migrador, _ := migrations.NewMigrador(db)
+	registro.Registrar(migrador.Checker())
+	healthHandler := NewHealthHandler(registro)
+	router.GET("/health/live", healthHandler.Liveness)
+	router.GET("/health/ready", healthHandler.Readiness)
+
+	prontidao := func() (int, health.Resultado) {
+		req, _ := http.NewRequest("GET", "/health/ready", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var resultado health.Resultado
+		json.Unmarshal(resp.Body.Bytes(), &resultado)
+		return resp.Code, resultado
+	}
+
+	// Caso de sucesso: Todas as dependências respondem
+	t.Run("Retorna pronto com banco e migrações em dia", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/health/live", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		status, resultado := prontidao()
+		assert.Equal(t, http.StatusOK, status, "Status code deve ser 200")
+		assert.Equal(t, health.StatusOK, resultado.Componentes["banco_de_dados"].Status)
+		assert.Equal(t, health.StatusOK, resultado.Componentes["migracoes"].Status)
+	})
+
+	// Caso de erro: Banco sem as migrações do binário
+	t.Run("Retorna indisponível com migração pendente", func(t *testing.T) {
+		vazio, err := gorm.Open(sqlite.Open("file:health-vazio?mode=memory&cache=shared"), &gorm.Config{})
+		assert.NoError(t, err)
+		pendente, _ := migrations.NewMigrador(vazio)
+		registro.Registrar(pendente.Checker())
+
+		status, resultado := prontidao()
+		assert.Equal(t, http.StatusServiceUnavailable, status, "Status code deve ser 503")
+		assert.Equal(t, health.StatusFalha, resultado.Status)
+	})
+
+	// Caso de erro: Aplicação drenando as requisições
+	t.Run("Retorna indisponível durante o encerramento", func(t *testing.T) {
+		registro := health.NewRegistro(50 * time.Millisecond)
+		registro.Registrar(health.BancoDeDados(db))
+		registro.IniciarEncerramento()
+
+		resultado := registro.Verificar(context.Background())
+		assert.Equal(t, health.StatusFalha, resultado.Status)
+		assert.Equal(t, health.StatusFalha, resultado.Componentes[health.ComponenteEncerramento].Status)
+	})
+
+	// Caso de erro: Dependência que falha ou excede o timeout
+	t.Run("Retorna indisponível quando uma dependência falha", func(t *testing.T) {
+		registro := health.NewRegistro(50 * time.Millisecond)
+		registro.Registrar(health.CheckerFunc{NomeChecker: "cache", Fn: func(ctx context.Context) error {
+			return errors.New("conexão recusada")
+		}})
+		registro.Registrar(health.CheckerFunc{NomeChecker: "fila", Fn: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+
+		resultado := registro.Verificar(context.Background())
+		assert.Equal(t, health.StatusFalha, resultado.Status)
+		assert.Equal(t, "conexão recusada", resultado.Componentes["cache"].Erro)
+		assert.Equal(t, health.StatusFalha, resultado.Componentes["fila"].Status)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	db := setupDB()
+	router := setupRouter(db)
+	router.Use(middlewares.AccessLogMiddleware())
+	router.GET("/teste/:documento", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("Mensagem do handler")
+		c.Set(middlewares.ChaveSujeito, "usuario@exemplo.com")
+		c.Status(http.StatusNoContent)
+	})
+
+	var saida bytes.Buffer
+	anterior := slog.Default()
+	_, err := logging.Configurar("info", logging.FormatoJSON, &saida)
+	assert.NoError(t, err)
+	defer slog.SetDefault(anterior)
+
+	linhas := func() []map[string]interface{} {
+		var registros []map[string]interface{}
+		for _, linha := range strings.Split(strings.TrimSpace(saida.String()), "\n") {
+			var registro map[string]interface{}
+			if json.Unmarshal([]byte(linha), &registro) == nil {
+				registros = append(registros, registro)
+			}
+		}
+		saida.Reset()
+		return registros
+	}
+
+	// Caso de sucesso: Id recebido é propagado para a resposta e para os logs
+	t.Run("Propaga o X-Request-ID recebido", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/teste/52998224725", nil)
+		req.Header.Set(middlewares.HeaderRequestID, "abc-123")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, "abc-123", resp.Header().Get(middlewares.HeaderRequestID))
+
+		registros := linhas()
+		if assert.Len(t, registros, 2) {
+			assert.Equal(t, "Mensagem do handler", registros[0]["msg"])
+			assert.Equal(t, "abc-123", registros[0]["request_id"])
+
+			acesso := registros[1]
+			assert.Equal(t, "abc-123", acesso["request_id"])
+			assert.Equal(t, "/teste/:documento", acesso["rota"])
+			assert.Equal(t, "/teste/52998224725", acesso["caminho"])
+			assert.Equal(t, float64(http.StatusNoContent), acesso["status"])
+			assert.Equal(t, "usuario@exemplo.com", acesso["sujeito"])
+			assert.Contains(t, acesso, "latencia_ms")
+		}
+	})
+
+	// Caso de sucesso: Gera um id quando não é informado ou é inválido
+	t.Run("Gera um X-Request-ID", func(t *testing.T) {
+		for _, recebido := range []string{"", "id com espaços\ne quebra de linha"} {
+			req, _ := http.NewRequest("GET", "/teste/52998224725", nil)
+			req.Header.Set(middlewares.HeaderRequestID, recebido)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			id := resp.Header().Get(middlewares.HeaderRequestID)
+			assert.Len(t, id, 32)
+			assert.NotEqual(t, recebido, id)
+
+			registros := linhas()
+			if assert.NotEmpty(t, registros) {
+				assert.Equal(t, id, registros[len(registros)-1]["request_id"])
+			}
+		}
+	})
+
+	// Caso de sucesso: O sujeito registrado na linha de acesso é a credencial que identificou o tenant
+	t.Run("Registra o sujeito autenticado", func(t *testing.T) {
+		resolvedor := tenant.NewResolvedor([]tenant.Tenant{{ID: "a"}}, map[string]string{"chave-a": "a"}, "segredo", true)
+		autenticado := gin.New()
+		autenticado.Use(middlewares.RequestIDMiddleware(), middlewares.AccessLogMiddleware(), middlewares.TenantMiddleware(resolvedor))
+		autenticado.GET("/teste", func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+
+		sujeito := func(cabecalho, valor string) interface{} {
+			req, _ := http.NewRequest("GET", "/teste", nil)
+			req.Header.Set(cabecalho, valor)
+			resp := httptest.NewRecorder()
+			autenticado.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusNoContent, resp.Code, "Status code deve ser 204")
+
+			registros := linhas()
+			if !assert.NotEmpty(t, registros) {
+				return nil
+			}
+			return registros[len(registros)-1]["sujeito"]
+		}
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{tenant.ClaimTenant: "a", "sub": "usuario@exemplo.com"}).SignedString([]byte("segredo"))
+		assert.NoError(t, err)
+		assert.Equal(t, "usuario@exemplo.com", sujeito("Authorization", "Bearer "+token))
+
+		assert.Equal(t, tenant.IDChave("chave-a"), sujeito(tenant.CabecalhoAPIKey, "chave-a"))
+		assert.NotContains(t, tenant.IDChave("chave-a"), "chave-a", "A chave não aparece nos logs")
+
+		assert.Nil(t, sujeito(tenant.CabecalhoTenant, "a"), "O cabeçalho X-Tenant-ID não autentica um sujeito")
+	})
+}
+
+func TestTracing(t *testing.T) {
+	gravador := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(gravador)))
+	_, err := tracing.Configurar(context.Background(), tracing.ExportadorNenhum, nil)
+	assert.NoError(t, err)
+
+	db := setupDB()
+	router := setupRouter(db)
+	clearTable(db)
+	db.Create(&models.Cliente{TenantID: tenant.Padrao, Documento: "52998224725", RazaoSocial: "João Silva"})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	// Caso de sucesso: Spans da requisição, do handler, do repositório e das consultas no mesmo trace
+	t.Run("Propaga o traceparent até as consultas", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/clientes?razao_social=silva", nil)
+		req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		assert.Equal(t, traceID, resp.Header().Get(middlewares.HeaderTraceID))
+
+		spans := map[string]sdktrace.ReadOnlySpan{}
+		var consultas []sdktrace.ReadOnlySpan
+		for _, span := range gravador.Ended() {
+			if span.SpanContext().TraceID().String() != traceID {
+				continue
+			}
+			if span.Name() == "gorm.query" || span.Name() == "gorm.row" {
+				consultas = append(consultas, span)
+				continue
+			}
+			spans[span.Name()] = span
+		}
+
+		requisicao, ok := spans["/clientes"]
+		assert.True(t, ok, "Deve existir o span da requisição")
+		handler, ok := spans["handlers.(*ClienteHandler).ListarClientes"]
+		assert.True(t, ok, "Deve existir o span do handler")
+		repo, ok := spans["ClienteRepository.ListarClientes"]
+		assert.True(t, ok, "Deve existir o span do repositório")
+		if requisicao == nil || handler == nil || repo == nil {
+			return
+		}
+
+		assert.Equal(t, "00f067aa0ba902b7", requisicao.Parent().SpanID().String())
+		assert.Equal(t, requisicao.SpanContext().SpanID(), handler.Parent().SpanID())
+		assert.Equal(t, handler.SpanContext().SpanID(), repo.Parent().SpanID())
+
+		// O COUNT e a consulta da página aparecem como spans separados
+		assert.Len(t, consultas, 2)
+		for _, consulta := range consultas {
+			assert.Equal(t, repo.SpanContext().SpanID(), consulta.Parent().SpanID())
+		}
+	})
+
+	// Caso de sucesso: Mensagens da requisição carregam o trace
+	t.Run("Inclui o trace nos logs", func(t *testing.T) {
+		var saida bytes.Buffer
+		anterior := slog.Default()
+		_, err := logging.Configurar("info", logging.FormatoJSON, &saida)
+		assert.NoError(t, err)
+		defer slog.SetDefault(anterior)
+
+		logRouter := setupRouter(db)
+		logRouter.Use(middlewares.AccessLogMiddleware())
+		logRouter.GET("/teste", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+		req, _ := http.NewRequest("GET", "/teste", nil)
+		req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		logRouter.ServeHTTP(httptest.NewRecorder(), req)
+
+		var registro map[string]interface{}
+		assert.NoError(t, json.Unmarshal(saida.Bytes(), &registro))
+		assert.Equal(t, traceID, registro["trace_id"])
+		assert.NotEmpty(t, registro["span_id"])
+	})
+
+	// Caso de erro: As falhas internas informam o trace no corpo da resposta
+	t.Run("Inclui o trace nas respostas de erro", func(t *testing.T) {
+		erroRouter := gin.New()
+		erroRouter.Use(otelgin.Middleware(tracing.NomeServico), middlewares.RecoveryMiddleware(), middlewares.HandlerSpanMiddleware())
+		erroRouter.GET("/banco", func(c *gin.Context) {
+			responderErroBanco(c, c.Request.Context(), errors.New("conexão recusada"), "Erro ao buscar cliente")
+		})
+		erroRouter.GET("/panico", func(c *gin.Context) {
+			panic("falha inesperada")
+		})
+
+		for _, rota := range []string{"/banco", "/panico"} {
+			req, _ := http.NewRequest("GET", rota, nil)
+			req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+			resp := httptest.NewRecorder()
+			erroRouter.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusInternalServerError, resp.Code, rota)
+			var erro dtos.ResponseErro
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &erro))
+			assert.NotEmpty(t, erro.Mensagem, rota)
+			assert.Equal(t, traceID, erro.TraceID, rota)
+		}
+	})
+
+	// Caso de sucesso: Sem traceparent um novo trace é iniciado
+	t.Run("Inicia um novo trace", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/clientes/52998224725", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Len(t, resp.Header().Get(middlewares.HeaderTraceID), 32)
+		assert.NotEqual(t, traceID, resp.Header().Get(middlewares.HeaderTraceID))
+	})
+}
+
+// repositorioLento simula um banco que só responde depois do prazo da operação
+type repositorioLento struct {
+	repository.ClienteRepository
+	erro error
+}
+
+func (r *repositorioLento) aguardar(ctx context.Context) error {
+	if r.erro != nil {
+		return r.erro
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (r *repositorioLento) FindByDocumento(ctx context.Context, documento string) (*models.Cliente, error) {
+	return nil, r.aguardar(ctx)
+}
+
+func (r *repositorioLento) ListarClientes(ctx context.Context, razao string, page, limit int) ([]models.Cliente, int64, error) {
+	return nil, 0, r.aguardar(ctx)
+}
+
+func (r *repositorioLento) Transaction(ctx context.Context, fn func(repo repository.ClienteRepository) error) error {
+	return fn(r)
+}
+
+func TestTimeouts(t *testing.T) {
+	setupRouterLento := func(erro error) *gin.Engine {
+		clienteHandler := NewClienteHandler(&repositorioLento{erro: erro})
+		clienteHandler.TimeoutConsulta = 10 * time.Millisecond
+		clienteHandler.TimeoutLote = 10 * time.Millisecond
+
+		router := gin.New()
+		router.POST("/clientes/lote", clienteHandler.ProcessarLote)
+		router.GET("/clientes", clienteHandler.ListarClientes)
+		router.GET("/clientes/:documento", clienteHandler.VerificarCliente)
+		return router
+	}
+
+	// Caso de erro: Consulta interrompida pelo prazo retorna 504
+	t.Run("Retorna 504 quando o prazo da consulta se esgota", func(t *testing.T) {
+		router := setupRouterLento(nil)
+
+		for _, url := range []string{"/clientes", "/clientes/52998224725"} {
+			req, _ := http.NewRequest("GET", url, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusGatewayTimeout, resp.Code, "Status code deve ser 504 em "+url)
+			assert.Contains(t, resp.Body.String(), "Tempo limite da operação excedido")
+		}
+	})
+
+	// Caso de erro: Requisição cancelada pelo cliente também interrompe a consulta
+	t.Run("Interrompe a consulta quando o cliente desconecta", func(t *testing.T) {
+		router := setupRouterLento(nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, _ := http.NewRequestWithContext(ctx, "GET", "/clientes/52998224725", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusGatewayTimeout, resp.Code, "Status code deve ser 504")
+	})
+
+	// Caso de erro: O prazo configurado para o tenant substitui o do handler
+	t.Run("Usa o prazo do tenant", func(t *testing.T) {
+		clienteHandler := NewClienteHandler(&repositorioLento{})
+		clienteHandler.TimeoutConsulta = time.Hour
+		resolvedor := tenant.NewResolvedor([]tenant.Tenant{{ID: "apressado", TimeoutConsulta: 10 * time.Millisecond}}, nil, "", true)
+
+		router := gin.New()
+		router.Use(middlewares.TenantMiddleware(resolvedor))
+		router.GET("/clientes/:documento", clienteHandler.VerificarCliente)
+
+		req, _ := http.NewRequest("GET", "/clientes/52998224725", nil)
+		req.Header.Set(tenant.CabecalhoTenant, "apressado")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusGatewayTimeout, resp.Code, "Status code deve ser 504")
+	})
+
+	// Caso de erro: Demais falhas do banco continuam retornando 500
+	t.Run("Retorna 500 para outros erros do banco", func(t *testing.T) {
+		router := setupRouterLento(errors.New("conexão recusada"))
+
+		req, _ := http.NewRequest("GET", "/clientes/52998224725", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusInternalServerError, resp.Code, "Status code deve ser 500")
+	})
+
+	// Caso de erro: Lote tudo_ou_nada interrompido pelo prazo retorna 504
+	t.Run("Retorna 504 quando o prazo do lote se esgota", func(t *testing.T) {
+		router := setupRouterLento(nil)
+
+		body := `{"modo": "tudo_ou_nada", "operacoes": [{"operacao": "remover", "documento": "52998224725"}]}`
+		req, _ := http.NewRequest("POST", "/clientes/lote", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var lote dtos.LoteResponse
+		json.Unmarshal(resp.Body.Bytes(), &lote)
+		assert.Equal(t, http.StatusGatewayTimeout, resp.Code, "Status code deve ser 504")
+		assert.Equal(t, http.StatusGatewayTimeout, lote.Resultados[0].Status)
+	})
+}
+
+func TestGraphQL(t *testing.T) {
+	db := setupDB()
+	clearTable(db)
+	db.Exec("DELETE FROM eventos")
+
+	servico := graphqlapi.NewServico(repository.NewClienteRepository(db), repository.NewEventoRepository(db))
+	graphqlHandler := NewGraphQLHandler(servico)
+
+	router := gin.New()
+	router.Use(middlewares.TenantMiddleware(tenant.NewResolvedor(nil, nil, "", false)))
+	router.POST("/graphql", graphqlHandler.ExecutarGraphQL)
+
+	executar := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Caso de sucesso: Cadastra e consulta o cliente com as variáveis da requisição
+	t.Run("Executa a operação", func(t *testing.T) {
+		resp := executar(`{"query": "mutation($doc: String!) { criarCliente(input: {documento: $doc, razaoSocial: \"João Silva\"}) { documento } }", "variables": {"doc": "529.982.247-25"}}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+
+		resp = executar(`{"query": "{ cliente(documento: \"52998224725\") { razaoSocial historico { tipo } } }"}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		var resposta dtos.GraphQLResponse
+		json.Unmarshal(resp.Body.Bytes(), &resposta)
+		assert.Empty(t, resposta.Errors)
+		assert.JSONEq(t, `{"cliente": {"razaoSocial": "João Silva", "historico": [{"tipo": "cliente.criado"}]}}`, string(resposta.Data))
+	})
+
+	// Caso de erro: Erros de execução são listados em errors com status 200
+	t.Run("Lista os erros da operação", func(t *testing.T) {
+		resp := executar(`{"query": "{ cliente(documento: \"123\") { documento } }"}`)
+		assert.Equal(t, http.StatusOK, resp.Code, "Status code deve ser 200")
+		var resposta dtos.GraphQLResponse
+		json.Unmarshal(resp.Body.Bytes(), &resposta)
+		if assert.Len(t, resposta.Errors, 1) {
+			assert.Equal(t, "Documento inválido", resposta.Errors[0].Message)
+			assert.Equal(t, graphqlapi.CodigoEntradaInvalida, resposta.Errors[0].Extensions["codigo"])
+		}
+	})
+
+	// Caso de erro: Requisição sem a consulta
+	t.Run("Retorna 400 sem a query", func(t *testing.T) {
+		resp := executar(`{"variables": {}}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
+
+		resp = executar(`não é json`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Status code deve ser 400")
 	})
 }
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Gileno29/clientes-API/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registro *health.Registro
+}
+
+func NewHealthHandler(registro *health.Registro) *HealthHandler {
+	return &HealthHandler{registro: registro}
+}
+
+// Liveness godoc
+// @Summary Verifica se a aplicação está viva
+// @Description Retorna 200 enquanto o processo estiver respondendo, sem verificar dependências.
+// @Tags suporte
+// @Produce json
+// @Success 200 {object} health.Resultado "Aplicação viva"
+// @Router /health/live [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Resultado{
+		Status:      health.StatusOK,
+		Componentes: map[string]health.Componente{},
+	})
+}
+
+// Readiness godoc
+// @Summary Verifica se a aplicação está pronta para receber tráfego
+// @Description Verifica cada dependência registrada (banco de dados, migrações, etc.) e retorna o status e a latência de cada uma.
+// @Tags suporte
+// @Produce json
+// @Success 200 {object} health.Resultado "Aplicação pronta"
+// @Failure 503 {object} health.Resultado "Alguma dependência falhou"
+// @Router /health/ready [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	resultado := h.registro.Verificar(c.Request.Context())
+
+	status := http.StatusOK
+	if resultado.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, resultado)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/jobs"
+	"github.com/Gileno29/clientes-API/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type JobHandler struct {
+	manager *jobs.Manager
+}
+
+func NewJobHandler(manager *jobs.Manager) *JobHandler {
+	return &JobHandler{manager: manager}
+}
+
+// SubmeterJob godoc
+// @Summary Submete um job para execução em segundo plano
+// @Description Cria um job de importacao, exportacao, revalidacao, expurgo ou recriptografia e retorna imediatamente o seu identificador.
+// @Description A importação aceita um JSON com a lista de clientes ou um CSV (text/csv) com as colunas documento, razaosocial e blocklist.
+// @Description Os demais jobs aceitam os filtros razao_social e somente_blocklist. O expurgo sem filtro remove todos os clientes e exige confirmar: true.
+// @Tags jobs
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param tipo path string true "Tipo do job" Enums(importacao, exportacao, revalidacao, expurgo, recriptografia)
+// @Param parametros body object false "Parâmetros do job"
+// @Success 202 {object} dtos.JobResponse "Job aceito"
+// @Failure 400 {object} dtos.ResponseErro "Parâmetros inválidos"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Tipo de job desconhecido"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao submeter o job"
+// @Router /jobs/{tipo} [post]
+func (h *JobHandler) SubmeterJob(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	tipo := c.Param("tipo")
+	if !h.manager.Suporta(tipo) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Tipo de job desconhecido'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+
+	corpo, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Dados inválidos: %v'}", err.Error()),
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	parametros, err := parametrosJob(tipo, c.ContentType(), corpo)
+	if err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Dados inválidos: %v'}", err.Error()),
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	job, err := h.manager.Submeter(t.ID, tipo, parametros)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao submeter o job'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	c.Header("Location", "/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, jobResponse(job))
+}
+
+// ConsultarJob godoc
+// @Summary Consulta o andamento de um job
+// @Description Retorna o status, o progresso e os erros registrados de um job.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Identificador do job"
+// @Success 200 {object} dtos.JobResponse "Job encontrado"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Job não encontrado"
+// @Router /jobs/{id} [get]
+func (h *JobHandler) ConsultarJob(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	job, err := h.manager.Buscar(t.ID, c.Param("id"))
+	if err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Job não encontrado'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobResponse(job))
+}
+
+// BaixarResultadoJob godoc
+// @Summary Baixa o resultado de um job
+// @Description Retorna o conteúdo gerado por um job concluído, como o CSV de uma exportação.
+// @Tags jobs
+// @Produce json
+// @Produce text/csv
+// @Param id path string true "Identificador do job"
+// @Success 200 {file} file "Resultado do job"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Job não encontrado"
+// @Failure 409 {object} dtos.ResponseErro "Job ainda não concluído"
+// @Router /jobs/{id}/resultado [get]
+func (h *JobHandler) BaixarResultadoJob(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	job, err := h.manager.Buscar(t.ID, c.Param("id"))
+	if err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Job não encontrado'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+
+	if job.Status != models.JobConcluido {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Job não concluído, status atual: %s'}", job.Status),
+		}
+		c.JSON(http.StatusConflict, erro)
+		return
+	}
+
+	if job.ResultadoContentType == "text/csv" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.csv", job.Tipo, job.ID))
+	}
+	c.Data(http.StatusOK, job.ResultadoContentType, job.Resultado)
+}
+
+// CancelarJob godoc
+// @Summary Cancela um job
+// @Description Cancela um job pendente ou interrompe um job em execução.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Identificador do job"
+// @Success 202 {object} dtos.ResponseSucesso "Cancelamento solicitado"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Job não encontrado"
+// @Failure 409 {object} dtos.ResponseErro "Job já finalizado"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao cancelar o job"
+// @Router /jobs/{id} [delete]
+func (h *JobHandler) CancelarJob(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	err := h.manager.Cancelar(t.ID, c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Job não encontrado'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+	if errors.Is(err, jobs.ErrJobFinalizado) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Job já finalizado'}",
+		}
+		c.JSON(http.StatusConflict, erro)
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao cancelar o job'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	resposta := dtos.ResponseSucesso{
+		Mensagem: "Cancelamento solicitado",
+	}
+	c.JSON(http.StatusAccepted, resposta)
+}
+
+// parametrosJob valida o corpo da requisição e o converte para os parâmetros gravados no job
+func parametrosJob(tipo, contentType string, corpo []byte) ([]byte, error) {
+	if tipo == jobs.TipoImportacao {
+		var parametros jobs.ParametrosImportacao
+		if contentType == "text/csv" {
+			clientes, err := jobs.LerCSVClientes(corpo)
+			if err != nil {
+				return nil, err
+			}
+			parametros.Clientes = clientes
+		} else if err := json.Unmarshal(corpo, &parametros); err != nil {
+			return nil, err
+		}
+		if len(parametros.Clientes) == 0 {
+			return nil, errors.New("nenhum cliente informado")
+		}
+		return json.Marshal(parametros)
+	}
+
+	var filtro jobs.ParametrosFiltro
+	if len(strings.TrimSpace(string(corpo))) > 0 {
+		if err := json.Unmarshal(corpo, &filtro); err != nil {
+			return nil, err
+		}
+	}
+	if tipo == jobs.TipoExpurgo && filtro.Vazio() && !filtro.Confirmar {
+		return nil, errors.New("o expurgo sem filtro remove todos os clientes, informe confirmar: true para prosseguir")
+	}
+	return json.Marshal(filtro)
+}
+
+func jobResponse(job *models.Job) dtos.JobResponse {
+	resposta := dtos.JobResponse{
+		ID:           job.ID,
+		Tipo:         job.Tipo,
+		Status:       job.Status,
+		Processados:  job.Processados,
+		Total:        job.Total,
+		CriadoEm:     job.CreatedAt,
+		IniciadoEm:   job.IniciadoEm,
+		FinalizadoEm: job.FinalizadoEm,
+	}
+	if job.Total > 0 {
+		resposta.Progresso = float64(job.Processados) * 100 / float64(job.Total)
+	}
+	if job.Status == models.JobConcluido {
+		resposta.Progresso = 100
+		resposta.ResultadoURL = "/jobs/" + job.ID + "/resultado"
+	}
+	if job.Erros != "" {
+		json.Unmarshal([]byte(job.Erros), &resposta.Erros)
+	}
+	return resposta
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	ModoTudoOuNada    = "tudo_ou_nada"
+	ModoMelhorEsforco = "melhor_esforco"
+
+	// MaxOperacoesLote limita a quantidade de operações aceitas em um único lote
+	MaxOperacoesLote = 1000
+)
+
+// errOperacaoLote sinaliza a falha de uma operação para desfazer a transação do lote
+var errOperacaoLote = errors.New("operação do lote falhou")
+
+// ProcessarLote godoc
+// @Summary Processa um lote de operações de clientes
+// @Description Executa em lote operações de cadastro (criar), substituição (atualizar), remoção (remover) e alteração de blocklist (blocklist).
+// @Description No modo tudo_ou_nada todas as operações são executadas em uma única transação e desfeitas se alguma falhar.
+// @Description No modo melhor_esforco cada operação é executada em sua própria transação e o resultado de cada uma é retornado.
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param lote body dtos.LoteRequest true "Operações do lote"
+// @Success 200 {object} dtos.LoteResponse "Lote processado"
+// @Failure 400 {object} dtos.ResponseErro "Requisição inválida"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 413 {object} dtos.ResponseErro "Lote excede o número máximo de operações"
+// @Failure 422 {object} dtos.LoteResponse "Lote tudo_ou_nada desfeito por falha em uma operação"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao processar o lote"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
+// @Router /clientes/lote [post]
+func (h *ClienteHandler) ProcessarLote(c *gin.Context) {
+	var lote dtos.LoteRequest
+	if err := c.ShouldBindJSON(&lote); err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Dados inválidos: %v'}", err.Error()),
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	if lote.Modo == "" {
+		lote.Modo = ModoTudoOuNada
+	}
+	if lote.Modo != ModoTudoOuNada && lote.Modo != ModoMelhorEsforco {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Modo inválido, utilize tudo_ou_nada ou melhor_esforco'}",
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	if len(lote.Operacoes) == 0 {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'O lote não possui operações'}",
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	if len(lote.Operacoes) > MaxOperacoesLote {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'O lote excede o limite de %d operações'}", MaxOperacoesLote),
+		}
+		c.JSON(http.StatusRequestEntityTooLarge, erro)
+		return
+	}
+
+	resultados := make([]dtos.ResultadoOperacaoLote, len(lote.Operacoes))
+	status := http.StatusOK
+
+	// O prazo vale para o lote inteiro, nos dois modos
+	ctx, cancel := comPrazo(c, h.prazoLote(c))
+	defer cancel()
+
+	if lote.Modo == ModoTudoOuNada {
+		falhou := -1
+		err := h.repo.Transaction(ctx, func(repo repository.ClienteRepository) error {
+			for i, op := range lote.Operacoes {
+				resultados[i] = executarOperacaoLote(ctx, repo, i, op)
+				if resultados[i].Status >= http.StatusBadRequest {
+					falhou = i
+					return errOperacaoLote
+				}
+			}
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errOperacaoLote) {
+			responderErroBanco(c, ctx, err, "Erro ao processar o lote")
+			return
+		}
+
+		if falhou >= 0 {
+			status = http.StatusUnprocessableEntity
+			if resultados[falhou].Status == http.StatusGatewayTimeout {
+				status = http.StatusGatewayTimeout
+			}
+			for i, op := range lote.Operacoes {
+				if i == falhou {
+					continue
+				}
+				resultados[i] = dtos.ResultadoOperacaoLote{
+					Indice:    i,
+					Operacao:  op.Operacao,
+					Documento: op.Documento,
+					Status:    http.StatusFailedDependency,
+					Mensagem:  "Operação não aplicada: lote desfeito",
+				}
+			}
+		}
+	} else {
+		for i, op := range lote.Operacoes {
+			err := h.repo.Transaction(ctx, func(repo repository.ClienteRepository) error {
+				resultados[i] = executarOperacaoLote(ctx, repo, i, op)
+				if resultados[i].Status >= http.StatusBadRequest {
+					return errOperacaoLote
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errOperacaoLote) {
+				resultados[i].Status = statusErroBanco(ctx, err)
+				resultados[i].Mensagem = "Erro ao confirmar a operação"
+				resultados[i].Cliente = nil
+			}
+		}
+	}
+
+	resposta := dtos.LoteResponse{
+		Modo:       lote.Modo,
+		Total:      len(resultados),
+		Resultados: resultados,
+	}
+	for _, resultado := range resultados {
+		if resultado.Status < http.StatusBadRequest {
+			resposta.Sucesso++
+		} else {
+			resposta.Falhas++
+		}
+	}
+
+	c.JSON(status, resposta)
+}
+
+// executarOperacaoLote aplica uma operação do lote com as mesmas regras dos endpoints individuais
+func executarOperacaoLote(ctx context.Context, repo repository.ClienteRepository, indice int, op dtos.OperacaoLoteRequest) dtos.ResultadoOperacaoLote {
+	documento := utils.ClearNumber(op.Documento)
+	resultado := dtos.ResultadoOperacaoLote{
+		Indice:    indice,
+		Operacao:  op.Operacao,
+		Documento: documento,
+	}
+
+	falha := func(status int, mensagem string) dtos.ResultadoOperacaoLote {
+		resultado.Status = status
+		resultado.Mensagem = mensagem
+		return resultado
+	}
+
+	if !utils.ValidaDocumento(documento) {
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		return falha(http.StatusBadRequest, "Documento inválido")
+	}
+
+	existente, err := repo.FindByDocumento(ctx, documento)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return falha(statusErroBanco(ctx, err), "Erro ao buscar cliente")
+	}
+	encontrado := err == nil && existente != nil
+
+	switch op.Operacao {
+	case "criar":
+		if op.RazaoSocial == nil || !utils.ValidaRazaoSocial(*op.RazaoSocial) {
+			metrics.FalhaValidacao(metrics.ValidacaoRazaoSocial)
+			return falha(http.StatusBadRequest, "Razão social inválida")
+		}
+		if encontrado {
+			return falha(http.StatusConflict, "Cliente já cadastrado")
+		}
+		cliente := models.Cliente{
+			Documento:   documento,
+			RazaoSocial: *op.RazaoSocial,
+		}
+		if op.Blocklist != nil {
+			cliente.Blocklist = *op.Blocklist
+		}
+		if err := repo.Create(ctx, &cliente); err != nil {
+			return falha(statusErroBanco(ctx, err), "Erro ao cadastrar cliente")
+		}
+		resultado.Status = http.StatusCreated
+		resultado.Cliente = clienteResponse(&cliente)
+		return resultado
+
+	case "atualizar", "blocklist":
+		dados := dtos.AtualizaClienteRequest{
+			RazaoSocial: op.RazaoSocial,
+			Blocklist:   op.Blocklist,
+		}
+		if op.Operacao == "blocklist" {
+			if op.Blocklist == nil {
+				return falha(http.StatusBadRequest, "Campo blocklist obrigatório")
+			}
+			dados.RazaoSocial = nil
+		} else if mensagem, tipo := validarAtualizacao(&dados); mensagem != "" {
+			metrics.FalhaValidacao(tipo)
+			return falha(http.StatusBadRequest, mensagem)
+		}
+		if !encontrado {
+			return falha(http.StatusNotFound, "Cliente não encontrado")
+		}
+		atualizado, err := repo.UpdateByDocumento(ctx, existente, &dados)
+		if err != nil {
+			return falha(statusErroBanco(ctx, err), "Erro ao atualizar cliente")
+		}
+		resultado.Status = http.StatusOK
+		resultado.Cliente = clienteResponse(atualizado)
+		return resultado
+
+	case "remover":
+		if !encontrado {
+			return falha(http.StatusNotFound, "Cliente não encontrado")
+		}
+		if err := repo.DeleteByDocumento(ctx, documento); err != nil {
+			return falha(statusErroBanco(ctx, err), "Erro ao deletar cliente")
+		}
+		resultado.Status = http.StatusOK
+		return resultado
+
+	default:
+		return falha(http.StatusBadRequest, "Operação inválida, utilize criar, atualizar, remover ou blocklist")
+	}
+}
+
+func clienteResponse(cliente *models.Cliente) *dtos.ClienteResponse {
+	return &dtos.ClienteResponse{
+		Documento:   cliente.Documento,
+		RazaoSocial: cliente.RazaoSocial,
+		Blocklist:   cliente.Blocklist,
+	}
+}
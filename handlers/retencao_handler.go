@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/retencao"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetencaoHandler expõe a simulação e a auditoria da política de retenção
+type RetencaoHandler struct {
+	executor *retencao.Executor
+	repo     repository.RetencaoRepository
+}
+
+func NewRetencaoHandler(executor *retencao.Executor, repo repository.RetencaoRepository) *RetencaoHandler {
+	return &RetencaoHandler{executor: executor, repo: repo}
+}
+
+// SimularRetencao godoc
+// @Summary Simula a política de retenção no tenant
+// @Description Conta, sem alterar nada, os registros que cada regra da política de retenção alcançaria agora. A simulação é registrada na auditoria como as rodadas agendadas.
+// @Tags retencao
+// @Produce json
+// @Success 200 {object} dtos.RelatorioRetencaoResponse "Registros alcançados por regra"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 503 {object} dtos.ResponseErro "Política de retenção não configurada"
+// @Router /retencao/simulacao [post]
+func (h *RetencaoHandler) SimularRetencao(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	if !h.executor.Habilitado() {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Política de retenção não configurada'}",
+		}
+		c.JSON(http.StatusServiceUnavailable, erro)
+		return
+	}
+
+	resultados := h.executor.ExecutarTenant(c.Request.Context(), "", t.ID, true)
+	relatorio := dtos.RelatorioRetencaoResponse{
+		Simulacao: true,
+		Regras:    regrasRetencaoResponse(resultados),
+	}
+	if len(resultados) > 0 {
+		relatorio.Execucao = resultados[0].Execucao
+	}
+	c.JSON(http.StatusOK, relatorio)
+}
+
+// ListarExecucoesRetencao godoc
+// @Summary Lista a auditoria da política de retenção
+// @Description Retorna as regras de retenção aplicadas no tenant, as mais recentes primeiro, com os registros alcançados e os erros. As regras de uma mesma rodada compartilham o campo execucao.
+// @Tags retencao
+// @Produce json
+// @Param limit query int false "Número máximo de registros" default(50)
+// @Success 200 {array} dtos.RetencaoRegraResponse "Regras aplicadas"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao listar a auditoria"
+// @Router /retencao/execucoes [get]
+func (h *RetencaoHandler) ListarExecucoesRetencao(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+
+	limite, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limite < 1 || limite > 500 {
+		limite = 50
+	}
+
+	execucoes, err := h.repo.ListarExecucoes(t.ID, limite)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao listar a auditoria'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+	c.JSON(http.StatusOK, regrasRetencaoResponse(execucoes))
+}
+
+func regrasRetencaoResponse(execucoes []models.ExecucaoRetencao) []dtos.RetencaoRegraResponse {
+	regras := make([]dtos.RetencaoRegraResponse, 0, len(execucoes))
+	for _, execucao := range execucoes {
+		regras = append(regras, dtos.RetencaoRegraResponse{
+			Execucao:     execucao.Execucao,
+			Regra:        execucao.Regra,
+			Simulacao:    execucao.Simulacao,
+			Limite:       execucao.Limite,
+			Afetados:     execucao.Afetados,
+			Erro:         execucao.Erro,
+			IniciadoEm:   execucao.IniciadoEm,
+			FinalizadoEm: execucao.FinalizadoEm,
+		})
+	}
+	return regras
+}
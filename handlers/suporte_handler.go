@@ -5,11 +5,18 @@ import (
 	"time"
 
 	"github.com/Gileno29/clientes-API/dtos"
-	"github.com/Gileno29/clientes-API/middlewares"
+	"github.com/Gileno29/clientes-API/metrics"
 	"github.com/Gileno29/clientes-API/utils"
 	"github.com/gin-gonic/gin"
 )
 
+type SuporteHandler struct {
+}
+
+func NewSuporteHandler() *SuporteHandler {
+	return &SuporteHandler{}
+}
+
 // Status godoc
 // @Summary Retorna o status do servidor
 // @Description Retorna informações sobre o tempo de atividade (uptime) e o número de requisições atendidas.
@@ -18,23 +25,22 @@ import (
 // @Produce json
 // @Success 200 {object} dtos.ResponseStatus "Status do servidor"
 // @Router /status [get]
-
-type SuporteHandler struct {
-}
-
-func NewSuporteHandler() *SuporteHandler {
-	return &SuporteHandler{}
-}
-
 func (s *SuporteHandler) Status(c *gin.Context) {
 
-	//uptime := time.Since(utils.StartTime).Seconds()
-
-	//requests := middlewares.GetRequestCount()
-
 	status := dtos.ResponseStatus{
 		Uptime:   time.Since(utils.StartTime).Seconds(),
-		Requests: middlewares.GetRequestCount(),
+		Requests: metrics.TotalRequisicoes(),
 	}
 	c.JSON(http.StatusOK, status)
 }
+
+// Metricas godoc
+// @Summary Métricas no formato do Prometheus
+// @Description Expõe contadores de requisições por rota, método e status, histogramas de latência, requisições em andamento, estatísticas do pool de conexões do banco e contadores de domínio.
+// @Tags suporte
+// @Produce plain
+// @Success 200 {string} string "Métricas"
+// @Router /metrics [get]
+func (s *SuporteHandler) Metricas(c *gin.Context) {
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
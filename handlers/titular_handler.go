@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TitularHandler atende os pedidos dos titulares previstos na LGPD
+type TitularHandler struct {
+	clientes        repository.ClienteRepository
+	eventos         repository.EventoRepository
+	webhooks        repository.WebhookRepository
+	pseudonimizador *lgpd.Pseudonimizador
+
+	// TimeoutConsulta é o prazo de cada leitura no banco
+	TimeoutConsulta time.Duration
+	// TimeoutEscrita é o prazo da anonimização
+	TimeoutEscrita time.Duration
+}
+
+func NewTitularHandler(clientes repository.ClienteRepository, eventos repository.EventoRepository, webhooks repository.WebhookRepository, pseudonimizador *lgpd.Pseudonimizador) *TitularHandler {
+	return &TitularHandler{
+		clientes:        clientes,
+		eventos:         eventos,
+		webhooks:        webhooks,
+		pseudonimizador: pseudonimizador,
+		TimeoutConsulta: 5 * time.Second,
+		TimeoutEscrita:  10 * time.Second,
+	}
+}
+
+// RelatorioTitular godoc
+// @Summary Gera o relatório dos dados mantidos sobre um titular
+// @Description Reúne os dados cadastrais, a anonimização, se houver, o histórico de eventos e os envios a webhooks do documento, em JSON ou PDF. Cada relatório gerado é registrado no histórico como o evento titular.relatorio_gerado, que não é enviado aos webhooks nem ao stream de eventos.
+// @Tags titulares
+// @Produce json,application/pdf
+// @Param documento path string true "Documento do titular (CPF/CNPJ)"
+// @Param formato query string false "Formato do relatório" Enums(json, pdf) default(json)
+// @Success 200 {object} dtos.RelatorioTitularResponse "Relatório do titular"
+// @Failure 400 {object} dtos.ResponseErro "Documento ou formato inválido"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao gerar o relatório"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
+// @Router /titulares/{documento}/relatorio [get]
+func (h *TitularHandler) RelatorioTitular(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+
+	documento, ok := documentoDoTitular(c)
+	if !ok {
+		return
+	}
+	formato := c.DefaultQuery("formato", "json")
+	if formato != "json" && formato != "pdf" {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Formato inválido, utilize json ou pdf'}",
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	ctx, cancel := comPrazo(c, prazoDoTenant(c, h.TimeoutConsulta, func(t tenant.Tenant) time.Duration { return t.TimeoutConsulta }))
+	defer cancel()
+
+	relatorio := &dtos.RelatorioTitularResponse{
+		Documento:         documento,
+		GeradoEm:          time.Now(),
+		Eventos:           []dtos.EventoResponse{},
+		Compartilhamentos: []dtos.CompartilhamentoResponse{},
+	}
+
+	cliente, err := h.clientes.FindByDocumento(ctx, documento)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		responderErroBanco(c, ctx, err, "Erro ao gerar o relatório")
+		return
+	}
+	if cliente != nil {
+		relatorio.Cliente = &dtos.ClienteTitularResponse{
+			Documento:    cliente.Documento,
+			RazaoSocial:  cliente.RazaoSocial,
+			Blocklist:    cliente.Blocklist,
+			CriadoEm:     cliente.CreatedAt,
+			AtualizadoEm: cliente.UpdatedAt,
+		}
+	}
+
+	// Depois da anonimização o histórico do titular fica sob o hash do documento
+	documentos := []string{documento}
+	referencia := documento
+	if h.pseudonimizador.Habilitado() {
+		hash, _ := h.pseudonimizador.Hash(t.ID, documento)
+		documentos = append(documentos, hash)
+		referencia = hash
+
+		anonimizado, err := h.clientes.FindAnonimizado(ctx, hash)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			responderErroBanco(c, ctx, err, "Erro ao gerar o relatório")
+			return
+		}
+		if anonimizado != nil {
+			relatorio.Anonimizacao = anonimizacaoResponse(anonimizado)
+		}
+	}
+
+	if err := h.historico(t.ID, documentos, relatorio); err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao gerar o relatório")
+		return
+	}
+
+	// O relatório só é entregue depois de auditado. Com a anonimização habilitada a auditoria
+	// referencia o hash, para não voltar a guardar o documento de quem já foi anonimizado. A
+	// auditoria fica somente no histórico, sem ser distribuída aos webhooks e ao stream.
+	if err := h.eventos.Registrar(t.ID, models.EventoRelatorioTitular, referencia, dtos.RelatorioTitularPayload{Formato: formato}); err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao registrar o relatório")
+		return
+	}
+
+	if formato == "json" {
+		c.JSON(http.StatusOK, relatorio)
+		return
+	}
+	conteudo, err := lgpd.GerarPDF(relatorio)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao gerar o relatório'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=relatorio-%s.pdf", documento))
+	c.Data(http.StatusOK, "application/pdf", conteudo)
+}
+
+// historico preenche os eventos dos documentos e as entregas deles aos webhooks do tenant
+func (h *TitularHandler) historico(tenantID string, documentos []string, relatorio *dtos.RelatorioTitularResponse) error {
+	eventos, err := h.eventos.ListarPorDocumentos(tenantID, documentos)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(eventos))
+	for _, evento := range eventos {
+		ids = append(ids, evento.ID)
+		relatorio.Eventos = append(relatorio.Eventos, dtos.EventoResponse{
+			ID:        evento.ID,
+			Tipo:      evento.Tipo,
+			Documento: evento.Documento,
+			CriadoEm:  evento.CreatedAt,
+			Dados:     json.RawMessage(evento.Payload),
+		})
+	}
+
+	entregas, err := h.webhooks.ListarEntregasDosEventos(ids)
+	if err != nil {
+		return err
+	}
+	assinaturas, err := h.webhooks.Listar(tenantID)
+	if err != nil {
+		return err
+	}
+	urls := make(map[string]string, len(assinaturas))
+	for _, assinatura := range assinaturas {
+		urls[assinatura.ID] = assinatura.URL
+	}
+	for _, entrega := range entregas {
+		relatorio.Compartilhamentos = append(relatorio.Compartilhamentos, dtos.CompartilhamentoResponse{
+			EventoID:     entrega.EventoID,
+			AssinaturaID: entrega.AssinaturaID,
+			URL:          urls[entrega.AssinaturaID],
+			Status:       entrega.Status,
+			EntregueEm:   entrega.EntregueEm,
+		})
+	}
+	return nil
+}
+
+// AnonimizarTitular godoc
+// @Summary Anonimiza um cliente a pedido do titular
+// @Description Remove de forma irreversível os dados pessoais do cliente. Resta somente o hash do documento, com sal, e a situação da blocklist, que continua valendo em GET /blocklist/{documento}. Os eventos do cliente passam a referenciar o hash e perdem o conteúdo, e o evento cliente.anonimizado registra a operação.
+// @Tags titulares
+// @Produce json
+// @Param documento path string true "Documento do titular (CPF/CNPJ)"
+// @Success 200 {object} dtos.AnonimizacaoResponse "Cliente anonimizado"
+// @Failure 400 {object} dtos.ResponseErro "Documento inválido"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Cliente não encontrado"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao anonimizar cliente"
+// @Failure 503 {object} dtos.ResponseErro "Anonimização não configurada"
+// @Failure 504 {object} dtos.ResponseErro "Tempo limite da operação excedido"
+// @Router /titulares/{documento}/anonimizar [post]
+func (h *TitularHandler) AnonimizarTitular(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+
+	documento, ok := documentoDoTitular(c)
+	if !ok {
+		return
+	}
+
+	hash, err := h.pseudonimizador.Hash(t.ID, documento)
+	if err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Anonimização não configurada'}",
+		}
+		c.JSON(http.StatusServiceUnavailable, erro)
+		return
+	}
+
+	ctx, cancel := comPrazo(c, prazoDoTenant(c, h.TimeoutEscrita, func(t tenant.Tenant) time.Duration { return t.TimeoutEscrita }))
+	defer cancel()
+
+	anonimizado, err := h.clientes.Anonimizar(ctx, documento, hash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Cliente não encontrado'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+	if err != nil {
+		responderErroBanco(c, ctx, err, "Erro ao anonimizar cliente")
+		return
+	}
+
+	c.JSON(http.StatusOK, anonimizacaoResponse(anonimizado))
+}
+
+// documentoDoTitular lê e valida o documento do caminho, respondendo 400 quando é inválido
+func documentoDoTitular(c *gin.Context) (string, bool) {
+	documento := utils.ClearNumber(c.Param("documento"))
+	if !utils.ValidaDocumento(documento) {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Documento inválido'}",
+		}
+		metrics.FalhaValidacao(metrics.ValidacaoDocumento)
+		c.JSON(http.StatusBadRequest, erro)
+		return "", false
+	}
+	return documento, true
+}
+
+func anonimizacaoResponse(anonimizado *models.ClienteAnonimizado) *dtos.AnonimizacaoResponse {
+	return &dtos.AnonimizacaoResponse{
+		HashDocumento: anonimizado.HashDocumento,
+		Blocklist:     anonimizado.Blocklist,
+		AnonimizadoEm: anonimizado.AnonimizadoEm,
+	}
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookHandler(repo repository.WebhookRepository) *WebhookHandler {
+	return &WebhookHandler{repo: repo}
+}
+
+// CadastrarWebhook godoc
+// @Summary Cadastra uma assinatura de webhook
+// @Description Cadastra uma URL que passa a receber os eventos informados (cliente.criado, cliente.atualizado, cliente.removido, blocklist.alterado). Sem eventos, todos são enviados.
+// @Description As entregas são assinadas com HMAC-SHA256 no header X-Webhook-Assinatura. Se o segredo não for informado ele é gerado e retornado somente nesta resposta.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body dtos.WebhookRequest true "Dados da assinatura"
+// @Success 201 {object} dtos.WebhookResponse "Assinatura cadastrada"
+// @Failure 400 {object} dtos.ResponseErro "Dados inválidos"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao cadastrar a assinatura"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CadastrarWebhook(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	var dados dtos.WebhookRequest
+	if err := c.ShouldBindJSON(&dados); err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: fmt.Sprintf("{'error': 'Dados inválidos: %v'}", err.Error()),
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	destino, err := url.Parse(dados.URL)
+	if err != nil || (destino.Scheme != "http" && destino.Scheme != "https") || destino.Host == "" {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'URL inválida'}",
+		}
+		c.JSON(http.StatusBadRequest, erro)
+		return
+	}
+
+	for _, evento := range dados.Eventos {
+		if !eventoValido(evento) {
+			erro := dtos.ResponseErro{
+				Mensagem: fmt.Sprintf("{'error': 'Evento desconhecido: %s'}", evento),
+			}
+			c.JSON(http.StatusBadRequest, erro)
+			return
+		}
+	}
+
+	id, err := gerarSegredo(16)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao cadastrar a assinatura'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+	if dados.Segredo == "" {
+		if dados.Segredo, err = gerarSegredo(32); err != nil {
+			c.Error(err)
+			erro := dtos.ResponseErro{
+				Mensagem: "{'error': 'Erro ao cadastrar a assinatura'}",
+			}
+			c.JSON(http.StatusInternalServerError, erro)
+			return
+		}
+	}
+
+	assinatura := models.WebhookAssinatura{
+		ID:       id,
+		TenantID: t.ID,
+		URL:      dados.URL,
+		Eventos:  strings.Join(dados.Eventos, ","),
+		Segredo:  dados.Segredo,
+		Ativo:    true,
+	}
+	if err := h.repo.Create(&assinatura); err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao cadastrar a assinatura'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	resposta := webhookResponse(&assinatura)
+	resposta.Segredo = assinatura.Segredo
+	c.JSON(http.StatusCreated, resposta)
+}
+
+// ListarWebhooks godoc
+// @Summary Lista as assinaturas de webhook
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} dtos.WebhookResponse "Assinaturas cadastradas"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao listar as assinaturas"
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListarWebhooks(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	assinaturas, err := h.repo.Listar(t.ID)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao listar as assinaturas'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	resposta := make([]dtos.WebhookResponse, 0, len(assinaturas))
+	for i := range assinaturas {
+		resposta = append(resposta, webhookResponse(&assinaturas[i]))
+	}
+	c.JSON(http.StatusOK, resposta)
+}
+
+// ConsultarWebhook godoc
+// @Summary Consulta uma assinatura de webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Identificador da assinatura"
+// @Success 200 {object} dtos.WebhookResponse "Assinatura encontrada"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Assinatura não encontrada"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) ConsultarWebhook(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	assinatura, err := h.repo.FindByID(t.ID, c.Param("id"))
+	if err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Assinatura não encontrada'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(assinatura))
+}
+
+// DeletarWebhook godoc
+// @Summary Remove uma assinatura de webhook
+// @Description Remove a assinatura e encerra as entregas pendentes para ela.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Identificador da assinatura"
+// @Success 200 {object} dtos.ResponseSucesso "Assinatura removida"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Assinatura não encontrada"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao remover a assinatura"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeletarWebhook(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	if _, err := h.repo.FindByID(t.ID, id); err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Assinatura não encontrada'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+
+	if err := h.repo.Delete(t.ID, id); err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao remover a assinatura'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	resposta := dtos.ResponseSucesso{
+		Mensagem: "Assinatura removida com sucesso",
+	}
+	c.JSON(http.StatusOK, resposta)
+}
+
+// ListarEntregasWebhook godoc
+// @Summary Lista as entregas de uma assinatura
+// @Description Retorna as entregas mais recentes de uma assinatura com o registro de cada tentativa.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Identificador da assinatura"
+// @Param limit query int false "Número máximo de entregas" default(50)
+// @Success 200 {array} dtos.WebhookEntregaResponse "Entregas da assinatura"
+// @Failure 401 {object} dtos.ResponseErro "Tenant não identificado ou credencial inválida"
+// @Failure 404 {object} dtos.ResponseErro "Assinatura não encontrada"
+// @Failure 500 {object} dtos.ResponseErro "Erro ao listar as entregas"
+// @Router /webhooks/{id}/entregas [get]
+func (h *WebhookHandler) ListarEntregasWebhook(c *gin.Context) {
+	t, ok := tenantDaRequisicao(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	if _, err := h.repo.FindByID(t.ID, id); err != nil {
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Assinatura não encontrada'}",
+		}
+		c.JSON(http.StatusNotFound, erro)
+		return
+	}
+
+	limite, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limite < 1 || limite > 500 {
+		limite = 50
+	}
+
+	entregas, err := h.repo.ListarEntregas(id, limite)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao listar as entregas'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	ids := make([]uint, 0, len(entregas))
+	for _, entrega := range entregas {
+		ids = append(ids, entrega.ID)
+	}
+	tentativas, err := h.repo.ListarTentativas(ids)
+	if err != nil {
+		c.Error(err)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro ao listar as entregas'}",
+		}
+		c.JSON(http.StatusInternalServerError, erro)
+		return
+	}
+
+	registros := map[uint][]dtos.WebhookTentativaResponse{}
+	for _, tentativa := range tentativas {
+		registros[tentativa.EntregaID] = append(registros[tentativa.EntregaID], dtos.WebhookTentativaResponse{
+			Numero:     tentativa.Numero,
+			StatusHTTP: tentativa.StatusHTTP,
+			Erro:       tentativa.Erro,
+			DuracaoMs:  tentativa.DuracaoMs,
+			CriadoEm:   tentativa.CreatedAt,
+		})
+	}
+
+	resposta := make([]dtos.WebhookEntregaResponse, 0, len(entregas))
+	for _, entrega := range entregas {
+		item := dtos.WebhookEntregaResponse{
+			ID:         entrega.ID,
+			EventoID:   entrega.EventoID,
+			Status:     entrega.Status,
+			Tentativas: entrega.Tentativas,
+			EntregueEm: entrega.EntregueEm,
+			Registros:  registros[entrega.ID],
+		}
+		if entrega.Status == models.EntregaPendente {
+			proxima := entrega.ProximaTentativaEm
+			item.ProximaTentativaEm = &proxima
+		}
+		if item.Registros == nil {
+			item.Registros = []dtos.WebhookTentativaResponse{}
+		}
+		resposta = append(resposta, item)
+	}
+	c.JSON(http.StatusOK, resposta)
+}
+
+func eventoValido(tipo string) bool {
+	for _, evento := range models.TiposEvento {
+		if evento == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+func gerarSegredo(tamanho int) (string, error) {
+	b := make([]byte, tamanho)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func webhookResponse(assinatura *models.WebhookAssinatura) dtos.WebhookResponse {
+	eventos := assinatura.ListaEventos()
+	if eventos == nil {
+		eventos = []string{}
+	}
+	return dtos.WebhookResponse{
+		ID:       assinatura.ID,
+		URL:      assinatura.URL,
+		Eventos:  eventos,
+		Ativo:    assinatura.Ativo,
+		CriadoEm: assinatura.CreatedAt,
+	}
+}
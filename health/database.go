@@ -0,0 +1,21 @@
+package health
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// BancoDeDados verifica se o banco responde a um ping dentro do timeout
+func BancoDeDados(db *gorm.DB) Checker {
+	return CheckerFunc{
+		NomeChecker: "banco_de_dados",
+		Fn: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK    = "ok"
+	StatusFalha = "falha"
+
+	// ComponenteEncerramento é o componente informado enquanto a aplicação drena as requisições
+	ComponenteEncerramento = "encerramento"
+)
+
+// Checker verifica uma dependência da aplicação. Novas dependências (cache, fila, etc.)
+// implementam essa interface e são registradas no Registro para compor a prontidão.
+type Checker interface {
+	Nome() string
+	Verificar(ctx context.Context) error
+}
+
+// CheckerFunc adapta uma função para a interface Checker
+type CheckerFunc struct {
+	NomeChecker string
+	Fn          func(ctx context.Context) error
+}
+
+func (c CheckerFunc) Nome() string {
+	return c.NomeChecker
+}
+
+func (c CheckerFunc) Verificar(ctx context.Context) error {
+	return c.Fn(ctx)
+}
+
+// Componente é o resultado da verificação de uma dependência
+type Componente struct {
+	Status     string  `json:"status"`
+	LatenciaMs float64 `json:"latencia_ms"`
+	Erro       string  `json:"erro,omitempty"`
+}
+
+// Resultado é o resultado consolidado da verificação de todas as dependências
+type Resultado struct {
+	Status      string                `json:"status"`
+	Componentes map[string]Componente `json:"componentes"`
+}
+
+type Registro struct {
+	// Timeout é o tempo máximo de cada verificação
+	Timeout time.Duration
+
+	mu         sync.RWMutex
+	checkers   []Checker
+	encerrando atomic.Bool
+}
+
+func NewRegistro(timeout time.Duration) *Registro {
+	return &Registro{Timeout: timeout}
+}
+
+// Registrar adiciona uma dependência à verificação de prontidão
+func (r *Registro) Registrar(checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checker)
+}
+
+// IniciarEncerramento faz a prontidão falhar para que o balanceador pare de enviar tráfego
+// enquanto as requisições em andamento são concluídas
+func (r *Registro) IniciarEncerramento() {
+	r.encerrando.Store(true)
+}
+
+// Verificar executa todas as verificações em paralelo, cada uma limitada pelo Timeout
+func (r *Registro) Verificar(ctx context.Context) Resultado {
+	if r.encerrando.Load() {
+		return Resultado{
+			Status: StatusFalha,
+			Componentes: map[string]Componente{
+				ComponenteEncerramento: {Status: StatusFalha, Erro: "aplicação em encerramento"},
+			},
+		}
+	}
+
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	resultado := Resultado{
+		Status:      StatusOK,
+		Componentes: make(map[string]Componente, len(checkers)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range checkers {
+		wg.Add(1)
+		go func(checker Checker) {
+			defer wg.Done()
+			componente := r.verificar(ctx, checker)
+
+			mu.Lock()
+			defer mu.Unlock()
+			resultado.Componentes[checker.Nome()] = componente
+			if componente.Status != StatusOK {
+				resultado.Status = StatusFalha
+			}
+		}(checker)
+	}
+	wg.Wait()
+
+	return resultado
+}
+
+func (r *Registro) verificar(ctx context.Context, checker Checker) Componente {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	inicio := time.Now()
+	erros := make(chan error, 1)
+	go func() {
+		erros <- checker.Verificar(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-erros:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	componente := Componente{
+		Status:     StatusOK,
+		LatenciaMs: float64(time.Since(inicio).Microseconds()) / 1000,
+	}
+	if err != nil {
+		componente.Status = StatusFalha
+		componente.Erro = err.Error()
+	}
+	return componente
+}
@@ -0,0 +1,325 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/utils"
+)
+
+const (
+	TipoImportacao  = "importacao"
+	TipoExportacao  = "exportacao"
+	TipoRevalidacao = "revalidacao"
+	TipoExpurgo     = "expurgo"
+	// TipoRecriptografia só é registrado com a criptografia habilitada
+	TipoRecriptografia = "recriptografia"
+
+	// tamanhoPagina é a quantidade de clientes lida do repositório a cada consulta
+	tamanhoPagina = 500
+)
+
+// ParametrosImportacao são os parâmetros de um job de importação
+type ParametrosImportacao struct {
+	Clientes []dtos.ClienteResponse `json:"clientes"`
+}
+
+// ParametrosFiltro são os parâmetros dos jobs que percorrem a base de clientes
+type ParametrosFiltro struct {
+	RazaoSocial      string `json:"razao_social"`
+	SomenteBlocklist bool   `json:"somente_blocklist"`
+	// Confirmar autoriza o expurgo sem filtro, que remove todos os clientes do tenant
+	Confirmar bool `json:"confirmar,omitempty"`
+}
+
+// Vazio informa se o filtro atende a todos os clientes
+func (f ParametrosFiltro) Vazio() bool {
+	return f.RazaoSocial == "" && !f.SomenteBlocklist
+}
+
+// LerCSVClientes lê os clientes de um CSV com as colunas documento, razaosocial e, opcionalmente,
+// blocklist, no formato gerado pela exportação
+func LerCSVClientes(corpo []byte) ([]dtos.ClienteResponse, error) {
+	leitor := csv.NewReader(strings.NewReader(string(corpo)))
+	linhas, err := leitor.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(linhas) == 0 {
+		return nil, nil
+	}
+
+	colunas := map[string]int{}
+	for i, coluna := range linhas[0] {
+		colunas[strings.ToLower(strings.TrimSpace(coluna))] = i
+	}
+	indiceDocumento, okDocumento := colunas["documento"]
+	indiceRazaoSocial, okRazaoSocial := colunas["razaosocial"]
+	if !okDocumento || !okRazaoSocial {
+		return nil, errors.New("o CSV deve possuir as colunas documento e razaosocial")
+	}
+	indiceBlocklist, okBlocklist := colunas["blocklist"]
+
+	clientes := make([]dtos.ClienteResponse, 0, len(linhas)-1)
+	for i, linha := range linhas[1:] {
+		cliente := dtos.ClienteResponse{
+			Documento:   linha[indiceDocumento],
+			RazaoSocial: linha[indiceRazaoSocial],
+		}
+		if okBlocklist && strings.TrimSpace(linha[indiceBlocklist]) != "" {
+			blocklist, err := strconv.ParseBool(strings.TrimSpace(linha[indiceBlocklist]))
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: blocklist inválido", i+2)
+			}
+			cliente.Blocklist = blocklist
+		}
+		clientes = append(clientes, cliente)
+	}
+	return clientes, nil
+}
+
+// RegistrarExecutoresClientes registra no manager os jobs que operam sobre os clientes
+func RegistrarExecutoresClientes(m *Manager, repo repository.ClienteRepository) {
+	for tipo, executor := range ExecutoresClientes(repo) {
+		m.Registrar(tipo, executor)
+	}
+}
+
+// ExecutoresClientes retorna, por tipo, os jobs que operam sobre os clientes. Os comandos do
+// binário os executam diretamente, sem passar pela fila.
+func ExecutoresClientes(repo repository.ClienteRepository) map[string]Executor {
+	return map[string]Executor{
+		TipoImportacao:  importarClientes(repo),
+		TipoExportacao:  exportarClientes(repo),
+		TipoRevalidacao: revalidarClientes(repo),
+		TipoExpurgo:     expurgarClientes(repo),
+	}
+}
+
+// ContarClientes retorna quantos clientes atendem ao filtro, usado para mostrar o alcance do
+// expurgo antes de confirmá-lo
+func ContarClientes(ctx context.Context, repo repository.ClienteRepository, filtro ParametrosFiltro) (int, error) {
+	total := 0
+	err := percorrerClientes(ctx, repo, filtro, nil, func(models.Cliente) {
+		total++
+	})
+	return total, err
+}
+
+// RegistrarRecriptografia registra o job que cifra com a chave mestra ativa os clientes e os
+// eventos do tenant gravados em texto puro ou com uma chave anterior, usado após a rotação das
+// chaves. Os repositórios são convertidos um depois do outro.
+func RegistrarRecriptografia(m *Manager, repos ...repository.Recriptografavel) {
+	m.Registrar(TipoRecriptografia, recriptografar(repos))
+}
+
+func importarClientes(repo repository.ClienteRepository) Executor {
+	return func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		var parametros ParametrosImportacao
+		if err := json.Unmarshal([]byte(job.Parametros), &parametros); err != nil {
+			return nil, "", fmt.Errorf("parâmetros inválidos: %w", err)
+		}
+
+		total := len(parametros.Clientes)
+		importados := 0
+		for i, item := range parametros.Clientes {
+			if err := ctx.Err(); err != nil {
+				return nil, "", err
+			}
+
+			documento := utils.ClearNumber(item.Documento)
+			switch {
+			case !utils.ValidaDocumento(documento):
+				progresso.Erro(fmt.Sprintf("linha %d: documento inválido", i+1))
+			case !utils.ValidaRazaoSocial(item.RazaoSocial):
+				progresso.Erro(fmt.Sprintf("linha %d: razão social inválida", i+1))
+			default:
+				if existente, err := repo.FindByDocumento(ctx, documento); err == nil && existente != nil {
+					progresso.Erro(fmt.Sprintf("linha %d: cliente já cadastrado", i+1))
+					break
+				}
+				cliente := models.Cliente{
+					Documento:   documento,
+					RazaoSocial: item.RazaoSocial,
+					Blocklist:   item.Blocklist,
+				}
+				if err := repo.Create(ctx, &cliente); err != nil {
+					progresso.Erro(fmt.Sprintf("linha %d: erro ao cadastrar cliente", i+1))
+					break
+				}
+				importados++
+			}
+			progresso.Atualizar(i+1, total)
+		}
+
+		resultado, err := json.Marshal(map[string]int{
+			"total":      total,
+			"importados": importados,
+			"falhas":     total - importados,
+		})
+		return resultado, "application/json", err
+	}
+}
+
+func exportarClientes(repo repository.ClienteRepository) Executor {
+	return func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		filtro, err := parametrosFiltro(job)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var buffer bytes.Buffer
+		escritor := csv.NewWriter(&buffer)
+		escritor.Write([]string{"documento", "razaosocial", "blocklist"})
+
+		err = percorrerClientes(ctx, repo, filtro, progresso, func(cliente models.Cliente) {
+			escritor.Write([]string{cliente.Documento, cliente.RazaoSocial, strconv.FormatBool(cliente.Blocklist)})
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		escritor.Flush()
+		return buffer.Bytes(), "text/csv", escritor.Error()
+	}
+}
+
+func revalidarClientes(repo repository.ClienteRepository) Executor {
+	return func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		filtro, err := parametrosFiltro(job)
+		if err != nil {
+			return nil, "", err
+		}
+
+		invalidos := []string{}
+		total := 0
+		err = percorrerClientes(ctx, repo, filtro, progresso, func(cliente models.Cliente) {
+			total++
+			if !utils.ValidaDocumento(cliente.Documento) {
+				invalidos = append(invalidos, cliente.Documento)
+			}
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		resultado, err := json.Marshal(map[string]interface{}{
+			"total":     total,
+			"invalidos": invalidos,
+		})
+		return resultado, "application/json", err
+	}
+}
+
+func expurgarClientes(repo repository.ClienteRepository) Executor {
+	return func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		filtro, err := parametrosFiltro(job)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Os documentos são coletados antes da remoção para não deslocar a paginação
+		var documentos []string
+		err = percorrerClientes(ctx, repo, filtro, nil, func(cliente models.Cliente) {
+			documentos = append(documentos, cliente.Documento)
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		removidos := 0
+		for i, documento := range documentos {
+			if err := ctx.Err(); err != nil {
+				return nil, "", err
+			}
+			if err := repo.DeleteByDocumento(ctx, documento); err != nil {
+				progresso.Erro(fmt.Sprintf("documento %s: erro ao remover cliente", documento))
+			} else {
+				removidos++
+			}
+			progresso.Atualizar(i+1, len(documentos))
+		}
+
+		resultado, err := json.Marshal(map[string]int{
+			"removidos": removidos,
+		})
+		return resultado, "application/json", err
+	}
+}
+
+func recriptografar(repos []repository.Recriptografavel) Executor {
+	return func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		convertidos := 0
+		for _, repo := range repos {
+			for {
+				if err := ctx.Err(); err != nil {
+					return nil, "", err
+				}
+				lote, restantes, err := repo.Recriptografar(ctx, tamanhoPagina)
+				if err != nil {
+					return nil, "", err
+				}
+				convertidos += lote
+				progresso.Atualizar(convertidos, convertidos+int(restantes))
+				if restantes == 0 || lote == 0 {
+					break
+				}
+			}
+		}
+
+		resultado, err := json.Marshal(map[string]int{
+			"convertidos": convertidos,
+		})
+		return resultado, "application/json", err
+	}
+}
+
+func parametrosFiltro(job *models.Job) (ParametrosFiltro, error) {
+	var filtro ParametrosFiltro
+	if job.Parametros == "" {
+		return filtro, nil
+	}
+	if err := json.Unmarshal([]byte(job.Parametros), &filtro); err != nil {
+		return filtro, fmt.Errorf("parâmetros inválidos: %w", err)
+	}
+	return filtro, nil
+}
+
+// percorrerClientes lê todas as páginas de clientes que atendem ao filtro, chamando fn para cada um
+func percorrerClientes(ctx context.Context, repo repository.ClienteRepository, filtro ParametrosFiltro, progresso Progresso, fn func(models.Cliente)) error {
+	processados := 0
+	for pagina := 1; ; pagina++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		clientes, total, err := repo.ListarClientes(ctx, filtro.RazaoSocial, pagina, tamanhoPagina)
+		if err != nil {
+			return err
+		}
+
+		for _, cliente := range clientes {
+			processados++
+			if filtro.SomenteBlocklist && !cliente.Blocklist {
+				continue
+			}
+			fn(cliente)
+		}
+		if progresso != nil {
+			progresso.Atualizar(processados, int(total))
+		}
+
+		if len(clientes) < tamanhoPagina {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+// recriptografavel simula um repositório com pendentes clientes a converter
+type recriptografavel struct {
+	pendentes int
+	tenants   []string
+}
+
+func (r *recriptografavel) Recriptografar(ctx context.Context, limite int) (int, int64, error) {
+	id, _ := tenant.ID(ctx)
+	r.tenants = append(r.tenants, id)
+	convertidos := min(limite, r.pendentes)
+	r.pendentes -= convertidos
+	return convertidos, int64(r.pendentes), nil
+}
+
+func TestRecriptografia(t *testing.T) {
+	manager, repo := setupManager(t)
+	clientes := &recriptografavel{pendentes: tamanhoPagina + 10}
+	RegistrarRecriptografia(manager, clientes)
+
+	manager.Iniciar(context.Background())
+	defer manager.Parar()
+
+	// Caso de sucesso: Converte os clientes em lotes até não restar nenhum
+	job, err := manager.Submeter("a", TipoRecriptografia, nil)
+	assert.NoError(t, err)
+	job = aguardarStatus(t, repo, job.ID, models.JobConcluido)
+	assert.JSONEq(t, `{"convertidos": 510}`, string(job.Resultado))
+	assert.Equal(t, 510, job.Processados)
+	assert.Equal(t, []string{"a", "a"}, clientes.tenants)
+}
@@ -0,0 +1,354 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"gorm.io/gorm"
+)
+
+// MaxErrosRegistrados limita a quantidade de erros guardados em cada job
+const MaxErrosRegistrados = 100
+
+var (
+	ErrTipoDesconhecido = errors.New("tipo de job desconhecido")
+	ErrJobFinalizado    = errors.New("job já finalizado")
+
+	errCancelado = errors.New("job cancelado")
+)
+
+// Progresso é usado pelos executores para informar o andamento do job
+type Progresso interface {
+	Atualizar(processados, total int)
+	Erro(mensagem string)
+}
+
+// Executor processa um job e retorna o conteúdo do resultado e seu content type.
+// O contexto é cancelado quando o job é cancelado ou o manager é parado.
+type Executor func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error)
+
+type Manager struct {
+	repo       repository.JobRepository
+	workers    int
+	executores map[string]Executor
+
+	// IntervaloFila é o tempo de espera entre consultas à fila quando não há jobs pendentes
+	IntervaloFila time.Duration
+	// IntervaloHeartbeat é a frequência com que o progresso é gravado e o cancelamento é verificado
+	IntervaloHeartbeat time.Duration
+	// TimeoutHeartbeat é o tempo sem heartbeat após o qual um job em execução é considerado abandonado
+	TimeoutHeartbeat time.Duration
+
+	mu            sync.Mutex
+	cancelamentos map[string]context.CancelCauseFunc
+	avisar        chan struct{}
+	parar         context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+func NewManager(repo repository.JobRepository, workers int) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Manager{
+		repo:               repo,
+		workers:            workers,
+		executores:         map[string]Executor{},
+		IntervaloFila:      2 * time.Second,
+		IntervaloHeartbeat: time.Second,
+		TimeoutHeartbeat:   time.Minute,
+		cancelamentos:      map[string]context.CancelCauseFunc{},
+		avisar:             make(chan struct{}, 1),
+	}
+}
+
+// Registrar associa um executor a um tipo de job
+func (m *Manager) Registrar(tipo string, executor Executor) {
+	m.executores[tipo] = executor
+}
+
+// Suporta indica se o tipo informado possui executor registrado
+func (m *Manager) Suporta(tipo string) bool {
+	_, ok := m.executores[tipo]
+	return ok
+}
+
+// Submeter grava um novo job do tenant na fila e acorda um worker para processá-lo. O executor
+// roda com o tenant no contexto, restrito aos dados dele.
+func (m *Manager) Submeter(tenantID, tipo string, parametros []byte) (*models.Job, error) {
+	if !m.Suporta(tipo) {
+		return nil, ErrTipoDesconhecido
+	}
+
+	id, err := gerarID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{
+		ID:         id,
+		TenantID:   tenantID,
+		Tipo:       tipo,
+		Status:     models.JobPendente,
+		Parametros: string(parametros),
+	}
+	if err := m.repo.Create(job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case m.avisar <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Buscar retorna o job do tenant; jobs de outros tenants retornam gorm.ErrRecordNotFound
+func (m *Manager) Buscar(tenantID, id string) (*models.Job, error) {
+	job, err := m.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.TenantID != tenantID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return job, nil
+}
+
+// Cancelar solicita o cancelamento de um job do tenant. Jobs pendentes são cancelados
+// imediatamente e jobs em execução são interrompidos pelo worker que os processa, nesta ou em
+// outra instância.
+func (m *Manager) Cancelar(tenantID, id string) error {
+	job, err := m.Buscar(tenantID, id)
+	if err != nil {
+		return err
+	}
+	if job.Finalizado() {
+		return ErrJobFinalizado
+	}
+
+	if err := m.repo.SolicitarCancelamento(id); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	cancelar, ok := m.cancelamentos[id]
+	m.mu.Unlock()
+	if ok {
+		cancelar(errCancelado)
+	}
+	return nil
+}
+
+// Iniciar sobe os workers e a rotina que devolve para a fila os jobs abandonados
+func (m *Manager) Iniciar(ctx context.Context) {
+	ctx, m.parar = context.WithCancel(ctx)
+
+	m.recuperarAbandonados()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.TimeoutHeartbeat / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.recuperarAbandonados()
+			}
+		}
+	}()
+
+	for i := 0; i < m.workers; i++ {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.worker(ctx)
+		}()
+	}
+}
+
+// Parar interrompe os workers e aguarda até que terminem. Jobs interrompidos voltam para a fila.
+func (m *Manager) Parar() {
+	if m.parar != nil {
+		m.parar()
+	}
+	m.wg.Wait()
+}
+
+func (m *Manager) recuperarAbandonados() {
+	recuperados, err := m.repo.RecuperarAbandonados(time.Now().Add(-m.TimeoutHeartbeat))
+	if err != nil {
+		slog.Error("Erro ao recuperar jobs abandonados", "erro", err)
+		return
+	}
+	if recuperados > 0 {
+		slog.Warn("Jobs abandonados devolvidos para a fila", "quantidade", recuperados)
+	}
+}
+
+func (m *Manager) worker(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := m.repo.ClaimNext()
+		if err != nil {
+			slog.Error("Erro ao buscar job pendente", "erro", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.avisar:
+			case <-time.After(m.IntervaloFila):
+			}
+			continue
+		}
+
+		m.executar(ctx, job)
+	}
+}
+
+func (m *Manager) executar(ctx context.Context, job *models.Job) {
+	jobCtx, cancelar := context.WithCancelCause(ctx)
+	defer cancelar(nil)
+
+	m.mu.Lock()
+	m.cancelamentos[job.ID] = cancelar
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.cancelamentos, job.ID)
+		m.mu.Unlock()
+	}()
+
+	progresso := &progressoJob{}
+	pararHeartbeat := make(chan struct{})
+	heartbeatParado := make(chan struct{})
+	go func() {
+		defer close(heartbeatParado)
+		m.heartbeat(job.ID, progresso, cancelar, pararHeartbeat)
+	}()
+
+	resultado, contentType, err := m.executarComRecover(tenant.ComID(jobCtx, job.TenantID), job, progresso)
+
+	close(pararHeartbeat)
+	<-heartbeatParado
+
+	job.Processados, job.Total, job.Erros = progresso.estado()
+
+	switch {
+	case errors.Is(context.Cause(jobCtx), errCancelado):
+		job.Status = models.JobCancelado
+	case err != nil && ctx.Err() != nil:
+		// O manager está parando, o job volta para a fila e é retomado por outra instância
+		if err := m.repo.Liberar(job.ID); err != nil {
+			slog.Error("Erro ao devolver o job para a fila", "job_id", job.ID, "erro", err)
+		}
+		return
+	case err != nil:
+		job.Status = models.JobFalhou
+		progresso.Erro(err.Error())
+		job.Processados, job.Total, job.Erros = progresso.estado()
+	default:
+		job.Status = models.JobConcluido
+		job.Resultado = resultado
+		job.ResultadoContentType = contentType
+	}
+
+	if err := m.repo.Finalizar(job); err != nil {
+		slog.Error("Erro ao finalizar o job", "job_id", job.ID, "erro", err)
+	}
+}
+
+func (m *Manager) executarComRecover(ctx context.Context, job *models.Job, progresso Progresso) (resultado []byte, contentType string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("falha inesperada ao executar o job")
+			slog.Error("Panic ao executar o job", "job_id", job.ID, "panic", fmt.Sprint(r))
+		}
+	}()
+
+	executor, ok := m.executores[job.Tipo]
+	if !ok {
+		return nil, "", ErrTipoDesconhecido
+	}
+	return executor(ctx, job, progresso)
+}
+
+// heartbeat grava periodicamente o progresso do job e verifica se o cancelamento foi solicitado
+func (m *Manager) heartbeat(id string, progresso *progressoJob, cancelar context.CancelCauseFunc, parar <-chan struct{}) {
+	ticker := time.NewTicker(m.IntervaloHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-parar:
+			return
+		case <-ticker.C:
+			processados, total, erros := progresso.estado()
+			if err := m.repo.AtualizarProgresso(id, processados, total, erros); err != nil {
+				slog.Error("Erro ao atualizar o progresso do job", "job_id", id, "erro", err)
+				continue
+			}
+			job, err := m.repo.FindByID(id)
+			if err == nil && job.CancelamentoSolicitado {
+				cancelar(errCancelado)
+			}
+		}
+	}
+}
+
+type progressoJob struct {
+	mu          sync.Mutex
+	processados int
+	total       int
+	erros       []string
+}
+
+func (p *progressoJob) Atualizar(processados, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processados = processados
+	p.total = total
+}
+
+func (p *progressoJob) Erro(mensagem string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.erros) < MaxErrosRegistrados {
+		p.erros = append(p.erros, mensagem)
+	}
+}
+
+func (p *progressoJob) estado() (int, int, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.erros) == 0 {
+		return p.processados, p.total, ""
+	}
+	erros, _ := json.Marshal(p.erros)
+	return p.processados, p.total, string(erros)
+}
+
+func gerarID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
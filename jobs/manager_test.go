@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupManager inicializa um manager com banco SQLite em memória e intervalos curtos para testes
+func setupManager(t *testing.T) (*Manager, repository.JobRepository) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	db.AutoMigrate(&models.Job{})
+	db.Exec("DELETE FROM jobs")
+
+	repo := repository.NewJobRepository(db)
+	manager := NewManager(repo, 1)
+	manager.IntervaloFila = 10 * time.Millisecond
+	manager.IntervaloHeartbeat = 10 * time.Millisecond
+	manager.TimeoutHeartbeat = 200 * time.Millisecond
+	return manager, repo
+}
+
+func aguardarStatus(t *testing.T, repo repository.JobRepository, id, status string) *models.Job {
+	var job *models.Job
+	assert.Eventually(t, func() bool {
+		job, _ = repo.FindByID(id)
+		return job != nil && job.Status == status
+	}, 5*time.Second, 10*time.Millisecond, "Job deve atingir o status %s", status)
+	return job
+}
+
+func TestManagerExecutaJob(t *testing.T) {
+	manager, repo := setupManager(t)
+	manager.Registrar("soma", func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		progresso.Atualizar(1, 2)
+		progresso.Erro("item ignorado")
+		progresso.Atualizar(2, 2)
+		return []byte("ok"), "text/plain", nil
+	})
+	manager.Registrar("falha", func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		return nil, "", errors.New("erro no processamento")
+	})
+
+	_, err := manager.Submeter("a", "inexistente", nil)
+	assert.ErrorIs(t, err, ErrTipoDesconhecido)
+
+	manager.Iniciar(context.Background())
+	defer manager.Parar()
+
+	job, err := manager.Submeter("a", "soma", nil)
+	assert.NoError(t, err)
+	job = aguardarStatus(t, repo, job.ID, models.JobConcluido)
+	assert.Equal(t, []byte("ok"), job.Resultado)
+	assert.Equal(t, 2, job.Processados)
+	assert.Equal(t, `["item ignorado"]`, job.Erros)
+
+	job, _ = manager.Submeter("a", "falha", nil)
+	job = aguardarStatus(t, repo, job.ID, models.JobFalhou)
+	assert.Contains(t, job.Erros, "erro no processamento")
+}
+
+func TestManagerCancelaJobEmExecucao(t *testing.T) {
+	manager, repo := setupManager(t)
+	iniciou := make(chan struct{})
+	manager.Registrar("lento", func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		close(iniciou)
+		<-ctx.Done()
+		return nil, "", ctx.Err()
+	})
+
+	manager.Iniciar(context.Background())
+	defer manager.Parar()
+
+	job, _ := manager.Submeter("a", "lento", nil)
+	<-iniciou
+	assert.NoError(t, manager.Cancelar("a", job.ID))
+	aguardarStatus(t, repo, job.ID, models.JobCancelado)
+
+	assert.ErrorIs(t, manager.Cancelar("a", job.ID), ErrJobFinalizado)
+}
+
+func TestManagerIsolaTenants(t *testing.T) {
+	manager, repo := setupManager(t)
+	tenants := make(chan string, 1)
+	manager.Registrar("tenant", func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		id, err := tenant.ID(ctx)
+		tenants <- id
+		return nil, "", err
+	})
+
+	manager.Iniciar(context.Background())
+	defer manager.Parar()
+
+	job, err := manager.Submeter("a", "tenant", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", <-tenants, "O executor roda no tenant do job")
+	aguardarStatus(t, repo, job.ID, models.JobConcluido)
+
+	_, err = manager.Buscar("b", job.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Outro tenant não enxerga o job")
+	assert.ErrorIs(t, manager.Cancelar("b", job.ID), gorm.ErrRecordNotFound)
+	_, err = manager.Buscar("a", job.ID)
+	assert.NoError(t, err)
+}
+
+func TestManagerRecuperaJobsAbandonados(t *testing.T) {
+	manager, repo := setupManager(t)
+	execucoes := 0
+	manager.Registrar("retomavel", func(ctx context.Context, job *models.Job, progresso Progresso) ([]byte, string, error) {
+		execucoes++
+		return nil, "", nil
+	})
+
+	// Simula um job que estava em execução quando a instância anterior foi encerrada
+	job, _ := manager.Submeter("a", "retomavel", nil)
+	reservado, err := repo.ClaimNext()
+	assert.NoError(t, err)
+	assert.Equal(t, job.ID, reservado.ID)
+	repo.AtualizarProgresso(job.ID, 0, 0, "")
+
+	manager.Iniciar(context.Background())
+	defer manager.Parar()
+
+	aguardarStatus(t, repo, job.ID, models.JobConcluido)
+	assert.Equal(t, 1, execucoes)
+}
@@ -0,0 +1,41 @@
+// Package lgpd atende os direitos do titular previstos na LGPD: o relatório dos dados mantidos
+// sobre um documento e a anonimização, que troca o documento por um hash com sal para que as
+// decisões de blocklist continuem valendo sem guardar o documento.
+package lgpd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrSemSal é retornado quando a anonimização é pedida sem o sal configurado
+var ErrSemSal = errors.New("sal da anonimização não configurado")
+
+// Pseudonimizador calcula o hash com sal dos documentos. Trocar o sal impede que os clientes já
+// anonimizados sejam reconhecidos, então ele deve ser mantido entre as versões da API.
+type Pseudonimizador struct {
+	sal []byte
+}
+
+// NewPseudonimizador cria o pseudonimizador; sem sal a anonimização fica desabilitada
+func NewPseudonimizador(sal string) *Pseudonimizador {
+	return &Pseudonimizador{sal: []byte(sal)}
+}
+
+// Habilitado indica se o sal foi configurado
+func (p *Pseudonimizador) Habilitado() bool {
+	return len(p.sal) > 0
+}
+
+// Hash retorna o HMAC-SHA256 do documento no tenant, em hexadecimal. O tenant entra no cálculo
+// para que o mesmo titular não possa ser relacionado entre tenants.
+func (p *Pseudonimizador) Hash(tenantID, documento string) (string, error) {
+	if !p.Habilitado() {
+		return "", ErrSemSal
+	}
+	mac := hmac.New(sha256.New, p.sal)
+	mac.Write([]byte(tenantID + ":" + documento))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
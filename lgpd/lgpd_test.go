@@ -0,0 +1,59 @@
+package lgpd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPseudonimizador(t *testing.T) {
+	// Caso de sucesso: Hash estável, dependente do sal e do tenant
+	p := NewPseudonimizador("sal-dos-testes-lgpd")
+	hash, err := p.Hash("a", "52998224725")
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+	assert.NotContains(t, hash, "52998224725")
+
+	mesmo, _ := p.Hash("a", "52998224725")
+	assert.Equal(t, hash, mesmo)
+	outroTenant, _ := p.Hash("b", "52998224725")
+	assert.NotEqual(t, hash, outroTenant, "O titular não pode ser relacionado entre tenants")
+	outroSal, _ := NewPseudonimizador("outro-sal-dos-testes").Hash("a", "52998224725")
+	assert.NotEqual(t, hash, outroSal)
+
+	// Caso de erro: Sem sal a anonimização fica desabilitada
+	semSal := NewPseudonimizador("")
+	assert.False(t, semSal.Habilitado())
+	_, err = semSal.Hash("a", "52998224725")
+	assert.ErrorIs(t, err, ErrSemSal)
+}
+
+func TestGerarPDF(t *testing.T) {
+	entregue := time.Now()
+	relatorio := &dtos.RelatorioTitularResponse{
+		Documento: "52998224725",
+		GeradoEm:  time.Now(),
+		Cliente:   &dtos.ClienteTitularResponse{Documento: "52998224725", RazaoSocial: "João Ávila", Blocklist: true},
+		Eventos: []dtos.EventoResponse{
+			{ID: 1, Tipo: "cliente.criado", Documento: "52998224725", Dados: json.RawMessage(`{"razaosocial":"João Ávila"}`)},
+		},
+		Compartilhamentos: []dtos.CompartilhamentoResponse{
+			{EventoID: 1, AssinaturaID: "crm", URL: "https://crm.exemplo.com/hook", Status: "entregue", EntregueEm: &entregue},
+			{EventoID: 1, AssinaturaID: "removida", Status: "falhou"},
+		},
+	}
+
+	conteudo, err := GerarPDF(relatorio)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(conteudo, []byte("%PDF-")))
+
+	relatorio.Cliente = nil
+	relatorio.Anonimizacao = &dtos.AnonimizacaoResponse{HashDocumento: "abc", AnonimizadoEm: time.Now()}
+	_, err = GerarPDF(relatorio)
+	assert.NoError(t, err)
+}
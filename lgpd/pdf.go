@@ -0,0 +1,102 @@
+package lgpd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const formatoData = "02/01/2006 15:04:05 MST"
+
+// GerarPDF monta a versão em PDF do relatório do titular, com as mesmas informações do JSON
+func GerarPDF(relatorio *dtos.RelatorioTitularResponse) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	// As fontes padrão do PDF usam cp1252, suficiente para os acentos do português
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetCreationDate(relatorio.GeradoEm)
+	pdf.SetTitle(tr("Relatório do titular "+relatorio.Documento), false)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	titulo := func(texto string) {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, tr(texto), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+	campo := func(rotulo, valor string) {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(45, 6, tr(rotulo), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 6, tr(valor), "", "L", false)
+	}
+	texto := func(valor string) {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 6, tr(valor), "", "L", false)
+	}
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr("Relatório de dados do titular"), "", 1, "L", false, 0, "")
+	campo("Documento", relatorio.Documento)
+	campo("Gerado em", relatorio.GeradoEm.Format(formatoData))
+
+	titulo("Dados cadastrais")
+	switch {
+	case relatorio.Cliente != nil:
+		campo("Documento", relatorio.Cliente.Documento)
+		campo("Razão social", relatorio.Cliente.RazaoSocial)
+		campo("Blocklist", simNao(relatorio.Cliente.Blocklist))
+		campo("Cadastrado em", relatorio.Cliente.CriadoEm.Format(formatoData))
+		campo("Atualizado em", relatorio.Cliente.AtualizadoEm.Format(formatoData))
+	case relatorio.Anonimizacao == nil:
+		texto("Nenhum cadastro encontrado para o documento.")
+	}
+	if relatorio.Anonimizacao != nil {
+		texto("O cadastro foi anonimizado. Resta somente o hash do documento, mantido para a blocklist.")
+		campo("Hash do documento", relatorio.Anonimizacao.HashDocumento)
+		campo("Blocklist", simNao(relatorio.Anonimizacao.Blocklist))
+		campo("Anonimizado em", relatorio.Anonimizacao.AnonimizadoEm.Format(formatoData))
+	}
+
+	titulo("Histórico de alterações")
+	if len(relatorio.Eventos) == 0 {
+		texto("Nenhum evento registrado.")
+	}
+	for _, evento := range relatorio.Eventos {
+		campo(evento.CriadoEm.Format(formatoData), fmt.Sprintf("#%d %s", evento.ID, evento.Tipo))
+		pdf.SetFont("Courier", "", 8)
+		pdf.MultiCell(0, 4, tr(string(evento.Dados)), "", "L", false)
+		pdf.Ln(1)
+	}
+
+	titulo("Compartilhamentos com terceiros (webhooks)")
+	if len(relatorio.Compartilhamentos) == 0 {
+		texto("Nenhum evento do titular foi enviado a webhooks.")
+	}
+	for _, compartilhamento := range relatorio.Compartilhamentos {
+		destino := compartilhamento.URL
+		if destino == "" {
+			destino = "assinatura removida " + compartilhamento.AssinaturaID
+		}
+		situacao := compartilhamento.Status
+		if compartilhamento.EntregueEm != nil {
+			situacao += " em " + compartilhamento.EntregueEm.Format(formatoData)
+		}
+		campo(fmt.Sprintf("Evento #%d", compartilhamento.EventoID), destino+" ("+situacao+")")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func simNao(valor bool) string {
+	if valor {
+		return "sim"
+	}
+	return "não"
+}
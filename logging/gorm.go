@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger envia os logs do gorm para o logger da requisição presente no contexto da consulta
+type GormLogger struct {
+	// ConsultaLenta é a duração a partir da qual uma consulta é registrada como aviso
+	ConsultaLenta time.Duration
+	nivel         logger.LogLevel
+}
+
+func NewGormLogger(consultaLenta time.Duration) *GormLogger {
+	return &GormLogger{ConsultaLenta: consultaLenta, nivel: logger.Info}
+}
+
+func (l *GormLogger) LogMode(nivel logger.LogLevel) logger.Interface {
+	copia := *l
+	copia.nivel = nivel
+	return &copia
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.nivel >= logger.Info {
+		FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.nivel >= logger.Warn {
+		FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.nivel >= logger.Error {
+		FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace registra erros de consulta, consultas lentas e, no nível debug, todas as consultas
+func (l *GormLogger) Trace(ctx context.Context, inicio time.Time, fc func() (string, int64), err error) {
+	if l.nivel <= logger.Silent {
+		return
+	}
+
+	log := FromContext(ctx)
+	duracao := time.Since(inicio)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.nivel >= logger.Error:
+		sql, linhas := fc()
+		log.ErrorContext(ctx, "Erro ao executar consulta", "erro", err, "sql", sql, "linhas", linhas, "duracao_ms", duracao.Milliseconds())
+	case l.ConsultaLenta > 0 && duracao > l.ConsultaLenta && l.nivel >= logger.Warn:
+		sql, linhas := fc()
+		log.WarnContext(ctx, "Consulta lenta", "sql", sql, "linhas", linhas, "duracao_ms", duracao.Milliseconds())
+	case log.Enabled(ctx, slog.LevelDebug):
+		sql, linhas := fc()
+		log.DebugContext(ctx, "Consulta executada", "sql", sql, "linhas", linhas, "duracao_ms", duracao.Milliseconds())
+	}
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const (
+	FormatoJSON  = "json"
+	FormatoTexto = "text"
+
+	// Redigido substitui o valor de atributos considerados sensíveis
+	Redigido = "[REDIGIDO]"
+)
+
+// chavesSensiveis são os trechos de nomes de atributo cujo valor nunca deve ser registrado
+var chavesSensiveis = []string{"password", "senha", "secret", "segredo", "token", "authorization", "api_key", "apikey", "dsn"}
+
+// senhaConexao encontra senhas em strings de conexão nos formatos chave=valor e URL
+var senhaConexao = regexp.MustCompile(`(?i)(password=)[^\s&]*|(://[^:/@\s]*:)[^@\s]+(@)`)
+
+type chaveContexto struct{}
+
+// Configurar cria o logger da aplicação com o nível e o formato informados e o define como padrão
+func Configurar(nivel, formato string, saida io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if nivel == "" {
+		nivel = "info"
+	}
+	if err := level.UnmarshalText([]byte(nivel)); err != nil {
+		return nil, fmt.Errorf("nível de log inválido: %s", nivel)
+	}
+
+	opcoes := &slog.HandlerOptions{Level: level, ReplaceAttr: Redigir}
+
+	var handler slog.Handler
+	switch strings.ToLower(formato) {
+	case "", FormatoJSON:
+		handler = slog.NewJSONHandler(saida, opcoes)
+	case FormatoTexto:
+		handler = slog.NewTextHandler(saida, opcoes)
+	default:
+		return nil, fmt.Errorf("formato de log inválido: %s", formato)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// Redigir oculta o valor de atributos sensíveis e as senhas contidas em strings de conexão
+func Redigir(grupos []string, attr slog.Attr) slog.Attr {
+	chave := strings.ToLower(attr.Key)
+	for _, sensivel := range chavesSensiveis {
+		if strings.Contains(chave, sensivel) {
+			return slog.String(attr.Key, Redigido)
+		}
+	}
+	if attr.Value.Kind() == slog.KindString {
+		attr.Value = slog.StringValue(RedigirTexto(attr.Value.String()))
+	}
+	return attr
+}
+
+// RedigirTexto remove as senhas de uma string de conexão
+func RedigirTexto(texto string) string {
+	return senhaConexao.ReplaceAllStringFunc(texto, func(trecho string) string {
+		partes := senhaConexao.FindStringSubmatch(trecho)
+		if partes[1] != "" {
+			return partes[1] + Redigido
+		}
+		return partes[2] + Redigido + partes[3]
+	})
+}
+
+// ComLogger retorna um contexto que carrega o logger da requisição
+func ComLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, chaveContexto{}, logger)
+}
+
+// FromContext retorna o logger associado ao contexto ou o logger padrão
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(chaveContexto{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
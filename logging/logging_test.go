@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurar(t *testing.T) {
+	anterior := slog.Default()
+	defer slog.SetDefault(anterior)
+
+	var saida bytes.Buffer
+	logger, err := Configurar("warn", FormatoJSON, &saida)
+	assert.NoError(t, err)
+
+	logger.Info("Ignorada pelo nível")
+	logger.Warn("Registrada", "documento", "52998224725")
+
+	var registro map[string]interface{}
+	assert.NoError(t, json.Unmarshal(saida.Bytes(), &registro), "Deve gerar uma única linha JSON")
+	assert.Equal(t, "Registrada", registro["msg"])
+	assert.Equal(t, "52998224725", registro["documento"])
+
+	_, err = Configurar("verboso", FormatoJSON, &saida)
+	assert.Error(t, err, "Nível inválido")
+	_, err = Configurar("info", "xml", &saida)
+	assert.Error(t, err, "Formato inválido")
+}
+
+func TestRedigir(t *testing.T) {
+	var saida bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&saida, &slog.HandlerOptions{ReplaceAttr: Redigir}))
+
+	logger.Info("Conectando",
+		"password", "s3nha",
+		"segredo_webhook", "abc",
+		"Authorization", "Bearer xyz",
+		"conexao", "user=api password=s3nha host=db",
+		"url", "postgres://api:s3nha@db:5432/clientes",
+		"redis", "redis://:s3nha@cache:6379/0",
+		"host", "db",
+	)
+
+	texto := saida.String()
+	assert.NotContains(t, texto, "s3nha")
+	assert.NotContains(t, texto, "abc")
+	assert.NotContains(t, texto, "xyz")
+	assert.Contains(t, texto, "password="+Redigido)
+	assert.Contains(t, texto, "postgres://api:"+Redigido+"@db:5432/clientes")
+	assert.Contains(t, texto, "redis://:"+Redigido+"@cache:6379/0")
+	assert.Contains(t, texto, "host=db")
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()), "Sem logger no contexto usa o padrão")
+
+	logger := slog.Default().With("request_id", "abc")
+	assert.Equal(t, logger, FromContext(ComLogger(context.Background(), logger)))
+}
@@ -1,17 +1,47 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/Gileno29/clientes-API/agendador"
+	"github.com/Gileno29/clientes-API/blocklist"
+	"github.com/Gileno29/clientes-API/config"
 	"github.com/Gileno29/clientes-API/database"
 	_ "github.com/Gileno29/clientes-API/docs"
+	"github.com/Gileno29/clientes-API/graphqlapi"
+	"github.com/Gileno29/clientes-API/grpcapi"
 	"github.com/Gileno29/clientes-API/handlers"
+	"github.com/Gileno29/clientes-API/health"
+	"github.com/Gileno29/clientes-API/jobs"
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/metrics"
 	"github.com/Gileno29/clientes-API/middlewares"
+	"github.com/Gileno29/clientes-API/migrations"
+	"github.com/Gileno29/clientes-API/models"
 	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/retencao"
+	"github.com/Gileno29/clientes-API/servidor"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/tracing"
 	"github.com/Gileno29/clientes-API/utils"
+	"github.com/Gileno29/clientes-API/webhooks"
 	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 var startTime time.Time
@@ -20,29 +50,371 @@ func main() {
 
 	utils.StartTime = time.Now()
 
-	database.Connect()
+	// O .env é opcional, as variáveis definidas nele têm a mesma precedência das variáveis de ambiente
+	godotenv.Load()
+
+	// Sem comando, ou somente com flags, o binário inicia a API. Os demais comandos são descritos
+	// em comandos.Uso.
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "serve" {
+		args = args[1:]
+	} else if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		os.Exit(executarComando(args[0], args[1:]))
+	}
+
+	cfg, err := config.Carregar(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuração inválida:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	logging.Configurar(cfg.Log.Nivel, cfg.Log.Formato, os.Stdout)
+
+	// Configura o exportador de traces: none (padrão), stdout ou otlp
+	encerrarTracing, err := tracing.Configurar(context.Background(), cfg.Tracing.Exportador, os.Stdout)
+	if err != nil {
+		slog.Error("Erro ao configurar o tracing", "erro", err)
+		os.Exit(1)
+	}
+
+	if err := database.Connect(cfg.BancoDeDados); err != nil {
+		slog.Error("Erro ao iniciar o banco de dados", "erro", err)
+		os.Exit(1)
+	}
 
 	db := database.DB
 
+	migrador, err := migrations.NewMigrador(db)
+	if err != nil {
+		slog.Error("Erro ao carregar as migrações", "erro", err)
+		os.Exit(1)
+	}
+	// O banco em memória começa vazio a cada execução e é migrado na inicialização
+	if cfg.BancoDeDados.Driver == config.DriverMemoria {
+		if _, err := migrador.Subir(context.Background(), 0); err != nil {
+			slog.Error("Erro ao executar as migrações", "erro", err)
+			os.Exit(1)
+		}
+	}
+
+	// Não atende requisições com o esquema atrás do binário ou com uma migração interrompida
+	if err := migrador.Verificar(context.Background()); err != nil {
+		slog.Error("Esquema do banco de dados desatualizado, execute 'apiclientes migrate up'", "erro", err)
+		os.Exit(1)
+	}
+
+	// Expõe as estatísticas do pool de conexões nas métricas
+	sqlDB, err := db.DB()
+	if err != nil {
+		slog.Error("Erro ao obter o pool de conexões", "erro", err)
+		os.Exit(1)
+	}
+	metrics.RegistrarBancoDeDados(sqlDB)
+
 	// Instancia repository e handler
-	clienteRepo := repository.NewClienteRepository(db)
+	// As buscas e listagens de clientes usam as réplicas de leitura, quando configuradas
+	roteador, err := database.ConectarReplicas(cfg.BancoDeDados, db)
+	if err != nil {
+		slog.Error("Erro ao conectar às réplicas", "erro", err)
+		os.Exit(1)
+	}
+	roteador.Iniciar(time.Duration(cfg.BancoDeDados.IntervaloVerificacaoReplicas))
+
+	cifrador, err := novoCifrador(cfg)
+	if err != nil {
+		slog.Error("Erro ao configurar a criptografia", "erro", err)
+		os.Exit(1)
+	}
+	clienteRepo, repoCifrado, cacheRedis, err := novoRepositorioClientes(cfg, roteador, cifrador)
+	if err != nil {
+		slog.Error("Erro ao montar o repositório de clientes", "erro", err)
+		os.Exit(1)
+	}
+	// Identifica o tenant de cada requisição; sem tenants configurados tudo pertence ao tenant padrão
+	resolvedor := novoResolvedor(cfg.Tenancy)
+
+	// Índice de blocklist em memória, carregado do banco e atualizado pelas escritas da instância
+	repoBlocklist := clienteRepo
+	indiceBlocklist := blocklist.NewIndice(resolvedor.IDs(), func(ctx context.Context, t string) ([]string, error) {
+		return repoBlocklist.ListarBloqueados(tenant.ComID(ctx, t))
+	})
+	if err := indiceBlocklist.Sincronizar(context.Background()); err != nil {
+		slog.Error("Erro ao carregar o índice de blocklist", "erro", err)
+		os.Exit(1)
+	}
+	indiceBlocklist.Iniciar(time.Duration(cfg.Blocklist.IntervaloSincronizacao))
+	clienteRepo = repository.NewClienteRepositoryComIndice(clienteRepo, indiceBlocklist)
+	// Hash com sal que substitui o documento dos clientes anonimizados a pedido do titular
+	pseudonimizador := lgpd.NewPseudonimizador(cfg.LGPD.Sal)
+	blocklistHandler := handlers.NewBlocklistHandler(indiceBlocklist, pseudonimizador)
+
 	clienteHandler := handlers.NewClienteHandler(clienteRepo)
+	clienteHandler.TimeoutConsulta = time.Duration(cfg.BancoDeDados.TimeoutConsulta)
+	clienteHandler.TimeoutEscrita = time.Duration(cfg.BancoDeDados.TimeoutEscrita)
+	clienteHandler.TimeoutLote = time.Duration(cfg.BancoDeDados.TimeoutLote)
+
+	// Os eventos gerados pelos clientes cifrados também são cifrados e são decifrados na leitura
+	eventoRepo := repository.NewEventoRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	if cifrador != nil {
+		eventoRepo = repository.NewEventoRepositoryCifrado(db, cifrador)
+		webhookRepo = repository.NewWebhookRepositoryCifrado(db, cifrador)
+	}
+
+	// Instancia o gerenciador de jobs em segundo plano e registra os tipos de job
+	jobManager := jobs.NewManager(repository.NewJobRepository(db), 4)
+	jobs.RegistrarExecutoresClientes(jobManager, clienteRepo)
+	if repoCifrado != nil {
+		jobs.RegistrarRecriptografia(jobManager, repoCifrado, eventoRepo.(repository.Recriptografavel))
+	}
+	jobManager.Iniciar(context.Background())
+	jobHandler := handlers.NewJobHandler(jobManager)
+
+	// Instancia o dispatcher que entrega os eventos do outbox para os webhooks
+	dispatcher := webhooks.NewDispatcher(eventoRepo, webhookRepo, nil)
+	dispatcher.Iniciar(context.Background())
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo)
+
+	// Relatório e anonimização dos titulares (LGPD)
+	titularHandler := handlers.NewTitularHandler(clienteRepo, eventoRepo, webhookRepo, pseudonimizador)
+	titularHandler.TimeoutConsulta = time.Duration(cfg.BancoDeDados.TimeoutConsulta)
+	titularHandler.TimeoutEscrita = time.Duration(cfg.BancoDeDados.TimeoutEscrita)
+
+	// Consultas GraphQL do back-office, com o histórico de eventos de cada cliente
+	servicoGraphQL := graphqlapi.NewServico(clienteRepo, eventoRepo)
+	servicoGraphQL.TimeoutConsulta = time.Duration(cfg.BancoDeDados.TimeoutConsulta)
+	servicoGraphQL.TimeoutEscrita = time.Duration(cfg.BancoDeDados.TimeoutEscrita)
+	graphqlHandler := handlers.NewGraphQLHandler(servicoGraphQL)
+
+	// Política de retenção, aplicada periodicamente em todos os tenants quando alguma regra é configurada
+	retencaoRepo := repository.NewRetencaoRepository(db)
+	politica := retencao.Politica{
+		ClientesRemovidos: time.Duration(cfg.Retencao.ClientesRemovidos),
+		ClientesInativos:  time.Duration(cfg.Retencao.ClientesInativos),
+		Eventos:           time.Duration(cfg.Retencao.Eventos),
+		Jobs:              time.Duration(cfg.Retencao.Jobs),
+	}
+	executorRetencao := retencao.NewExecutor(clienteRepo, retencaoRepo, pseudonimizador, politica, resolvedor.IDs())
+	retencaoHandler := handlers.NewRetencaoHandler(executorRetencao, retencaoRepo)
+
+	// Agendador das tarefas periódicas, executadas somente pela instância líder
+	lider, err := agendador.NewLider(db)
+	if err != nil {
+		slog.Error("Erro ao configurar a eleição do agendador", "erro", err)
+		os.Exit(1)
+	}
+	agendadorRepo := repository.NewAgendadorRepository(db)
+	agenda := agendador.NewAgendador(agendadorRepo, lider)
+	agenda.IntervaloVerificacao = time.Duration(cfg.Agendador.IntervaloVerificacao)
+	agenda.Jitter = time.Duration(cfg.Agendador.Jitter)
+	agenda.EsperaMinima = time.Duration(cfg.Agendador.EsperaMinima)
+	agenda.EsperaMaxima = time.Duration(cfg.Agendador.EsperaMaxima)
+	tarefas := map[string]agendador.Funcao{
+		// A revalidação é executada pelos jobs, um por tenant, com o progresso acompanhado em /jobs
+		"revalidacao": submeterJobs(jobManager, jobs.TipoRevalidacao, resolvedor.IDs()),
+	}
+	if politica.Habilitada() {
+		tarefas["retencao"] = func(ctx context.Context) (string, error) {
+			return resumoRetencao(executorRetencao.Executar(ctx, cfg.Retencao.Simulacao))
+		}
+	}
+	if repoCifrado != nil {
+		tarefas["recriptografia"] = submeterJobs(jobManager, jobs.TipoRecriptografia, resolvedor.IDs())
+	}
+	for nome, tarefa := range tarefas {
+		if err := agenda.Registrar(nome, cfg.Agendador.Agendas[nome], tarefa); err != nil {
+			slog.Error("Erro ao registrar a tarefa do agendador", "tarefa", nome, "erro", err)
+			os.Exit(1)
+		}
+	}
+	for nome, expressao := range cfg.Agendador.Agendas {
+		if _, ok := tarefas[nome]; !ok && expressao != "" {
+			slog.Warn("Agenda de tarefa não disponível ignorada", "tarefa", nome)
+		}
+	}
+	if cfg.Agendador.Habilitado {
+		agenda.Iniciar(context.Background())
+	}
+	agendadorHandler := handlers.NewAgendadorHandler(agenda, agendadorRepo)
+
+	// Stream de eventos (SSE) lido do mesmo log de eventos usado pelos webhooks
+	eventoHandler := handlers.NewEventoHandler(eventoRepo)
+
+	// Registra as dependências verificadas pela prontidão
+	registroHealth := health.NewRegistro(2 * time.Second)
+	registroHealth.Registrar(health.BancoDeDados(db))
+	registroHealth.Registrar(migrador.Checker())
+	healthHandler := handlers.NewHealthHandler(registroHealth)
 
 	// Cria o handler de suporte
 	suporteHandler := handlers.NewSuporteHandler()
 
-	// instancia o GIN
-	r := gin.Default()
+	// instancia o GIN sem o logger de texto padrão, substituído pelo log de acesso estruturado
+	r := gin.New()
+
+	// abre o span da requisição a partir do traceparent recebido, antes dos demais middlewares
+	r.Use(otelgin.Middleware(tracing.NomeServico))
+
+	// atribui o X-Request-ID e o logger da requisição
+	r.Use(middlewares.RequestIDMiddleware())
+	r.Use(middlewares.AccessLogMiddleware())
+	r.Use(middlewares.RecoveryMiddleware())
+	r.Use(middlewares.LeituraAposEscritaMiddleware())
+
+	// configura o middleware que registra as métricas de cada requisição, também usadas pelo /status
+	r.Use(middlewares.MetricsMiddleware())
 
-	// configura ara utilizzar o midware para interceptação e contagem das requests.
-	r.Use(middlewares.RequestCounterMiddleware())
+	// span do handler, registrado por último para medir somente o handler
+	r.Use(middlewares.HandlerSpanMiddleware())
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	r.POST("/clientes", clienteHandler.CadastrarCliente)
-	r.GET("/clientes", clienteHandler.ListarClientes)
-	r.GET("/clientes/:documento", clienteHandler.VerificarCliente)
 	r.GET("/status", suporteHandler.Status)
-	r.PUT("/clientes/:documento", clienteHandler.AtualizaCliente)
-	r.DELETE("/clientes/:documento", clienteHandler.DeletarCliente)
-	r.Run(":8080")
+	r.GET("/metrics", suporteHandler.Metricas)
+	r.GET("/health/live", healthHandler.Liveness)
+	r.GET("/health/ready", healthHandler.Readiness)
+
+	// rotas com dados de um tenant, que precisa ser identificado pela requisição
+	api := r.Group("/", middlewares.TenantMiddleware(resolvedor))
+	api.POST("/clientes", clienteHandler.CadastrarCliente)
+	api.POST("/clientes/lote", clienteHandler.ProcessarLote)
+	api.GET("/clientes", clienteHandler.ListarClientes)
+	api.GET("/clientes/:documento", clienteHandler.VerificarCliente)
+	api.GET("/blocklist/:documento", blocklistHandler.ConsultarBlocklist)
+	api.PUT("/clientes/:documento", clienteHandler.AtualizaCliente)
+	api.PATCH("/clientes/:documento", clienteHandler.AtualizaParcialCliente)
+	api.DELETE("/clientes/:documento", clienteHandler.DeletarCliente)
+	api.POST("/graphql", graphqlHandler.ExecutarGraphQL)
+	api.POST("/jobs/:tipo", jobHandler.SubmeterJob)
+	api.GET("/jobs/:id", jobHandler.ConsultarJob)
+	api.GET("/jobs/:id/resultado", jobHandler.BaixarResultadoJob)
+	api.DELETE("/jobs/:id", jobHandler.CancelarJob)
+	api.POST("/webhooks", webhookHandler.CadastrarWebhook)
+	api.GET("/webhooks", webhookHandler.ListarWebhooks)
+	api.GET("/webhooks/:id", webhookHandler.ConsultarWebhook)
+	api.DELETE("/webhooks/:id", webhookHandler.DeletarWebhook)
+	api.GET("/webhooks/:id/entregas", webhookHandler.ListarEntregasWebhook)
+	api.GET("/eventos", eventoHandler.StreamEventos)
+	api.GET("/titulares/:documento/relatorio", titularHandler.RelatorioTitular)
+	api.POST("/titulares/:documento/anonimizar", titularHandler.AnonimizarTitular)
+	api.POST("/retencao/simulacao", retencaoHandler.SimularRetencao)
+	api.GET("/retencao/execucoes", retencaoHandler.ListarExecucoesRetencao)
+
+	// rotas administrativas, que operam sobre todos os tenants
+	admin := r.Group("/admin", middlewares.AdminMiddleware(cfg.Admin.ChavesAPI))
+	admin.GET("/agendador/tarefas", agendadorHandler.ListarTarefas)
+	admin.POST("/agendador/tarefas/:nome/execucoes", agendadorHandler.DispararTarefa)
+	admin.GET("/agendador/execucoes", agendadorHandler.ListarExecucoesAgendador)
+	admin.GET("/agendador/execucoes/:id", agendadorHandler.ConsultarExecucaoAgendador)
+
+	// Encerra de forma graciosa ao receber SIGINT ou SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := servidor.NewServidor(cfg.Servidor, r, registroHealth)
+	srv.HTTP.RegisterOnShutdown(eventoHandler.Encerrar)
+	if cfg.GRPC.Porta > 0 {
+		// API gRPC com o mesmo repositório e índice da API REST, encerrada antes dos recursos que usa
+		servicoGRPC := grpcapi.NewServicoClientes(clienteRepo, indiceBlocklist, pseudonimizador)
+		servicoGRPC.TimeoutConsulta = time.Duration(cfg.BancoDeDados.TimeoutConsulta)
+		servicoGRPC.TimeoutEscrita = time.Duration(cfg.BancoDeDados.TimeoutEscrita)
+		servidorGRPC := grpcapi.NewServidor(servicoGRPC, resolvedor, registroHealth)
+
+		listenerGRPC, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.GRPC.Porta))
+		if err != nil {
+			slog.Error("Erro ao escutar na porta gRPC", "erro", err)
+			os.Exit(1)
+		}
+		go func() {
+			if err := servidorGRPC.Servir(listenerGRPC); err != nil {
+				slog.Error("Erro ao executar o servidor gRPC", "erro", err)
+			}
+		}()
+		srv.AoEncerrar("grpc", servidorGRPC.Encerrar)
+	}
+	// O agendador para antes dos jobs, que recebem os jobs submetidos pelas tarefas
+	srv.AoEncerrar("agendador", func(context.Context) error {
+		agenda.Parar()
+		return nil
+	})
+	srv.AoEncerrar("webhooks", func(context.Context) error {
+		dispatcher.Parar()
+		return nil
+	})
+	srv.AoEncerrar("jobs", func(context.Context) error {
+		jobManager.Parar()
+		return nil
+	})
+	srv.AoEncerrar("blocklist", func(context.Context) error {
+		indiceBlocklist.Encerrar()
+		return nil
+	})
+	srv.AoEncerrar("tracing", encerrarTracing)
+	if cacheRedis != nil {
+		srv.AoEncerrar("cache", func(context.Context) error {
+			return cacheRedis.Fechar()
+		})
+	}
+	srv.AoEncerrar("replicas", func(context.Context) error {
+		return roteador.Encerrar()
+	})
+	srv.AoEncerrar("banco de dados", func(context.Context) error {
+		return sqlDB.Close()
+	})
+
+	if err := srv.Executar(ctx); err != nil {
+		slog.Error("Erro ao executar o servidor", "erro", err)
+		os.Exit(1)
+	}
+}
+
+// submeterJobs retorna a tarefa do agendador que submete um job do tipo em cada tenant
+func submeterJobs(manager *jobs.Manager, tipo string, tenants []string) agendador.Funcao {
+	return func(ctx context.Context) (string, error) {
+		var erros []error
+		for _, t := range tenants {
+			if _, err := manager.Submeter(t, tipo, nil); err != nil {
+				erros = append(erros, fmt.Errorf("tenant %s: %w", t, err))
+			}
+		}
+		return fmt.Sprintf("%d jobs de %s submetidos", len(tenants)-len(erros), tipo), errors.Join(erros...)
+	}
+}
+
+// resumoRetencao resume a rodada da política de retenção, falhando se alguma regra falhou para que
+// o agendador a repita
+func resumoRetencao(resultados []models.ExecucaoRetencao) (string, error) {
+	var afetados int64
+	var falhas []string
+	for _, resultado := range resultados {
+		afetados += resultado.Afetados
+		if resultado.Erro != "" {
+			falhas = append(falhas, fmt.Sprintf("%s/%s: %s", resultado.TenantID, resultado.Regra, resultado.Erro))
+		}
+	}
+	resumo := fmt.Sprintf("%d regras aplicadas, %d registros alcançados", len(resultados), afetados)
+	if len(falhas) > 0 {
+		return resumo, errors.New(strings.Join(falhas, "; "))
+	}
+	return resumo, nil
+}
+
+// novoResolvedor monta o resolvedor de tenants a partir da configuração
+func novoResolvedor(cfg config.Tenancy) *tenant.Resolvedor {
+	tenants := make([]tenant.Tenant, 0, len(cfg.Tenants))
+	chaves := map[string]string{}
+	for _, t := range cfg.Tenants {
+		tenants = append(tenants, tenant.Tenant{
+			ID:              t.ID,
+			TimeoutConsulta: time.Duration(t.TimeoutConsulta),
+			TimeoutEscrita:  time.Duration(t.TimeoutEscrita),
+			TimeoutLote:     time.Duration(t.TimeoutLote),
+		})
+		for _, chave := range t.ChavesAPI {
+			chaves[chave] = t.ID
+		}
+	}
+	return tenant.NewResolvedor(tenants, chaves, cfg.SegredoJWT, cfg.AceitarCabecalho)
 }
@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "clientes_api"
+
+// Registry concentra todas as métricas expostas em /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	requisicoes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total de requisições HTTP atendidas por rota, método e status.",
+	}, []string{"method", "route", "status"})
+
+	duracaoRequisicoes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP por rota e método.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	requisicoesEmAndamento = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Requisições HTTP em atendimento no momento.",
+	})
+
+	requisicoesGRPC = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "Total de chamadas gRPC atendidas por método e código de status.",
+	}, []string{"method", "code"})
+
+	duracaoRequisicoesGRPC = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Latência das chamadas gRPC por método, até o fim do stream nas chamadas com stream.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	clientesCriados = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clientes_criados_total",
+		Help:      "Total de clientes cadastrados.",
+	})
+
+	alteracoesBlocklist = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clientes_blocklist_alteracoes_total",
+		Help:      "Total de alterações de blocklist pelo novo valor.",
+	}, []string{"blocklist"})
+
+	falhasValidacao = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validacao_falhas_total",
+		Help:      "Total de requisições recusadas por falha de validação, por tipo.",
+	}, []string{"tipo"})
+
+	consultasCache = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_consultas_total",
+		Help:      "Total de consultas ao cache de clientes por resultado.",
+	}, []string{"resultado"})
+
+	registrosRetencao = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retencao_registros_total",
+		Help:      "Total de registros alcançados pela política de retenção por regra, separando as simulações.",
+	}, []string{"regra", "simulacao"})
+
+	aplicacoesRetencao = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retencao_aplicacoes_total",
+		Help:      "Total de aplicações das regras de retenção por regra e resultado.",
+	}, []string{"regra", "resultado"})
+
+	ultimaRetencao = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "retencao_ultima_execucao_timestamp_seconds",
+		Help:      "Momento do fim da última rodada da política de retenção.",
+	})
+
+	execucoesAgendador = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "agendador_execucoes_total",
+		Help:      "Total de execuções das tarefas do agendador por tarefa, origem e resultado.",
+	}, []string{"tarefa", "origem", "resultado"})
+
+	duracaoAgendador = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "agendador_execucao_duracao_seconds",
+		Help:      "Duração das execuções das tarefas do agendador por tarefa.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 60, 300, 900, 3600},
+	}, []string{"tarefa"})
+
+	liderAgendador = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "agendador_lider",
+		Help:      "1 quando esta instância é a líder que executa as tarefas do agendador.",
+	})
+
+	// totalRequisicoes mantém a soma de todas as requisições para o endpoint /status
+	totalRequisicoes atomic.Int64
+)
+
+// Tipos de falha de validação usados no rótulo "tipo"
+const (
+	ValidacaoDocumento   = "documento"
+	ValidacaoRazaoSocial = "razao_social"
+	ValidacaoDados       = "dados"
+	ValidacaoPatch       = "patch"
+)
+
+// Resultados das consultas ao cache usados no rótulo "resultado"
+const (
+	CacheAcerto = "acerto"
+	// CacheAcertoNegativo é o acerto de um documento já conhecido como não cadastrado
+	CacheAcertoNegativo = "acerto_negativo"
+	CacheFalta          = "falta"
+	CacheErro           = "erro"
+)
+
+// Resultados das aplicações das regras de retenção usados no rótulo "resultado"
+const (
+	RetencaoSucesso = "sucesso"
+	RetencaoErro    = "erro"
+)
+
+// Resultados das execuções do agendador usados no rótulo "resultado"
+const (
+	AgendadorSucesso = "sucesso"
+	AgendadorErro    = "erro"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requisicoes,
+		duracaoRequisicoes,
+		requisicoesEmAndamento,
+		requisicoesGRPC,
+		duracaoRequisicoesGRPC,
+		clientesCriados,
+		alteracoesBlocklist,
+		falhasValidacao,
+		consultasCache,
+		registrosRetencao,
+		aplicacoesRetencao,
+		ultimaRetencao,
+		execucoesAgendador,
+		duracaoAgendador,
+		liderAgendador,
+	)
+}
+
+// RegistrarBancoDeDados passa a expor as estatísticas do pool de conexões do banco
+func RegistrarBancoDeDados(db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, "clientes"))
+}
+
+// Handler retorna o handler HTTP que expõe as métricas no formato do Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// IniciarRequisicao incrementa as requisições em andamento, devolvendo a função que as decrementa
+func IniciarRequisicao() func() {
+	requisicoesEmAndamento.Inc()
+	return requisicoesEmAndamento.Dec
+}
+
+// ObservarRequisicao registra uma requisição atendida
+func ObservarRequisicao(metodo, rota, status string, duracao time.Duration) {
+	requisicoes.WithLabelValues(metodo, rota, status).Inc()
+	duracaoRequisicoes.WithLabelValues(metodo, rota).Observe(duracao.Seconds())
+	totalRequisicoes.Add(1)
+}
+
+// ObservarRequisicaoGRPC registra uma chamada gRPC atendida
+func ObservarRequisicaoGRPC(metodo, codigo string, duracao time.Duration) {
+	requisicoesGRPC.WithLabelValues(metodo, codigo).Inc()
+	duracaoRequisicoesGRPC.WithLabelValues(metodo).Observe(duracao.Seconds())
+}
+
+// TotalRequisicoes retorna o total de requisições atendidas desde o início da aplicação
+func TotalRequisicoes() int {
+	return int(totalRequisicoes.Load())
+}
+
+func ClienteCriado() {
+	clientesCriados.Inc()
+}
+
+func BlocklistAlterado(blocklist bool) {
+	if blocklist {
+		alteracoesBlocklist.WithLabelValues("true").Inc()
+	} else {
+		alteracoesBlocklist.WithLabelValues("false").Inc()
+	}
+}
+
+func FalhaValidacao(tipo string) {
+	falhasValidacao.WithLabelValues(tipo).Inc()
+}
+
+func ConsultaCache(resultado string) {
+	consultasCache.WithLabelValues(resultado).Inc()
+}
+
+// RegraRetencao registra a aplicação de uma regra de retenção e os registros alcançados por ela
+func RegraRetencao(regra string, simulacao bool, afetados int64, err error) {
+	registrosRetencao.WithLabelValues(regra, strconv.FormatBool(simulacao)).Add(float64(afetados))
+	if err != nil {
+		aplicacoesRetencao.WithLabelValues(regra, RetencaoErro).Inc()
+	} else {
+		aplicacoesRetencao.WithLabelValues(regra, RetencaoSucesso).Inc()
+	}
+}
+
+// RodadaRetencao registra o fim de uma rodada da política de retenção
+func RodadaRetencao(fim time.Time) {
+	ultimaRetencao.Set(float64(fim.Unix()))
+}
+
+// ExecucaoAgendador registra o fim de uma execução de uma tarefa do agendador
+func ExecucaoAgendador(tarefa, origem string, duracao time.Duration, err error) {
+	resultado := AgendadorSucesso
+	if err != nil {
+		resultado = AgendadorErro
+	}
+	execucoesAgendador.WithLabelValues(tarefa, origem, resultado).Inc()
+	duracaoAgendador.WithLabelValues(tarefa).Observe(duracao.Seconds())
+}
+
+// LiderAgendador informa se esta instância é a líder do agendador
+func LiderAgendador(lider bool) {
+	if lider {
+		liderAgendador.Set(1)
+	} else {
+		liderAgendador.Set(0)
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogMiddleware registra uma linha estruturada por requisição atendida. Deve ser
+// registrado após o RequestIDMiddleware para que a linha carregue o id da requisição.
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inicio := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		nivel := slog.LevelInfo
+		switch {
+		case status >= 500:
+			nivel = slog.LevelError
+		case status >= 400:
+			nivel = slog.LevelWarn
+		}
+
+		rota := c.FullPath()
+		if rota == "" {
+			rota = "nao_encontrada"
+		}
+
+		atributos := []slog.Attr{
+			slog.String("metodo", c.Request.Method),
+			slog.String("rota", rota),
+			slog.String("caminho", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latencia_ms", float64(time.Since(inicio).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if sujeito := c.GetString(ChaveSujeito); sujeito != "" {
+			atributos = append(atributos, slog.String("sujeito", sujeito))
+		}
+		if len(c.Errors) > 0 {
+			atributos = append(atributos, slog.String("erros", c.Errors.String()))
+		}
+
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).LogAttrs(ctx, nivel, "Requisição atendida", atributos...)
+	}
+}
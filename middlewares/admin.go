@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"net/http"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware libera as rotas administrativas, que operam sobre todos os tenants, somente para
+// as chaves de API administrativas informadas no cabeçalho X-API-Key. Sem chaves configuradas as
+// rotas respondem 403.
+func AdminMiddleware(chaves []string) gin.HandlerFunc {
+	// As chaves são comparadas pelo hash, como as dos tenants, para não depender do tempo da comparação
+	hashes := map[[sha256.Size]byte]bool{}
+	for _, chave := range chaves {
+		hashes[sha256.Sum256([]byte(chave))] = true
+	}
+
+	return func(c *gin.Context) {
+		if len(hashes) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, dtos.ResponseErro{
+				Mensagem: "{'error': 'Acesso administrativo desabilitado'}",
+			})
+			return
+		}
+		chave := c.GetHeader(tenant.CabecalhoAPIKey)
+		if chave == "" || !hashes[sha256.Sum256([]byte(chave))] {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dtos.ResponseErro{
+				Mensagem: "{'error': 'Credencial inválida'}",
+			})
+			return
+		}
+		c.Set(ChaveSujeito, tenant.IDChave(chave))
+		c.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/Gileno29/clientes-API/database"
+	"github.com/gin-gonic/gin"
+)
+
+// LeituraAposEscritaMiddleware faz as leituras de uma requisição que já escreveu no banco irem
+// para o primário, para que ela enxergue as próprias alterações mesmo com réplicas atrasadas
+func LeituraAposEscritaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(database.ComLeituraAposEscrita(c.Request.Context()))
+		c.Next()
+	}
+}
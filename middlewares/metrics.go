@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware registra contagem, latência e requisições em andamento por rota
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inicio := time.Now()
+		finalizar := metrics.IniciarRequisicao()
+		defer finalizar()
+
+		c.Next()
+
+		// Usa o template da rota para não gerar uma série por documento consultado
+		rota := c.FullPath()
+		if rota == "" {
+			rota = "nao_encontrada"
+		}
+		metrics.ObservarRequisicao(c.Request.Method, rota, strconv.Itoa(c.Writer.Status()), time.Since(inicio))
+	}
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryMiddleware responde 500 quando um handler entra em pânico e registra o pânico
+// no logger da requisição, no lugar da saída em texto do gin.Recovery
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recuperado any) {
+		logging.FromContext(c.Request.Context()).Error("Panic ao atender a requisição",
+			"panic", fmt.Sprint(recuperado),
+			"stack", string(debug.Stack()),
+		)
+		erro := dtos.ResponseErro{
+			Mensagem: "{'error': 'Erro interno'}",
+			TraceID:  tracing.TraceID(c.Request.Context()),
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, erro)
+	})
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	// ChaveRequestID é a chave do id da requisição no contexto do gin
+	ChaveRequestID = "request_id"
+	// ChaveSujeito é a chave em que a autenticação registra o sujeito autenticado da requisição
+	ChaveSujeito = "sujeito"
+)
+
+// requestIDValido limita o id recebido do cliente para que ele não polua os logs
+var requestIDValido = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestIDMiddleware propaga o X-Request-ID recebido, ou gera um novo, e associa à requisição
+// um logger que inclui esse id em todas as mensagens
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !requestIDValido.MatchString(id) {
+			id = novoRequestID()
+		}
+
+		c.Set(ChaveRequestID, id)
+		c.Header(HeaderRequestID, id)
+
+		logger := slog.Default().With(slog.String(ChaveRequestID, id))
+		// Com o otelgin registrado antes, as mensagens também carregam o trace da requisição
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			logger = logger.With(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+		c.Request = c.Request.WithContext(logging.ComLogger(c.Request.Context(), logger))
+
+		c.Next()
+	}
+}
+
+func novoRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
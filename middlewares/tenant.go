@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TenantMiddleware identifica o tenant da requisição e o associa ao contexto usado pelos
+// repositórios, ao logger e ao span, registrando em ChaveSujeito a credencial que o identificou.
+// Requisições sem tenant identificado recebem 401.
+func TenantMiddleware(resolvedor *tenant.Resolvedor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, err := resolvedor.Resolver(c.Request)
+		if err != nil {
+			mensagem := "Tenant não identificado"
+			switch {
+			case errors.Is(err, tenant.ErrCredencial):
+				mensagem = "Credencial inválida"
+			case errors.Is(err, tenant.ErrDesconhecido):
+				mensagem = "Tenant desconhecido"
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dtos.ResponseErro{
+				Mensagem: "{'error': '" + mensagem + "'}",
+			})
+			return
+		}
+
+		// O sujeito autenticado aparece na linha de acesso
+		if t.Sujeito != "" {
+			c.Set(ChaveSujeito, t.Sujeito)
+		}
+
+		ctx := c.Request.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("tenant.id", t.ID))
+		logger := logging.FromContext(ctx).With(slog.String("tenant", t.ID))
+		ctx = logging.ComLogger(tenant.ComTenant(ctx, t), logger)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
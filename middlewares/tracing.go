@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Gileno29/clientes-API/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const HeaderTraceID = "X-Trace-ID"
+
+// HandlerSpanMiddleware abre um span para o handler da rota, filho do span da requisição criado
+// pelo otelgin, e devolve o id do trace no header X-Trace-ID para ser informado em chamados.
+// Deve ser o último middleware registrado para medir somente o handler.
+func HandlerSpanMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+			c.Header(HeaderTraceID, traceID)
+		}
+
+		// Sem rota o handler é o próprio middleware e não há o que medir
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		ctx, span := tracing.Tracer().Start(c.Request.Context(), nomeHandler(c.HandlerName()))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			for _, erro := range c.Errors {
+				span.RecordError(erro.Err)
+			}
+			span.SetStatus(codes.Error, http.StatusText(c.Writer.Status()))
+		}
+	}
+}
+
+// nomeHandler reduz o nome completo da função ao pacote, tipo e método,
+// ex.: handlers.(*ClienteHandler).ListarClientes
+func nomeHandler(nome string) string {
+	if i := strings.LastIndex(nome, "/"); i >= 0 {
+		nome = nome[i+1:]
+	}
+	return strings.TrimSuffix(nome, "-fm")
+}
@@ -0,0 +1,120 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Uso descreve o subcomando migrate
+const Uso = `uso: apiclientes migrate <comando> [flags de configuração]
+
+comandos:
+  up [n]          aplica as migrações pendentes, ou somente as próximas n
+  down [n]        desfaz a última migração aplicada, ou as últimas n
+  status          lista as migrações e se foram aplicadas
+  force <versao>  registra o esquema na versão informada sem executar SQL`
+
+// ErrUso indica argumentos inválidos para o subcomando migrate
+var ErrUso = errors.New(Uso)
+
+// ExecutarComando executa o subcomando migrate e escreve o resultado em saida
+func ExecutarComando(ctx context.Context, m *Migrador, args []string, saida io.Writer) error {
+	if len(args) == 0 {
+		return ErrUso
+	}
+
+	switch args[0] {
+	case "up":
+		limite, err := argumentoNumerico(args[1:], 0)
+		if err != nil {
+			return err
+		}
+		aplicadas, err := m.Subir(ctx, int(limite))
+		for _, migracao := range aplicadas {
+			fmt.Fprintf(saida, "aplicada %d_%s\n", migracao.Versao, migracao.Nome)
+		}
+		if err == nil && len(aplicadas) == 0 {
+			fmt.Fprintln(saida, "nenhuma migração pendente")
+		}
+		return err
+	case "down":
+		quantidade, err := argumentoNumerico(args[1:], 1)
+		if err != nil {
+			return err
+		}
+		desfeitas, err := m.Descer(ctx, int(quantidade))
+		for _, migracao := range desfeitas {
+			fmt.Fprintf(saida, "desfeita %d_%s\n", migracao.Versao, migracao.Nome)
+		}
+		if err == nil && len(desfeitas) == 0 {
+			fmt.Fprintln(saida, "nenhuma migração aplicada")
+		}
+		return err
+	case "status":
+		if len(args) > 1 {
+			return ErrUso
+		}
+		estados, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		escreverStatus(saida, estados)
+		return nil
+	case "force":
+		if len(args) != 2 {
+			return ErrUso
+		}
+		versao, err := argumentoNumerico(args[1:], 0)
+		if err != nil {
+			return err
+		}
+		if err := m.Forcar(ctx, versao); err != nil {
+			return err
+		}
+		fmt.Fprintf(saida, "esquema registrado na versão %d\n", versao)
+		return nil
+	}
+	return ErrUso
+}
+
+func escreverStatus(saida io.Writer, estados []Estado) {
+	tabela := tabwriter.NewWriter(saida, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabela, "VERSÃO\tNOME\tESTADO\tAPLICADA EM")
+	for _, estado := range estados {
+		situacao := "pendente"
+		switch {
+		case estado.Dirty:
+			situacao = "suja"
+		case estado.Desconhecida:
+			situacao = "desconhecida"
+		case estado.Aplicada:
+			situacao = "aplicada"
+		}
+		aplicadaEm := "-"
+		if estado.AplicadaEm != nil {
+			aplicadaEm = estado.AplicadaEm.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tabela, "%d\t%s\t%s\t%s\n", estado.Versao, estado.Nome, situacao, aplicadaEm)
+	}
+	tabela.Flush()
+}
+
+// argumentoNumerico lê o argumento opcional não negativo, usando padrao quando ausente
+func argumentoNumerico(args []string, padrao int64) (int64, error) {
+	if len(args) == 0 {
+		return padrao, nil
+	}
+	if len(args) > 1 {
+		return 0, ErrUso
+	}
+	valor, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || valor < 0 {
+		return 0, fmt.Errorf("número inválido: %s", args[0])
+	}
+	return valor, nil
+}
@@ -0,0 +1,378 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Gileno29/clientes-API/health"
+	"gorm.io/gorm"
+)
+
+// TabelaControle registra as versões aplicadas e se alguma ficou pela metade
+const TabelaControle = "schema_migrations"
+
+// chaveLock identifica o lock consultivo das migrações no Postgres
+const chaveLock int64 = 72451873
+
+var (
+	// ErrMigracoesPendentes indica que o esquema está atrás das migrações do binário
+	ErrMigracoesPendentes = errors.New("há migrações pendentes")
+	// ErrEsquemaSujo indica uma migração interrompida, que precisa de intervenção manual
+	ErrEsquemaSujo = errors.New("esquema sujo")
+)
+
+// lockLocal serializa as migrações nos bancos sem lock consultivo, como o SQLite de um único processo
+var lockLocal sync.Mutex
+
+// registro é a linha da tabela de controle de uma versão aplicada
+type registro struct {
+	Versao     int64 `gorm:"primaryKey;autoIncrement:false"`
+	Nome       string
+	Dirty      bool
+	AplicadaEm time.Time
+}
+
+func (registro) TableName() string {
+	return TabelaControle
+}
+
+// Estado descreve uma migração conhecida pelo binário ou registrada no banco
+type Estado struct {
+	Versao     int64
+	Nome       string
+	Aplicada   bool
+	Dirty      bool
+	AplicadaEm *time.Time
+	// Desconhecida indica uma versão registrada no banco que não existe neste binário
+	Desconhecida bool
+}
+
+// Migrador aplica e desfaz as migrações, uma de cada vez e em ordem de versão
+type Migrador struct {
+	db        *gorm.DB
+	dialeto   string
+	migracoes []Migracao
+}
+
+// NewMigrador usa as migrações embutidas para o dialeto do banco
+func NewMigrador(db *gorm.DB) (*Migrador, error) {
+	fontes, err := Fontes(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return NewMigradorDe(db, fontes)
+}
+
+// NewMigradorDe usa as migrações do diretório informado
+func NewMigradorDe(db *gorm.DB, fontes fs.FS) (*Migrador, error) {
+	migracoes, err := Ler(fontes)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrador{db: db, dialeto: db.Dialector.Name(), migracoes: migracoes}, nil
+}
+
+// Migracoes retorna as migrações conhecidas em ordem de versão
+func (m *Migrador) Migracoes() []Migracao {
+	return m.migracoes
+}
+
+// Status combina as migrações do binário com as registradas no banco, em ordem de versão
+func (m *Migrador) Status(ctx context.Context) ([]Estado, error) {
+	registros, err := m.registros(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	estados := make([]Estado, 0, len(m.migracoes))
+	for _, migracao := range m.migracoes {
+		estado := Estado{Versao: migracao.Versao, Nome: migracao.Nome}
+		if r, ok := registros[migracao.Versao]; ok {
+			estado.Aplicada = true
+			estado.Dirty = r.Dirty
+			estado.AplicadaEm = &r.AplicadaEm
+			delete(registros, migracao.Versao)
+		}
+		estados = append(estados, estado)
+	}
+	for _, r := range registros {
+		estados = append(estados, Estado{
+			Versao:       r.Versao,
+			Nome:         r.Nome,
+			Aplicada:     true,
+			Dirty:        r.Dirty,
+			AplicadaEm:   &r.AplicadaEm,
+			Desconhecida: true,
+		})
+	}
+	sort.Slice(estados, func(i, j int) bool {
+		return estados[i].Versao < estados[j].Versao
+	})
+	return estados, nil
+}
+
+// Verificar retorna erro se o esquema estiver sujo ou atrás das migrações do binário.
+// Versões mais novas que o binário são aceitas para permitir o deploy gradual.
+func (m *Migrador) Verificar(ctx context.Context) error {
+	estados, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pendentes []Estado
+	for _, estado := range estados {
+		if estado.Dirty {
+			return fmt.Errorf("%w: a migração %d_%s foi interrompida", ErrEsquemaSujo, estado.Versao, estado.Nome)
+		}
+		if !estado.Aplicada {
+			pendentes = append(pendentes, estado)
+		}
+	}
+	if len(pendentes) > 0 {
+		return fmt.Errorf("%w: %d, a partir de %d_%s", ErrMigracoesPendentes, len(pendentes), pendentes[0].Versao, pendentes[0].Nome)
+	}
+	return nil
+}
+
+// Checker verifica na prontidão, com Verificar, se o esquema acompanha as migrações do binário
+func (m *Migrador) Checker() health.Checker {
+	return health.CheckerFunc{NomeChecker: "migracoes", Fn: m.Verificar}
+}
+
+// Subir aplica as migrações pendentes em ordem, no máximo limite delas quando limite > 0
+func (m *Migrador) Subir(ctx context.Context, limite int) ([]Migracao, error) {
+	var aplicadas []Migracao
+	err := m.comLock(ctx, func(db *gorm.DB) error {
+		registros, err := m.registros(db)
+		if err != nil {
+			return err
+		}
+		if err := verificarSujo(registros); err != nil {
+			return err
+		}
+
+		for _, migracao := range m.migracoes {
+			if _, ok := registros[migracao.Versao]; ok {
+				continue
+			}
+			if limite > 0 && len(aplicadas) == limite {
+				break
+			}
+			if err := m.aplicar(db, migracao); err != nil {
+				return err
+			}
+			aplicadas = append(aplicadas, migracao)
+		}
+		return nil
+	})
+	return aplicadas, err
+}
+
+// Descer desfaz as últimas quantidade migrações aplicadas, da mais nova para a mais antiga
+func (m *Migrador) Descer(ctx context.Context, quantidade int) ([]Migracao, error) {
+	if quantidade <= 0 {
+		return nil, errors.New("informe quantas migrações desfazer")
+	}
+
+	var desfeitas []Migracao
+	err := m.comLock(ctx, func(db *gorm.DB) error {
+		registros, err := m.registros(db)
+		if err != nil {
+			return err
+		}
+		if err := verificarSujo(registros); err != nil {
+			return err
+		}
+
+		versoes := make([]int64, 0, len(registros))
+		for versao := range registros {
+			versoes = append(versoes, versao)
+		}
+		sort.Slice(versoes, func(i, j int) bool { return versoes[i] > versoes[j] })
+
+		for _, versao := range versoes {
+			if len(desfeitas) == quantidade {
+				break
+			}
+			migracao, ok := m.migracao(versao)
+			if !ok {
+				return fmt.Errorf("a versão %d_%s não existe neste binário e não pode ser desfeita", versao, registros[versao].Nome)
+			}
+			if err := m.desfazer(db, migracao); err != nil {
+				return err
+			}
+			desfeitas = append(desfeitas, migracao)
+		}
+		return nil
+	})
+	return desfeitas, err
+}
+
+// Forcar registra o esquema como estando exatamente na versão informada, sem executar SQL.
+// Serve para limpar um esquema sujo depois de corrigi-lo manualmente; 0 remove todos os registros.
+func (m *Migrador) Forcar(ctx context.Context, versao int64) error {
+	if _, ok := m.migracao(versao); !ok && versao != 0 {
+		return fmt.Errorf("versão %d não existe", versao)
+	}
+
+	return m.comLock(ctx, func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("versao > ?", versao).Delete(&registro{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&registro{}).Where("versao <= ?", versao).Update("dirty", false).Error; err != nil {
+				return err
+			}
+
+			registros, err := m.registros(tx)
+			if err != nil {
+				return err
+			}
+			for _, migracao := range m.migracoes {
+				if _, ok := registros[migracao.Versao]; ok || migracao.Versao > versao {
+					continue
+				}
+				r := registro{Versao: migracao.Versao, Nome: migracao.Nome, AplicadaEm: time.Now()}
+				if err := tx.Create(&r).Error; err != nil {
+					return err
+				}
+			}
+			slog.Warn("Versão do esquema forçada", "versao", versao)
+			return nil
+		})
+	})
+}
+
+// aplicar registra a versão como suja antes de executar o SQL, assim uma falha ou queda do
+// processo no meio da migração impede novas migrações e a inicialização até a intervenção
+func (m *Migrador) aplicar(db *gorm.DB, migracao Migracao) error {
+	inicio := time.Now()
+	slog.Info("Aplicando migração", "versao", migracao.Versao, "nome", migracao.Nome)
+
+	r := registro{Versao: migracao.Versao, Nome: migracao.Nome, Dirty: true, AplicadaEm: inicio}
+	if err := db.Create(&r).Error; err != nil {
+		return fmt.Errorf("falha ao registrar a migração %d_%s: %w", migracao.Versao, migracao.Nome, err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migracao.Up).Error; err != nil {
+			return err
+		}
+		return tx.Model(&registro{}).Where("versao = ?", migracao.Versao).Update("dirty", false).Error
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao aplicar a migração %d_%s: %w", migracao.Versao, migracao.Nome, err)
+	}
+
+	slog.Info("Migração aplicada", "versao", migracao.Versao, "nome", migracao.Nome, "duracao_ms", time.Since(inicio).Milliseconds())
+	return nil
+}
+
+// desfazer marca a versão como suja, executa o down e remove o registro na mesma transação
+func (m *Migrador) desfazer(db *gorm.DB, migracao Migracao) error {
+	inicio := time.Now()
+	slog.Info("Desfazendo migração", "versao", migracao.Versao, "nome", migracao.Nome)
+
+	if err := db.Model(&registro{}).Where("versao = ?", migracao.Versao).Update("dirty", true).Error; err != nil {
+		return fmt.Errorf("falha ao registrar a migração %d_%s: %w", migracao.Versao, migracao.Nome, err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migracao.Down).Error; err != nil {
+			return err
+		}
+		return tx.Where("versao = ?", migracao.Versao).Delete(&registro{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao desfazer a migração %d_%s: %w", migracao.Versao, migracao.Nome, err)
+	}
+
+	slog.Info("Migração desfeita", "versao", migracao.Versao, "nome", migracao.Nome, "duracao_ms", time.Since(inicio).Milliseconds())
+	return nil
+}
+
+// comLock garante a tabela de controle e executa fn com exclusividade entre as instâncias.
+// No Postgres o lock consultivo é de sessão, então fn roda na mesma conexão que o obteve.
+func (m *Migrador) comLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	if m.dialeto != "postgres" {
+		lockLocal.Lock()
+		defer lockLocal.Unlock()
+
+		db := m.db.WithContext(ctx)
+		if err := m.criarTabelaControle(db); err != nil {
+			return err
+		}
+		return fn(db)
+	}
+
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", chaveLock).Error; err != nil {
+			return fmt.Errorf("falha ao obter o lock das migrações: %w", err)
+		}
+		// Libera o lock mesmo com o contexto cancelado, senão ele volta ao pool preso à conexão
+		defer conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(?)", chaveLock)
+
+		if err := m.criarTabelaControle(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func (m *Migrador) criarTabelaControle(db *gorm.DB) error {
+	tipoData := "datetime"
+	if m.dialeto == "postgres" {
+		tipoData = "timestamptz"
+	}
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + TabelaControle + ` (
+		versao bigint PRIMARY KEY,
+		nome text NOT NULL,
+		dirty boolean NOT NULL DEFAULT false,
+		aplicada_em ` + tipoData + ` NOT NULL
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("falha ao criar a tabela %s: %w", TabelaControle, err)
+	}
+	return nil
+}
+
+// registros lê a tabela de controle; sem a tabela nenhuma versão foi aplicada
+func (m *Migrador) registros(db *gorm.DB) (map[int64]registro, error) {
+	if !db.Migrator().HasTable(TabelaControle) {
+		return map[int64]registro{}, nil
+	}
+
+	var lista []registro
+	if err := db.Order("versao").Find(&lista).Error; err != nil {
+		return nil, fmt.Errorf("falha ao ler a tabela %s: %w", TabelaControle, err)
+	}
+	registros := make(map[int64]registro, len(lista))
+	for _, r := range lista {
+		registros[r.Versao] = r
+	}
+	return registros, nil
+}
+
+func (m *Migrador) migracao(versao int64) (Migracao, bool) {
+	for _, migracao := range m.migracoes {
+		if migracao.Versao == versao {
+			return migracao, true
+		}
+	}
+	return Migracao{}, false
+}
+
+func verificarSujo(registros map[int64]registro) error {
+	for _, r := range registros {
+		if r.Dirty {
+			return fmt.Errorf("%w: a migração %d_%s foi interrompida, corrija o banco e use migrate force", ErrEsquemaSujo, r.Versao, r.Nome)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Os arquivos seguem o padrão NNNN_descricao.up.sql e NNNN_descricao.down.sql, um diretório por dialeto
+//
+//go:embed sql
+var arquivos embed.FS
+
+var padraoArquivo = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migracao é uma alteração versionada do esquema com o SQL para aplicá-la e desfazê-la
+type Migracao struct {
+	Versao int64
+	Nome   string
+	Up     string
+	Down   string
+}
+
+// Fontes retorna as migrações embutidas no binário para o dialeto do banco
+func Fontes(dialeto string) (fs.FS, error) {
+	fontes, err := fs.Sub(arquivos, path.Join("sql", dialeto))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(fontes, "."); err != nil {
+		return nil, fmt.Errorf("não há migrações para o banco %s", dialeto)
+	}
+	return fontes, nil
+}
+
+// Ler carrega as migrações do diretório ordenadas pela versão. Cada versão precisa dos
+// arquivos up e down e as versões não podem se repetir.
+func Ler(fontes fs.FS) ([]Migracao, error) {
+	entradas, err := fs.ReadDir(fontes, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	porVersao := map[int64]*Migracao{}
+	for _, entrada := range entradas {
+		if entrada.IsDir() {
+			continue
+		}
+		partes := padraoArquivo.FindStringSubmatch(entrada.Name())
+		if partes == nil {
+			return nil, fmt.Errorf("nome de migração inválido: %s", entrada.Name())
+		}
+		versao, err := strconv.ParseInt(partes[1], 10, 64)
+		if err != nil || versao <= 0 {
+			return nil, fmt.Errorf("versão de migração inválida: %s", entrada.Name())
+		}
+
+		conteudo, err := fs.ReadFile(fontes, entrada.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migracao, ok := porVersao[versao]
+		if !ok {
+			migracao = &Migracao{Versao: versao, Nome: partes[2]}
+			porVersao[versao] = migracao
+		}
+		if migracao.Nome != partes[2] {
+			return nil, fmt.Errorf("versão %d repetida: %s e %s", versao, migracao.Nome, partes[2])
+		}
+		if partes[3] == "up" {
+			migracao.Up = string(conteudo)
+		} else {
+			migracao.Down = string(conteudo)
+		}
+	}
+
+	migracoes := make([]Migracao, 0, len(porVersao))
+	for _, migracao := range porVersao {
+		if migracao.Up == "" || migracao.Down == "" {
+			return nil, fmt.Errorf("migração %d_%s precisa dos arquivos up e down", migracao.Versao, migracao.Nome)
+		}
+		migracoes = append(migracoes, *migracao)
+	}
+	sort.Slice(migracoes, func(i, j int) bool {
+		return migracoes[i].Versao < migracoes[j].Versao
+	})
+	return migracoes, nil
+}
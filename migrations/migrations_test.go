@@ -0,0 +1,328 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// verificarModelos confere se as tabelas e colunas de todos os modelos existem no esquema criado
+// pelas migrações
+func verificarModelos(t *testing.T, db *gorm.DB, modelos ...interface{}) {
+	t.Helper()
+	for _, modelo := range modelos {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(modelo))
+		if !assert.True(t, db.Migrator().HasTable(modelo), "tabela %s não encontrada", stmt.Schema.Table) {
+			continue
+		}
+		for _, coluna := range stmt.Schema.DBNames {
+			assert.True(t, db.Migrator().HasColumn(modelo, coluna), "coluna %s.%s não encontrada", stmt.Schema.Table, coluna)
+		}
+	}
+}
+
+// Modelos como eram antes da multi-tenancy, usados para reproduzir os bancos criados pelo AutoMigrate
+type clienteLegado struct {
+	Documento   string `gorm:"primaryKey;type:varchar(14)"`
+	RazaoSocial string `gorm:"not null"`
+	Blocklist   bool   `gorm:"default:false"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type jobLegado struct {
+	ID                     string `gorm:"primaryKey;type:varchar(32)"`
+	Tipo                   string `gorm:"not null;index"`
+	Status                 string `gorm:"not null;index;default:pendente"`
+	Parametros             string `gorm:"type:text"`
+	Processados            int
+	Total                  int
+	Erros                  string `gorm:"type:text"`
+	Resultado              []byte
+	ResultadoContentType   string
+	CancelamentoSolicitado bool `gorm:"default:false"`
+	HeartbeatEm            *time.Time
+	IniciadoEm             *time.Time
+	FinalizadoEm           *time.Time
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+type eventoLegado struct {
+	ID          uint   `gorm:"primaryKey"`
+	Tipo        string `gorm:"not null;index"`
+	Documento   string `gorm:"type:varchar(14);index"`
+	Payload     string `gorm:"type:text"`
+	PublicadoEm *time.Time
+	CreatedAt   time.Time
+}
+
+type assinaturaLegada struct {
+	ID        string `gorm:"primaryKey;type:varchar(32)"`
+	URL       string `gorm:"not null"`
+	Eventos   string `gorm:"type:text"`
+	Segredo   string `gorm:"not null"`
+	Ativo     bool   `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (clienteLegado) TableName() string    { return "clientes" }
+func (jobLegado) TableName() string        { return "jobs" }
+func (eventoLegado) TableName() string     { return "eventos" }
+func (assinaturaLegada) TableName() string { return "webhook_assinaturas" }
+
+// setupDB abre um banco SQLite em memória exclusivo do teste
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// fontesTeste cria migrações simples; a versão 3 falha ao ser aplicada quando quebrada é true
+func fontesTeste(quebrada bool) fstest.MapFS {
+	fontes := fstest.MapFS{
+		"0001_criar_a.up.sql":   {Data: []byte("CREATE TABLE a (id integer PRIMARY KEY);")},
+		"0001_criar_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"0002_criar_b.up.sql":   {Data: []byte("CREATE TABLE b (id integer PRIMARY KEY);")},
+		"0002_criar_b.down.sql": {Data: []byte("DROP TABLE b;")},
+	}
+	if quebrada {
+		fontes["0003_quebrada.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE c (id integer PRIMARY KEY); SELECT * FROM inexistente;")}
+		fontes["0003_quebrada.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE c;")}
+	}
+	return fontes
+}
+
+func TestLer(t *testing.T) {
+	t.Run("Ordena as migrações pela versão", func(t *testing.T) {
+		migracoes, err := Ler(fontesTeste(true))
+		require.NoError(t, err)
+		require.Len(t, migracoes, 3)
+		assert.Equal(t, int64(1), migracoes[0].Versao)
+		assert.Equal(t, "criar_a", migracoes[0].Nome)
+		assert.Equal(t, int64(3), migracoes[2].Versao)
+	})
+
+	t.Run("Retorna erro sem o arquivo down", func(t *testing.T) {
+		fontes := fontesTeste(false)
+		delete(fontes, "0002_criar_b.down.sql")
+		_, err := Ler(fontes)
+		assert.Error(t, err)
+	})
+
+	t.Run("Retorna erro para versão repetida", func(t *testing.T) {
+		fontes := fontesTeste(false)
+		fontes["0002_outra.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		fontes["0002_outra.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+		_, err := Ler(fontes)
+		assert.Error(t, err)
+	})
+
+	t.Run("Carrega as migrações embutidas dos dialetos", func(t *testing.T) {
+		for _, dialeto := range []string{"postgres", "sqlite"} {
+			fontes, err := Fontes(dialeto)
+			require.NoError(t, err)
+			migracoes, err := Ler(fontes)
+			require.NoError(t, err)
+			assert.NotEmpty(t, migracoes, dialeto)
+		}
+
+		_, err := Fontes("mysql")
+		assert.Error(t, err)
+	})
+}
+
+func TestMigrador(t *testing.T) {
+	ctx := context.Background()
+
+	// Caso de sucesso: As migrações embutidas criam o esquema esperado pelos modelos
+	t.Run("Cria o esquema dos modelos", func(t *testing.T) {
+		db := setupDB(t)
+		migrador, err := NewMigrador(db)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, migrador.Verificar(ctx), ErrMigracoesPendentes)
+
+		aplicadas, err := migrador.Subir(ctx, 0)
+		require.NoError(t, err)
+		assert.Len(t, aplicadas, len(migrador.Migracoes()))
+		assert.NoError(t, migrador.Verificar(ctx))
+		assert.NoError(t, migrador.Checker().Verificar(ctx))
+		verificarModelos(t, db, models.Todos()...)
+
+		// Executar de novo não tem efeito
+		aplicadas, err = migrador.Subir(ctx, 0)
+		require.NoError(t, err)
+		assert.Empty(t, aplicadas)
+
+		desfeitas, err := migrador.Descer(ctx, len(migrador.Migracoes()))
+		require.NoError(t, err)
+		assert.Len(t, desfeitas, len(migrador.Migracoes()))
+		assert.False(t, db.Migrator().HasTable(&models.Cliente{}))
+	})
+
+	// Caso de sucesso: Banco criado pelo AutoMigrate das versões anteriores é adotado
+	t.Run("Adota o esquema criado pelo AutoMigrate", func(t *testing.T) {
+		db := setupDB(t)
+		require.NoError(t, db.AutoMigrate(&clienteLegado{}, &jobLegado{}, &eventoLegado{}, &assinaturaLegada{}, &models.WebhookEntrega{}, &models.WebhookTentativa{}))
+		db.Create(&clienteLegado{Documento: "52998224725", RazaoSocial: "João Silva"})
+
+		migrador, err := NewMigrador(db)
+		require.NoError(t, err)
+		_, err = migrador.Subir(ctx, 0)
+		require.NoError(t, err)
+
+		var cliente models.Cliente
+		require.NoError(t, db.First(&cliente).Error)
+		assert.Equal(t, "padrao", cliente.TenantID, "Os dados existentes devem ser mantidos no tenant padrão")
+		assert.NoError(t, db.Create(&models.Cliente{TenantID: "outro", Documento: "52998224725", RazaoSocial: "João Silva"}).Error,
+			"O mesmo documento pode existir em outro tenant")
+	})
+
+	// Caso de sucesso: Sobe e desce uma quantidade limitada de migrações
+	t.Run("Respeita a quantidade informada", func(t *testing.T) {
+		db := setupDB(t)
+		migrador, err := NewMigradorDe(db, fontesTeste(false))
+		require.NoError(t, err)
+
+		aplicadas, err := migrador.Subir(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, aplicadas, 1)
+		assert.True(t, db.Migrator().HasTable("a"))
+		assert.False(t, db.Migrator().HasTable("b"))
+
+		_, err = migrador.Subir(ctx, 0)
+		require.NoError(t, err)
+		desfeitas, err := migrador.Descer(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, desfeitas, 1)
+		assert.Equal(t, int64(2), desfeitas[0].Versao)
+		assert.True(t, db.Migrator().HasTable("a"))
+		assert.False(t, db.Migrator().HasTable("b"))
+	})
+
+	// Caso de erro: Migração que falha deixa o esquema sujo até o force
+	t.Run("Marca o esquema como sujo quando a migração falha", func(t *testing.T) {
+		db := setupDB(t)
+		migrador, err := NewMigradorDe(db, fontesTeste(true))
+		require.NoError(t, err)
+
+		aplicadas, err := migrador.Subir(ctx, 0)
+		assert.Error(t, err)
+		assert.Len(t, aplicadas, 2)
+		assert.False(t, db.Migrator().HasTable("c"), "A migração que falhou deve ter sido desfeita")
+		assert.ErrorIs(t, migrador.Verificar(ctx), ErrEsquemaSujo)
+
+		_, err = migrador.Subir(ctx, 0)
+		assert.ErrorIs(t, err, ErrEsquemaSujo, "Não deve migrar com o esquema sujo")
+		_, err = migrador.Descer(ctx, 1)
+		assert.ErrorIs(t, err, ErrEsquemaSujo)
+
+		require.NoError(t, migrador.Forcar(ctx, 2))
+		assert.ErrorIs(t, migrador.Verificar(ctx), ErrMigracoesPendentes)
+
+		estados, err := migrador.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, estados, 3)
+		assert.True(t, estados[1].Aplicada)
+		assert.False(t, estados[2].Aplicada)
+		assert.False(t, estados[2].Dirty)
+	})
+
+	// Caso de sucesso: Versões mais novas que o binário não impedem a inicialização
+	t.Run("Aceita versões desconhecidas", func(t *testing.T) {
+		db := setupDB(t)
+		migrador, err := NewMigradorDe(db, fontesTeste(false))
+		require.NoError(t, err)
+		novo, err := NewMigradorDe(db, fontesTeste(true))
+		require.NoError(t, err)
+
+		require.NoError(t, novo.Forcar(ctx, 3))
+		assert.NoError(t, migrador.Verificar(ctx))
+
+		estados, err := migrador.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, estados, 3)
+		assert.True(t, estados[2].Desconhecida)
+
+		_, err = migrador.Descer(ctx, 1)
+		assert.Error(t, err, "Versão desconhecida não pode ser desfeita")
+	})
+
+	// Caso de sucesso: Instâncias concorrentes aplicam cada migração uma única vez
+	t.Run("Serializa migrações concorrentes", func(t *testing.T) {
+		db := setupDB(t)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		total := 0
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				migrador, err := NewMigradorDe(db, fontesTeste(false))
+				if !assert.NoError(t, err) {
+					return
+				}
+				aplicadas, err := migrador.Subir(ctx, 0)
+				assert.NoError(t, err)
+				mu.Lock()
+				total += len(aplicadas)
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 2, total)
+	})
+}
+
+func TestExecutarComando(t *testing.T) {
+	ctx := context.Background()
+	db := setupDB(t)
+	migrador, err := NewMigradorDe(db, fontesTeste(false))
+	require.NoError(t, err)
+
+	executar := func(args ...string) (string, error) {
+		var saida bytes.Buffer
+		err := ExecutarComando(ctx, migrador, args, &saida)
+		return saida.String(), err
+	}
+
+	saida, err := executar("up")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "aplicada 1_criar_a")
+	assert.Contains(t, saida, "aplicada 2_criar_b")
+
+	saida, err = executar("status")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "VERSÃO")
+	assert.Regexp(t, `2\s+criar_b\s+aplicada`, saida)
+
+	saida, err = executar("down")
+	require.NoError(t, err)
+	assert.Equal(t, "desfeita 2_criar_b\n", saida)
+
+	saida, err = executar("status")
+	require.NoError(t, err)
+	assert.Regexp(t, `2\s+criar_b\s+pendente\s+-`, saida)
+
+	_, err = executar("force")
+	assert.ErrorIs(t, err, ErrUso)
+	_, err = executar("down", "x")
+	assert.Error(t, err)
+	_, err = executar("sideways")
+	assert.ErrorIs(t, err, ErrUso)
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS webhook_tentativas;
+DROP TABLE IF EXISTS webhook_entregas;
+DROP TABLE IF EXISTS webhook_assinaturas;
+DROP TABLE IF EXISTS eventos;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS clientes;
@@ -0,0 +1,77 @@
+-- Esquema inicial. Usa IF NOT EXISTS para adotar os bancos criados pelo AutoMigrate
+-- das versões anteriores sem recriar as tabelas.
+CREATE TABLE IF NOT EXISTS clientes (
+    documento varchar(14) PRIMARY KEY,
+    razao_social text NOT NULL,
+    blocklist boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id varchar(32) PRIMARY KEY,
+    tipo text NOT NULL,
+    status text NOT NULL DEFAULT 'pendente',
+    parametros text,
+    processados bigint,
+    total bigint,
+    erros text,
+    resultado bytea,
+    resultado_content_type text,
+    cancelamento_solicitado boolean DEFAULT false,
+    heartbeat_em timestamptz,
+    iniciado_em timestamptz,
+    finalizado_em timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_tipo ON jobs (tipo);
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status);
+
+CREATE TABLE IF NOT EXISTS eventos (
+    id bigserial PRIMARY KEY,
+    tipo text NOT NULL,
+    documento varchar(14),
+    payload text,
+    publicado_em timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_eventos_tipo ON eventos (tipo);
+CREATE INDEX IF NOT EXISTS idx_eventos_documento ON eventos (documento);
+
+CREATE TABLE IF NOT EXISTS webhook_assinaturas (
+    id varchar(32) PRIMARY KEY,
+    url text NOT NULL,
+    eventos text,
+    segredo text NOT NULL,
+    ativo boolean DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS webhook_entregas (
+    id bigserial PRIMARY KEY,
+    assinatura_id varchar(32) NOT NULL,
+    evento_id bigint NOT NULL,
+    status text NOT NULL DEFAULT 'pendente',
+    tentativas bigint,
+    proxima_tentativa_em timestamptz,
+    entregue_em timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_assinatura_id ON webhook_entregas (assinatura_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_evento_id ON webhook_entregas (evento_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_status ON webhook_entregas (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_proxima_tentativa_em ON webhook_entregas (proxima_tentativa_em);
+
+CREATE TABLE IF NOT EXISTS webhook_tentativas (
+    id bigserial PRIMARY KEY,
+    entrega_id bigint NOT NULL,
+    numero bigint,
+    status_http bigint,
+    erro text,
+    duracao_ms bigint,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_tentativas_entrega_id ON webhook_tentativas (entrega_id);
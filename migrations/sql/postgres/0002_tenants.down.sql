@@ -0,0 +1,13 @@
+-- Falha se o mesmo documento estiver cadastrado em mais de um tenant
+ALTER TABLE clientes DROP CONSTRAINT clientes_pkey;
+ALTER TABLE clientes ADD PRIMARY KEY (documento);
+ALTER TABLE clientes DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_jobs_tenant_id;
+ALTER TABLE jobs DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_eventos_tenant_id;
+ALTER TABLE eventos DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_webhook_assinaturas_tenant_id;
+ALTER TABLE webhook_assinaturas DROP COLUMN tenant_id;
@@ -0,0 +1,18 @@
+-- Multi-tenancy: os dados existentes passam a pertencer ao tenant padrao e o documento do
+-- cliente passa a ser único somente dentro do tenant.
+ALTER TABLE clientes ADD COLUMN tenant_id varchar(64) NOT NULL DEFAULT 'padrao';
+ALTER TABLE clientes ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE clientes DROP CONSTRAINT clientes_pkey;
+ALTER TABLE clientes ADD PRIMARY KEY (tenant_id, documento);
+
+ALTER TABLE jobs ADD COLUMN tenant_id varchar(64) NOT NULL DEFAULT 'padrao';
+ALTER TABLE jobs ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_jobs_tenant_id ON jobs (tenant_id);
+
+ALTER TABLE eventos ADD COLUMN tenant_id varchar(64) NOT NULL DEFAULT 'padrao';
+ALTER TABLE eventos ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_eventos_tenant_id ON eventos (tenant_id);
+
+ALTER TABLE webhook_assinaturas ADD COLUMN tenant_id varchar(64) NOT NULL DEFAULT 'padrao';
+ALTER TABLE webhook_assinaturas ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_webhook_assinaturas_tenant_id ON webhook_assinaturas (tenant_id);
@@ -0,0 +1,4 @@
+-- Falha se houver eventos de titulares anonimizados, que não cabem mais na coluna original
+ALTER TABLE eventos ALTER COLUMN documento TYPE varchar(14);
+
+DROP TABLE IF EXISTS clientes_anonimizados;
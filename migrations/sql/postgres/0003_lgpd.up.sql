@@ -0,0 +1,11 @@
+-- LGPD: clientes anonimizados a pedido do titular. Resta somente o hash do documento, com sal, e a
+-- situação da blocklist. Os eventos do titular passam a referenciar o hash, mais longo que o documento.
+CREATE TABLE IF NOT EXISTS clientes_anonimizados (
+    tenant_id varchar(64) NOT NULL,
+    hash_documento varchar(64) NOT NULL,
+    blocklist boolean DEFAULT false,
+    anonimizado_em timestamptz NOT NULL,
+    PRIMARY KEY (tenant_id, hash_documento)
+);
+
+ALTER TABLE eventos ALTER COLUMN documento TYPE varchar(64);
@@ -0,0 +1,7 @@
+-- Falha se houver clientes cifrados, que não cabem mais na coluna original. Decifre-os antes,
+-- desabilitando a criptografia somente depois de gravá-los em texto puro.
+DROP INDEX IF EXISTS idx_clientes_chave_id;
+ALTER TABLE clientes DROP COLUMN chave_cifrada;
+ALTER TABLE clientes DROP COLUMN chave_id;
+ALTER TABLE clientes DROP COLUMN documento_cifrado;
+ALTER TABLE clientes ALTER COLUMN documento TYPE varchar(14);
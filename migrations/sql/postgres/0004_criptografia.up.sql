@@ -0,0 +1,8 @@
+-- Criptografia dos dados pessoais: a coluna documento passa a aceitar o hash com chave do
+-- documento e o envelope de cada cliente fica nas novas colunas. Os clientes existentes continuam
+-- em texto puro até serem gravados de novo ou convertidos pelo job de recriptografia.
+ALTER TABLE clientes ALTER COLUMN documento TYPE varchar(64);
+ALTER TABLE clientes ADD COLUMN documento_cifrado text NOT NULL DEFAULT '';
+ALTER TABLE clientes ADD COLUMN chave_id varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE clientes ADD COLUMN chave_cifrada text NOT NULL DEFAULT '';
+CREATE INDEX idx_clientes_chave_id ON clientes (chave_id);
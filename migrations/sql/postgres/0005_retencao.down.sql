@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_jobs_finalizado_em;
+DROP INDEX IF EXISTS idx_eventos_created_at;
+DROP INDEX IF EXISTS idx_clientes_updated_at;
+
+DROP TABLE IF EXISTS retencao_execucoes;
@@ -0,0 +1,17 @@
+-- Agendador: histórico das execuções das tarefas periódicas e das solicitadas pela API
+CREATE TABLE IF NOT EXISTS agendador_execucoes (
+    id bigserial PRIMARY KEY,
+    tarefa varchar(64) NOT NULL,
+    origem varchar(16) NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pendente',
+    agendado_para timestamptz,
+    tentativa bigint,
+    instancia text,
+    resultado text,
+    erro text,
+    iniciado_em timestamptz,
+    finalizado_em timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_agendador_execucoes_tarefa ON agendador_execucoes (tarefa);
+CREATE INDEX IF NOT EXISTS idx_agendador_execucoes_status ON agendador_execucoes (status);
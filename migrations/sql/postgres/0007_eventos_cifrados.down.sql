@@ -0,0 +1,5 @@
+-- Os eventos cifrados perdem o envelope e deixam de ser legíveis
+DROP INDEX IF EXISTS idx_eventos_chave_id;
+ALTER TABLE eventos DROP COLUMN chave_cifrada;
+ALTER TABLE eventos DROP COLUMN chave_id;
+ALTER TABLE eventos DROP COLUMN documento_cifrado;
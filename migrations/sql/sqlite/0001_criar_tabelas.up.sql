@@ -0,0 +1,77 @@
+-- Esquema inicial. Usa IF NOT EXISTS para adotar os bancos criados pelo AutoMigrate
+-- das versões anteriores sem recriar as tabelas.
+CREATE TABLE IF NOT EXISTS clientes (
+    documento varchar(14) PRIMARY KEY,
+    razao_social text NOT NULL,
+    blocklist boolean DEFAULT false,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id varchar(32) PRIMARY KEY,
+    tipo text NOT NULL,
+    status text NOT NULL DEFAULT 'pendente',
+    parametros text,
+    processados integer,
+    total integer,
+    erros text,
+    resultado blob,
+    resultado_content_type text,
+    cancelamento_solicitado boolean DEFAULT false,
+    heartbeat_em datetime,
+    iniciado_em datetime,
+    finalizado_em datetime,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_tipo ON jobs (tipo);
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status);
+
+CREATE TABLE IF NOT EXISTS eventos (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    tipo text NOT NULL,
+    documento varchar(14),
+    payload text,
+    publicado_em datetime,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_eventos_tipo ON eventos (tipo);
+CREATE INDEX IF NOT EXISTS idx_eventos_documento ON eventos (documento);
+
+CREATE TABLE IF NOT EXISTS webhook_assinaturas (
+    id varchar(32) PRIMARY KEY,
+    url text NOT NULL,
+    eventos text,
+    segredo text NOT NULL,
+    ativo boolean DEFAULT true,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS webhook_entregas (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    assinatura_id varchar(32) NOT NULL,
+    evento_id integer NOT NULL,
+    status text NOT NULL DEFAULT 'pendente',
+    tentativas integer,
+    proxima_tentativa_em datetime,
+    entregue_em datetime,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_assinatura_id ON webhook_entregas (assinatura_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_evento_id ON webhook_entregas (evento_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_status ON webhook_entregas (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_entregas_proxima_tentativa_em ON webhook_entregas (proxima_tentativa_em);
+
+CREATE TABLE IF NOT EXISTS webhook_tentativas (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    entrega_id integer NOT NULL,
+    numero integer,
+    status_http integer,
+    erro text,
+    duracao_ms integer,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_tentativas_entrega_id ON webhook_tentativas (entrega_id);
@@ -0,0 +1,21 @@
+-- Falha se o mesmo documento estiver cadastrado em mais de um tenant
+CREATE TABLE clientes_sem_tenant (
+    documento varchar(14) PRIMARY KEY,
+    razao_social text NOT NULL,
+    blocklist boolean DEFAULT false,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO clientes_sem_tenant (documento, razao_social, blocklist, created_at, updated_at)
+    SELECT documento, razao_social, blocklist, created_at, updated_at FROM clientes;
+DROP TABLE clientes;
+ALTER TABLE clientes_sem_tenant RENAME TO clientes;
+
+DROP INDEX IF EXISTS idx_jobs_tenant_id;
+ALTER TABLE jobs DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_eventos_tenant_id;
+ALTER TABLE eventos DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS idx_webhook_assinaturas_tenant_id;
+ALTER TABLE webhook_assinaturas DROP COLUMN tenant_id;
@@ -0,0 +1,25 @@
+-- Multi-tenancy: os dados existentes passam a pertencer ao tenant padrao e o documento do
+-- cliente passa a ser único somente dentro do tenant. O SQLite não altera a chave primária,
+-- então a tabela de clientes é recriada.
+CREATE TABLE clientes_tenants (
+    tenant_id varchar(64) NOT NULL,
+    documento varchar(14) NOT NULL,
+    razao_social text NOT NULL,
+    blocklist boolean DEFAULT false,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (tenant_id, documento)
+);
+INSERT INTO clientes_tenants (tenant_id, documento, razao_social, blocklist, created_at, updated_at)
+    SELECT 'padrao', documento, razao_social, blocklist, created_at, updated_at FROM clientes;
+DROP TABLE clientes;
+ALTER TABLE clientes_tenants RENAME TO clientes;
+
+ALTER TABLE jobs ADD COLUMN tenant_id varchar(64) NOT NULL DEFAULT 'padrao';
+CREATE INDEX idx_jobs_tenant_id ON jobs (tenant_id);
+
+ALTER TABLE eventos ADD COLUMN tenant_id varchar(64) NOT NULL DEFAULT 'padrao';
+CREATE INDEX idx_eventos_tenant_id ON eventos (tenant_id);
+
+ALTER TABLE webhook_assinaturas ADD COLUMN tenant_id varchar(64) NOT NULL DEFAULT 'padrao';
+CREATE INDEX idx_webhook_assinaturas_tenant_id ON webhook_assinaturas (tenant_id);
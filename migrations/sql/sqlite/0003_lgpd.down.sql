@@ -0,0 +1 @@
+DROP TABLE IF EXISTS clientes_anonimizados;
@@ -0,0 +1,9 @@
+-- LGPD: clientes anonimizados a pedido do titular. Resta somente o hash do documento, com sal, e a
+-- situação da blocklist. O SQLite não limita o tamanho do varchar, então os eventos não mudam.
+CREATE TABLE IF NOT EXISTS clientes_anonimizados (
+    tenant_id varchar(64) NOT NULL,
+    hash_documento varchar(64) NOT NULL,
+    blocklist boolean DEFAULT false,
+    anonimizado_em datetime NOT NULL,
+    PRIMARY KEY (tenant_id, hash_documento)
+);
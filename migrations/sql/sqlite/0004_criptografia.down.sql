@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_clientes_chave_id;
+ALTER TABLE clientes DROP COLUMN chave_cifrada;
+ALTER TABLE clientes DROP COLUMN chave_id;
+ALTER TABLE clientes DROP COLUMN documento_cifrado;
@@ -0,0 +1,6 @@
+-- Criptografia dos dados pessoais: o envelope de cada cliente fica nas novas colunas. O SQLite não
+-- limita o tamanho do varchar, então a coluna documento aceita o hash sem alteração.
+ALTER TABLE clientes ADD COLUMN documento_cifrado text NOT NULL DEFAULT '';
+ALTER TABLE clientes ADD COLUMN chave_id varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE clientes ADD COLUMN chave_cifrada text NOT NULL DEFAULT '';
+CREATE INDEX idx_clientes_chave_id ON clientes (chave_id);
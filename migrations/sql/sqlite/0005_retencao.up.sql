@@ -0,0 +1,19 @@
+-- Política de retenção: registro de cada regra aplicada e índices das datas consultadas por ela
+CREATE TABLE IF NOT EXISTS retencao_execucoes (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    execucao varchar(32) NOT NULL,
+    tenant_id varchar(64) NOT NULL,
+    regra text NOT NULL,
+    simulacao boolean DEFAULT false,
+    limite datetime,
+    afetados bigint,
+    erro text,
+    iniciado_em datetime,
+    finalizado_em datetime
+);
+CREATE INDEX IF NOT EXISTS idx_retencao_execucoes_execucao ON retencao_execucoes (execucao);
+CREATE INDEX IF NOT EXISTS idx_retencao_execucoes_tenant_id ON retencao_execucoes (tenant_id);
+
+CREATE INDEX IF NOT EXISTS idx_clientes_updated_at ON clientes (updated_at);
+CREATE INDEX IF NOT EXISTS idx_eventos_created_at ON eventos (created_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_finalizado_em ON jobs (finalizado_em);
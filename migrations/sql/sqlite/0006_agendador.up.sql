@@ -0,0 +1,17 @@
+-- Agendador: histórico das execuções das tarefas periódicas e das solicitadas pela API
+CREATE TABLE IF NOT EXISTS agendador_execucoes (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    tarefa varchar(64) NOT NULL,
+    origem varchar(16) NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pendente',
+    agendado_para datetime,
+    tentativa bigint,
+    instancia text,
+    resultado text,
+    erro text,
+    iniciado_em datetime,
+    finalizado_em datetime,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_agendador_execucoes_tarefa ON agendador_execucoes (tarefa);
+CREATE INDEX IF NOT EXISTS idx_agendador_execucoes_status ON agendador_execucoes (status);
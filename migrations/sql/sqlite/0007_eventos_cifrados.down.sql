@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_eventos_chave_id;
+ALTER TABLE eventos DROP COLUMN chave_cifrada;
+ALTER TABLE eventos DROP COLUMN chave_id;
+ALTER TABLE eventos DROP COLUMN documento_cifrado;
@@ -0,0 +1,7 @@
+-- Criptografia dos eventos: com a criptografia habilitada a coluna documento guarda o hash com
+-- chave do documento e o documento e o conteúdo do evento ficam cifrados no envelope, como nos
+-- clientes. Os eventos existentes são convertidos pelo job de recriptografia.
+ALTER TABLE eventos ADD COLUMN documento_cifrado text NOT NULL DEFAULT '';
+ALTER TABLE eventos ADD COLUMN chave_id varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE eventos ADD COLUMN chave_cifrada text NOT NULL DEFAULT '';
+CREATE INDEX idx_eventos_chave_id ON eventos (chave_id);
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ExecucaoPendente   = "pendente"
+	ExecucaoExecutando = "executando"
+	ExecucaoConcluida  = "concluida"
+	ExecucaoFalhou     = "falhou"
+
+	// OrigemAgendada indica a execução disparada pela agenda da tarefa
+	OrigemAgendada = "agendada"
+	// OrigemManual indica a execução solicitada pela API
+	OrigemManual = "manual"
+)
+
+// ExecucaoAgendada registra uma execução de uma tarefa periódica do agendador
+type ExecucaoAgendada struct {
+	ID     uint   `gorm:"primaryKey"`
+	Tarefa string `gorm:"type:varchar(64);not null;index"`
+	Origem string `gorm:"type:varchar(16);not null"`
+	Status string `gorm:"type:varchar(16);not null;index;default:pendente"`
+	// AgendadoPara é o horário da agenda que a execução atende, repetido nas novas tentativas após
+	// uma falha; vazio nas execuções manuais
+	AgendadoPara *time.Time
+	Tentativa    int
+	// Instancia é o host da instância líder que executou a tarefa
+	Instancia    string
+	Resultado    string `gorm:"type:text"`
+	Erro         string `gorm:"type:text"`
+	IniciadoEm   *time.Time
+	FinalizadoEm *time.Time
+	CreatedAt    time.Time
+}
+
+func (ExecucaoAgendada) TableName() string {
+	return "agendador_execucoes"
+}
+
+// Finalizada indica se a execução já atingiu um estado terminal
+func (e *ExecucaoAgendada) Finalizada() bool {
+	return e.Status == ExecucaoConcluida || e.Status == ExecucaoFalhou
+}
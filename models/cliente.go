@@ -4,11 +4,33 @@ import (
 	"time"
 )
 
+// Cliente é identificado pelo documento dentro do tenant; o mesmo documento pode existir em
+// tenants diferentes. Com a criptografia habilitada, a coluna documento guarda o hash com chave
+// do documento, razao_social o valor cifrado e os campos de envelope a chave de dados cifrada; o
+// repositório devolve sempre os valores decifrados.
 type Cliente struct {
 	//gorm.Model
-	Documento   string `gorm:"primaryKey;type:varchar(14)"`
+	TenantID    string `gorm:"primaryKey;type:varchar(64)" json:"-"`
+	Documento   string `gorm:"primaryKey;type:varchar(64)"`
 	RazaoSocial string `gorm:"not null"`
 	Blocklist   bool   `gorm:"default:false"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	DocumentoCifrado string `gorm:"type:text;not null;default:''" json:"-"`
+	ChaveID          string `gorm:"type:varchar(64);not null;default:'';index" json:"-"`
+	ChaveCifrada     string `gorm:"type:text;not null;default:''" json:"-"`
+}
+
+// ClienteAnonimizado é o que resta de um cliente anonimizado a pedido do titular: o hash do
+// documento, com sal, e a situação da blocklist, para que a decisão continue valendo
+type ClienteAnonimizado struct {
+	TenantID      string    `gorm:"primaryKey;type:varchar(64)"`
+	HashDocumento string    `gorm:"primaryKey;type:varchar(64)"`
+	Blocklist     bool      `gorm:"default:false"`
+	AnonimizadoEm time.Time `gorm:"not null"`
+}
+
+func (ClienteAnonimizado) TableName() string {
+	return "clientes_anonimizados"
 }
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	EventoClienteCriado     = "cliente.criado"
+	EventoClienteAtualizado = "cliente.atualizado"
+	EventoClienteRemovido   = "cliente.removido"
+	EventoBlocklistAlterado = "blocklist.alterado"
+	// EventoClienteAnonimizado e EventoRelatorioTitular auditam os atendimentos aos titulares (LGPD)
+	EventoClienteAnonimizado = "cliente.anonimizado"
+	EventoRelatorioTitular   = "titular.relatorio_gerado"
+)
+
+// TiposEvento lista os tipos de evento distribuídos aos webhooks e ao stream de eventos. O
+// EventoRelatorioTitular é somente auditoria: fica no histórico do titular e nunca é distribuído.
+var TiposEvento = []string{
+	EventoClienteCriado,
+	EventoClienteAtualizado,
+	EventoClienteRemovido,
+	EventoBlocklistAlterado,
+	EventoClienteAnonimizado,
+}
+
+// Distribuido informa se os eventos do tipo podem ser entregues aos webhooks e ao stream de eventos
+func Distribuido(tipo string) bool {
+	for _, distribuido := range TiposEvento {
+		if distribuido == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// Evento registra uma alteração de cliente. É gravado na mesma transação da alteração
+// (transactional outbox) e serve de fonte para webhooks e auditoria. Depois que o titular é
+// anonimizado, Documento guarda o hash do documento. Com a criptografia habilitada, como no
+// Cliente, Documento guarda o hash com chave, Payload o conteúdo cifrado e os campos de envelope a
+// chave de dados cifrada; o repositório devolve os valores decifrados.
+type Evento struct {
+	ID          uint   `gorm:"primaryKey"`
+	TenantID    string `gorm:"type:varchar(64);not null;index"`
+	Tipo        string `gorm:"not null;index"`
+	Documento   string `gorm:"type:varchar(64);index"`
+	Payload     string `gorm:"type:text"`
+	PublicadoEm *time.Time
+	CreatedAt   time.Time
+
+	DocumentoCifrado string `gorm:"type:text;not null;default:''" json:"-"`
+	ChaveID          string `gorm:"type:varchar(64);not null;default:'';index" json:"-"`
+	ChaveCifrada     string `gorm:"type:text;not null;default:''" json:"-"`
+}
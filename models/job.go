@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	JobPendente   = "pendente"
+	JobExecutando = "executando"
+	JobConcluido  = "concluido"
+	JobFalhou     = "falhou"
+	JobCancelado  = "cancelado"
+)
+
+// Job representa uma operação de longa duração executada em segundo plano
+type Job struct {
+	ID                     string `gorm:"primaryKey;type:varchar(32)"`
+	TenantID               string `gorm:"type:varchar(64);not null;index"`
+	Tipo                   string `gorm:"not null;index"`
+	Status                 string `gorm:"not null;index;default:pendente"`
+	Parametros             string `gorm:"type:text"`
+	Processados            int
+	Total                  int
+	Erros                  string `gorm:"type:text"`
+	Resultado              []byte
+	ResultadoContentType   string
+	CancelamentoSolicitado bool `gorm:"default:false"`
+	HeartbeatEm            *time.Time
+	IniciadoEm             *time.Time
+	FinalizadoEm           *time.Time
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+// Finalizado indica se o job já atingiu um estado terminal
+func (j *Job) Finalizado() bool {
+	return j.Status == JobConcluido || j.Status == JobFalhou || j.Status == JobCancelado
+}
@@ -0,0 +1,16 @@
+package models
+
+// Todos retorna todos os modelos persistidos pela aplicação
+func Todos() []interface{} {
+	return []interface{}{
+		&Cliente{},
+		&ClienteAnonimizado{},
+		&Job{},
+		&Evento{},
+		&WebhookAssinatura{},
+		&WebhookEntrega{},
+		&WebhookTentativa{},
+		&ExecucaoRetencao{},
+		&ExecucaoAgendada{},
+	}
+}
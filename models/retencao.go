@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Regras da política de retenção
+const (
+	// RetencaoClientesRemovidos apaga o histórico de eventos que resta dos clientes removidos
+	RetencaoClientesRemovidos = "clientes_removidos"
+	// RetencaoClientesInativos anonimiza os clientes sem alteração há mais que o prazo
+	RetencaoClientesInativos = "clientes_inativos"
+	// RetencaoEventos apaga os eventos de auditoria já entregues aos webhooks
+	RetencaoEventos = "eventos"
+	// RetencaoJobs apaga os jobs finalizados, com os seus resultados
+	RetencaoJobs = "jobs"
+)
+
+// ExecucaoRetencao registra a aplicação de uma regra da política de retenção em um tenant. As
+// regras aplicadas na mesma rodada compartilham o Execucao.
+type ExecucaoRetencao struct {
+	ID        uint   `gorm:"primaryKey"`
+	Execucao  string `gorm:"type:varchar(32);not null;index"`
+	TenantID  string `gorm:"type:varchar(64);not null;index"`
+	Regra     string `gorm:"not null"`
+	Simulacao bool   `gorm:"default:false"`
+	// Limite é o instante antes do qual os registros são alcançados pela regra
+	Limite       time.Time
+	Afetados     int64
+	Erro         string `gorm:"type:text"`
+	IniciadoEm   time.Time
+	FinalizadoEm time.Time
+}
+
+func (ExecucaoRetencao) TableName() string {
+	return "retencao_execucoes"
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	EntregaPendente = "pendente"
+	EntregaEntregue = "entregue"
+	EntregaFalhou   = "falhou"
+)
+
+// WebhookAssinatura representa um destino que recebe os eventos selecionados do seu tenant.
+// As entregas e tentativas pertencem ao tenant da assinatura.
+type WebhookAssinatura struct {
+	ID        string `gorm:"primaryKey;type:varchar(32)"`
+	TenantID  string `gorm:"type:varchar(64);not null;index"`
+	URL       string `gorm:"not null"`
+	Eventos   string `gorm:"type:text"`
+	Segredo   string `gorm:"not null"`
+	Ativo     bool   `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ListaEventos retorna os tipos de evento assinados, vazio indica todos
+func (w *WebhookAssinatura) ListaEventos() []string {
+	if w.Eventos == "" {
+		return nil
+	}
+	return strings.Split(w.Eventos, ",")
+}
+
+// Recebe indica se a assinatura deve receber o tipo de evento informado
+func (w *WebhookAssinatura) Recebe(tipo string) bool {
+	if !Distribuido(tipo) {
+		return false
+	}
+	eventos := w.ListaEventos()
+	if len(eventos) == 0 {
+		return true
+	}
+	for _, evento := range eventos {
+		if evento == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEntrega é a entrega de um evento para uma assinatura, com suas tentativas
+type WebhookEntrega struct {
+	ID                 uint   `gorm:"primaryKey"`
+	AssinaturaID       string `gorm:"type:varchar(32);not null;index"`
+	EventoID           uint   `gorm:"not null;index"`
+	Status             string `gorm:"not null;index;default:pendente"`
+	Tentativas         int
+	ProximaTentativaEm time.Time `gorm:"index"`
+	EntregueEm         *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// WebhookTentativa registra o resultado de uma tentativa de entrega
+type WebhookTentativa struct {
+	ID         uint `gorm:"primaryKey"`
+	EntregaID  uint `gorm:"not null;index"`
+	Numero     int
+	StatusHTTP int
+	Erro       string
+	DuracaoMs  int64
+	CreatedAt  time.Time
+}
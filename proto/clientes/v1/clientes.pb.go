@@ -0,0 +1,734 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: clientes/v1/clientes.proto
+
+// API gRPC dos clientes, com as mesmas regras e validações da API REST. O tenant é identificado
+// pelos metadados x-api-key, authorization (Bearer) ou x-tenant-id, como os cabeçalhos HTTP.
+
+package clientesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Cliente struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documento     string                 `protobuf:"bytes,1,opt,name=documento,proto3" json:"documento,omitempty"`
+	RazaoSocial   string                 `protobuf:"bytes,2,opt,name=razao_social,json=razaoSocial,proto3" json:"razao_social,omitempty"`
+	Blocklist     bool                   `protobuf:"varint,3,opt,name=blocklist,proto3" json:"blocklist,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cliente) Reset() {
+	*x = Cliente{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cliente) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cliente) ProtoMessage() {}
+
+func (x *Cliente) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cliente.ProtoReflect.Descriptor instead.
+func (*Cliente) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Cliente) GetDocumento() string {
+	if x != nil {
+		return x.Documento
+	}
+	return ""
+}
+
+func (x *Cliente) GetRazaoSocial() string {
+	if x != nil {
+		return x.RazaoSocial
+	}
+	return ""
+}
+
+func (x *Cliente) GetBlocklist() bool {
+	if x != nil {
+		return x.Blocklist
+	}
+	return false
+}
+
+type CriarClienteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documento     string                 `protobuf:"bytes,1,opt,name=documento,proto3" json:"documento,omitempty"`
+	RazaoSocial   string                 `protobuf:"bytes,2,opt,name=razao_social,json=razaoSocial,proto3" json:"razao_social,omitempty"`
+	Blocklist     bool                   `protobuf:"varint,3,opt,name=blocklist,proto3" json:"blocklist,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CriarClienteRequest) Reset() {
+	*x = CriarClienteRequest{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CriarClienteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CriarClienteRequest) ProtoMessage() {}
+
+func (x *CriarClienteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CriarClienteRequest.ProtoReflect.Descriptor instead.
+func (*CriarClienteRequest) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CriarClienteRequest) GetDocumento() string {
+	if x != nil {
+		return x.Documento
+	}
+	return ""
+}
+
+func (x *CriarClienteRequest) GetRazaoSocial() string {
+	if x != nil {
+		return x.RazaoSocial
+	}
+	return ""
+}
+
+func (x *CriarClienteRequest) GetBlocklist() bool {
+	if x != nil {
+		return x.Blocklist
+	}
+	return false
+}
+
+type BuscarClienteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documento     string                 `protobuf:"bytes,1,opt,name=documento,proto3" json:"documento,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuscarClienteRequest) Reset() {
+	*x = BuscarClienteRequest{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuscarClienteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuscarClienteRequest) ProtoMessage() {}
+
+func (x *BuscarClienteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuscarClienteRequest.ProtoReflect.Descriptor instead.
+func (*BuscarClienteRequest) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BuscarClienteRequest) GetDocumento() string {
+	if x != nil {
+		return x.Documento
+	}
+	return ""
+}
+
+type ListarClientesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// razao_social filtra os clientes cuja razão social contém o texto
+	RazaoSocial string `protobuf:"bytes,1,opt,name=razao_social,json=razaoSocial,proto3" json:"razao_social,omitempty"`
+	// limite é o máximo de clientes enviados; 0 envia todos
+	Limite        int32 `protobuf:"varint,2,opt,name=limite,proto3" json:"limite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListarClientesRequest) Reset() {
+	*x = ListarClientesRequest{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListarClientesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListarClientesRequest) ProtoMessage() {}
+
+func (x *ListarClientesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListarClientesRequest.ProtoReflect.Descriptor instead.
+func (*ListarClientesRequest) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListarClientesRequest) GetRazaoSocial() string {
+	if x != nil {
+		return x.RazaoSocial
+	}
+	return ""
+}
+
+func (x *ListarClientesRequest) GetLimite() int32 {
+	if x != nil {
+		return x.Limite
+	}
+	return 0
+}
+
+type AtualizarClienteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documento     string                 `protobuf:"bytes,1,opt,name=documento,proto3" json:"documento,omitempty"`
+	RazaoSocial   *string                `protobuf:"bytes,2,opt,name=razao_social,json=razaoSocial,proto3,oneof" json:"razao_social,omitempty"`
+	Blocklist     *bool                  `protobuf:"varint,3,opt,name=blocklist,proto3,oneof" json:"blocklist,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AtualizarClienteRequest) Reset() {
+	*x = AtualizarClienteRequest{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AtualizarClienteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AtualizarClienteRequest) ProtoMessage() {}
+
+func (x *AtualizarClienteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AtualizarClienteRequest.ProtoReflect.Descriptor instead.
+func (*AtualizarClienteRequest) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AtualizarClienteRequest) GetDocumento() string {
+	if x != nil {
+		return x.Documento
+	}
+	return ""
+}
+
+func (x *AtualizarClienteRequest) GetRazaoSocial() string {
+	if x != nil && x.RazaoSocial != nil {
+		return *x.RazaoSocial
+	}
+	return ""
+}
+
+func (x *AtualizarClienteRequest) GetBlocklist() bool {
+	if x != nil && x.Blocklist != nil {
+		return *x.Blocklist
+	}
+	return false
+}
+
+type RemoverClienteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documento     string                 `protobuf:"bytes,1,opt,name=documento,proto3" json:"documento,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoverClienteRequest) Reset() {
+	*x = RemoverClienteRequest{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoverClienteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoverClienteRequest) ProtoMessage() {}
+
+func (x *RemoverClienteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoverClienteRequest.ProtoReflect.Descriptor instead.
+func (*RemoverClienteRequest) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RemoverClienteRequest) GetDocumento() string {
+	if x != nil {
+		return x.Documento
+	}
+	return ""
+}
+
+type RemoverClienteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoverClienteResponse) Reset() {
+	*x = RemoverClienteResponse{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoverClienteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoverClienteResponse) ProtoMessage() {}
+
+func (x *RemoverClienteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoverClienteResponse.ProtoReflect.Descriptor instead.
+func (*RemoverClienteResponse) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{6}
+}
+
+type VerificarBlocklistRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// documentos a consultar, no máximo 1000 por chamada
+	Documentos    []string `protobuf:"bytes,1,rep,name=documentos,proto3" json:"documentos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerificarBlocklistRequest) Reset() {
+	*x = VerificarBlocklistRequest{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerificarBlocklistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificarBlocklistRequest) ProtoMessage() {}
+
+func (x *VerificarBlocklistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificarBlocklistRequest.ProtoReflect.Descriptor instead.
+func (*VerificarBlocklistRequest) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerificarBlocklistRequest) GetDocumentos() []string {
+	if x != nil {
+		return x.Documentos
+	}
+	return nil
+}
+
+type SituacaoBlocklist struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// documento como informado na requisição
+	Documento string `protobuf:"bytes,1,opt,name=documento,proto3" json:"documento,omitempty"`
+	// valido é falso para documentos que não são CPF ou CNPJ válidos, sempre fora da blocklist
+	Valido        bool `protobuf:"varint,2,opt,name=valido,proto3" json:"valido,omitempty"`
+	Blocklist     bool `protobuf:"varint,3,opt,name=blocklist,proto3" json:"blocklist,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SituacaoBlocklist) Reset() {
+	*x = SituacaoBlocklist{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SituacaoBlocklist) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SituacaoBlocklist) ProtoMessage() {}
+
+func (x *SituacaoBlocklist) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SituacaoBlocklist.ProtoReflect.Descriptor instead.
+func (*SituacaoBlocklist) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SituacaoBlocklist) GetDocumento() string {
+	if x != nil {
+		return x.Documento
+	}
+	return ""
+}
+
+func (x *SituacaoBlocklist) GetValido() bool {
+	if x != nil {
+		return x.Valido
+	}
+	return false
+}
+
+func (x *SituacaoBlocklist) GetBlocklist() bool {
+	if x != nil {
+		return x.Blocklist
+	}
+	return false
+}
+
+type VerificarBlocklistResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// resultados na ordem dos documentos da requisição
+	Resultados []*SituacaoBlocklist `protobuf:"bytes,1,rep,name=resultados,proto3" json:"resultados,omitempty"`
+	// versao do índice usado na consulta, que aumenta a cada alteração
+	Versao uint64 `protobuf:"varint,2,opt,name=versao,proto3" json:"versao,omitempty"`
+	// sincronizado_em é a última comparação completa do índice com o banco
+	SincronizadoEm *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=sincronizado_em,json=sincronizadoEm,proto3" json:"sincronizado_em,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VerificarBlocklistResponse) Reset() {
+	*x = VerificarBlocklistResponse{}
+	mi := &file_clientes_v1_clientes_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerificarBlocklistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificarBlocklistResponse) ProtoMessage() {}
+
+func (x *VerificarBlocklistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_clientes_v1_clientes_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificarBlocklistResponse.ProtoReflect.Descriptor instead.
+func (*VerificarBlocklistResponse) Descriptor() ([]byte, []int) {
+	return file_clientes_v1_clientes_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *VerificarBlocklistResponse) GetResultados() []*SituacaoBlocklist {
+	if x != nil {
+		return x.Resultados
+	}
+	return nil
+}
+
+func (x *VerificarBlocklistResponse) GetVersao() uint64 {
+	if x != nil {
+		return x.Versao
+	}
+	return 0
+}
+
+func (x *VerificarBlocklistResponse) GetSincronizadoEm() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SincronizadoEm
+	}
+	return nil
+}
+
+var File_clientes_v1_clientes_proto protoreflect.FileDescriptor
+
+var file_clientes_v1_clientes_proto_rawDesc = string([]byte{
+	0x0a, 0x1a, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x68, 0x0a, 0x07, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e,
+	0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x7a, 0x61, 0x6f, 0x5f, 0x73, 0x6f, 0x63,
+	0x69, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x61, 0x7a, 0x61, 0x6f,
+	0x53, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6c,
+	0x69, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x6c, 0x69, 0x73, 0x74, 0x22, 0x74, 0x0a, 0x13, 0x43, 0x72, 0x69, 0x61, 0x72, 0x43, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64,
+	0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x7a,
+	0x61, 0x6f, 0x5f, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x72, 0x61, 0x7a, 0x61, 0x6f, 0x53, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x12, 0x1c, 0x0a, 0x09,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x22, 0x34, 0x0a, 0x14, 0x42, 0x75,
+	0x73, 0x63, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f,
+	0x22, 0x52, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x7a,
+	0x61, 0x6f, 0x5f, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x72, 0x61, 0x7a, 0x61, 0x6f, 0x53, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x65, 0x22, 0xa1, 0x01, 0x0a, 0x17, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a,
+	0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x26,
+	0x0a, 0x0c, 0x72, 0x61, 0x7a, 0x61, 0x6f, 0x5f, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x61, 0x7a, 0x61, 0x6f, 0x53, 0x6f, 0x63,
+	0x69, 0x61, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6c,
+	0x69, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x01, 0x52, 0x09, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x72, 0x61,
+	0x7a, 0x61, 0x6f, 0x5f, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x22, 0x35, 0x0a, 0x15, 0x52, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x22,
+	0x18, 0x0a, 0x16, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3b, 0x0a, 0x19, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x72, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x6f, 0x63, 0x75,
+	0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x73, 0x22, 0x67, 0x0a, 0x11, 0x53, 0x69, 0x74, 0x75, 0x61, 0x63,
+	0x61, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64,
+	0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x22,
+	0xb9, 0x01, 0x0a, 0x1a, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x72, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e,
+	0x0a, 0x0a, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x69, 0x74, 0x75, 0x61, 0x63, 0x61, 0x6f, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69,
+	0x73, 0x74, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x64, 0x6f, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
+	0x76, 0x65, 0x72, 0x73, 0x61, 0x6f, 0x12, 0x43, 0x0a, 0x0f, 0x73, 0x69, 0x6e, 0x63, 0x72, 0x6f,
+	0x6e, 0x69, 0x7a, 0x61, 0x64, 0x6f, 0x5f, 0x65, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x73, 0x69, 0x6e,
+	0x63, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x64, 0x6f, 0x45, 0x6d, 0x32, 0x82, 0x04, 0x0a, 0x0e,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x46,
+	0x0a, 0x0c, 0x43, 0x72, 0x69, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x12, 0x20,
+	0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x69,
+	0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x14, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x12, 0x21, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x63, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65,
+	0x12, 0x4c, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x65, 0x73, 0x12, 0x22, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x30, 0x01, 0x12, 0x4e,
+	0x0a, 0x10, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x65, 0x12, 0x24, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x12, 0x59,
+	0x0a, 0x0e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65,
+	0x12, 0x22, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x65, 0x0a, 0x12, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x72, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12,
+	0x26, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x72, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x72, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x42, 0x3f, 0x5a, 0x3d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x47,
+	0x69, 0x6c, 0x65, 0x6e, 0x6f, 0x32, 0x39, 0x2f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73,
+	0x2d, 0x41, 0x50, 0x49, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x65, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x65, 0x73, 0x76,
+	0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_clientes_v1_clientes_proto_rawDescOnce sync.Once
+	file_clientes_v1_clientes_proto_rawDescData []byte
+)
+
+func file_clientes_v1_clientes_proto_rawDescGZIP() []byte {
+	file_clientes_v1_clientes_proto_rawDescOnce.Do(func() {
+		file_clientes_v1_clientes_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_clientes_v1_clientes_proto_rawDesc), len(file_clientes_v1_clientes_proto_rawDesc)))
+	})
+	return file_clientes_v1_clientes_proto_rawDescData
+}
+
+var file_clientes_v1_clientes_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_clientes_v1_clientes_proto_goTypes = []any{
+	(*Cliente)(nil),                    // 0: clientes.v1.Cliente
+	(*CriarClienteRequest)(nil),        // 1: clientes.v1.CriarClienteRequest
+	(*BuscarClienteRequest)(nil),       // 2: clientes.v1.BuscarClienteRequest
+	(*ListarClientesRequest)(nil),      // 3: clientes.v1.ListarClientesRequest
+	(*AtualizarClienteRequest)(nil),    // 4: clientes.v1.AtualizarClienteRequest
+	(*RemoverClienteRequest)(nil),      // 5: clientes.v1.RemoverClienteRequest
+	(*RemoverClienteResponse)(nil),     // 6: clientes.v1.RemoverClienteResponse
+	(*VerificarBlocklistRequest)(nil),  // 7: clientes.v1.VerificarBlocklistRequest
+	(*SituacaoBlocklist)(nil),          // 8: clientes.v1.SituacaoBlocklist
+	(*VerificarBlocklistResponse)(nil), // 9: clientes.v1.VerificarBlocklistResponse
+	(*timestamppb.Timestamp)(nil),      // 10: google.protobuf.Timestamp
+}
+var file_clientes_v1_clientes_proto_depIdxs = []int32{
+	8,  // 0: clientes.v1.VerificarBlocklistResponse.resultados:type_name -> clientes.v1.SituacaoBlocklist
+	10, // 1: clientes.v1.VerificarBlocklistResponse.sincronizado_em:type_name -> google.protobuf.Timestamp
+	1,  // 2: clientes.v1.ClienteService.CriarCliente:input_type -> clientes.v1.CriarClienteRequest
+	2,  // 3: clientes.v1.ClienteService.BuscarCliente:input_type -> clientes.v1.BuscarClienteRequest
+	3,  // 4: clientes.v1.ClienteService.ListarClientes:input_type -> clientes.v1.ListarClientesRequest
+	4,  // 5: clientes.v1.ClienteService.AtualizarCliente:input_type -> clientes.v1.AtualizarClienteRequest
+	5,  // 6: clientes.v1.ClienteService.RemoverCliente:input_type -> clientes.v1.RemoverClienteRequest
+	7,  // 7: clientes.v1.ClienteService.VerificarBlocklist:input_type -> clientes.v1.VerificarBlocklistRequest
+	0,  // 8: clientes.v1.ClienteService.CriarCliente:output_type -> clientes.v1.Cliente
+	0,  // 9: clientes.v1.ClienteService.BuscarCliente:output_type -> clientes.v1.Cliente
+	0,  // 10: clientes.v1.ClienteService.ListarClientes:output_type -> clientes.v1.Cliente
+	0,  // 11: clientes.v1.ClienteService.AtualizarCliente:output_type -> clientes.v1.Cliente
+	6,  // 12: clientes.v1.ClienteService.RemoverCliente:output_type -> clientes.v1.RemoverClienteResponse
+	9,  // 13: clientes.v1.ClienteService.VerificarBlocklist:output_type -> clientes.v1.VerificarBlocklistResponse
+	8,  // [8:14] is the sub-list for method output_type
+	2,  // [2:8] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_clientes_v1_clientes_proto_init() }
+func file_clientes_v1_clientes_proto_init() {
+	if File_clientes_v1_clientes_proto != nil {
+		return
+	}
+	file_clientes_v1_clientes_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_clientes_v1_clientes_proto_rawDesc), len(file_clientes_v1_clientes_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_clientes_v1_clientes_proto_goTypes,
+		DependencyIndexes: file_clientes_v1_clientes_proto_depIdxs,
+		MessageInfos:      file_clientes_v1_clientes_proto_msgTypes,
+	}.Build()
+	File_clientes_v1_clientes_proto = out.File
+	file_clientes_v1_clientes_proto_goTypes = nil
+	file_clientes_v1_clientes_proto_depIdxs = nil
+}
@@ -0,0 +1,332 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: clientes/v1/clientes.proto
+
+// API gRPC dos clientes, com as mesmas regras e validações da API REST. O tenant é identificado
+// pelos metadados x-api-key, authorization (Bearer) ou x-tenant-id, como os cabeçalhos HTTP.
+
+package clientesv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ClienteService_CriarCliente_FullMethodName       = "/clientes.v1.ClienteService/CriarCliente"
+	ClienteService_BuscarCliente_FullMethodName      = "/clientes.v1.ClienteService/BuscarCliente"
+	ClienteService_ListarClientes_FullMethodName     = "/clientes.v1.ClienteService/ListarClientes"
+	ClienteService_AtualizarCliente_FullMethodName   = "/clientes.v1.ClienteService/AtualizarCliente"
+	ClienteService_RemoverCliente_FullMethodName     = "/clientes.v1.ClienteService/RemoverCliente"
+	ClienteService_VerificarBlocklist_FullMethodName = "/clientes.v1.ClienteService/VerificarBlocklist"
+)
+
+// ClienteServiceClient is the client API for ClienteService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ClienteServiceClient interface {
+	// CriarCliente cadastra o cliente. Retorna INVALID_ARGUMENT para documento ou razão social
+	// inválidos e ALREADY_EXISTS para documento já cadastrado.
+	CriarCliente(ctx context.Context, in *CriarClienteRequest, opts ...grpc.CallOption) (*Cliente, error)
+	// BuscarCliente retorna o cliente pelo documento ou NOT_FOUND
+	BuscarCliente(ctx context.Context, in *BuscarClienteRequest, opts ...grpc.CallOption) (*Cliente, error)
+	// ListarClientes envia os clientes do tenant, na ordem da listagem REST, até o limite
+	ListarClientes(ctx context.Context, in *ListarClientesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Cliente], error)
+	// AtualizarCliente substitui a razão social e a situação da blocklist; os dois campos são obrigatórios
+	AtualizarCliente(ctx context.Context, in *AtualizarClienteRequest, opts ...grpc.CallOption) (*Cliente, error)
+	// RemoverCliente remove o cliente pelo documento ou retorna NOT_FOUND
+	RemoverCliente(ctx context.Context, in *RemoverClienteRequest, opts ...grpc.CallOption) (*RemoverClienteResponse, error)
+	// VerificarBlocklist consulta vários documentos no índice em memória, sem acessar o banco
+	VerificarBlocklist(ctx context.Context, in *VerificarBlocklistRequest, opts ...grpc.CallOption) (*VerificarBlocklistResponse, error)
+}
+
+type clienteServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClienteServiceClient(cc grpc.ClientConnInterface) ClienteServiceClient {
+	return &clienteServiceClient{cc}
+}
+
+func (c *clienteServiceClient) CriarCliente(ctx context.Context, in *CriarClienteRequest, opts ...grpc.CallOption) (*Cliente, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Cliente)
+	err := c.cc.Invoke(ctx, ClienteService_CriarCliente_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clienteServiceClient) BuscarCliente(ctx context.Context, in *BuscarClienteRequest, opts ...grpc.CallOption) (*Cliente, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Cliente)
+	err := c.cc.Invoke(ctx, ClienteService_BuscarCliente_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clienteServiceClient) ListarClientes(ctx context.Context, in *ListarClientesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Cliente], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ClienteService_ServiceDesc.Streams[0], ClienteService_ListarClientes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListarClientesRequest, Cliente]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClienteService_ListarClientesClient = grpc.ServerStreamingClient[Cliente]
+
+func (c *clienteServiceClient) AtualizarCliente(ctx context.Context, in *AtualizarClienteRequest, opts ...grpc.CallOption) (*Cliente, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Cliente)
+	err := c.cc.Invoke(ctx, ClienteService_AtualizarCliente_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clienteServiceClient) RemoverCliente(ctx context.Context, in *RemoverClienteRequest, opts ...grpc.CallOption) (*RemoverClienteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoverClienteResponse)
+	err := c.cc.Invoke(ctx, ClienteService_RemoverCliente_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clienteServiceClient) VerificarBlocklist(ctx context.Context, in *VerificarBlocklistRequest, opts ...grpc.CallOption) (*VerificarBlocklistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerificarBlocklistResponse)
+	err := c.cc.Invoke(ctx, ClienteService_VerificarBlocklist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClienteServiceServer is the server API for ClienteService service.
+// All implementations must embed UnimplementedClienteServiceServer
+// for forward compatibility.
+type ClienteServiceServer interface {
+	// CriarCliente cadastra o cliente. Retorna INVALID_ARGUMENT para documento ou razão social
+	// inválidos e ALREADY_EXISTS para documento já cadastrado.
+	CriarCliente(context.Context, *CriarClienteRequest) (*Cliente, error)
+	// BuscarCliente retorna o cliente pelo documento ou NOT_FOUND
+	BuscarCliente(context.Context, *BuscarClienteRequest) (*Cliente, error)
+	// ListarClientes envia os clientes do tenant, na ordem da listagem REST, até o limite
+	ListarClientes(*ListarClientesRequest, grpc.ServerStreamingServer[Cliente]) error
+	// AtualizarCliente substitui a razão social e a situação da blocklist; os dois campos são obrigatórios
+	AtualizarCliente(context.Context, *AtualizarClienteRequest) (*Cliente, error)
+	// RemoverCliente remove o cliente pelo documento ou retorna NOT_FOUND
+	RemoverCliente(context.Context, *RemoverClienteRequest) (*RemoverClienteResponse, error)
+	// VerificarBlocklist consulta vários documentos no índice em memória, sem acessar o banco
+	VerificarBlocklist(context.Context, *VerificarBlocklistRequest) (*VerificarBlocklistResponse, error)
+	mustEmbedUnimplementedClienteServiceServer()
+}
+
+// UnimplementedClienteServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClienteServiceServer struct{}
+
+func (UnimplementedClienteServiceServer) CriarCliente(context.Context, *CriarClienteRequest) (*Cliente, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CriarCliente not implemented")
+}
+func (UnimplementedClienteServiceServer) BuscarCliente(context.Context, *BuscarClienteRequest) (*Cliente, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarCliente not implemented")
+}
+func (UnimplementedClienteServiceServer) ListarClientes(*ListarClientesRequest, grpc.ServerStreamingServer[Cliente]) error {
+	return status.Errorf(codes.Unimplemented, "method ListarClientes not implemented")
+}
+func (UnimplementedClienteServiceServer) AtualizarCliente(context.Context, *AtualizarClienteRequest) (*Cliente, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AtualizarCliente not implemented")
+}
+func (UnimplementedClienteServiceServer) RemoverCliente(context.Context, *RemoverClienteRequest) (*RemoverClienteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoverCliente not implemented")
+}
+func (UnimplementedClienteServiceServer) VerificarBlocklist(context.Context, *VerificarBlocklistRequest) (*VerificarBlocklistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerificarBlocklist not implemented")
+}
+func (UnimplementedClienteServiceServer) mustEmbedUnimplementedClienteServiceServer() {}
+func (UnimplementedClienteServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeClienteServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClienteServiceServer will
+// result in compilation errors.
+type UnsafeClienteServiceServer interface {
+	mustEmbedUnimplementedClienteServiceServer()
+}
+
+func RegisterClienteServiceServer(s grpc.ServiceRegistrar, srv ClienteServiceServer) {
+	// If the following call pancis, it indicates UnimplementedClienteServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ClienteService_ServiceDesc, srv)
+}
+
+func _ClienteService_CriarCliente_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CriarClienteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClienteServiceServer).CriarCliente(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClienteService_CriarCliente_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClienteServiceServer).CriarCliente(ctx, req.(*CriarClienteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClienteService_BuscarCliente_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuscarClienteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClienteServiceServer).BuscarCliente(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClienteService_BuscarCliente_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClienteServiceServer).BuscarCliente(ctx, req.(*BuscarClienteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClienteService_ListarClientes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListarClientesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClienteServiceServer).ListarClientes(m, &grpc.GenericServerStream[ListarClientesRequest, Cliente]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClienteService_ListarClientesServer = grpc.ServerStreamingServer[Cliente]
+
+func _ClienteService_AtualizarCliente_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AtualizarClienteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClienteServiceServer).AtualizarCliente(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClienteService_AtualizarCliente_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClienteServiceServer).AtualizarCliente(ctx, req.(*AtualizarClienteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClienteService_RemoverCliente_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoverClienteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClienteServiceServer).RemoverCliente(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClienteService_RemoverCliente_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClienteServiceServer).RemoverCliente(ctx, req.(*RemoverClienteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClienteService_VerificarBlocklist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerificarBlocklistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClienteServiceServer).VerificarBlocklist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClienteService_VerificarBlocklist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClienteServiceServer).VerificarBlocklist(ctx, req.(*VerificarBlocklistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ClienteService_ServiceDesc is the grpc.ServiceDesc for ClienteService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ClienteService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clientes.v1.ClienteService",
+	HandlerType: (*ClienteServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CriarCliente",
+			Handler:    _ClienteService_CriarCliente_Handler,
+		},
+		{
+			MethodName: "BuscarCliente",
+			Handler:    _ClienteService_BuscarCliente_Handler,
+		},
+		{
+			MethodName: "AtualizarCliente",
+			Handler:    _ClienteService_AtualizarCliente_Handler,
+		},
+		{
+			MethodName: "RemoverCliente",
+			Handler:    _ClienteService_RemoverCliente_Handler,
+		},
+		{
+			MethodName: "VerificarBlocklist",
+			Handler:    _ClienteService_VerificarBlocklist_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListarClientes",
+			Handler:       _ClienteService_ListarClientes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "clientes/v1/clientes.proto",
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"github.com/Gileno29/clientes-API/models"
+)
+
+// AgendadorRepository guarda o histórico das execuções das tarefas periódicas, que não pertencem a
+// nenhum tenant
+type AgendadorRepository interface {
+	Create(execucao *models.ExecucaoAgendada) error
+	FindByID(id uint) (*models.ExecucaoAgendada, error)
+	// Reservar passa a execução pendente para executando, retornando false se outra instância já a
+	// tiver reservado
+	Reservar(execucao *models.ExecucaoAgendada, instancia string) (bool, error)
+	Finalizar(execucao *models.ExecucaoAgendada) error
+	// ListarPendentes retorna as execuções solicitadas pela API que ainda não foram iniciadas
+	ListarPendentes() ([]models.ExecucaoAgendada, error)
+	// UltimaAgendada retorna a última execução da tarefa disparada pela agenda, ou nil se não houver
+	UltimaAgendada(tarefa string) (*models.ExecucaoAgendada, error)
+	// Interromper marca como falhas as execuções em andamento de outras instâncias, iniciadas por
+	// uma líder encerrada antes de concluí-las
+	Interromper(instancia, motivo string) (int64, error)
+	// Listar retorna as execuções mais recentes primeiro; tarefa vazia lista todas
+	Listar(tarefa string, limite int) ([]models.ExecucaoAgendada, error)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+	"gorm.io/gorm"
+)
+
+type agendadorRepository struct {
+	db *gorm.DB
+}
+
+func NewAgendadorRepository(db *gorm.DB) AgendadorRepository {
+	return &agendadorRepository{db: db}
+}
+
+func (r *agendadorRepository) Create(execucao *models.ExecucaoAgendada) error {
+	return r.db.Create(execucao).Error
+}
+
+func (r *agendadorRepository) FindByID(id uint) (*models.ExecucaoAgendada, error) {
+	var execucao models.ExecucaoAgendada
+	if err := r.db.Where("id = ?", id).First(&execucao).Error; err != nil {
+		return nil, err
+	}
+	return &execucao, nil
+}
+
+func (r *agendadorRepository) Reservar(execucao *models.ExecucaoAgendada, instancia string) (bool, error) {
+	agora := time.Now()
+	res := r.db.Model(&models.ExecucaoAgendada{}).
+		Where("id = ? AND status = ?", execucao.ID, models.ExecucaoPendente).
+		Updates(map[string]interface{}{
+			"status":      models.ExecucaoExecutando,
+			"instancia":   instancia,
+			"tentativa":   1,
+			"iniciado_em": agora,
+		})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	execucao.Status = models.ExecucaoExecutando
+	execucao.Instancia = instancia
+	execucao.Tentativa = 1
+	execucao.IniciadoEm = &agora
+	return true, nil
+}
+
+func (r *agendadorRepository) Finalizar(execucao *models.ExecucaoAgendada) error {
+	agora := time.Now()
+	execucao.FinalizadoEm = &agora
+	return r.db.Model(&models.ExecucaoAgendada{}).Where("id = ?", execucao.ID).Updates(map[string]interface{}{
+		"status":        execucao.Status,
+		"resultado":     execucao.Resultado,
+		"erro":          execucao.Erro,
+		"finalizado_em": agora,
+	}).Error
+}
+
+func (r *agendadorRepository) ListarPendentes() ([]models.ExecucaoAgendada, error) {
+	var execucoes []models.ExecucaoAgendada
+	err := r.db.Where("status = ?", models.ExecucaoPendente).Order("id ASC").Find(&execucoes).Error
+	return execucoes, err
+}
+
+func (r *agendadorRepository) UltimaAgendada(tarefa string) (*models.ExecucaoAgendada, error) {
+	var execucao models.ExecucaoAgendada
+	err := r.db.Where("tarefa = ? AND origem = ?", tarefa, models.OrigemAgendada).Order("id DESC").First(&execucao).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &execucao, nil
+}
+
+func (r *agendadorRepository) Interromper(instancia, motivo string) (int64, error) {
+	res := r.db.Model(&models.ExecucaoAgendada{}).
+		Where("status = ? AND instancia <> ?", models.ExecucaoExecutando, instancia).
+		Updates(map[string]interface{}{
+			"status":        models.ExecucaoFalhou,
+			"erro":          motivo,
+			"finalizado_em": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+func (r *agendadorRepository) Listar(tarefa string, limite int) ([]models.ExecucaoAgendada, error) {
+	consulta := r.db.Order("id DESC").Limit(limite)
+	if tarefa != "" {
+		consulta = consulta.Where("tarefa = ?", tarefa)
+	}
+	var execucoes []models.ExecucaoAgendada
+	err := consulta.Find(&execucoes).Error
+	return execucoes, err
+}
@@ -1,14 +1,37 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/Gileno29/clientes-API/dtos"
 	"github.com/Gileno29/clientes-API/models"
 )
 
+// ClienteRepository acessa os clientes. Todas as operações recebem o contexto da requisição,
+// que propaga o trace e o logger e interrompe a consulta quando é cancelado ou expira.
 type ClienteRepository interface {
-	Create(cliente *models.Cliente) error
-	FindByDocumento(documento string) (*models.Cliente, error)
-	UpdateByDocumento(cliente *models.Cliente, dadosAtualizados *dtos.AtualizaClienteRequest) (*models.Cliente, error)
-	DeleteByDocumento(documento string) error
-	ListarClientes(razaoSocial string, page, limit int) ([]models.Cliente, int64, error)
+	Create(ctx context.Context, cliente *models.Cliente) error
+	FindByDocumento(ctx context.Context, documento string) (*models.Cliente, error)
+	// FindByDocumentos busca os clientes de vários documentos em uma única consulta, sem ordem
+	// definida. Os documentos não cadastrados são omitidos.
+	FindByDocumentos(ctx context.Context, documentos []string) ([]models.Cliente, error)
+	UpdateByDocumento(ctx context.Context, cliente *models.Cliente, dadosAtualizados *dtos.AtualizaClienteRequest) (*models.Cliente, error)
+	DeleteByDocumento(ctx context.Context, documento string) error
+	ListarClientes(ctx context.Context, razaoSocial string, page, limit int) ([]models.Cliente, int64, error)
+	// ListarBloqueados retorna os documentos de todos os clientes em blocklist e os hashes dos
+	// clientes anonimizados que estavam em blocklist
+	ListarBloqueados(ctx context.Context) ([]string, error)
+	// ListarInativos retorna até limite clientes sem alteração desde antesDe, os mais antigos
+	// primeiro, e o total deles, usado pela política de retenção
+	ListarInativos(ctx context.Context, antesDe time.Time, limite int) ([]models.Cliente, int64, error)
+	// Anonimizar remove o cliente guardando somente o hash do documento e a situação da blocklist.
+	// Os eventos do cliente passam a referenciar o hash e perdem o conteúdo, e os jobs finalizados
+	// que contêm o documento perdem os parâmetros e o resultado.
+	Anonimizar(ctx context.Context, documento, hashDocumento string) (*models.ClienteAnonimizado, error)
+	// FindAnonimizado busca um cliente anonimizado pelo hash do documento
+	FindAnonimizado(ctx context.Context, hashDocumento string) (*models.ClienteAnonimizado, error)
+	// Transaction executa fn dentro de uma transação, repassando um repositório vinculado a ela.
+	// Se fn retornar erro a transação é desfeita.
+	Transaction(ctx context.Context, fn func(repo ClienteRepository) error) error
 }
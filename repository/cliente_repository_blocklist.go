@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Gileno29/clientes-API/blocklist"
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+)
+
+// clienteRepositoryIndice repassa ao índice de blocklist as escritas confirmadas
+type clienteRepositoryIndice struct {
+	repo   ClienteRepository
+	indice *blocklist.Indice
+	// alteracoes acumula as alterações da transação, aplicadas somente após o commit da externa
+	alteracoes *[]alteracaoBlocklist
+}
+
+type alteracaoBlocklist struct {
+	tenant    string
+	documento string
+	bloqueado bool
+}
+
+// NewClienteRepositoryComIndice mantém o índice atualizado com os cadastros, atualizações e
+// remoções feitos por repo. Escritas que retornam erro não são aplicadas; se tiverem sido
+// confirmadas mesmo assim, a próxima sincronização do índice as recupera.
+func NewClienteRepositoryComIndice(repo ClienteRepository, indice *blocklist.Indice) ClienteRepository {
+	return &clienteRepositoryIndice{repo: repo, indice: indice}
+}
+
+func (r *clienteRepositoryIndice) Create(ctx context.Context, cliente *models.Cliente) error {
+	if err := r.repo.Create(ctx, cliente); err != nil {
+		return err
+	}
+	r.aplicar(ctx, cliente.Documento, cliente.Blocklist)
+	return nil
+}
+
+func (r *clienteRepositoryIndice) FindByDocumento(ctx context.Context, documento string) (*models.Cliente, error) {
+	return r.repo.FindByDocumento(ctx, documento)
+}
+
+func (r *clienteRepositoryIndice) FindByDocumentos(ctx context.Context, documentos []string) ([]models.Cliente, error) {
+	return r.repo.FindByDocumentos(ctx, documentos)
+}
+
+func (r *clienteRepositoryIndice) UpdateByDocumento(ctx context.Context, cliente *models.Cliente, dadosAtualizados *dtos.AtualizaClienteRequest) (*models.Cliente, error) {
+	atualizado, err := r.repo.UpdateByDocumento(ctx, cliente, dadosAtualizados)
+	if err != nil {
+		return nil, err
+	}
+	r.aplicar(ctx, atualizado.Documento, atualizado.Blocklist)
+	return atualizado, nil
+}
+
+func (r *clienteRepositoryIndice) DeleteByDocumento(ctx context.Context, documento string) error {
+	if err := r.repo.DeleteByDocumento(ctx, documento); err != nil {
+		return err
+	}
+	r.aplicar(ctx, documento, false)
+	return nil
+}
+
+func (r *clienteRepositoryIndice) ListarClientes(ctx context.Context, razaoSocial string, page, limit int) ([]models.Cliente, int64, error) {
+	return r.repo.ListarClientes(ctx, razaoSocial, page, limit)
+}
+
+func (r *clienteRepositoryIndice) ListarBloqueados(ctx context.Context) ([]string, error) {
+	return r.repo.ListarBloqueados(ctx)
+}
+
+func (r *clienteRepositoryIndice) ListarInativos(ctx context.Context, antesDe time.Time, limite int) ([]models.Cliente, int64, error) {
+	return r.repo.ListarInativos(ctx, antesDe, limite)
+}
+
+// Anonimizar troca no índice o documento pelo hash, que continua em blocklist se o cliente estava
+func (r *clienteRepositoryIndice) Anonimizar(ctx context.Context, documento, hashDocumento string) (*models.ClienteAnonimizado, error) {
+	anonimizado, err := r.repo.Anonimizar(ctx, documento, hashDocumento)
+	if err != nil {
+		return nil, err
+	}
+	r.aplicar(ctx, documento, false)
+	r.aplicar(ctx, hashDocumento, anonimizado.Blocklist)
+	return anonimizado, nil
+}
+
+func (r *clienteRepositoryIndice) FindAnonimizado(ctx context.Context, hashDocumento string) (*models.ClienteAnonimizado, error) {
+	return r.repo.FindAnonimizado(ctx, hashDocumento)
+}
+
+func (r *clienteRepositoryIndice) Transaction(ctx context.Context, fn func(repo ClienteRepository) error) error {
+	var alteracoes []alteracaoBlocklist
+	err := r.repo.Transaction(ctx, func(tx ClienteRepository) error {
+		return fn(&clienteRepositoryIndice{repo: tx, indice: r.indice, alteracoes: &alteracoes})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Em transações aninhadas as alterações sobem para a transação externa
+	for _, alteracao := range alteracoes {
+		r.registrar(alteracao)
+	}
+	return nil
+}
+
+// aplicar registra a alteração no tenant do contexto, que o repositório já exigiu para a escrita
+func (r *clienteRepositoryIndice) aplicar(ctx context.Context, documento string, bloqueado bool) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return
+	}
+	r.registrar(alteracaoBlocklist{tenant: tenantID, documento: documento, bloqueado: bloqueado})
+}
+
+// registrar altera o índice imediatamente fora de transações ou após o commit da transação atual
+func (r *clienteRepositoryIndice) registrar(alteracao alteracaoBlocklist) {
+	if r.alteracoes != nil {
+		*r.alteracoes = append(*r.alteracoes, alteracao)
+		return
+	}
+	r.indice.Definir(alteracao.tenant, alteracao.documento, alteracao.bloqueado)
+}
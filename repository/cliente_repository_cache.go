@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Gileno29/clientes-API/cache"
+	"github.com/Gileno29/clientes-API/cripto"
+	"github.com/Gileno29/clientes-API/database"
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/logging"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+	"gorm.io/gorm"
+)
+
+// clienteRepositoryCache guarda as buscas por documento em um cache, inclusive os documentos não
+// cadastrados, e as invalida nas escritas. As faltas são buscadas no primário, para que uma réplica
+// atrasada não volte a guardar o valor anterior à escrita. Uma busca concorrente com a escrita
+// ainda pode gravar o valor anterior, que fica no cache até o ttl expirar.
+type clienteRepositoryCache struct {
+	repo        ClienteRepository
+	cache       cache.Cache
+	ttl         time.Duration
+	ttlNegativo time.Duration
+	// cifrador, quando há, troca o documento das chaves pelo hash com chave e cifra as entradas
+	cifrador *cripto.Cifrador
+	// pendentes acumula as chaves alteradas na transação, invalidadas ao fim da transação externa
+	pendentes *[]string
+}
+
+// entradaCache é o valor gravado no cache; Cliente nil indica documento não cadastrado
+type entradaCache struct {
+	Cliente *models.Cliente `json:"cliente"`
+}
+
+// NewClienteRepositoryComCache envolve repo com o cache informado. ttl é a validade dos clientes
+// encontrados e ttlNegativo a dos documentos não cadastrados; ttlNegativo zero não os guarda.
+// Falhas do cache são registradas no log e a busca segue para repo. As chaves incluem o tenant do
+// contexto, de forma que um tenant nunca recebe o cliente guardado por outro.
+func NewClienteRepositoryComCache(repo ClienteRepository, c cache.Cache, ttl, ttlNegativo time.Duration) ClienteRepository {
+	return &clienteRepositoryCache{repo: repo, cache: c, ttl: ttl, ttlNegativo: ttlNegativo}
+}
+
+// NewClienteRepositoryComCacheCifrado funciona como NewClienteRepositoryComCache para o repositório
+// cifrado: as chaves usam o hash com chave do documento e cada entrada é gravada cifrada com uma
+// chave de dados própria, como no banco, de forma que o cache não guarda dados pessoais em texto puro
+func NewClienteRepositoryComCacheCifrado(repo ClienteRepository, c cache.Cache, ttl, ttlNegativo time.Duration, cifrador *cripto.Cifrador) ClienteRepository {
+	return &clienteRepositoryCache{repo: repo, cache: c, ttl: ttl, ttlNegativo: ttlNegativo, cifrador: cifrador}
+}
+
+func (r *clienteRepositoryCache) Create(ctx context.Context, cliente *models.Cliente) error {
+	err := r.repo.Create(ctx, cliente)
+	r.invalidar(ctx, cliente.Documento)
+	return err
+}
+
+func (r *clienteRepositoryCache) FindByDocumento(ctx context.Context, documento string) (*models.Cliente, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Dentro da transação o cache não enxerga as alterações ainda não confirmadas
+	if r.pendentes != nil {
+		return r.repo.FindByDocumento(ctx, documento)
+	}
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return r.repo.FindByDocumento(ctx, documento)
+	}
+	chave := r.chave(tenantID, documento)
+	if cliente, encontrado, ok := r.obter(ctx, chave); ok {
+		if !encontrado {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return cliente, nil
+	}
+
+	cliente, err := r.repo.FindByDocumento(database.NoPrimario(ctx), documento)
+	switch {
+	case err == nil:
+		r.definir(ctx, chave, entradaCache{Cliente: cliente}, r.ttl)
+	case errors.Is(err, gorm.ErrRecordNotFound) && r.ttlNegativo > 0:
+		r.definir(ctx, chave, entradaCache{}, r.ttlNegativo)
+	}
+	return cliente, err
+}
+
+// FindByDocumentos atende pelo cache os documentos guardados e busca os demais em uma única
+// consulta, guardando também os não cadastrados
+func (r *clienteRepositoryCache) FindByDocumentos(ctx context.Context, documentos []string) ([]models.Cliente, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tenantID, err := tenant.ID(ctx)
+	if r.pendentes != nil || err != nil {
+		return r.repo.FindByDocumentos(ctx, documentos)
+	}
+
+	clientes := []models.Cliente{}
+	var faltantes []string
+	vistos := make(map[string]bool, len(documentos))
+	for _, documento := range documentos {
+		if vistos[documento] {
+			continue
+		}
+		vistos[documento] = true
+		cliente, encontrado, ok := r.obter(ctx, r.chave(tenantID, documento))
+		switch {
+		case !ok:
+			faltantes = append(faltantes, documento)
+		case encontrado:
+			clientes = append(clientes, *cliente)
+		}
+	}
+	if len(faltantes) == 0 {
+		return clientes, nil
+	}
+
+	encontrados, err := r.repo.FindByDocumentos(database.NoPrimario(ctx), faltantes)
+	if err != nil {
+		return nil, err
+	}
+	cadastrados := make(map[string]bool, len(encontrados))
+	for i := range encontrados {
+		cadastrados[encontrados[i].Documento] = true
+		r.definir(ctx, r.chave(tenantID, encontrados[i].Documento), entradaCache{Cliente: &encontrados[i]}, r.ttl)
+	}
+	if r.ttlNegativo > 0 {
+		for _, documento := range faltantes {
+			if !cadastrados[documento] {
+				r.definir(ctx, r.chave(tenantID, documento), entradaCache{}, r.ttlNegativo)
+			}
+		}
+	}
+	return append(clientes, encontrados...), nil
+}
+
+func (r *clienteRepositoryCache) UpdateByDocumento(ctx context.Context, cliente *models.Cliente, dadosAtualizados *dtos.AtualizaClienteRequest) (*models.Cliente, error) {
+	atualizado, err := r.repo.UpdateByDocumento(ctx, cliente, dadosAtualizados)
+	r.invalidar(ctx, cliente.Documento)
+	return atualizado, err
+}
+
+func (r *clienteRepositoryCache) DeleteByDocumento(ctx context.Context, documento string) error {
+	err := r.repo.DeleteByDocumento(ctx, documento)
+	r.invalidar(ctx, documento)
+	return err
+}
+
+// ListarClientes não usa o cache, já que a página muda a cada cadastro
+func (r *clienteRepositoryCache) ListarClientes(ctx context.Context, razaoSocial string, page, limit int) ([]models.Cliente, int64, error) {
+	return r.repo.ListarClientes(ctx, razaoSocial, page, limit)
+}
+
+func (r *clienteRepositoryCache) ListarBloqueados(ctx context.Context) ([]string, error) {
+	return r.repo.ListarBloqueados(ctx)
+}
+
+func (r *clienteRepositoryCache) ListarInativos(ctx context.Context, antesDe time.Time, limite int) ([]models.Cliente, int64, error) {
+	return r.repo.ListarInativos(ctx, antesDe, limite)
+}
+
+func (r *clienteRepositoryCache) Anonimizar(ctx context.Context, documento, hashDocumento string) (*models.ClienteAnonimizado, error) {
+	anonimizado, err := r.repo.Anonimizar(ctx, documento, hashDocumento)
+	r.invalidar(ctx, documento)
+	return anonimizado, err
+}
+
+func (r *clienteRepositoryCache) FindAnonimizado(ctx context.Context, hashDocumento string) (*models.ClienteAnonimizado, error) {
+	return r.repo.FindAnonimizado(ctx, hashDocumento)
+}
+
+func (r *clienteRepositoryCache) Transaction(ctx context.Context, fn func(repo ClienteRepository) error) error {
+	// Em transações aninhadas os documentos sobem para a transação externa
+	if r.pendentes != nil {
+		return r.repo.Transaction(ctx, func(tx ClienteRepository) error {
+			return fn(r.naTransacao(tx, r.pendentes))
+		})
+	}
+
+	var pendentes []string
+	err := r.repo.Transaction(ctx, func(tx ClienteRepository) error {
+		return fn(r.naTransacao(tx, &pendentes))
+	})
+	r.remover(ctx, pendentes)
+	return err
+}
+
+// naTransacao cria o decorador sobre o repositório da transação, com as mesmas configurações
+func (r *clienteRepositoryCache) naTransacao(tx ClienteRepository, pendentes *[]string) *clienteRepositoryCache {
+	return &clienteRepositoryCache{repo: tx, cache: r.cache, ttl: r.ttl, ttlNegativo: r.ttlNegativo, cifrador: r.cifrador, pendentes: pendentes}
+}
+
+// obter lê a entrada do documento; ok é false quando ela não está no cache ou não pôde ser lida
+func (r *clienteRepositoryCache) obter(ctx context.Context, chave string) (cliente *models.Cliente, encontrado, ok bool) {
+	valor, existe, err := r.cache.Obter(ctx, chave)
+	if err == nil && existe {
+		valor, err = r.abrir(ctx, chave, valor)
+	}
+	if err == nil && existe {
+		var entrada entradaCache
+		if err = json.Unmarshal(valor, &entrada); err == nil {
+			if entrada.Cliente == nil {
+				metrics.ConsultaCache(metrics.CacheAcertoNegativo)
+				return nil, false, true
+			}
+			metrics.ConsultaCache(metrics.CacheAcerto)
+			return entrada.Cliente, true, true
+		}
+	}
+	if err != nil {
+		metrics.ConsultaCache(metrics.CacheErro)
+		logging.FromContext(ctx).Warn("Erro ao consultar o cache, buscando no banco", "chave", chave, "erro", err)
+		return nil, false, false
+	}
+	metrics.ConsultaCache(metrics.CacheFalta)
+	return nil, false, false
+}
+
+func (r *clienteRepositoryCache) definir(ctx context.Context, chave string, entrada entradaCache, ttl time.Duration) {
+	valor, err := json.Marshal(entrada)
+	if err == nil {
+		valor, err = r.selar(ctx, chave, valor)
+	}
+	if err == nil {
+		err = r.cache.Definir(ctx, chave, valor, ttl)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("Erro ao gravar no cache", "chave", chave, "erro", err)
+	}
+}
+
+// invalidar remove os documentos do cache ou, dentro de uma transação, os guarda para o fim dela.
+// A remoção acontece mesmo quando a escrita falha, já que ela pode ter sido confirmada antes do
+// prazo expirar.
+func (r *clienteRepositoryCache) invalidar(ctx context.Context, documento string) {
+	// Sem tenant a escrita foi recusada pelo repositório e não há o que invalidar
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return
+	}
+	chave := r.chave(tenantID, documento)
+	if r.pendentes != nil {
+		*r.pendentes = append(*r.pendentes, chave)
+		return
+	}
+	r.remover(ctx, []string{chave})
+}
+
+func (r *clienteRepositoryCache) remover(ctx context.Context, chaves []string) {
+	if len(chaves) == 0 {
+		return
+	}
+	// O contexto da requisição pode já ter expirado, mas a entrada precisa sair do cache
+	if err := r.cache.Remover(context.WithoutCancel(ctx), chaves...); err != nil {
+		logging.FromContext(ctx).Error("Erro ao invalidar o cache, valores antigos podem ser servidos até o ttl", "chaves", chaves, "erro", err)
+	}
+}
+
+// chave retorna a chave do documento no cache, com o hash com chave no lugar do documento quando
+// há cifrador
+func (r *clienteRepositoryCache) chave(tenantID, documento string) string {
+	if r.cifrador != nil {
+		documento = r.cifrador.Hash(tenantID, documento)
+	}
+	return "cliente:" + tenantID + ":" + documento
+}
+
+// selar cifra a entrada vinculada à chave dela, quando há cifrador
+func (r *clienteRepositoryCache) selar(ctx context.Context, chave string, valor []byte) ([]byte, error) {
+	if r.cifrador == nil {
+		return valor, nil
+	}
+	envelope, err := r.cifrador.Cifrar(ctx, chave, string(valor))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope)
+}
+
+// abrir decifra a entrada gravada por selar
+func (r *clienteRepositoryCache) abrir(ctx context.Context, chave string, valor []byte) ([]byte, error) {
+	if r.cifrador == nil {
+		return valor, nil
+	}
+	var envelope cripto.Envelope
+	if err := json.Unmarshal(valor, &envelope); err != nil {
+		return nil, err
+	}
+	valores, err := r.cifrador.Decifrar(ctx, chave, envelope)
+	if err != nil {
+		return nil, err
+	}
+	if len(valores) != 1 {
+		return nil, errors.New("entrada do cache inválida")
+	}
+	return []byte(valores[0]), nil
+}
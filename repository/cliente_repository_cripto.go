@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/Gileno29/clientes-API/cripto"
+	"github.com/Gileno29/clientes-API/database"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/tracing"
+	"gorm.io/gorm"
+)
+
+// LimiteBuscaCifrada é a quantidade máxima de clientes de um tenant decifrados em memória para
+// filtrar a listagem por razão social quando a criptografia está habilitada
+var LimiteBuscaCifrada = 10000
+
+var (
+	// ErrBuscaIndisponivel é retornado pela listagem filtrada por razão social quando os clientes
+	// estão cifrados e o tenant passa de LimiteBuscaCifrada
+	ErrBuscaIndisponivel = errors.New("filtro por razão social indisponível para os clientes cifrados")
+	// ErrSemCriptografia é retornado ao ler um cliente cifrado sem o cifrador configurado
+	ErrSemCriptografia = errors.New("cliente cifrado e criptografia não configurada")
+)
+
+// Recriptografavel é implementado pelos repositórios que cifram os clientes e os eventos
+type Recriptografavel interface {
+	// Recriptografar cifra com a chave mestra ativa até limite registros do tenant ainda em texto
+	// puro ou cifrados com outra chave. Retorna quantos foram convertidos e quantos restam.
+	Recriptografar(ctx context.Context, limite int) (convertidos int, restantes int64, err error)
+}
+
+// chaves retorna os valores da coluna documento que podem identificar o cliente: o hash com chave
+// e, até que a base seja convertida, o documento em texto puro
+func (r *clienteRepository) chaves(tenantID, documento string) []string {
+	return chavesDocumento(r.cifrador, tenantID, documento)
+}
+
+// armazenar retorna o cliente como deve ser gravado, cifrado quando há cifrador
+func (r *clienteRepository) armazenar(ctx context.Context, cliente *models.Cliente) (*models.Cliente, error) {
+	if r.cifrador == nil {
+		return cliente, nil
+	}
+	hash := r.cifrador.Hash(cliente.TenantID, cliente.Documento)
+	envelope, err := r.cifrador.Cifrar(ctx, hash, cliente.Documento, cliente.RazaoSocial)
+	if err != nil {
+		return nil, err
+	}
+	armazenado := *cliente
+	armazenado.Documento = hash
+	armazenado.DocumentoCifrado = envelope.Valores[0]
+	armazenado.RazaoSocial = envelope.Valores[1]
+	armazenado.ChaveID = envelope.ChaveID
+	armazenado.ChaveCifrada = envelope.ChaveCifrada
+	return &armazenado, nil
+}
+
+// abrir decifra o cliente lido do banco. Os gravados em texto puro são mantidos como estão.
+func (r *clienteRepository) abrir(ctx context.Context, cliente *models.Cliente) error {
+	if cliente.ChaveID == "" {
+		return nil
+	}
+	if r.cifrador == nil {
+		return ErrSemCriptografia
+	}
+	envelope := cripto.Envelope{
+		ChaveID:      cliente.ChaveID,
+		ChaveCifrada: cliente.ChaveCifrada,
+		Valores:      []string{cliente.DocumentoCifrado, cliente.RazaoSocial},
+	}
+	valores, err := r.cifrador.Decifrar(ctx, cliente.Documento, envelope)
+	if err != nil {
+		return err
+	}
+	cliente.Documento, cliente.RazaoSocial = valores[0], valores[1]
+	cliente.DocumentoCifrado, cliente.ChaveID, cliente.ChaveCifrada = "", "", ""
+	return nil
+}
+
+// listarCifrados pagina no banco a listagem sem filtro, na ordem do hash do documento, já que a
+// razão social cifrada não pode ser ordenada pelo banco. O filtro por razão social é aplicado em
+// memória, decifrando a cada página todos os clientes do tenant, e retorna ErrBuscaIndisponivel
+// acima de LimiteBuscaCifrada.
+func (r *clienteRepository) listarCifrados(ctx context.Context, db *gorm.DB, tenantID, razaoSocial string, page, limit int) ([]models.Cliente, int64, error) {
+	var clientes []models.Cliente
+	var total int64
+
+	err := r.ler(db, func(db *gorm.DB) error {
+		clientes, total = nil, 0
+		query := db.Model(&models.Cliente{}).Where("tenant_id = ?", tenantID)
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+		if razaoSocial == "" {
+			return query.Order("documento ASC").Offset((page - 1) * limit).Limit(limit).Find(&clientes).Error
+		}
+		if total > int64(LimiteBuscaCifrada) {
+			return ErrBuscaIndisponivel
+		}
+		return query.Find(&clientes).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range clientes {
+		if err := r.abrir(ctx, &clientes[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	if razaoSocial == "" {
+		return clientes, total, nil
+	}
+
+	filtro := strings.ToLower(razaoSocial)
+	encontrados := []models.Cliente{}
+	for _, cliente := range clientes {
+		if strings.Contains(strings.ToLower(cliente.RazaoSocial), filtro) {
+			encontrados = append(encontrados, cliente)
+		}
+	}
+	sort.Slice(encontrados, func(i, j int) bool {
+		if encontrados[i].RazaoSocial != encontrados[j].RazaoSocial {
+			return encontrados[i].RazaoSocial < encontrados[j].RazaoSocial
+		}
+		return encontrados[i].Documento < encontrados[j].Documento
+	})
+	inicio := min(max((page-1)*limit, 0), len(encontrados))
+	fim := min(inicio+max(limit, 0), len(encontrados))
+	return encontrados[inicio:fim], int64(len(encontrados)), nil
+}
+
+// Recriptografar não gera eventos nem altera a data de atualização, já que os dados do cliente
+// continuam os mesmos
+func (r *clienteRepository) Recriptografar(ctx context.Context, limite int) (_ int, _ int64, err error) {
+	db, span := r.iniciarSpan(ctx, "Recriptografar")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if r.cifrador == nil {
+		return 0, 0, ErrSemCriptografia
+	}
+	ativa := r.cifrador.ChaveAtiva()
+
+	convertidos := 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var clientes []models.Cliente
+		err := tx.Where("tenant_id = ? AND chave_id <> ?", tenantID, ativa).Order("documento ASC").Limit(limite).Find(&clientes).Error
+		if err != nil {
+			return err
+		}
+		for i := range clientes {
+			anterior := clientes[i].Documento
+			if err := r.abrir(ctx, &clientes[i]); err != nil {
+				return err
+			}
+			armazenado, err := r.armazenar(ctx, &clientes[i])
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("tenant_id = ? AND documento = ?", tenantID, anterior).Delete(&models.Cliente{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(armazenado).Error; err != nil {
+				return err
+			}
+			convertidos++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var restantes int64
+	err = db.Model(&models.Cliente{}).Where("tenant_id = ? AND chave_id <> ?", tenantID, ativa).Count(&restantes).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	database.RegistrarEscrita(ctx)
+	return convertidos, restantes, nil
+}
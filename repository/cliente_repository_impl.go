@@ -2,73 +2,450 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Gileno29/clientes-API/cripto"
+	"github.com/Gileno29/clientes-API/database"
 	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/metrics"
 	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/Gileno29/clientes-API/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
 type clienteRepository struct {
 	db *gorm.DB
+	// roteador envia as leituras para as réplicas; nil usa db, como nas transações
+	roteador *database.Roteador
+	// aposCommit acumula as ações que só devem acontecer quando a transação externa for confirmada
+	aposCommit *[]func()
+	// cifrador cifra os dados pessoais antes da gravação; nil grava em texto puro
+	cifrador *cripto.Cifrador
 }
 
+// NewClienteRepository cria o repositório sobre db. Todas as operações ficam restritas ao tenant do
+// contexto e falham com tenant.ErrSemTenant quando ele não é informado.
 func NewClienteRepository(db *gorm.DB) ClienteRepository {
 	return &clienteRepository{db: db}
 }
 
-func (r *clienteRepository) Create(cliente *models.Cliente) error {
-	return r.db.Create(cliente).Error
+// NewClienteRepositoryComReplicas escreve no primário do roteador e faz as buscas e listagens nas
+// réplicas, exceto depois que a requisição escreveu (veja database.ComLeituraAposEscrita)
+func NewClienteRepositoryComReplicas(roteador *database.Roteador) ClienteRepository {
+	return &clienteRepository{db: roteador.Primario(), roteador: roteador}
+}
+
+// NewClienteRepositoryCifrado funciona como NewClienteRepositoryComReplicas, gravando o documento
+// e a razão social cifrados. Os clientes gravados em texto puro continuam sendo lidos e são
+// cifrados na próxima gravação ou pelo job de recriptografia.
+func NewClienteRepositoryCifrado(roteador *database.Roteador, cifrador *cripto.Cifrador) ClienteRepository {
+	return &clienteRepository{db: roteador.Primario(), roteador: roteador, cifrador: cifrador}
+}
+
+func (r *clienteRepository) Create(ctx context.Context, cliente *models.Cliente) (err error) {
+	db, span := r.iniciarSpan(ctx, "Create")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return err
+	}
+	cliente.TenantID = tenantID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if r.cifrador != nil {
+			// A chave primária não impede o cadastro de um documento ainda gravado em texto puro
+			var existentes int64
+			if err := tx.Model(&models.Cliente{}).Where("tenant_id = ? AND documento = ?", tenantID, cliente.Documento).Count(&existentes).Error; err != nil {
+				return err
+			}
+			if existentes > 0 {
+				return gorm.ErrDuplicatedKey
+			}
+		}
+		armazenado, err := r.armazenar(tx.Statement.Context, cliente)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(armazenado).Error; err != nil {
+			return err
+		}
+		cliente.CreatedAt, cliente.UpdatedAt = armazenado.CreatedAt, armazenado.UpdatedAt
+		return registrarEvento(tx, r.cifrador, tenantID, models.EventoClienteCriado, cliente.Documento, payloadCliente(cliente))
+	})
+	if err != nil {
+		return err
+	}
+
+	database.RegistrarEscrita(ctx)
+	r.confirmado(metrics.ClienteCriado)
+	return nil
 }
 
-func (r *clienteRepository) FindByDocumento(documento string) (*models.Cliente, error) {
+func (r *clienteRepository) FindByDocumento(ctx context.Context, documento string) (_ *models.Cliente, err error) {
+	db, span := r.iniciarSpan(ctx, "FindByDocumento")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var cliente models.Cliente
-	err := r.db.Where("documento = ?", documento).First(&cliente).Error
+	err = r.ler(db, func(db *gorm.DB) error {
+		return db.Where("tenant_id = ? AND documento IN ?", tenantID, r.chaves(tenantID, documento)).First(&cliente).Error
+	})
 	if err != nil {
 		return nil, err
 	}
+	if err := r.abrir(ctx, &cliente); err != nil {
+		return nil, err
+	}
 	return &cliente, nil
 }
 
-func (r *clienteRepository) UpdateByDocumento(cliente *models.Cliente, dadosAtualizados *dtos.AtualizaClienteRequest) (*models.Cliente, error) {
-	// Busca o cliente pelo documento
+func (r *clienteRepository) FindByDocumentos(ctx context.Context, documentos []string) (_ []models.Cliente, err error) {
+	db, span := r.iniciarSpan(ctx, "FindByDocumentos")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(documentos) == 0 {
+		return []models.Cliente{}, db.Statement.Context.Err()
+	}
+
+	chaves := make([]string, 0, 2*len(documentos))
+	for _, documento := range documentos {
+		chaves = append(chaves, r.chaves(tenantID, documento)...)
+	}
+	var clientes []models.Cliente
+	err = r.ler(db, func(db *gorm.DB) error {
+		return db.Where("tenant_id = ? AND documento IN ?", tenantID, chaves).Find(&clientes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range clientes {
+		if err := r.abrir(ctx, &clientes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return clientes, nil
+}
 
-	// Atualiza os campos do cliente
-	if dadosAtualizados.RazaoSocial != nil && *dadosAtualizados.RazaoSocial != "" && *dadosAtualizados.RazaoSocial != " " {
+func (r *clienteRepository) UpdateByDocumento(ctx context.Context, cliente *models.Cliente, dadosAtualizados *dtos.AtualizaClienteRequest) (_ *models.Cliente, err error) {
+	db, span := r.iniciarSpan(ctx, "UpdateByDocumento")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// O cliente deve ter sido lido no mesmo tenant; o Save não pode alcançar a linha de outro
+	if cliente.TenantID != "" && cliente.TenantID != tenantID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cliente.TenantID = tenantID
+
+	blocklistAnterior := cliente.Blocklist
+
+	// Atualiza os campos informados, a validação dos valores é feita pelo handler
+	if dadosAtualizados.RazaoSocial != nil {
 		cliente.RazaoSocial = *dadosAtualizados.RazaoSocial
 	}
 	if dadosAtualizados.Blocklist != nil {
 		cliente.Blocklist = *dadosAtualizados.Blocklist
 	}
 
-	if err := r.db.Save(cliente).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		armazenado, err := r.armazenar(tx.Statement.Context, cliente)
+		if err != nil {
+			return err
+		}
+		// O cliente ainda em texto puro é substituído pelo cifrado, que tem outra chave primária
+		if r.cifrador != nil {
+			if err := tx.Where("tenant_id = ? AND documento = ?", tenantID, cliente.Documento).Delete(&models.Cliente{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(armazenado).Error; err != nil {
+			return err
+		}
+		cliente.CreatedAt, cliente.UpdatedAt = armazenado.CreatedAt, armazenado.UpdatedAt
+		if err := registrarEvento(tx, r.cifrador, tenantID, models.EventoClienteAtualizado, cliente.Documento, payloadCliente(cliente)); err != nil {
+			return err
+		}
+		if cliente.Blocklist != blocklistAnterior {
+			payload := dtos.BlocklistAlteradoPayload{
+				Documento:         cliente.Documento,
+				Blocklist:         cliente.Blocklist,
+				BlocklistAnterior: blocklistAnterior,
+			}
+			return registrarEvento(tx, r.cifrador, tenantID, models.EventoBlocklistAlterado, cliente.Documento, payload)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	database.RegistrarEscrita(ctx)
+	if cliente.Blocklist != blocklistAnterior {
+		blocklist := cliente.Blocklist
+		r.confirmado(func() { metrics.BlocklistAlterado(blocklist) })
+	}
 	return cliente, nil
 }
 
 // DeleteByDocumento remove um cliente pelo documento
-func (r *clienteRepository) DeleteByDocumento(documento string) error {
-	return r.db.Where("documento = ?", documento).Delete(&models.Cliente{}).Error
+func (r *clienteRepository) DeleteByDocumento(ctx context.Context, documento string) (err error) {
+	db, span := r.iniciarSpan(ctx, "DeleteByDocumento")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var cliente models.Cliente
+		err := tx.Where("tenant_id = ? AND documento IN ?", tenantID, r.chaves(tenantID, documento)).First(&cliente).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.abrir(ctx, &cliente); err != nil {
+			return err
+		}
+
+		if err := tx.Where("tenant_id = ? AND documento IN ?", tenantID, r.chaves(tenantID, documento)).Delete(&models.Cliente{}).Error; err != nil {
+			return err
+		}
+		return registrarEvento(tx, r.cifrador, tenantID, models.EventoClienteRemovido, documento, payloadCliente(&cliente))
+	})
+	if err != nil {
+		return err
+	}
+
+	database.RegistrarEscrita(ctx)
+	return nil
 }
 
-func (r *clienteRepository) ListarClientes(razaoSocial string, page, limit int) ([]models.Cliente, int64, error) {
+func (r *clienteRepository) ListarClientes(ctx context.Context, razaoSocial string, page, limit int) (_ []models.Cliente, _ int64, err error) {
+	db, span := r.iniciarSpan(ctx, "ListarClientes")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if r.cifrador != nil {
+		return r.listarCifrados(ctx, db, tenantID, razaoSocial, page, limit)
+	}
+
 	var clientes []models.Cliente
 	var total int64
 
-	query := r.db.Model(&models.Cliente{})
+	err = r.ler(db, func(db *gorm.DB) error {
+		clientes, total = nil, 0
+		query := db.Model(&models.Cliente{}).Where("tenant_id = ?", tenantID)
 
-	if razaoSocial != "" {
-		query = query.Where("LOWER(razao_social) LIKE LOWER(?)", "%"+razaoSocial+"%")
-	}
+		if razaoSocial != "" {
+			query = query.Where("LOWER(razao_social) LIKE LOWER(?)", "%"+razaoSocial+"%")
+		}
 
-	if err := query.Count(&total).Error; err != nil {
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+
+		offset := (page - 1) * limit
+		return query.Order("razao_social ASC").Offset(offset).Limit(limit).Find(&clientes).Error
+	})
+	if err != nil {
 		return nil, 0, err
 	}
+	for i := range clientes {
+		if err := r.abrir(ctx, &clientes[i]); err != nil {
+			return nil, 0, err
+		}
+	}
 
-	offset := (page - 1) * limit
-	if err := query.Order("razao_social ASC").Offset(offset).Limit(limit).Find(&clientes).Error; err != nil {
+	return clientes, total, nil
+}
+
+// ListarInativos consulta sempre o primário, já que os clientes listados são anonimizados em seguida
+func (r *clienteRepository) ListarInativos(ctx context.Context, antesDe time.Time, limite int) (_ []models.Cliente, _ int64, err error) {
+	db, span := r.iniciarSpan(ctx, "ListarInativos")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
 		return nil, 0, err
 	}
 
+	var clientes []models.Cliente
+	var total int64
+	query := db.Model(&models.Cliente{}).Where("tenant_id = ? AND updated_at < ?", tenantID, antesDe)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("updated_at ASC").Limit(limite).Find(&clientes).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range clientes {
+		if err := r.abrir(ctx, &clientes[i]); err != nil {
+			return nil, 0, err
+		}
+	}
 	return clientes, total, nil
 }
+
+// ListarBloqueados consulta sempre o primário, já que é usado para sincronizar o índice de blocklist
+func (r *clienteRepository) ListarBloqueados(ctx context.Context) (_ []string, err error) {
+	db, span := r.iniciarSpan(ctx, "ListarBloqueados")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bloqueados []models.Cliente
+	err = db.Where("tenant_id = ? AND blocklist = ?", tenantID, true).Find(&bloqueados).Error
+	if err != nil {
+		return nil, err
+	}
+	documentos := make([]string, 0, len(bloqueados))
+	for i := range bloqueados {
+		if err := r.abrir(ctx, &bloqueados[i]); err != nil {
+			return nil, err
+		}
+		documentos = append(documentos, bloqueados[i].Documento)
+	}
+	var hashes []string
+	err = db.Model(&models.ClienteAnonimizado{}).Where("tenant_id = ? AND blocklist = ?", tenantID, true).Pluck("hash_documento", &hashes).Error
+	if err != nil {
+		return nil, err
+	}
+	return append(documentos, hashes...), nil
+}
+
+func (r *clienteRepository) Anonimizar(ctx context.Context, documento, hashDocumento string) (_ *models.ClienteAnonimizado, err error) {
+	db, span := r.iniciarSpan(ctx, "Anonimizar")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var anonimizado *models.ClienteAnonimizado
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var cliente models.Cliente
+		if err := tx.Where("tenant_id = ? AND documento IN ?", tenantID, r.chaves(tenantID, documento)).First(&cliente).Error; err != nil {
+			return err
+		}
+		if err := r.abrir(ctx, &cliente); err != nil {
+			return err
+		}
+
+		anonimizado = &models.ClienteAnonimizado{
+			TenantID:      tenantID,
+			HashDocumento: hashDocumento,
+			Blocklist:     cliente.Blocklist,
+			AnonimizadoEm: time.Now(),
+		}
+		// O mesmo titular pode ter sido anonimizado antes e cadastrado de novo
+		if err := tx.Save(anonimizado).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ? AND documento IN ?", tenantID, r.chaves(tenantID, documento)).Delete(&models.Cliente{}).Error; err != nil {
+			return err
+		}
+		if err := anonimizarEventos(tx, tenantID, r.chaves(tenantID, documento), hashDocumento); err != nil {
+			return err
+		}
+		if err := expurgarJobsDoTitular(tx, tenantID, documento); err != nil {
+			return err
+		}
+		payload := dtos.ClienteAnonimizadoPayload{HashDocumento: hashDocumento, Blocklist: cliente.Blocklist}
+		return registrarEvento(tx, r.cifrador, tenantID, models.EventoClienteAnonimizado, hashDocumento, payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	database.RegistrarEscrita(ctx)
+	return anonimizado, nil
+}
+
+// FindAnonimizado consulta sempre o primário, para que a anonimização recém-feita seja encontrada
+func (r *clienteRepository) FindAnonimizado(ctx context.Context, hashDocumento string) (_ *models.ClienteAnonimizado, err error) {
+	db, span := r.iniciarSpan(ctx, "FindAnonimizado")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var anonimizado models.ClienteAnonimizado
+	if err := db.Where("tenant_id = ? AND hash_documento = ?", tenantID, hashDocumento).First(&anonimizado).Error; err != nil {
+		return nil, err
+	}
+	return &anonimizado, nil
+}
+
+func (r *clienteRepository) Transaction(ctx context.Context, fn func(repo ClienteRepository) error) (err error) {
+	db, span := r.iniciarSpan(ctx, "Transaction")
+	defer func() { tracing.FinalizarSpan(span, err) }()
+
+	var pendentes []func()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return fn(&clienteRepository{db: tx, aposCommit: &pendentes, cifrador: r.cifrador})
+	})
+	if err != nil {
+		return err
+	}
+
+	// As leituras seguintes da requisição vão para o primário, que já tem as alterações da transação
+	database.RegistrarEscrita(ctx)
+
+	// Em transações aninhadas as ações sobem para a transação externa
+	for _, acao := range pendentes {
+		r.confirmado(acao)
+	}
+	return nil
+}
+
+// iniciarSpan abre o span do método e retorna a conexão vinculada ao contexto dele
+func (r *clienteRepository) iniciarSpan(ctx context.Context, metodo string) (*gorm.DB, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, "ClienteRepository."+metodo)
+	return r.db.WithContext(ctx), span
+}
+
+// ler executa a consulta somente leitura em uma réplica, quando houver, com o contexto de db
+func (r *clienteRepository) ler(db *gorm.DB, fn func(db *gorm.DB) error) error {
+	if r.roteador == nil {
+		return fn(db)
+	}
+	return r.roteador.Ler(db.Statement.Context, fn)
+}
+
+// confirmado executa a ação imediatamente fora de transações ou após o commit da transação atual
+func (r *clienteRepository) confirmado(acao func()) {
+	if r.aposCommit != nil {
+		*r.aposCommit = append(*r.aposCommit, acao)
+		return
+	}
+	acao()
+}
@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+	"gorm.io/gorm"
+)
+
+// clienteRepositoryMemoria guarda os clientes em um mapa, sem persistência. Os eventos das
+// alterações são gravados no outbox do banco informado, quando há um.
+type clienteRepositoryMemoria struct {
+	// mu é compartilhado pelas transações, que o mantêm até o commit ou rollback
+	mu       *sync.RWMutex
+	clientes map[chaveMemoria]models.Cliente
+	// anonimizados usa o hash do documento na chave
+	anonimizados map[chaveMemoria]models.ClienteAnonimizado
+	// emTransacao indica que mu já pertence à transação em andamento
+	emTransacao bool
+	aposCommit  *[]func()
+	// db guarda o outbox de eventos; nil não registra eventos
+	db *gorm.DB
+	// eventosPendentes acumula os eventos da transação, gravados juntos no commit
+	eventosPendentes *[]func(tx *gorm.DB) error
+}
+
+// chaveMemoria reproduz a chave primária composta da tabela clientes
+type chaveMemoria struct {
+	tenant    string
+	documento string
+}
+
+// NewClienteRepositoryMemoria cria um repositório vazio, seguro para uso concorrente. As
+// transações são serializadas e isoladas por cópia: fn altera uma cópia dos dados, que só
+// substitui o original se fn terminar sem erro.
+func NewClienteRepositoryMemoria() ClienteRepository {
+	return &clienteRepositoryMemoria{
+		mu:           &sync.RWMutex{},
+		clientes:     map[chaveMemoria]models.Cliente{},
+		anonimizados: map[chaveMemoria]models.ClienteAnonimizado{},
+	}
+}
+
+// NewClienteRepositoryMemoriaComEventos funciona como NewClienteRepositoryMemoria, gravando os
+// eventos das alterações no outbox de db para os webhooks e o stream de eventos. Cada alteração só
+// é aplicada à memória depois que os eventos dela são gravados.
+func NewClienteRepositoryMemoriaComEventos(db *gorm.DB) ClienteRepository {
+	repo := NewClienteRepositoryMemoria().(*clienteRepositoryMemoria)
+	repo.db = db
+	return repo
+}
+
+func (r *clienteRepositoryMemoria) Create(ctx context.Context, cliente *models.Cliente) error {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return err
+	}
+	r.travar()
+	defer r.destravar()
+
+	chave := chaveMemoria{tenantID, cliente.Documento}
+	if _, ok := r.clientes[chave]; ok {
+		return gorm.ErrDuplicatedKey
+	}
+	agora := time.Now()
+	if cliente.CreatedAt.IsZero() {
+		cliente.CreatedAt = agora
+	}
+	if cliente.UpdatedAt.IsZero() {
+		cliente.UpdatedAt = agora
+	}
+	cliente.TenantID = tenantID
+	payload := payloadCliente(cliente)
+	err = r.registrar(ctx, func(tx *gorm.DB) error {
+		return registrarEvento(tx, nil, tenantID, models.EventoClienteCriado, cliente.Documento, payload)
+	})
+	if err != nil {
+		return err
+	}
+	r.clientes[chave] = *cliente
+
+	r.confirmado(metrics.ClienteCriado)
+	return nil
+}
+
+func (r *clienteRepositoryMemoria) FindByDocumento(ctx context.Context, documento string) (*models.Cliente, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.travarLeitura()
+	defer r.destravarLeitura()
+
+	cliente, ok := r.clientes[chaveMemoria{tenantID, documento}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &cliente, nil
+}
+
+func (r *clienteRepositoryMemoria) FindByDocumentos(ctx context.Context, documentos []string) ([]models.Cliente, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.travarLeitura()
+	defer r.destravarLeitura()
+
+	clientes := []models.Cliente{}
+	vistos := make(map[string]bool, len(documentos))
+	for _, documento := range documentos {
+		if vistos[documento] {
+			continue
+		}
+		vistos[documento] = true
+		if cliente, ok := r.clientes[chaveMemoria{tenantID, documento}]; ok {
+			clientes = append(clientes, cliente)
+		}
+	}
+	return clientes, nil
+}
+
+func (r *clienteRepositoryMemoria) UpdateByDocumento(ctx context.Context, cliente *models.Cliente, dadosAtualizados *dtos.AtualizaClienteRequest) (*models.Cliente, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cliente.TenantID != "" && cliente.TenantID != tenantID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	r.travar()
+	defer r.destravar()
+
+	blocklistAnterior := cliente.Blocklist
+
+	// Atualiza os campos informados, a validação dos valores é feita pelo handler
+	if dadosAtualizados.RazaoSocial != nil {
+		cliente.RazaoSocial = *dadosAtualizados.RazaoSocial
+	}
+	if dadosAtualizados.Blocklist != nil {
+		cliente.Blocklist = *dadosAtualizados.Blocklist
+	}
+	if cliente.CreatedAt.IsZero() {
+		cliente.CreatedAt = time.Now()
+	}
+	cliente.UpdatedAt = time.Now()
+	cliente.TenantID = tenantID
+	payload := payloadCliente(cliente)
+	err = r.registrar(ctx, func(tx *gorm.DB) error {
+		if err := registrarEvento(tx, nil, tenantID, models.EventoClienteAtualizado, cliente.Documento, payload); err != nil {
+			return err
+		}
+		if payload.Blocklist != blocklistAnterior {
+			alteracao := dtos.BlocklistAlteradoPayload{
+				Documento:         payload.Documento,
+				Blocklist:         payload.Blocklist,
+				BlocklistAnterior: blocklistAnterior,
+			}
+			return registrarEvento(tx, nil, tenantID, models.EventoBlocklistAlterado, payload.Documento, alteracao)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.clientes[chaveMemoria{tenantID, cliente.Documento}] = *cliente
+
+	if cliente.Blocklist != blocklistAnterior {
+		blocklist := cliente.Blocklist
+		r.confirmado(func() { metrics.BlocklistAlterado(blocklist) })
+	}
+	return cliente, nil
+}
+
+func (r *clienteRepositoryMemoria) DeleteByDocumento(ctx context.Context, documento string) error {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return err
+	}
+	r.travar()
+	defer r.destravar()
+
+	chave := chaveMemoria{tenantID, documento}
+	cliente, ok := r.clientes[chave]
+	if !ok {
+		return nil
+	}
+	payload := payloadCliente(&cliente)
+	err = r.registrar(ctx, func(tx *gorm.DB) error {
+		return registrarEvento(tx, nil, tenantID, models.EventoClienteRemovido, documento, payload)
+	})
+	if err != nil {
+		return err
+	}
+	delete(r.clientes, chave)
+	return nil
+}
+
+func (r *clienteRepositoryMemoria) ListarClientes(ctx context.Context, razaoSocial string, page, limit int) ([]models.Cliente, int64, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	r.travarLeitura()
+	defer r.destravarLeitura()
+
+	filtro := strings.ToLower(razaoSocial)
+	clientes := []models.Cliente{}
+	for chave, cliente := range r.clientes {
+		if chave.tenant == tenantID && strings.Contains(strings.ToLower(cliente.RazaoSocial), filtro) {
+			clientes = append(clientes, cliente)
+		}
+	}
+	sort.Slice(clientes, func(i, j int) bool {
+		if clientes[i].RazaoSocial != clientes[j].RazaoSocial {
+			return clientes[i].RazaoSocial < clientes[j].RazaoSocial
+		}
+		return clientes[i].Documento < clientes[j].Documento
+	})
+
+	total := int64(len(clientes))
+	inicio := min(max((page-1)*limit, 0), len(clientes))
+	fim := min(inicio+max(limit, 0), len(clientes))
+	return clientes[inicio:fim], total, nil
+}
+
+func (r *clienteRepositoryMemoria) ListarBloqueados(ctx context.Context) ([]string, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.travarLeitura()
+	defer r.destravarLeitura()
+
+	documentos := []string{}
+	for chave, cliente := range r.clientes {
+		if chave.tenant == tenantID && cliente.Blocklist {
+			documentos = append(documentos, chave.documento)
+		}
+	}
+	for chave, anonimizado := range r.anonimizados {
+		if chave.tenant == tenantID && anonimizado.Blocklist {
+			documentos = append(documentos, chave.documento)
+		}
+	}
+	return documentos, nil
+}
+
+func (r *clienteRepositoryMemoria) ListarInativos(ctx context.Context, antesDe time.Time, limite int) ([]models.Cliente, int64, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	r.travarLeitura()
+	defer r.destravarLeitura()
+
+	clientes := []models.Cliente{}
+	for chave, cliente := range r.clientes {
+		if chave.tenant == tenantID && cliente.UpdatedAt.Before(antesDe) {
+			clientes = append(clientes, cliente)
+		}
+	}
+	sort.Slice(clientes, func(i, j int) bool {
+		return clientes[i].UpdatedAt.Before(clientes[j].UpdatedAt)
+	})
+
+	total := int64(len(clientes))
+	return clientes[:min(max(limite, 0), len(clientes))], total, nil
+}
+
+func (r *clienteRepositoryMemoria) Anonimizar(ctx context.Context, documento, hashDocumento string) (*models.ClienteAnonimizado, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.travar()
+	defer r.destravar()
+
+	chave := chaveMemoria{tenantID, documento}
+	cliente, ok := r.clientes[chave]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	anonimizado := models.ClienteAnonimizado{
+		TenantID:      tenantID,
+		HashDocumento: hashDocumento,
+		Blocklist:     cliente.Blocklist,
+		AnonimizadoEm: time.Now(),
+	}
+	err = r.registrar(ctx, func(tx *gorm.DB) error {
+		if err := anonimizarEventos(tx, tenantID, []string{documento}, hashDocumento); err != nil {
+			return err
+		}
+		if err := expurgarJobsDoTitular(tx, tenantID, documento); err != nil {
+			return err
+		}
+		payload := dtos.ClienteAnonimizadoPayload{HashDocumento: hashDocumento, Blocklist: anonimizado.Blocklist}
+		return registrarEvento(tx, nil, tenantID, models.EventoClienteAnonimizado, hashDocumento, payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.anonimizados[chaveMemoria{tenantID, hashDocumento}] = anonimizado
+	delete(r.clientes, chave)
+	return &anonimizado, nil
+}
+
+func (r *clienteRepositoryMemoria) FindAnonimizado(ctx context.Context, hashDocumento string) (*models.ClienteAnonimizado, error) {
+	tenantID, err := tenantDaOperacao(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.travarLeitura()
+	defer r.destravarLeitura()
+
+	anonimizado, ok := r.anonimizados[chaveMemoria{tenantID, hashDocumento}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &anonimizado, nil
+}
+
+func (r *clienteRepositoryMemoria) Transaction(ctx context.Context, fn func(repo ClienteRepository) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.travar()
+	defer r.destravar()
+
+	copia := make(map[chaveMemoria]models.Cliente, len(r.clientes))
+	for chave, cliente := range r.clientes {
+		copia[chave] = cliente
+	}
+	anonimizados := make(map[chaveMemoria]models.ClienteAnonimizado, len(r.anonimizados))
+	for chave, anonimizado := range r.anonimizados {
+		anonimizados[chave] = anonimizado
+	}
+
+	var pendentes []func()
+	var eventos []func(tx *gorm.DB) error
+	tx := &clienteRepositoryMemoria{
+		mu:               r.mu,
+		clientes:         copia,
+		anonimizados:     anonimizados,
+		emTransacao:      true,
+		aposCommit:       &pendentes,
+		db:               r.db,
+		eventosPendentes: &eventos,
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Os eventos são gravados em uma única transação do banco antes de a cópia ser confirmada
+	err := r.registrar(ctx, func(db *gorm.DB) error {
+		for _, evento := range eventos {
+			if err := evento(db); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Transações aninhadas confirmadas substituem o mapa de tx
+	r.clientes, r.anonimizados = tx.clientes, tx.anonimizados
+
+	// Em transações aninhadas as ações sobem para a transação externa
+	for _, acao := range pendentes {
+		r.confirmado(acao)
+	}
+	return nil
+}
+
+// tenantDaOperacao verifica o contexto e retorna o tenant ao qual a operação fica restrita
+func tenantDaOperacao(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return tenant.ID(ctx)
+}
+
+// travar obtém o lock de escrita, exceto dentro de uma transação, que já o possui
+func (r *clienteRepositoryMemoria) travar() {
+	if !r.emTransacao {
+		r.mu.Lock()
+	}
+}
+
+func (r *clienteRepositoryMemoria) destravar() {
+	if !r.emTransacao {
+		r.mu.Unlock()
+	}
+}
+
+func (r *clienteRepositoryMemoria) travarLeitura() {
+	if !r.emTransacao {
+		r.mu.RLock()
+	}
+}
+
+func (r *clienteRepositoryMemoria) destravarLeitura() {
+	if !r.emTransacao {
+		r.mu.RUnlock()
+	}
+}
+
+// registrar grava os eventos de uma alteração no outbox, ou os guarda para o commit dentro de
+// uma transação. Sem banco os eventos são descartados.
+func (r *clienteRepositoryMemoria) registrar(ctx context.Context, eventos func(tx *gorm.DB) error) error {
+	if r.db == nil {
+		return nil
+	}
+	if r.eventosPendentes != nil {
+		*r.eventosPendentes = append(*r.eventosPendentes, eventos)
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(eventos)
+}
+
+// confirmado executa a ação imediatamente fora de transações ou após o commit da transação atual
+func (r *clienteRepositoryMemoria) confirmado(acao func()) {
+	if r.aposCommit != nil {
+		*r.aposCommit = append(*r.aposCommit, acao)
+		return
+	}
+	acao()
+}
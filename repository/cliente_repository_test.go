@@ -0,0 +1,753 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/blocklist"
+	"github.com/Gileno29/clientes-API/cache"
+	"github.com/Gileno29/clientes-API/cripto"
+	"github.com/Gileno29/clientes-API/database"
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/migrations"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/repository/repositorytest"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var bancos atomic.Int64
+
+// setupDB cria um banco SQLite em memória exclusivo, com o esquema das migrações
+func setupDB(t *testing.T) *gorm.DB {
+	return abrirDB(t, fmt.Sprintf("file:repositorio%d?mode=memory&cache=shared", bancos.Add(1)))
+}
+
+// abrirDB abre uma conexão com o banco informado, aplicando as migrações pendentes
+func abrirDB(t *testing.T, nome string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(nome), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrador, err := migrations.NewMigrador(db)
+	require.NoError(t, err)
+	_, err = migrador.Subir(context.Background(), 0)
+	require.NoError(t, err)
+	return db
+}
+
+func TestClienteRepositoryGorm(t *testing.T) {
+	repositorytest.ContratoClienteRepository(t, func(t *testing.T) repository.ClienteRepository {
+		return repository.NewClienteRepository(setupDB(t))
+	})
+
+	// Caso de sucesso: A anonimização não deixa os dados do titular nos jobs finalizados
+	t.Run("Apaga dos jobs finalizados os dados do titular anonimizado", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewClienteRepository(db)
+		ctx := tenant.ComID(context.Background(), "a")
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+
+		exportacao := []byte("documento,razaosocial,blocklist\n52998224725,João Silva,false\n")
+		jobs := []models.Job{
+			{ID: "exportacao", TenantID: "a", Tipo: "exportacao", Status: models.JobConcluido, Parametros: "{}", Resultado: exportacao, ResultadoContentType: "text/csv"},
+			{ID: "importacao", TenantID: "a", Tipo: "importacao", Status: models.JobFalhou, Parametros: `{"clientes":[{"documento":"529.982.247-25","razao_social":"João Silva"}]}`},
+			{ID: "executando", TenantID: "a", Tipo: "exportacao", Status: models.JobExecutando, Parametros: "{}", Resultado: exportacao},
+			{ID: "outro-titular", TenantID: "a", Tipo: "revalidacao", Status: models.JobConcluido, Parametros: "{}", Resultado: []byte(`{"invalidos":["11111111111"]}`)},
+			// Os dígitos do documento só aparecem juntando valores vizinhos
+			{ID: "vizinhos", TenantID: "a", Tipo: "exportacao", Status: models.JobConcluido, Parametros: `{"lote":52998,"pagina":224725}`, Resultado: []byte("documento,total\n11152998,224725\n"), ResultadoContentType: "text/csv"},
+			{ID: "id-do-job", TenantID: "a", Tipo: "revalidacao", Status: models.JobConcluido, Parametros: `{"origem":"job-a52998224725"}`},
+			{ID: "outro-tenant", TenantID: "b", Tipo: "exportacao", Status: models.JobConcluido, Parametros: "{}", Resultado: exportacao},
+		}
+		require.NoError(t, db.Create(&jobs).Error)
+
+		_, err := repo.Anonimizar(ctx, "52998224725", "hash-do-titular")
+		require.NoError(t, err)
+
+		buscar := func(id string) models.Job {
+			var job models.Job
+			require.NoError(t, db.Where("id = ?", id).First(&job).Error)
+			return job
+		}
+		for _, id := range []string{"exportacao", "importacao"} {
+			job := buscar(id)
+			assert.Empty(t, job.Resultado, id)
+			assert.Empty(t, job.ResultadoContentType, id)
+			assert.Equal(t, "{}", job.Parametros, id)
+		}
+		assert.Equal(t, exportacao, buscar("executando").Resultado, "O job em andamento é mantido")
+		assert.NotEmpty(t, buscar("outro-titular").Resultado)
+		assert.NotEmpty(t, buscar("vizinhos").Resultado, "Números vizinhos não formam o documento")
+		assert.Equal(t, `{"lote":52998,"pagina":224725}`, buscar("vizinhos").Parametros)
+		assert.Equal(t, `{"origem":"job-a52998224725"}`, buscar("id-do-job").Parametros, "O documento dentro de um id não é o do titular")
+		assert.Equal(t, exportacao, buscar("outro-tenant").Resultado)
+	})
+}
+
+func TestClienteRepositoryMemoria(t *testing.T) {
+	repositorytest.ContratoClienteRepository(t, func(t *testing.T) repository.ClienteRepository {
+		return repository.NewClienteRepositoryMemoria()
+	})
+	repositorytest.ContratoClienteRepository(t, func(t *testing.T) repository.ClienteRepository {
+		return repository.NewClienteRepositoryMemoriaComEventos(setupDB(t))
+	})
+
+	ctx := tenant.ComID(context.Background(), tenant.Padrao)
+	tipos := func(t *testing.T, db *gorm.DB) []string {
+		var eventos []models.Evento
+		require.NoError(t, db.Order("id ASC").Find(&eventos).Error)
+		tipos := []string{}
+		for _, evento := range eventos {
+			tipos = append(tipos, evento.Tipo)
+		}
+		return tipos
+	}
+
+	// Caso de sucesso: As alterações geram os mesmos eventos do repositório Gorm
+	t.Run("Grava os eventos no outbox", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewClienteRepositoryMemoriaComEventos(db)
+
+		cliente := &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}
+		require.NoError(t, repo.Create(ctx, cliente))
+		bloqueado := true
+		_, err := repo.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{Blocklist: &bloqueado})
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteByDocumento(ctx, "52998224725"))
+		require.NoError(t, repo.DeleteByDocumento(ctx, "52998224725"), "Remover o ausente não gera evento")
+
+		assert.Equal(t, []string{
+			models.EventoClienteCriado,
+			models.EventoClienteAtualizado,
+			models.EventoBlocklistAlterado,
+			models.EventoClienteRemovido,
+		}, tipos(t, db))
+
+		eventos, err := repository.NewEventoRepository(db).ListarPorDocumentos(tenant.Padrao, []string{"52998224725"})
+		require.NoError(t, err)
+		require.Len(t, eventos, 4)
+		assert.Contains(t, eventos[0].Payload, "João Silva")
+	})
+
+	// Caso de sucesso: A anonimização substitui o conteúdo dos eventos do titular
+	t.Run("Anonimiza os eventos do titular", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewClienteRepositoryMemoriaComEventos(db)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+
+		_, err := repo.Anonimizar(ctx, "52998224725", "hash-do-titular")
+		require.NoError(t, err)
+
+		eventos, err := repository.NewEventoRepository(db).ListarPorDocumentos(tenant.Padrao, []string{"hash-do-titular"})
+		require.NoError(t, err)
+		require.Len(t, eventos, 2)
+		for _, evento := range eventos {
+			assert.NotContains(t, evento.Payload, "João Silva")
+		}
+		assert.Equal(t, models.EventoClienteAnonimizado, eventos[1].Tipo)
+	})
+
+	// Caso de sucesso: Os eventos da transação só são gravados no commit
+	t.Run("Grava os eventos da transação no commit", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewClienteRepositoryMemoriaComEventos(db)
+
+		err := repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			require.NoError(t, tx.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+			assert.Empty(t, tipos(t, db))
+			return errors.New("falha")
+		})
+		require.Error(t, err)
+		assert.Empty(t, tipos(t, db))
+
+		err = repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			return tx.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"})
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.EventoClienteCriado}, tipos(t, db))
+	})
+
+	// Caso de erro: A alteração não é aplicada se o evento não puder ser gravado
+	t.Run("Não altera a memória sem o evento", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewClienteRepositoryMemoriaComEventos(db)
+		require.NoError(t, db.Migrator().DropTable(&models.Evento{}))
+
+		err := repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"})
+		require.Error(t, err)
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestClienteRepositoryReplicas(t *testing.T) {
+	// Réplica sem atraso: a mesma base aberta por outro pool
+	repositorytest.ContratoClienteRepository(t, func(t *testing.T) repository.ClienteRepository {
+		nome := fmt.Sprintf("file:repositorio%d?mode=memory&cache=shared", bancos.Add(1))
+		return repository.NewClienteRepositoryComReplicas(database.NewRoteador(abrirDB(t, nome), abrirDB(t, nome)))
+	})
+
+	// Réplica atrasada: uma base separada que não recebe as escritas
+	t.Run("Lê as próprias escritas com a réplica atrasada", func(t *testing.T) {
+		repo := repository.NewClienteRepositoryComReplicas(database.NewRoteador(setupDB(t), setupDB(t)))
+		ctx := tenant.ComID(context.Background(), tenant.Padrao)
+		requisicao := database.ComLeituraAposEscrita(ctx)
+
+		_, err := repo.FindByDocumento(requisicao, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		require.NoError(t, repo.Create(requisicao, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+		_, err = repo.FindByDocumento(requisicao, "52998224725")
+		assert.NoError(t, err, "A requisição que escreveu deve ler do primário")
+		_, total, err := repo.ListarClientes(requisicao, "", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Outras leituras vão para a réplica")
+	})
+}
+
+// repositorioContador conta as buscas que chegam ao repositório envolvido pelo cache
+type repositorioContador struct {
+	repository.ClienteRepository
+	buscas atomic.Int64
+}
+
+func (r *repositorioContador) FindByDocumento(ctx context.Context, documento string) (*models.Cliente, error) {
+	r.buscas.Add(1)
+	return r.ClienteRepository.FindByDocumento(ctx, documento)
+}
+
+func (r *repositorioContador) FindByDocumentos(ctx context.Context, documentos []string) ([]models.Cliente, error) {
+	r.buscas.Add(1)
+	return r.ClienteRepository.FindByDocumentos(ctx, documentos)
+}
+
+func TestClienteRepositoryCache(t *testing.T) {
+	repositorytest.ContratoClienteRepository(t, func(t *testing.T) repository.ClienteRepository {
+		return repository.NewClienteRepositoryComCache(repository.NewClienteRepositoryMemoria(), cache.NewLRU(100), time.Minute, time.Minute)
+	})
+
+	ctx := tenant.ComID(context.Background(), "a")
+	novo := func(c cache.Cache) (*repositorioContador, repository.ClienteRepository) {
+		contador := &repositorioContador{ClienteRepository: repository.NewClienteRepository(setupDB(t))}
+		return contador, repository.NewClienteRepositoryComCache(contador, c, time.Minute, time.Minute)
+	}
+
+	// Caso de sucesso: A segunda busca é atendida pelo cache
+	t.Run("Guarda o cliente encontrado", func(t *testing.T) {
+		contador, repo := novo(cache.NewLRU(100))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+
+		for i := 0; i < 3; i++ {
+			cliente, err := repo.FindByDocumento(ctx, "52998224725")
+			require.NoError(t, err)
+			assert.Equal(t, "João Silva", cliente.RazaoSocial)
+		}
+		assert.Equal(t, int64(1), contador.buscas.Load())
+	})
+
+	// Caso de sucesso: Documento não cadastrado também é guardado e sai do cache ao ser cadastrado
+	t.Run("Guarda o documento não cadastrado", func(t *testing.T) {
+		contador, repo := novo(cache.NewLRU(100))
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.FindByDocumento(ctx, "52998224725")
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		}
+		assert.Equal(t, int64(1), contador.buscas.Load())
+
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+		_, err := repo.FindByDocumento(ctx, "52998224725")
+		assert.NoError(t, err)
+	})
+
+	// Caso de sucesso: A busca de vários documentos consulta somente os que não estão no cache
+	t.Run("Guarda os clientes da busca em lote", func(t *testing.T) {
+		contador, repo := novo(cache.NewLRU(100))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira"}))
+		_, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+
+		documentos := []string{"52998224725", "86405508838", "11144477735"}
+		clientes, err := repo.FindByDocumentos(ctx, documentos)
+		require.NoError(t, err)
+		assert.Len(t, clientes, 2)
+		assert.Equal(t, int64(2), contador.buscas.Load(), "Somente os documentos fora do cache vão ao banco")
+
+		clientes, err = repo.FindByDocumentos(ctx, documentos)
+		require.NoError(t, err)
+		assert.Len(t, clientes, 2)
+		assert.Equal(t, int64(2), contador.buscas.Load(), "Cadastrados e não cadastrados ficam no cache")
+	})
+
+	// Caso de sucesso: A réplica atrasada não é usada para preencher o cache
+	t.Run("Busca as faltas no primário", func(t *testing.T) {
+		replicas := repository.NewClienteRepositoryComReplicas(database.NewRoteador(setupDB(t), setupDB(t)))
+		repo := repository.NewClienteRepositoryComCache(replicas, cache.NewLRU(100), time.Minute, time.Minute)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial)
+
+		clientes, err := repo.FindByDocumentos(ctx, []string{"52998224725", "86405508838"})
+		require.NoError(t, err)
+		assert.Len(t, clientes, 1)
+
+		_, err = replicas.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "As demais leituras continuam na réplica")
+	})
+
+	// Caso de sucesso: Atualização e remoção invalidam o documento
+	t.Run("Invalida nas escritas", func(t *testing.T) {
+		_, repo := novo(cache.NewLRU(100))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		bloqueado := true
+		_, err = repo.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{Blocklist: &bloqueado})
+		require.NoError(t, err)
+
+		cliente, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.True(t, cliente.Blocklist, "A busca não deve retornar o valor anterior")
+
+		require.NoError(t, repo.DeleteByDocumento(ctx, "52998224725"))
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	// Caso de sucesso: As escritas da transação invalidam o cache somente ao final
+	t.Run("Invalida após a transação", func(t *testing.T) {
+		contador, repo := novo(cache.NewLRU(100))
+		_, err := repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		err = repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			return tx.Transaction(ctx, func(interna repository.ClienteRepository) error {
+				if err := interna.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}); err != nil {
+					return err
+				}
+				// O documento está no cache como não cadastrado, mas a transação já o enxerga
+				_, err := interna.FindByDocumento(ctx, "52998224725")
+				return err
+			})
+		})
+		require.NoError(t, err)
+
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), contador.buscas.Load())
+	})
+
+	// Caso de sucesso: Um tenant não recebe o cliente guardado no cache por outro
+	t.Run("Separa o cache por tenant", func(t *testing.T) {
+		contador, repo := novo(cache.NewLRU(100))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+		_, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+
+		outro := tenant.ComID(context.Background(), "b")
+		for i := 0; i < 2; i++ {
+			_, err = repo.FindByDocumento(outro, "52998224725")
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		}
+		assert.Equal(t, int64(2), contador.buscas.Load(), "A busca do outro tenant vai ao banco e guarda a própria entrada")
+
+		require.NoError(t, repo.Create(outro, &models.Cliente{Documento: "52998224725", RazaoSocial: "Maria Oliveira"}))
+		cliente, err := repo.FindByDocumento(outro, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "Maria Oliveira", cliente.RazaoSocial)
+		cliente, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial)
+	})
+
+	// Caso de erro: Com o cache fora do ar as buscas vão para o banco
+	t.Run("Busca no banco quando o cache falha", func(t *testing.T) {
+		servidor := miniredis.RunT(t)
+		redis, err := cache.NewRedis("redis://"+servidor.Addr(), "")
+		require.NoError(t, err)
+		t.Cleanup(func() { redis.Fechar() })
+		servidor.Close()
+
+		contador, repo := novo(redis)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+		for i := 0; i < 2; i++ {
+			_, err := repo.FindByDocumento(ctx, "52998224725")
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int64(2), contador.buscas.Load())
+	})
+
+	// Caso de sucesso: Com a criptografia o Redis não guarda o documento nem a razão social
+	t.Run("Cifra as entradas do repositório cifrado", func(t *testing.T) {
+		servidor := miniredis.RunT(t)
+		redis, err := cache.NewRedis("redis://"+servidor.Addr(), "")
+		require.NoError(t, err)
+		t.Cleanup(func() { redis.Fechar() })
+
+		cifrador := novoCifrador(t, "k1")
+		contador := &repositorioContador{ClienteRepository: repository.NewClienteRepositoryCifrado(database.NewRoteador(setupDB(t)), cifrador)}
+		repo := repository.NewClienteRepositoryComCacheCifrado(contador, redis, time.Minute, time.Minute, cifrador)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+		for i := 0; i < 2; i++ {
+			cliente, err := repo.FindByDocumento(ctx, "52998224725")
+			require.NoError(t, err)
+			assert.Equal(t, "João Silva", cliente.RazaoSocial)
+		}
+		assert.Equal(t, int64(1), contador.buscas.Load())
+
+		_, err = repo.FindByDocumento(ctx, "86405508838")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		chaves := servidor.Keys()
+		require.Len(t, chaves, 2)
+		for _, chave := range chaves {
+			assert.NotContains(t, chave, "52998224725")
+			assert.NotContains(t, chave, "86405508838")
+			valor, err := servidor.Get(chave)
+			require.NoError(t, err)
+			assert.NotContains(t, valor, "João")
+			assert.NotContains(t, valor, "52998224725")
+		}
+
+		// A atualização invalida a entrada pela mesma chave com hash
+		nova := "João da Silva"
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		_, err = repo.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{RazaoSocial: &nova})
+		require.NoError(t, err)
+		cliente, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, nova, cliente.RazaoSocial)
+	})
+}
+
+func TestClienteRepositoryIndice(t *testing.T) {
+	repositorytest.ContratoClienteRepository(t, func(t *testing.T) repository.ClienteRepository {
+		memoria := repository.NewClienteRepositoryMemoria()
+		return repository.NewClienteRepositoryComIndice(memoria, blocklist.NewIndice([]string{"a"}, carregarBloqueados(memoria)))
+	})
+
+	ctx := tenant.ComID(context.Background(), "a")
+	novo := func() (*blocklist.Indice, repository.ClienteRepository) {
+		base := repository.NewClienteRepository(setupDB(t))
+		indice := blocklist.NewIndice([]string{"a", "b"}, carregarBloqueados(base))
+		require.NoError(t, indice.Sincronizar(ctx))
+		return indice, repository.NewClienteRepositoryComIndice(base, indice)
+	}
+	bloqueado := func(valor bool) *dtos.AtualizaClienteRequest {
+		return &dtos.AtualizaClienteRequest{Blocklist: &valor}
+	}
+
+	// Caso de sucesso: Cadastro, atualização e remoção chegam ao índice
+	t.Run("Aplica as escritas ao índice", func(t *testing.T) {
+		indice, repo := novo()
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}))
+		assert.True(t, indice.Consultar("a", "52998224725").Blocklist)
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		_, err = repo.UpdateByDocumento(ctx, cliente, bloqueado(false))
+		require.NoError(t, err)
+		assert.False(t, indice.Consultar("a", "52998224725").Blocklist)
+
+		_, err = repo.UpdateByDocumento(ctx, cliente, bloqueado(true))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteByDocumento(ctx, "52998224725"))
+		assert.False(t, indice.Consultar("a", "52998224725").Blocklist)
+
+		require.NoError(t, indice.Sincronizar(ctx))
+		assert.Equal(t, uint64(4), indice.Consultar("a", "52998224725").Versao, "O índice já estava igual ao banco")
+	})
+
+	// Caso de sucesso: As escritas chegam somente ao índice do tenant que as fez
+	t.Run("Aplica as escritas no tenant", func(t *testing.T) {
+		indice, repo := novo()
+		outro := tenant.ComID(context.Background(), "b")
+		require.NoError(t, repo.Create(outro, &models.Cliente{Documento: "52998224725", RazaoSocial: "Maria Oliveira", Blocklist: true}))
+		assert.True(t, indice.Consultar("b", "52998224725").Blocklist)
+		assert.False(t, indice.Consultar("a", "52998224725").Blocklist)
+
+		require.NoError(t, repo.DeleteByDocumento(ctx, "52998224725"))
+		assert.True(t, indice.Consultar("b", "52998224725").Blocklist, "A remoção no tenant a não alcança o b")
+	})
+
+	// Caso de sucesso: A anonimização troca o documento pelo hash no índice
+	t.Run("Aplica a anonimização", func(t *testing.T) {
+		indice, repo := novo()
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}))
+
+		_, err := repo.Anonimizar(ctx, "52998224725", "hash-joao")
+		require.NoError(t, err)
+		assert.False(t, indice.Consultar("a", "52998224725").Blocklist)
+		assert.True(t, indice.Consultar("a", "hash-joao").Blocklist)
+
+		require.NoError(t, indice.Sincronizar(context.Background()))
+		assert.True(t, indice.Consultar("a", "hash-joao").Blocklist, "O hash é recarregado do banco")
+	})
+
+	// Caso de sucesso: Somente as transações confirmadas alteram o índice
+	t.Run("Aplica a transação após o commit", func(t *testing.T) {
+		indice, repo := novo()
+
+		err := repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			if err := tx.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}); err != nil {
+				return err
+			}
+			assert.False(t, indice.Consultar("a", "52998224725").Blocklist, "Ainda não confirmado")
+
+			tx.Transaction(ctx, func(interna repository.ClienteRepository) error {
+				interna.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira", Blocklist: true})
+				return errors.New("falha")
+			})
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, indice.Consultar("a", "52998224725").Blocklist)
+		assert.False(t, indice.Consultar("a", "86405508838").Blocklist, "A transação interna foi desfeita")
+
+		err = repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			tx.Create(ctx, &models.Cliente{Documento: "33000167000101", RazaoSocial: "Empresa XYZ", Blocklist: true})
+			return errors.New("falha")
+		})
+		assert.Error(t, err)
+		assert.False(t, indice.Consultar("a", "33000167000101").Blocklist)
+	})
+}
+
+// carregarBloqueados lê os documentos em blocklist de cada tenant para o índice
+func carregarBloqueados(repo repository.ClienteRepository) func(ctx context.Context, t string) ([]string, error) {
+	return func(ctx context.Context, t string) ([]string, error) {
+		return repo.ListarBloqueados(tenant.ComID(ctx, t))
+	}
+}
+
+// novoCifrador cria o cifrador com as chaves k1 e k2, usando ativa nas novas cifragens
+func novoCifrador(t *testing.T, ativa string) *cripto.Cifrador {
+	chave := func(b byte) string {
+		return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+	}
+	caminho := filepath.Join(t.TempDir(), "chaves.json")
+	conteudo := fmt.Sprintf(`{"ativa": %q, "chaves": {"k1": %q, "k2": %q}, "hash": %q}`, ativa, chave(1), chave(2), chave(9))
+	require.NoError(t, os.WriteFile(caminho, []byte(conteudo), 0o600))
+
+	arquivo, err := cripto.CarregarArquivoChaves(caminho)
+	require.NoError(t, err)
+	cifrador, err := cripto.NewCifrador(arquivo, arquivo.ChaveHash())
+	require.NoError(t, err)
+	return cifrador
+}
+
+func TestClienteRepositoryCifrado(t *testing.T) {
+	repositorytest.ContratoClienteRepository(t, func(t *testing.T) repository.ClienteRepository {
+		return repository.NewClienteRepositoryCifrado(database.NewRoteador(setupDB(t)), novoCifrador(t, "k1"))
+	}, repositorytest.Opcoes{ListagemSemOrdem: true})
+
+	ctx := tenant.ComID(context.Background(), "a")
+
+	// Caso de sucesso: O banco guarda somente o hash e os valores cifrados
+	t.Run("Não grava os dados pessoais em texto puro", func(t *testing.T) {
+		db := setupDB(t)
+		cifrador := novoCifrador(t, "k1")
+		repo := repository.NewClienteRepositoryCifrado(database.NewRoteador(db), cifrador)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+
+		var gravado models.Cliente
+		require.NoError(t, db.Where("tenant_id = ?", "a").First(&gravado).Error)
+		assert.Len(t, gravado.Documento, 64)
+		assert.NotContains(t, gravado.RazaoSocial, "João")
+		assert.NotContains(t, gravado.DocumentoCifrado, "52998224725")
+		assert.Equal(t, "k1", gravado.ChaveID)
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "52998224725", cliente.Documento)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial)
+		assert.Empty(t, cliente.DocumentoCifrado)
+
+		var eventos []models.Evento
+		require.NoError(t, db.Find(&eventos).Error)
+		require.Len(t, eventos, 1)
+		assert.Equal(t, gravado.Documento, eventos[0].Documento, "Os eventos também guardam somente o hash")
+		assert.NotContains(t, eventos[0].Payload, "João")
+		assert.NotContains(t, eventos[0].Payload, "52998224725")
+		assert.NotContains(t, eventos[0].DocumentoCifrado, "52998224725")
+
+		// Os eventos são decifrados na leitura pelo histórico, pelo stream e pelos webhooks
+		eventoRepo := repository.NewEventoRepositoryCifrado(db, cifrador)
+		historico, err := eventoRepo.ListarPorDocumentos("a", []string{"52998224725"})
+		require.NoError(t, err)
+		require.Len(t, historico, 1)
+		assert.Equal(t, "52998224725", historico[0].Documento)
+		assert.Contains(t, historico[0].Payload, "João Silva")
+		stream, err := eventoRepo.ListarApos("a", 0, nil, "52998224725", 10)
+		require.NoError(t, err)
+		assert.Len(t, stream, 1)
+		entregue, err := repository.NewWebhookRepositoryCifrado(db, cifrador).FindEvento(eventos[0].ID)
+		require.NoError(t, err)
+		assert.Contains(t, entregue.Payload, "João Silva")
+
+		// Caso de erro: Evento cifrado lido sem a criptografia configurada
+		_, err = repository.NewEventoRepository(db).ListarApos("a", 0, nil, "", 10)
+		assert.ErrorIs(t, err, repository.ErrSemCriptografia)
+
+		// A anonimização troca o envelope dos eventos pelo hash do titular
+		_, err = repo.Anonimizar(ctx, "52998224725", "hash-do-titular")
+		require.NoError(t, err)
+		historico, err = eventoRepo.ListarPorDocumentos("a", []string{"hash-do-titular"})
+		require.NoError(t, err)
+		require.Len(t, historico, 2)
+		assert.JSONEq(t, `{"anonimizado": true}`, historico[0].Payload)
+		assert.Equal(t, models.EventoClienteAnonimizado, historico[1].Tipo)
+		assert.Equal(t, "hash-do-titular", historico[1].Documento)
+	})
+
+	// Caso de sucesso: Clientes gravados antes da criptografia continuam acessíveis e são convertidos
+	t.Run("Converte os clientes em texto puro", func(t *testing.T) {
+		db := setupDB(t)
+		require.NoError(t, repository.NewClienteRepository(db).Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}))
+		repo := repository.NewClienteRepositoryCifrado(database.NewRoteador(db), novoCifrador(t, "k1"))
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial)
+		err = repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "Outro"})
+		assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+		bloqueados, err := repo.ListarBloqueados(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"52998224725"}, bloqueados)
+
+		convertidos, restantes, err := repo.(repository.Recriptografavel).Recriptografar(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, convertidos)
+		assert.Zero(t, restantes)
+
+		// Os eventos gravados antes da criptografia são convertidos pelo repositório de eventos
+		eventoRepo := repository.NewEventoRepositoryCifrado(db, novoCifrador(t, "k1"))
+		convertidos, restantes, err = eventoRepo.(repository.Recriptografavel).Recriptografar(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, convertidos)
+		assert.Zero(t, restantes)
+		var semCifra int64
+		require.NoError(t, db.Model(&models.Evento{}).Where("payload LIKE ?", "%João%").Count(&semCifra).Error)
+		assert.Zero(t, semCifra)
+		historico, err := eventoRepo.ListarPorDocumentos("a", []string{"52998224725"})
+		require.NoError(t, err)
+		require.Len(t, historico, 1)
+		assert.Contains(t, historico[0].Payload, "João Silva")
+
+		var gravados []models.Cliente
+		require.NoError(t, db.Find(&gravados).Error)
+		require.Len(t, gravados, 1)
+		assert.NotEqual(t, "52998224725", gravados[0].Documento)
+		cliente, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.True(t, cliente.Blocklist)
+	})
+
+	// Caso de sucesso: Após a rotação os clientes são convertidos em lotes para a nova chave
+	t.Run("Recriptografa com a nova chave ativa", func(t *testing.T) {
+		db := setupDB(t)
+		antigo := repository.NewClienteRepositoryCifrado(database.NewRoteador(db), novoCifrador(t, "k1"))
+		for _, documento := range []string{"52998224725", "86405508838", "33000167000101"} {
+			require.NoError(t, antigo.Create(ctx, &models.Cliente{Documento: documento, RazaoSocial: "Cliente " + documento}))
+		}
+		outroTenant := tenant.ComID(context.Background(), "b")
+		require.NoError(t, antigo.Create(outroTenant, &models.Cliente{Documento: "52998224725", RazaoSocial: "Outro tenant"}))
+
+		repo := repository.NewClienteRepositoryCifrado(database.NewRoteador(db), novoCifrador(t, "k2")).(repository.Recriptografavel)
+		convertidos, restantes, err := repo.Recriptografar(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 2, convertidos)
+		assert.Equal(t, int64(1), restantes)
+		convertidos, restantes, err = repo.Recriptografar(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 1, convertidos)
+		assert.Zero(t, restantes)
+
+		var chaves []string
+		require.NoError(t, db.Model(&models.Cliente{}).Where("tenant_id = ?", "a").Distinct().Pluck("chave_id", &chaves).Error)
+		assert.Equal(t, []string{"k2"}, chaves)
+		var outro models.Cliente
+		require.NoError(t, db.Where("tenant_id = ?", "b").First(&outro).Error)
+		assert.Equal(t, "k1", outro.ChaveID, "Somente o tenant do job é convertido")
+
+		clientes, total, err := repo.(repository.ClienteRepository).ListarClientes(ctx, "", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		razoes := []string{}
+		for _, cliente := range clientes {
+			razoes = append(razoes, cliente.RazaoSocial)
+		}
+		assert.ElementsMatch(t, []string{"Cliente 52998224725", "Cliente 86405508838", "Cliente 33000167000101"}, razoes)
+
+		// Caso de erro: Sem a criptografia não há o que converter
+		_, _, err = repository.NewClienteRepository(db).(repository.Recriptografavel).Recriptografar(ctx, 10)
+		assert.ErrorIs(t, err, repository.ErrSemCriptografia)
+	})
+
+	// Caso de sucesso: O filtro por razão social é aplicado nos valores decifrados
+	t.Run("Filtra e ordena os clientes decifrados", func(t *testing.T) {
+		cifrador := novoCifrador(t, "k1")
+		repo := repository.NewClienteRepositoryCifrado(database.NewRoteador(setupDB(t)), cifrador)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Silva"}))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "33000167000101", RazaoSocial: "Empresa XYZ"}))
+
+		clientes, total, err := repo.ListarClientes(ctx, "silva", 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, clientes, 1)
+		assert.Equal(t, "João Silva", clientes[0].RazaoSocial)
+		clientes, _, err = repo.ListarClientes(ctx, "silva", 2, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Maria Silva", clientes[0].RazaoSocial)
+
+		// Caso de erro: Acima do limite o filtro fica indisponível; a listagem sem filtro é paginada
+		// pelo banco e não depende do limite
+		limite := repository.LimiteBuscaCifrada
+		repository.LimiteBuscaCifrada = 2
+		t.Cleanup(func() { repository.LimiteBuscaCifrada = limite })
+
+		_, _, err = repo.ListarClientes(ctx, "silva", 1, 10)
+		assert.ErrorIs(t, err, repository.ErrBuscaIndisponivel)
+		clientes, total, err = repo.ListarClientes(ctx, "", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, clientes, 3)
+		assert.True(t, sort.SliceIsSorted(clientes, func(i, j int) bool {
+			return cifrador.Hash("a", clientes[i].Documento) < cifrador.Hash("a", clientes[j].Documento)
+		}), "A listagem sem filtro segue a ordem do hash do documento")
+	})
+
+	// Caso de erro: Cliente cifrado lido sem a criptografia configurada
+	t.Run("Recusa ler cliente cifrado sem o cifrador", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewClienteRepositoryCifrado(database.NewRoteador(db), novoCifrador(t, "k1"))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}))
+
+		_, _, err := repository.NewClienteRepository(db).ListarClientes(ctx, "", 1, 10)
+		assert.ErrorIs(t, err, repository.ErrSemCriptografia)
+	})
+}
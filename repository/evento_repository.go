@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"github.com/Gileno29/clientes-API/models"
+)
+
+type EventoRepository interface {
+	// ListarNaoPublicados retorna os eventos do outbox que ainda não foram distribuídos
+	ListarNaoPublicados(limite int) ([]models.Evento, error)
+	// ListarApos retorna, em ordem, os eventos do tenant posteriores ao id informado que atendem
+	// aos filtros. Sem filtro de tipo retorna somente os tipos distribuídos (models.TiposEvento).
+	ListarApos(tenantID string, id uint, tipos []string, documento string, limite int) ([]models.Evento, error)
+	// ListarPorDocumentos retorna, em ordem, todos os eventos do tenant que referenciam um dos
+	// documentos, usado no relatório do titular
+	ListarPorDocumentos(tenantID string, documentos []string) ([]models.Evento, error)
+	// Registrar grava um evento fora de uma alteração de cliente, como a auditoria de um relatório
+	Registrar(tenantID, tipo, documento string, payload interface{}) error
+	// ListarSequencia retorna, em ordem, o id e a data dos eventos de todos os tenants posteriores
+	// ao id informado, usados pelo stream para encontrar os ids ainda não confirmados
+	ListarSequencia(id uint, limite int) ([]models.Evento, error)
+	// UltimoID retorna o id do evento mais recente, ou zero se não houver eventos
+	UltimoID() (uint, error)
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Gileno29/clientes-API/cripto"
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/tenant"
+	"gorm.io/gorm"
+)
+
+type eventoRepository struct {
+	db *gorm.DB
+	// cifrador cifra o documento e o conteúdo dos eventos registrados; nil grava em texto puro
+	cifrador *cripto.Cifrador
+}
+
+func NewEventoRepository(db *gorm.DB) EventoRepository {
+	return &eventoRepository{db: db}
+}
+
+// NewEventoRepositoryCifrado funciona como NewEventoRepository, buscando os eventos pelo hash com
+// chave do documento e decifrando-os na leitura. Deve receber o mesmo cifrador dos clientes. O
+// repositório também implementa Recriptografavel.
+func NewEventoRepositoryCifrado(db *gorm.DB, cifrador *cripto.Cifrador) EventoRepository {
+	return &eventoRepository{db: db, cifrador: cifrador}
+}
+
+// ListarNaoPublicados não decifra os eventos, já que a distribuição usa somente o tenant e o tipo
+func (r *eventoRepository) ListarNaoPublicados(limite int) ([]models.Evento, error) {
+	var eventos []models.Evento
+	err := r.db.Where("publicado_em IS NULL").Order("id ASC").Limit(limite).Find(&eventos).Error
+	return eventos, err
+}
+
+func (r *eventoRepository) ListarApos(tenantID string, id uint, tipos []string, documento string, limite int) ([]models.Evento, error) {
+	var eventos []models.Evento
+	// As auditorias dos titulares nunca chegam ao stream
+	if len(tipos) == 0 {
+		tipos = models.TiposEvento
+	}
+	query := r.db.Where("tenant_id = ? AND id > ? AND tipo IN ?", tenantID, id, tipos)
+	if documento != "" {
+		query = query.Where("documento IN ?", chavesDocumento(r.cifrador, tenantID, documento))
+	}
+	if err := query.Order("id ASC").Limit(limite).Find(&eventos).Error; err != nil {
+		return nil, err
+	}
+	return eventos, abrirEventos(context.Background(), r.cifrador, eventos)
+}
+
+func (r *eventoRepository) ListarSequencia(id uint, limite int) ([]models.Evento, error) {
+	var eventos []models.Evento
+	err := r.db.Select("id", "created_at").Where("id > ?", id).Order("id ASC").Limit(limite).Find(&eventos).Error
+	return eventos, err
+}
+
+func (r *eventoRepository) ListarPorDocumentos(tenantID string, documentos []string) ([]models.Evento, error) {
+	chaves := make([]string, 0, 2*len(documentos))
+	for _, documento := range documentos {
+		chaves = append(chaves, chavesDocumento(r.cifrador, tenantID, documento)...)
+	}
+	var eventos []models.Evento
+	if err := r.db.Where("tenant_id = ? AND documento IN ?", tenantID, chaves).Order("id ASC").Find(&eventos).Error; err != nil {
+		return nil, err
+	}
+	return eventos, abrirEventos(context.Background(), r.cifrador, eventos)
+}
+
+func (r *eventoRepository) Registrar(tenantID, tipo, documento string, payload interface{}) error {
+	return registrarEvento(r.db, r.cifrador, tenantID, tipo, documento, payload)
+}
+
+func (r *eventoRepository) UltimoID() (uint, error) {
+	var id uint
+	err := r.db.Model(&models.Evento{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// Recriptografar converte os eventos do tenant em texto puro ou cifrados com outra chave, como o
+// repositório de clientes, sem alterar a data nem a publicação deles
+func (r *eventoRepository) Recriptografar(ctx context.Context, limite int) (int, int64, error) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if r.cifrador == nil {
+		return 0, 0, ErrSemCriptografia
+	}
+	db := r.db.WithContext(ctx)
+	ativa := r.cifrador.ChaveAtiva()
+
+	convertidos := 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var eventos []models.Evento
+		if err := tx.Where("tenant_id = ? AND chave_id <> ?", tenantID, ativa).Order("id ASC").Limit(limite).Find(&eventos).Error; err != nil {
+			return err
+		}
+		for i := range eventos {
+			if err := abrirEvento(ctx, r.cifrador, &eventos[i]); err != nil {
+				return err
+			}
+			if err := cifrarEvento(ctx, r.cifrador, &eventos[i]); err != nil {
+				return err
+			}
+			err := tx.Model(&models.Evento{}).Where("id = ?", eventos[i].ID).Updates(map[string]interface{}{
+				"documento":         eventos[i].Documento,
+				"payload":           eventos[i].Payload,
+				"documento_cifrado": eventos[i].DocumentoCifrado,
+				"chave_id":          eventos[i].ChaveID,
+				"chave_cifrada":     eventos[i].ChaveCifrada,
+			}).Error
+			if err != nil {
+				return err
+			}
+			convertidos++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var restantes int64
+	err = db.Model(&models.Evento{}).Where("tenant_id = ? AND chave_id <> ?", tenantID, ativa).Count(&restantes).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return convertidos, restantes, nil
+}
+
+// registrarEvento grava o evento no outbox usando a transação da alteração que o originou,
+// cifrado quando há cifrador
+func registrarEvento(tx *gorm.DB, cifrador *cripto.Cifrador, tenantID, tipo, documento string, payload interface{}) error {
+	conteudo, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	evento := &models.Evento{
+		TenantID:  tenantID,
+		Tipo:      tipo,
+		Documento: documento,
+		Payload:   string(conteudo),
+	}
+	if err := cifrarEvento(tx.Statement.Context, cifrador, evento); err != nil {
+		return err
+	}
+	return tx.Create(evento).Error
+}
+
+// chavesDocumento retorna os valores da coluna documento que podem identificar o documento: o
+// hash com chave e, até que a base seja convertida, o documento em texto puro
+func chavesDocumento(cifrador *cripto.Cifrador, tenantID, documento string) []string {
+	if cifrador == nil {
+		return []string{documento}
+	}
+	return []string{cifrador.Hash(tenantID, documento), documento}
+}
+
+// cifrarEvento substitui o documento pelo hash com chave e cifra o documento e o conteúdo do
+// evento. Sem cifrador o evento é mantido em texto puro.
+func cifrarEvento(ctx context.Context, cifrador *cripto.Cifrador, evento *models.Evento) error {
+	if cifrador == nil {
+		return nil
+	}
+	hash := cifrador.Hash(evento.TenantID, evento.Documento)
+	envelope, err := cifrador.Cifrar(ctx, hash, evento.Documento, evento.Payload)
+	if err != nil {
+		return err
+	}
+	evento.Documento = hash
+	evento.DocumentoCifrado = envelope.Valores[0]
+	evento.Payload = envelope.Valores[1]
+	evento.ChaveID = envelope.ChaveID
+	evento.ChaveCifrada = envelope.ChaveCifrada
+	return nil
+}
+
+// abrirEvento decifra o evento lido do banco. Os gravados em texto puro são mantidos como estão.
+func abrirEvento(ctx context.Context, cifrador *cripto.Cifrador, evento *models.Evento) error {
+	if evento.ChaveID == "" {
+		return nil
+	}
+	if cifrador == nil {
+		return ErrSemCriptografia
+	}
+	envelope := cripto.Envelope{
+		ChaveID:      evento.ChaveID,
+		ChaveCifrada: evento.ChaveCifrada,
+		Valores:      []string{evento.DocumentoCifrado, evento.Payload},
+	}
+	valores, err := cifrador.Decifrar(ctx, evento.Documento, envelope)
+	if err != nil {
+		return err
+	}
+	evento.Documento, evento.Payload = valores[0], valores[1]
+	evento.DocumentoCifrado, evento.ChaveID, evento.ChaveCifrada = "", "", ""
+	return nil
+}
+
+func abrirEventos(ctx context.Context, cifrador *cripto.Cifrador, eventos []models.Evento) error {
+	for i := range eventos {
+		if err := abrirEvento(ctx, cifrador, &eventos[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// anonimizarEventos faz os eventos do titular guardarem somente o hash do documento, sem o
+// envelope com os dados dele
+func anonimizarEventos(tx *gorm.DB, tenantID string, chaves []string, hashDocumento string) error {
+	return tx.Model(&models.Evento{}).Where("tenant_id = ? AND documento IN ?", tenantID, chaves).
+		Updates(map[string]interface{}{
+			"documento":         hashDocumento,
+			"payload":           payloadAnonimizado,
+			"documento_cifrado": "",
+			"chave_id":          "",
+			"chave_cifrada":     "",
+		}).Error
+}
+
+// payloadAnonimizado substitui o conteúdo dos eventos de um cliente anonimizado
+const payloadAnonimizado = `{"anonimizado":true}`
+
+func payloadCliente(cliente *models.Cliente) dtos.ClienteResponse {
+	return dtos.ClienteResponse{
+		Documento:   cliente.Documento,
+		RazaoSocial: cliente.RazaoSocial,
+		Blocklist:   cliente.Blocklist,
+	}
+}
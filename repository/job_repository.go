@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+)
+
+type JobRepository interface {
+	Create(job *models.Job) error
+	FindByID(id string) (*models.Job, error)
+	// ClaimNext reserva o job pendente mais antigo, retornando nil se não houver nenhum
+	ClaimNext() (*models.Job, error)
+	// AtualizarProgresso grava o progresso e o heartbeat de um job em execução
+	AtualizarProgresso(id string, processados, total int, erros string) error
+	Finalizar(job *models.Job) error
+	SolicitarCancelamento(id string) error
+	// Liberar devolve para a fila um job em execução interrompido antes de terminar
+	Liberar(id string) error
+	// RecuperarAbandonados devolve para a fila os jobs em execução sem heartbeat desde o limite informado
+	RecuperarAbandonados(limite time.Time) (int64, error)
+}
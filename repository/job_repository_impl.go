@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/utils"
+	"gorm.io/gorm"
+)
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(job *models.Job) error {
+	return r.db.Create(job).Error
+}
+
+func (r *jobRepository) FindByID(id string) (*models.Job, error) {
+	var job models.Job
+	err := r.db.Where("id = ?", id).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) ClaimNext() (*models.Job, error) {
+	for {
+		var job models.Job
+		err := r.db.Where("status = ?", models.JobPendente).Order("created_at ASC").First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// A reserva só é efetivada se nenhuma outra instância tiver pego o job antes
+		agora := time.Now()
+		res := r.db.Model(&models.Job{}).
+			Where("id = ? AND status = ?", job.ID, models.JobPendente).
+			Updates(map[string]interface{}{
+				"status":       models.JobExecutando,
+				"iniciado_em":  agora,
+				"heartbeat_em": agora,
+			})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			job.Status = models.JobExecutando
+			job.IniciadoEm = &agora
+			job.HeartbeatEm = &agora
+			return &job, nil
+		}
+	}
+}
+
+func (r *jobRepository) AtualizarProgresso(id string, processados, total int, erros string) error {
+	return r.db.Model(&models.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"processados":  processados,
+		"total":        total,
+		"erros":        erros,
+		"heartbeat_em": time.Now(),
+	}).Error
+}
+
+func (r *jobRepository) Finalizar(job *models.Job) error {
+	agora := time.Now()
+	job.FinalizadoEm = &agora
+	return r.db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":                 job.Status,
+		"processados":            job.Processados,
+		"total":                  job.Total,
+		"erros":                  job.Erros,
+		"resultado":              job.Resultado,
+		"resultado_content_type": job.ResultadoContentType,
+		"finalizado_em":          agora,
+	}).Error
+}
+
+func (r *jobRepository) SolicitarCancelamento(id string) error {
+	// Jobs ainda na fila são cancelados imediatamente, os em execução são interrompidos pelo worker
+	agora := time.Now()
+	err := r.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobPendente).
+		Updates(map[string]interface{}{
+			"status":                  models.JobCancelado,
+			"cancelamento_solicitado": true,
+			"finalizado_em":           agora,
+		}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobExecutando).
+		Update("cancelamento_solicitado", true).Error
+}
+
+func (r *jobRepository) Liberar(id string) error {
+	return r.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobExecutando).
+		Updates(map[string]interface{}{
+			"status":       models.JobPendente,
+			"heartbeat_em": nil,
+		}).Error
+}
+
+func (r *jobRepository) RecuperarAbandonados(limite time.Time) (int64, error) {
+	res := r.db.Model(&models.Job{}).
+		Where("status = ? AND (heartbeat_em IS NULL OR heartbeat_em < ?)", models.JobExecutando, limite).
+		Updates(map[string]interface{}{
+			"status":       models.JobPendente,
+			"heartbeat_em": nil,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// expurgarJobsDoTitular apaga os parâmetros e o resultado dos jobs finalizados do tenant que
+// contêm o documento, como as importações, exportações e revalidações, para que a anonimização não
+// deixe cópias dos dados do titular. Os jobs ainda não finalizados são mantidos.
+func expurgarJobsDoTitular(tx *gorm.DB, tenantID, documento string) error {
+	var jobs []models.Job
+	finalizados := []string{models.JobConcluido, models.JobFalhou, models.JobCancelado}
+	err := tx.Select("id", "parametros", "resultado").
+		Where("tenant_id = ? AND status IN ?", tenantID, finalizados).
+		FindInBatches(&jobs, 100, func(lote *gorm.DB, _ int) error {
+			var ids []string
+			for _, job := range jobs {
+				if contemDocumento(job.Parametros, documento) || contemDocumento(string(job.Resultado), documento) {
+					ids = append(ids, job.ID)
+				}
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			return tx.Session(&gorm.Session{NewDB: true}).Model(&models.Job{}).Where("id IN ?", ids).
+				Updates(map[string]interface{}{
+					"parametros":             "{}",
+					"resultado":              nil,
+					"resultado_content_type": "",
+				}).Error
+		}).Error
+	return err
+}
+
+// contemDocumento procura o documento entre os valores do conteúdo, com ou sem a formatação, já
+// que as importações guardam o documento como foi enviado. Os valores são separados por qualquer
+// caractere que não faça parte de um documento formatado, para que números vizinhos, como os de
+// outro documento ou de uma data, não sejam juntados; valores com letras, como ids, são ignorados.
+func contemDocumento(conteudo, documento string) bool {
+	documento = utils.ClearNumber(documento)
+	valores := strings.FieldsFunc(conteudo, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '/'
+	})
+	for _, valor := range valores {
+		if strings.IndexFunc(valor, unicode.IsLetter) < 0 && utils.ClearNumber(valor) == documento {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,406 @@
+// Package repositorytest reúne os testes de contrato que toda implementação dos repositórios
+// precisa passar, independente do armazenamento.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// Opcoes ajusta o contrato às diferenças documentadas de uma implementação
+type Opcoes struct {
+	// ListagemSemOrdem indica que a listagem sem filtro não segue a ordem da razão social, como no
+	// repositório cifrado, que a ordena pelo hash do documento
+	ListagemSemOrdem bool
+}
+
+// ContratoClienteRepository executa o contrato de repository.ClienteRepository. novo deve
+// retornar um repositório vazio e isolado dos demais a cada chamada.
+func ContratoClienteRepository(t *testing.T, novo func(t *testing.T) repository.ClienteRepository, opcoes ...Opcoes) {
+	var opcao Opcoes
+	if len(opcoes) > 0 {
+		opcao = opcoes[0]
+	}
+	ctx := tenant.ComID(context.Background(), "a")
+	outroTenant := tenant.ComID(context.Background(), "b")
+
+	criar := func(t *testing.T, repo repository.ClienteRepository, documento, razaoSocial string) {
+		t.Helper()
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: documento, RazaoSocial: razaoSocial}))
+	}
+	texto := func(valor string) *string { return &valor }
+	booleano := func(valor bool) *bool { return &valor }
+
+	t.Run("Cadastra e busca pelo documento", func(t *testing.T) {
+		repo := novo(t)
+		cliente := &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}
+		require.NoError(t, repo.Create(ctx, cliente))
+		assert.False(t, cliente.CreatedAt.IsZero(), "CreatedAt deve ser preenchido")
+
+		encontrado, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", encontrado.RazaoSocial)
+		assert.True(t, encontrado.Blocklist)
+		assert.False(t, encontrado.UpdatedAt.IsZero(), "UpdatedAt deve ser preenchido")
+	})
+
+	t.Run("Retorna ErrRecordNotFound para documento inexistente", func(t *testing.T) {
+		repo := novo(t)
+		_, err := repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Busca vários documentos de uma vez", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+		criar(t, repo, "86405508838", "Maria Oliveira")
+
+		clientes, err := repo.FindByDocumentos(ctx, []string{"86405508838", "11144477735", "52998224725", "86405508838"})
+		require.NoError(t, err)
+		razoes := map[string]string{}
+		for _, cliente := range clientes {
+			razoes[cliente.Documento] = cliente.RazaoSocial
+		}
+		assert.Len(t, clientes, 2, "Documentos repetidos ou não cadastrados não geram clientes")
+		assert.Equal(t, map[string]string{"52998224725": "João Silva", "86405508838": "Maria Oliveira"}, razoes)
+
+		clientes, err = repo.FindByDocumentos(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, clientes)
+		clientes, err = repo.FindByDocumentos(outroTenant, []string{"52998224725"})
+		require.NoError(t, err)
+		assert.Empty(t, clientes, "Um tenant não lê o cliente de outro")
+	})
+
+	t.Run("Recusa documento duplicado", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+		assert.Error(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "Outro"}))
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial, "O cadastro original deve ser mantido")
+	})
+
+	t.Run("Atualiza somente os campos informados", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		atualizado, err := repo.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{Blocklist: booleano(true)})
+		require.NoError(t, err)
+		assert.True(t, atualizado.Blocklist)
+		assert.Equal(t, "João Silva", atualizado.RazaoSocial)
+
+		cliente, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		_, err = repo.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{RazaoSocial: texto("João da Silva")})
+		require.NoError(t, err)
+
+		cliente, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João da Silva", cliente.RazaoSocial)
+		assert.True(t, cliente.Blocklist)
+	})
+
+	t.Run("Remove pelo documento", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+
+		require.NoError(t, repo.DeleteByDocumento(ctx, "52998224725"))
+		_, err := repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		assert.NoError(t, repo.DeleteByDocumento(ctx, "52998224725"), "Remover documento inexistente não é erro")
+	})
+
+	t.Run("Lista com filtro, ordem e paginação", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "Carlos Souza")
+		criar(t, repo, "86405508838", "Ana Souza")
+		criar(t, repo, "33000167000101", "Empresa XYZ")
+		criar(t, repo, "11222333000181", "Bruno Souza")
+
+		clientes, total, err := repo.ListarClientes(ctx, "", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total)
+		require.Len(t, clientes, 4)
+		if !opcao.ListagemSemOrdem {
+			assert.Equal(t, "Ana Souza", clientes[0].RazaoSocial)
+			assert.Equal(t, "Empresa XYZ", clientes[3].RazaoSocial)
+		}
+
+		primeira, _, err := repo.ListarClientes(ctx, "", 1, 2)
+		require.NoError(t, err)
+		segunda, _, err := repo.ListarClientes(ctx, "", 2, 2)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, clientes, append(primeira, segunda...), "As páginas cobrem todos os clientes, sem repetição")
+
+		clientes, total, err = repo.ListarClientes(ctx, "SOUZA", 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total, "O total considera o filtro e não a página")
+		require.Len(t, clientes, 1)
+		assert.Equal(t, "Carlos Souza", clientes[0].RazaoSocial)
+
+		clientes, total, err = repo.ListarClientes(ctx, "souza", 5, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Empty(t, clientes)
+
+		clientes, total, err = repo.ListarClientes(ctx, "inexistente", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+		assert.Empty(t, clientes)
+	})
+
+	t.Run("Lista os documentos em blocklist", func(t *testing.T) {
+		repo := novo(t)
+		documentos, err := repo.ListarBloqueados(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, documentos)
+
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}))
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "33000167000101", RazaoSocial: "Empresa XYZ", Blocklist: true}))
+		criar(t, repo, "86405508838", "Maria Oliveira")
+
+		documentos, err = repo.ListarBloqueados(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"52998224725", "33000167000101"}, documentos)
+	})
+
+	t.Run("Lista os clientes inativos", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+		criar(t, repo, "86405508838", "Maria Oliveira")
+		require.NoError(t, repo.Create(outroTenant, &models.Cliente{Documento: "11144477735", RazaoSocial: "Outro tenant"}))
+		time.Sleep(20 * time.Millisecond)
+		corte := time.Now()
+		time.Sleep(20 * time.Millisecond)
+		criar(t, repo, "33000167000101", "Empresa XYZ")
+		joao, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		_, err = repo.UpdateByDocumento(ctx, joao, &dtos.AtualizaClienteRequest{Blocklist: booleano(true)})
+		require.NoError(t, err)
+
+		clientes, total, err := repo.ListarInativos(ctx, corte, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, clientes, 1)
+		assert.Equal(t, "86405508838", clientes[0].Documento)
+		assert.Equal(t, "Maria Oliveira", clientes[0].RazaoSocial)
+
+		clientes, total, err = repo.ListarInativos(ctx, time.Now().Add(time.Minute), 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, clientes, 2)
+		assert.Equal(t, "86405508838", clientes[0].Documento, "Os mais antigos primeiro")
+		assert.Equal(t, "33000167000101", clientes[1].Documento)
+	})
+
+	t.Run("Confirma a transação", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+
+		err := repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			if err := tx.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira"}); err != nil {
+				return err
+			}
+			// A transação enxerga as próprias alterações
+			if _, err := tx.FindByDocumento(ctx, "86405508838"); err != nil {
+				return err
+			}
+			return tx.DeleteByDocumento(ctx, "52998224725")
+		})
+		require.NoError(t, err)
+
+		_, err = repo.FindByDocumento(ctx, "86405508838")
+		assert.NoError(t, err)
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Desfaz a transação quando fn retorna erro", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+		falha := errors.New("falha")
+
+		err := repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			if err := tx.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira"}); err != nil {
+				return err
+			}
+			cliente, err := tx.FindByDocumento(ctx, "52998224725")
+			if err != nil {
+				return err
+			}
+			if _, err := tx.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{Blocklist: booleano(true)}); err != nil {
+				return err
+			}
+			return falha
+		})
+		assert.ErrorIs(t, err, falha)
+
+		_, err = repo.FindByDocumento(ctx, "86405508838")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "O cadastro deve ter sido desfeito")
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.False(t, cliente.Blocklist, "A atualização deve ter sido desfeita")
+	})
+
+	t.Run("Desfaz somente a transação aninhada que falhou", func(t *testing.T) {
+		repo := novo(t)
+
+		err := repo.Transaction(ctx, func(tx repository.ClienteRepository) error {
+			if err := tx.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}); err != nil {
+				return err
+			}
+			errInterna := tx.Transaction(ctx, func(interna repository.ClienteRepository) error {
+				if err := interna.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira"}); err != nil {
+					return err
+				}
+				return errors.New("falha")
+			})
+			if errInterna == nil {
+				return errors.New("a transação interna deveria ter falhado")
+			}
+			return tx.Transaction(ctx, func(interna repository.ClienteRepository) error {
+				return interna.Create(ctx, &models.Cliente{Documento: "33000167000101", RazaoSocial: "Empresa XYZ"})
+			})
+		})
+		require.NoError(t, err)
+
+		_, total, err := repo.ListarClientes(ctx, "", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		_, err = repo.FindByDocumento(ctx, "86405508838")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Anonimiza o cliente mantendo a blocklist pelo hash", func(t *testing.T) {
+		repo := novo(t)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}))
+		criar(t, repo, "86405508838", "Maria Oliveira")
+
+		anonimizado, err := repo.Anonimizar(ctx, "52998224725", "hash-joao")
+		require.NoError(t, err)
+		assert.True(t, anonimizado.Blocklist)
+		assert.False(t, anonimizado.AnonimizadoEm.IsZero())
+
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		encontrado, err := repo.FindAnonimizado(ctx, "hash-joao")
+		require.NoError(t, err)
+		assert.True(t, encontrado.Blocklist)
+		documentos, err := repo.ListarBloqueados(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hash-joao"}, documentos)
+
+		_, err = repo.Anonimizar(ctx, "52998224725", "hash-joao")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "O cliente já foi removido")
+		_, err = repo.Anonimizar(outroTenant, "86405508838", "hash-maria")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Um tenant não anonimiza o cliente de outro")
+		_, err = repo.FindAnonimizado(outroTenant, "hash-joao")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		// O titular pode voltar a ser cliente e ser anonimizado de novo
+		criar(t, repo, "52998224725", "João Silva")
+		anonimizado, err = repo.Anonimizar(ctx, "52998224725", "hash-joao")
+		require.NoError(t, err)
+		assert.False(t, anonimizado.Blocklist)
+	})
+
+	t.Run("Retorna erro com o contexto cancelado", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+
+		cancelado, cancel := context.WithCancel(ctx)
+		cancel()
+
+		assert.ErrorIs(t, repo.Create(cancelado, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira"}), context.Canceled)
+		_, err := repo.FindByDocumento(cancelado, "52998224725")
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = repo.FindByDocumentos(cancelado, []string{"52998224725"})
+		assert.ErrorIs(t, err, context.Canceled)
+		_, _, err = repo.ListarClientes(cancelado, "", 1, 10)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, repo.DeleteByDocumento(cancelado, "52998224725"), context.Canceled)
+		err = repo.Transaction(cancelado, func(tx repository.ClienteRepository) error { return nil })
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = repo.FindByDocumento(ctx, "52998224725")
+		assert.NoError(t, err, "Nenhuma alteração deve ter sido aplicada")
+		_, err = repo.FindByDocumento(ctx, "86405508838")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Isola os clientes de cada tenant", func(t *testing.T) {
+		repo := novo(t)
+		require.NoError(t, repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}))
+
+		_, err := repo.FindByDocumento(outroTenant, "52998224725")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Um tenant não lê o cliente de outro")
+		clientes, total, err := repo.ListarClientes(outroTenant, "", 1, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, clientes)
+		documentos, err := repo.ListarBloqueados(outroTenant)
+		require.NoError(t, err)
+		assert.Empty(t, documentos)
+
+		// Escritas de outro tenant não alcançam o cliente
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		_, err = repo.UpdateByDocumento(outroTenant, cliente, &dtos.AtualizaClienteRequest{RazaoSocial: texto("Invasor")})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		require.NoError(t, repo.DeleteByDocumento(outroTenant, "52998224725"))
+
+		encontrado, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err, "O cliente deve continuar cadastrado")
+		assert.Equal(t, "João Silva", encontrado.RazaoSocial)
+
+		// O mesmo documento pode ser cadastrado de forma independente em outro tenant
+		require.NoError(t, repo.Create(outroTenant, &models.Cliente{Documento: "52998224725", RazaoSocial: "Maria Oliveira"}))
+		encontrado, err = repo.FindByDocumento(outroTenant, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "Maria Oliveira", encontrado.RazaoSocial)
+		assert.False(t, encontrado.Blocklist)
+		encontrado, err = repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", encontrado.RazaoSocial)
+	})
+
+	t.Run("Recusa operações sem tenant", func(t *testing.T) {
+		repo := novo(t)
+		criar(t, repo, "52998224725", "João Silva")
+		semTenant := context.Background()
+
+		assert.ErrorIs(t, repo.Create(semTenant, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira"}), tenant.ErrSemTenant)
+		_, err := repo.FindByDocumento(semTenant, "52998224725")
+		assert.ErrorIs(t, err, tenant.ErrSemTenant)
+		_, err = repo.FindByDocumentos(semTenant, []string{"52998224725"})
+		assert.ErrorIs(t, err, tenant.ErrSemTenant)
+		_, _, err = repo.ListarClientes(semTenant, "", 1, 10)
+		assert.ErrorIs(t, err, tenant.ErrSemTenant)
+		_, err = repo.ListarBloqueados(semTenant)
+		assert.ErrorIs(t, err, tenant.ErrSemTenant)
+		_, _, err = repo.ListarInativos(semTenant, time.Now(), 10)
+		assert.ErrorIs(t, err, tenant.ErrSemTenant)
+		assert.ErrorIs(t, repo.DeleteByDocumento(semTenant, "52998224725"), tenant.ErrSemTenant)
+		_, err = repo.UpdateByDocumento(semTenant, &models.Cliente{Documento: "52998224725"}, &dtos.AtualizaClienteRequest{RazaoSocial: texto("Outro")})
+		assert.ErrorIs(t, err, tenant.ErrSemTenant)
+
+		cliente, err := repo.FindByDocumento(ctx, "52998224725")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", cliente.RazaoSocial)
+	})
+}
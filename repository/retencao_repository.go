@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+)
+
+// RetencaoRepository apaga os registros alcançados pela política de retenção de um tenant. Com
+// simular, os métodos somente contam o que seria apagado.
+type RetencaoRepository interface {
+	// ExpurgarRemovidos apaga os eventos, e as entregas deles aos webhooks, dos clientes removidos
+	// antes de antesDe e não cadastrados de novo. Como em ExpurgarEventos, um cliente só é expurgado
+	// quando todos os eventos dele foram distribuídos e não têm entregas pendentes. Retorna a
+	// quantidade de clientes.
+	ExpurgarRemovidos(tenantID string, antesDe time.Time, simular bool) (int64, error)
+	// ExpurgarEventos apaga os eventos criados antes de antesDe que já foram distribuídos e não
+	// têm entregas pendentes, junto com as entregas deles
+	ExpurgarEventos(tenantID string, antesDe time.Time, simular bool) (int64, error)
+	// ExpurgarJobs apaga os jobs finalizados antes de antesDe, com os seus resultados
+	ExpurgarJobs(tenantID string, antesDe time.Time, simular bool) (int64, error)
+	// RegistrarExecucoes grava a auditoria das regras aplicadas em uma rodada
+	RegistrarExecucoes(execucoes []models.ExecucaoRetencao) error
+	// ListarExecucoes retorna as regras aplicadas no tenant, as mais recentes primeiro
+	ListarExecucoes(tenantID string, limite int) ([]models.ExecucaoRetencao, error)
+}
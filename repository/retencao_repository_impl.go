@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+	"gorm.io/gorm"
+)
+
+type retencaoRepository struct {
+	db *gorm.DB
+}
+
+func NewRetencaoRepository(db *gorm.DB) RetencaoRepository {
+	return &retencaoRepository{db: db}
+}
+
+func (r *retencaoRepository) ExpurgarRemovidos(tenantID string, antesDe time.Time, simular bool) (int64, error) {
+	// Remoções sem um novo cadastro do mesmo documento depois delas. Como em ExpurgarEventos, o
+	// documento fica para a próxima rodada enquanto algum evento dele até a remoção não tiver sido
+	// distribuído ou tiver entregas pendentes.
+	remocoes := func(db *gorm.DB) *gorm.DB {
+		return db.Table("eventos AS r").
+			Where("r.tenant_id = ? AND r.tipo = ? AND r.created_at < ?", tenantID, models.EventoClienteRemovido, antesDe).
+			Where("NOT EXISTS (SELECT 1 FROM eventos c WHERE c.tenant_id = r.tenant_id AND c.documento = r.documento AND c.tipo = ? AND c.id > r.id)", models.EventoClienteCriado).
+			Where(`NOT EXISTS (SELECT 1 FROM eventos p WHERE p.tenant_id = r.tenant_id AND p.documento = r.documento AND p.id <= r.id
+				AND (p.publicado_em IS NULL OR EXISTS (SELECT 1 FROM webhook_entregas w WHERE w.evento_id = p.id AND w.status = ?)))`, models.EntregaPendente)
+	}
+
+	var clientes int64
+	if err := remocoes(r.db).Distinct("r.documento").Count(&clientes).Error; err != nil {
+		return 0, err
+	}
+	if simular || clientes == 0 {
+		return clientes, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Os eventos do documento até a remoção, inclusive ela
+		eventos := tx.Table("eventos AS e").Select("e.id").
+			Where("e.tenant_id = ?", tenantID).
+			Where("EXISTS (?)", remocoes(tx).Select("1").Where("r.documento = e.documento AND r.id >= e.id"))
+		return apagarEventos(tx, eventos)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return clientes, nil
+}
+
+func (r *retencaoRepository) ExpurgarEventos(tenantID string, antesDe time.Time, simular bool) (int64, error) {
+	eventos := func(db *gorm.DB) *gorm.DB {
+		return db.Model(&models.Evento{}).
+			Where("tenant_id = ? AND created_at < ? AND publicado_em IS NOT NULL", tenantID, antesDe).
+			Where("NOT EXISTS (SELECT 1 FROM webhook_entregas w WHERE w.evento_id = eventos.id AND w.status = ?)", models.EntregaPendente)
+	}
+
+	var total int64
+	if err := eventos(r.db).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	if simular || total == 0 {
+		return total, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return apagarEventos(tx, eventos(tx).Select("id"))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *retencaoRepository) ExpurgarJobs(tenantID string, antesDe time.Time, simular bool) (int64, error) {
+	query := r.db.Where("tenant_id = ? AND status IN ? AND finalizado_em < ?", tenantID,
+		[]string{models.JobConcluido, models.JobFalhou, models.JobCancelado}, antesDe)
+	if simular {
+		var total int64
+		err := query.Model(&models.Job{}).Count(&total).Error
+		return total, err
+	}
+	resultado := query.Delete(&models.Job{})
+	return resultado.RowsAffected, resultado.Error
+}
+
+func (r *retencaoRepository) RegistrarExecucoes(execucoes []models.ExecucaoRetencao) error {
+	if len(execucoes) == 0 {
+		return nil
+	}
+	return r.db.Create(&execucoes).Error
+}
+
+func (r *retencaoRepository) ListarExecucoes(tenantID string, limite int) ([]models.ExecucaoRetencao, error) {
+	var execucoes []models.ExecucaoRetencao
+	err := r.db.Where("tenant_id = ?", tenantID).Order("id DESC").Limit(limite).Find(&execucoes).Error
+	return execucoes, err
+}
+
+// apagarEventos apaga os eventos selecionados pela subconsulta de ids, com as entregas e as
+// tentativas de entrega deles
+func apagarEventos(tx *gorm.DB, eventos *gorm.DB) error {
+	entregas := tx.Model(&models.WebhookEntrega{}).Select("id").Where("evento_id IN (?)", eventos)
+	if err := tx.Where("entrega_id IN (?)", entregas).Delete(&models.WebhookTentativa{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("evento_id IN (?)", eventos).Delete(&models.WebhookEntrega{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN (?)", eventos).Delete(&models.Evento{}).Error
+}
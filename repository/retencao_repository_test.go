@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRetencaoRepository(t *testing.T) {
+	ctx := tenant.ComID(context.Background(), "a")
+	depois := time.Now().Add(time.Minute)
+
+	contar := func(db *gorm.DB, modelo interface{}) int64 {
+		var total int64
+		require.NoError(t, db.Model(modelo).Count(&total).Error)
+		return total
+	}
+
+	// Caso de sucesso: Apaga o histórico dos clientes removidos, mantendo o dos recadastrados
+	t.Run("Expurga os clientes removidos", func(t *testing.T) {
+		db := setupDB(t)
+		clientes := repository.NewClienteRepository(db)
+		repo := repository.NewRetencaoRepository(db)
+		for _, documento := range []string{"52998224725", "86405508838", "33000167000101"} {
+			require.NoError(t, clientes.Create(ctx, &models.Cliente{Documento: documento, RazaoSocial: "Cliente " + documento}))
+		}
+		require.NoError(t, clientes.Create(tenant.ComID(context.Background(), "b"), &models.Cliente{Documento: "52998224725", RazaoSocial: "Outro tenant"}))
+		require.NoError(t, clientes.DeleteByDocumento(ctx, "52998224725"))
+		require.NoError(t, clientes.DeleteByDocumento(ctx, "86405508838"))
+		require.NoError(t, clientes.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Recadastrado"}))
+
+		removidos, err := repo.ExpurgarRemovidos("a", depois, true)
+		require.NoError(t, err)
+		assert.Zero(t, removidos, "Os eventos ainda não foram distribuídos")
+		require.NoError(t, db.Model(&models.Evento{}).Where("1 = 1").Update("publicado_em", time.Now()).Error)
+
+		var evento models.Evento
+		require.NoError(t, db.Where("documento = ? AND tipo = ?", "52998224725", models.EventoClienteRemovido).First(&evento).Error)
+		pendente := models.WebhookEntrega{AssinaturaID: "w1", EventoID: evento.ID, Status: models.EntregaPendente}
+		require.NoError(t, db.Create(&pendente).Error)
+		removidos, err = repo.ExpurgarRemovidos("a", depois, false)
+		require.NoError(t, err)
+		assert.Zero(t, removidos, "A remoção ainda tem uma entrega pendente")
+		require.NoError(t, db.Model(&pendente).Update("status", models.EntregaEntregue).Error)
+
+		removidos, err = repo.ExpurgarRemovidos("a", time.Now().Add(-time.Hour), false)
+		require.NoError(t, err)
+		assert.Zero(t, removidos, "A remoção ainda está no prazo")
+
+		removidos, err = repo.ExpurgarRemovidos("a", depois, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removidos)
+		assert.Equal(t, int64(7), contar(db, &models.Evento{}), "A simulação não apaga")
+
+		removidos, err = repo.ExpurgarRemovidos("a", depois, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removidos)
+		var documentos []string
+		require.NoError(t, db.Model(&models.Evento{}).Where("tenant_id = ?", "a").Distinct().Pluck("documento", &documentos).Error)
+		assert.ElementsMatch(t, []string{"86405508838", "33000167000101"}, documentos)
+		assert.Equal(t, int64(1), contar(db.Where("tenant_id = ?", "b"), &models.Evento{}))
+		assert.Zero(t, contar(db, &models.WebhookEntrega{}))
+
+		removidos, err = repo.ExpurgarRemovidos("a", depois, false)
+		require.NoError(t, err)
+		assert.Zero(t, removidos)
+	})
+
+	// Caso de sucesso: Apaga os eventos antigos já entregues e mantém os pendentes
+	t.Run("Expurga os eventos entregues", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewRetencaoRepository(db)
+		agora := time.Now()
+		eventos := []models.Evento{
+			{TenantID: "a", Tipo: models.EventoClienteCriado, Documento: "52998224725", PublicadoEm: &agora},
+			{TenantID: "a", Tipo: models.EventoClienteCriado, Documento: "86405508838", PublicadoEm: &agora},
+			{TenantID: "a", Tipo: models.EventoClienteCriado, Documento: "33000167000101"},
+			{TenantID: "b", Tipo: models.EventoClienteCriado, Documento: "52998224725", PublicadoEm: &agora},
+		}
+		require.NoError(t, db.Create(&eventos).Error)
+		entregue := models.WebhookEntrega{AssinaturaID: "w1", EventoID: eventos[0].ID, Status: models.EntregaEntregue}
+		require.NoError(t, db.Create(&entregue).Error)
+		require.NoError(t, db.Create(&models.WebhookTentativa{EntregaID: entregue.ID, Numero: 1}).Error)
+		require.NoError(t, db.Create(&models.WebhookEntrega{AssinaturaID: "w1", EventoID: eventos[1].ID, Status: models.EntregaPendente}).Error)
+
+		total, err := repo.ExpurgarEventos("a", depois, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+
+		total, err = repo.ExpurgarEventos("a", depois, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		var restantes []uint
+		require.NoError(t, db.Model(&models.Evento{}).Order("id").Pluck("id", &restantes).Error)
+		assert.Equal(t, []uint{eventos[1].ID, eventos[2].ID, eventos[3].ID}, restantes, "Pendentes, não distribuídos e de outro tenant ficam")
+		assert.Zero(t, contar(db, &models.WebhookTentativa{}))
+		assert.Equal(t, int64(1), contar(db, &models.WebhookEntrega{}))
+	})
+
+	// Caso de sucesso: Apaga somente os jobs finalizados
+	t.Run("Expurga os jobs finalizados", func(t *testing.T) {
+		db := setupDB(t)
+		repo := repository.NewRetencaoRepository(db)
+		agora := time.Now()
+		require.NoError(t, db.Create(&[]models.Job{
+			{ID: "j1", TenantID: "a", Tipo: "exportacao", Status: models.JobConcluido, FinalizadoEm: &agora},
+			{ID: "j2", TenantID: "a", Tipo: "exportacao", Status: models.JobExecutando},
+			{ID: "j3", TenantID: "b", Tipo: "exportacao", Status: models.JobFalhou, FinalizadoEm: &agora},
+		}).Error)
+
+		total, err := repo.ExpurgarJobs("a", depois, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		total, err = repo.ExpurgarJobs("a", depois, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+
+		var ids []string
+		require.NoError(t, db.Model(&models.Job{}).Order("id").Pluck("id", &ids).Error)
+		assert.Equal(t, []string{"j2", "j3"}, ids)
+	})
+
+	// Caso de sucesso: Auditoria separada por tenant, a mais recente primeiro
+	t.Run("Registra e lista as execuções", func(t *testing.T) {
+		repo := repository.NewRetencaoRepository(setupDB(t))
+		require.NoError(t, repo.RegistrarExecucoes(nil))
+		require.NoError(t, repo.RegistrarExecucoes([]models.ExecucaoRetencao{
+			{Execucao: "e1", TenantID: "a", Regra: models.RetencaoJobs, Afetados: 2},
+			{Execucao: "e1", TenantID: "b", Regra: models.RetencaoJobs},
+			{Execucao: "e2", TenantID: "a", Regra: models.RetencaoEventos, Simulacao: true, Afetados: 5},
+		}))
+
+		execucoes, err := repo.ListarExecucoes("a", 10)
+		require.NoError(t, err)
+		require.Len(t, execucoes, 2)
+		assert.Equal(t, "e2", execucoes[0].Execucao)
+		assert.True(t, execucoes[0].Simulacao)
+		assert.Equal(t, int64(2), execucoes[1].Afetados)
+	})
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Gileno29/clientes-API/models"
+)
+
+// WebhookRepository acessa as assinaturas, que pertencem a um tenant. As operações de gestão recebem
+// o tenant dono; as usadas pelo dispatcher percorrem todos os tenants.
+type WebhookRepository interface {
+	Create(assinatura *models.WebhookAssinatura) error
+	FindByID(tenantID, id string) (*models.WebhookAssinatura, error)
+	Listar(tenantID string) ([]models.WebhookAssinatura, error)
+	Delete(tenantID, id string) error
+	ListarAtivas() ([]models.WebhookAssinatura, error)
+	// DistribuirEvento cria as entregas do evento para as assinaturas do tenant dele e o marca
+	// como publicado na mesma transação. Retorna false se outra instância já distribuiu o evento.
+	DistribuirEvento(evento *models.Evento, assinaturas []models.WebhookAssinatura) (bool, error)
+	// ReservarEntregas reserva as entregas pendentes cuja próxima tentativa já venceu,
+	// adiando a próxima tentativa pela duração informada para evitar entregas em duplicidade
+	ReservarEntregas(limite int, reserva time.Duration) ([]models.WebhookEntrega, error)
+	FindEvento(id uint) (*models.Evento, error)
+	RegistrarTentativa(entrega *models.WebhookEntrega, tentativa *models.WebhookTentativa) error
+	ListarEntregas(assinaturaID string, limite int) ([]models.WebhookEntrega, error)
+	ListarTentativas(entregaIDs []uint) ([]models.WebhookTentativa, error)
+	// ListarEntregasDosEventos retorna as entregas dos eventos, inclusive das assinaturas removidas
+	ListarEntregasDosEventos(eventoIDs []uint) ([]models.WebhookEntrega, error)
+}
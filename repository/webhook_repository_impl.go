@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Gileno29/clientes-API/cripto"
+	"github.com/Gileno29/clientes-API/models"
+	"gorm.io/gorm"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+	// cifrador decifra os eventos entregues; nil lê somente os eventos em texto puro
+	cifrador *cripto.Cifrador
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// NewWebhookRepositoryCifrado funciona como NewWebhookRepository, decifrando os eventos gravados
+// com a criptografia habilitada antes da entrega
+func NewWebhookRepositoryCifrado(db *gorm.DB, cifrador *cripto.Cifrador) WebhookRepository {
+	return &webhookRepository{db: db, cifrador: cifrador}
+}
+
+func (r *webhookRepository) Create(assinatura *models.WebhookAssinatura) error {
+	return r.db.Create(assinatura).Error
+}
+
+func (r *webhookRepository) FindByID(tenantID, id string) (*models.WebhookAssinatura, error) {
+	var assinatura models.WebhookAssinatura
+	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&assinatura).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assinatura, nil
+}
+
+func (r *webhookRepository) Listar(tenantID string) ([]models.WebhookAssinatura, error) {
+	var assinaturas []models.WebhookAssinatura
+	err := r.db.Where("tenant_id = ?", tenantID).Order("created_at ASC").Find(&assinaturas).Error
+	return assinaturas, err
+}
+
+// Delete remove a assinatura e encerra as entregas que ainda estavam pendentes
+func (r *webhookRepository) Delete(tenantID, id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var total int64
+		if err := tx.Model(&models.WebhookAssinatura{}).Where("tenant_id = ? AND id = ?", tenantID, id).Count(&total).Error; err != nil {
+			return err
+		}
+		// A assinatura de outro tenant é tratada como inexistente
+		if total == 0 {
+			return nil
+		}
+
+		err := tx.Model(&models.WebhookEntrega{}).
+			Where("assinatura_id = ? AND status = ?", id, models.EntregaPendente).
+			Update("status", models.EntregaFalhou).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&models.WebhookAssinatura{}).Error
+	})
+}
+
+func (r *webhookRepository) ListarAtivas() ([]models.WebhookAssinatura, error) {
+	var assinaturas []models.WebhookAssinatura
+	err := r.db.Where("ativo = ?", true).Find(&assinaturas).Error
+	return assinaturas, err
+}
+
+func (r *webhookRepository) DistribuirEvento(evento *models.Evento, assinaturas []models.WebhookAssinatura) (bool, error) {
+	distribuido := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Evento{}).
+			Where("id = ? AND publicado_em IS NULL", evento.ID).
+			Update("publicado_em", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		agora := time.Now()
+		for _, assinatura := range assinaturas {
+			if assinatura.TenantID != evento.TenantID || !assinatura.Recebe(evento.Tipo) {
+				continue
+			}
+			entrega := models.WebhookEntrega{
+				AssinaturaID:       assinatura.ID,
+				EventoID:           evento.ID,
+				Status:             models.EntregaPendente,
+				ProximaTentativaEm: agora,
+			}
+			if err := tx.Create(&entrega).Error; err != nil {
+				return err
+			}
+		}
+		distribuido = true
+		return nil
+	})
+	return distribuido, err
+}
+
+func (r *webhookRepository) ReservarEntregas(limite int, reserva time.Duration) ([]models.WebhookEntrega, error) {
+	var candidatas []models.WebhookEntrega
+	agora := time.Now()
+	err := r.db.Where("status = ? AND proxima_tentativa_em <= ?", models.EntregaPendente, agora).
+		Order("proxima_tentativa_em ASC").Limit(limite).Find(&candidatas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	reservadas := make([]models.WebhookEntrega, 0, len(candidatas))
+	for _, entrega := range candidatas {
+		// A reserva só vale se a entrega não tiver sido reservada por outra instância nesse meio tempo
+		res := r.db.Model(&models.WebhookEntrega{}).
+			Where("id = ? AND status = ? AND proxima_tentativa_em <= ?", entrega.ID, models.EntregaPendente, agora).
+			Update("proxima_tentativa_em", agora.Add(reserva))
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			reservadas = append(reservadas, entrega)
+		}
+	}
+	return reservadas, nil
+}
+
+func (r *webhookRepository) FindEvento(id uint) (*models.Evento, error) {
+	var evento models.Evento
+	err := r.db.Where("id = ?", id).First(&evento).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := abrirEvento(context.Background(), r.cifrador, &evento); err != nil {
+		return nil, err
+	}
+	return &evento, nil
+}
+
+func (r *webhookRepository) RegistrarTentativa(entrega *models.WebhookEntrega, tentativa *models.WebhookTentativa) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tentativa).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WebhookEntrega{}).Where("id = ?", entrega.ID).Updates(map[string]interface{}{
+			"status":               entrega.Status,
+			"tentativas":           entrega.Tentativas,
+			"proxima_tentativa_em": entrega.ProximaTentativaEm,
+			"entregue_em":          entrega.EntregueEm,
+		}).Error
+	})
+}
+
+func (r *webhookRepository) ListarEntregas(assinaturaID string, limite int) ([]models.WebhookEntrega, error) {
+	var entregas []models.WebhookEntrega
+	err := r.db.Where("assinatura_id = ?", assinaturaID).Order("id DESC").Limit(limite).Find(&entregas).Error
+	return entregas, err
+}
+
+func (r *webhookRepository) ListarTentativas(entregaIDs []uint) ([]models.WebhookTentativa, error) {
+	var tentativas []models.WebhookTentativa
+	if len(entregaIDs) == 0 {
+		return tentativas, nil
+	}
+	err := r.db.Where("entrega_id IN ?", entregaIDs).Order("id ASC").Find(&tentativas).Error
+	return tentativas, err
+}
+
+func (r *webhookRepository) ListarEntregasDosEventos(eventoIDs []uint) ([]models.WebhookEntrega, error) {
+	var entregas []models.WebhookEntrega
+	if len(eventoIDs) == 0 {
+		return entregas, nil
+	}
+	err := r.db.Where("evento_id IN ?", eventoIDs).Order("id ASC").Find(&entregas).Error
+	return entregas, err
+}
@@ -0,0 +1,186 @@
+// Package retencao aplica a política de retenção: a cada rodada, executada pelo agendador, apaga ou
+// anonimiza em cada tenant os dados que passaram do prazo de guarda, registrando cada regra aplicada
+// para auditoria.
+package retencao
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/metrics"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"gorm.io/gorm"
+)
+
+// tamanhoLote é a quantidade de clientes inativos lida do repositório a cada consulta
+const tamanhoLote = 500
+
+// Politica define o prazo de guarda de cada regra; zero desabilita a regra
+type Politica struct {
+	// ClientesRemovidos é o prazo, a partir da remoção, para apagar o histórico do cliente
+	ClientesRemovidos time.Duration
+	// ClientesInativos é o tempo sem alterações após o qual o cliente é anonimizado
+	ClientesInativos time.Duration
+	// Eventos é o prazo de guarda dos eventos de auditoria já entregues
+	Eventos time.Duration
+	// Jobs é o prazo de guarda dos jobs finalizados e dos seus resultados
+	Jobs time.Duration
+}
+
+// Habilitada indica se alguma regra foi configurada
+func (p Politica) Habilitada() bool {
+	return p.ClientesRemovidos > 0 || p.ClientesInativos > 0 || p.Eventos > 0 || p.Jobs > 0
+}
+
+// Executor aplica a política nos tenants informados
+type Executor struct {
+	clientes        repository.ClienteRepository
+	retencao        repository.RetencaoRepository
+	pseudonimizador *lgpd.Pseudonimizador
+	politica        Politica
+	tenants         []string
+
+	agora func() time.Time
+}
+
+// NewExecutor cria o executor. A anonimização dos clientes inativos usa o mesmo hash com sal dos
+// pedidos dos titulares e falha enquanto o sal não for configurado.
+func NewExecutor(clientes repository.ClienteRepository, retencao repository.RetencaoRepository, pseudonimizador *lgpd.Pseudonimizador, politica Politica, tenants []string) *Executor {
+	return &Executor{
+		clientes:        clientes,
+		retencao:        retencao,
+		pseudonimizador: pseudonimizador,
+		politica:        politica,
+		tenants:         tenants,
+		agora:           time.Now,
+	}
+}
+
+// Habilitado indica se alguma regra da política foi configurada
+func (e *Executor) Habilitado() bool {
+	return e.politica.Habilitada()
+}
+
+// Executar aplica a política em todos os tenants. A falha de uma regra é registrada e não impede
+// a aplicação das demais.
+func (e *Executor) Executar(ctx context.Context, simular bool) []models.ExecucaoRetencao {
+	execucao := gerarID()
+	var resultados []models.ExecucaoRetencao
+	for _, t := range e.tenants {
+		resultados = append(resultados, e.ExecutarTenant(ctx, execucao, t, simular)...)
+	}
+	metrics.RodadaRetencao(e.agora())
+	return resultados
+}
+
+// ExecutarTenant aplica a política no tenant e grava uma entrada de auditoria para cada regra
+// habilitada. Com simular, somente conta os registros que seriam alcançados.
+func (e *Executor) ExecutarTenant(ctx context.Context, execucao, tenantID string, simular bool) []models.ExecucaoRetencao {
+	if execucao == "" {
+		execucao = gerarID()
+	}
+	ctx = tenant.ComID(ctx, tenantID)
+
+	regras := []struct {
+		nome     string
+		prazo    time.Duration
+		executar func(antesDe time.Time) (int64, error)
+	}{
+		{models.RetencaoClientesRemovidos, e.politica.ClientesRemovidos, func(antesDe time.Time) (int64, error) {
+			return e.retencao.ExpurgarRemovidos(tenantID, antesDe, simular)
+		}},
+		{models.RetencaoClientesInativos, e.politica.ClientesInativos, func(antesDe time.Time) (int64, error) {
+			return e.anonimizarInativos(ctx, tenantID, antesDe, simular)
+		}},
+		{models.RetencaoEventos, e.politica.Eventos, func(antesDe time.Time) (int64, error) {
+			return e.retencao.ExpurgarEventos(tenantID, antesDe, simular)
+		}},
+		{models.RetencaoJobs, e.politica.Jobs, func(antesDe time.Time) (int64, error) {
+			return e.retencao.ExpurgarJobs(tenantID, antesDe, simular)
+		}},
+	}
+
+	resultados := []models.ExecucaoRetencao{}
+	for _, regra := range regras {
+		if regra.prazo <= 0 {
+			continue
+		}
+		inicio := e.agora()
+		resultado := models.ExecucaoRetencao{
+			Execucao:   execucao,
+			TenantID:   tenantID,
+			Regra:      regra.nome,
+			Simulacao:  simular,
+			Limite:     inicio.Add(-regra.prazo),
+			IniciadoEm: inicio,
+		}
+		afetados, err := regra.executar(resultado.Limite)
+		resultado.Afetados = afetados
+		resultado.FinalizadoEm = e.agora()
+		metrics.RegraRetencao(regra.nome, simular, afetados, err)
+
+		if err != nil {
+			resultado.Erro = err.Error()
+			slog.Error("Erro ao aplicar a regra de retenção", "tenant", tenantID, "regra", regra.nome, "afetados", afetados, "erro", err)
+		} else if afetados > 0 {
+			slog.Info("Regra de retenção aplicada", "tenant", tenantID, "regra", regra.nome, "simulacao", simular, "afetados", afetados)
+		}
+		resultados = append(resultados, resultado)
+	}
+
+	if err := e.retencao.RegistrarExecucoes(resultados); err != nil {
+		slog.Error("Erro ao registrar a auditoria da retenção", "tenant", tenantID, "execucao", execucao, "erro", err)
+	}
+	return resultados
+}
+
+// anonimizarInativos anonimiza em lotes os clientes sem alteração desde antesDe
+func (e *Executor) anonimizarInativos(ctx context.Context, tenantID string, antesDe time.Time, simular bool) (int64, error) {
+	if simular {
+		_, total, err := e.clientes.ListarInativos(ctx, antesDe, 1)
+		return total, err
+	}
+
+	var anonimizados int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return anonimizados, err
+		}
+		clientes, _, err := e.clientes.ListarInativos(ctx, antesDe, tamanhoLote)
+		if err != nil {
+			return anonimizados, err
+		}
+		for _, cliente := range clientes {
+			hash, err := e.pseudonimizador.Hash(tenantID, cliente.Documento)
+			if err != nil {
+				return anonimizados, err
+			}
+			// O cliente removido entre a listagem e a anonimização não precisa mais dela
+			_, err = e.clientes.Anonimizar(ctx, cliente.Documento, hash)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return anonimizados, err
+			}
+			anonimizados++
+		}
+		if len(clientes) < tamanhoLote {
+			return anonimizados, nil
+		}
+	}
+}
+
+// gerarID gera o identificador que agrupa as regras aplicadas em uma rodada
+func gerarID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
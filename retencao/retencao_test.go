@@ -0,0 +1,133 @@
+package retencao
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/lgpd"
+	"github.com/Gileno29/clientes-API/migrations"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var bancos atomic.Int64
+
+// setupDB cria um banco SQLite em memória exclusivo, com o esquema das migrações
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:retencao%d?mode=memory&cache=shared", bancos.Add(1))), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrador, err := migrations.NewMigrador(db)
+	require.NoError(t, err)
+	_, err = migrador.Subir(context.Background(), 0)
+	require.NoError(t, err)
+	return db
+}
+
+// removidoNoIntervalo lista também um cliente removido entre a listagem e a anonimização
+type removidoNoIntervalo struct {
+	repository.ClienteRepository
+}
+
+func (r removidoNoIntervalo) ListarInativos(ctx context.Context, antesDe time.Time, limite int) ([]models.Cliente, int64, error) {
+	clientes, total, err := r.ClienteRepository.ListarInativos(ctx, antesDe, limite)
+	return append(clientes, models.Cliente{Documento: "33000167000101"}), total + 1, err
+}
+
+func TestExecutor(t *testing.T) {
+	ctx := tenant.ComID(context.Background(), "a")
+	politica := Politica{ClientesInativos: time.Hour, Jobs: time.Hour}
+
+	// novo cria o executor com o relógio adiantado, de forma que todos os registros passaram do prazo
+	novo := func(t *testing.T, sal string) (*Executor, *gorm.DB) {
+		db := setupDB(t)
+		clientes := repository.NewClienteRepository(db)
+		require.NoError(t, clientes.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva", Blocklist: true}))
+		require.NoError(t, clientes.Create(ctx, &models.Cliente{Documento: "86405508838", RazaoSocial: "Maria Oliveira"}))
+		require.NoError(t, clientes.Create(tenant.ComID(context.Background(), "b"), &models.Cliente{Documento: "52998224725", RazaoSocial: "Outro tenant"}))
+		agora := time.Now()
+		require.NoError(t, db.Create(&models.Job{ID: "j1", TenantID: "a", Tipo: "exportacao", Status: models.JobConcluido, FinalizadoEm: &agora}).Error)
+
+		executor := NewExecutor(clientes, repository.NewRetencaoRepository(db), lgpd.NewPseudonimizador(sal), politica, []string{"a", "b"})
+		executor.agora = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		return executor, db
+	}
+	contar := func(db *gorm.DB, modelo interface{}) int64 {
+		var total int64
+		require.NoError(t, db.Model(modelo).Count(&total).Error)
+		return total
+	}
+
+	// Caso de sucesso: A simulação conta os registros sem alterá-los e fica na auditoria
+	t.Run("Simula a política", func(t *testing.T) {
+		executor, db := novo(t, "sal-dos-testes-lgpd")
+		resultados := executor.ExecutarTenant(context.Background(), "", "a", true)
+		require.Len(t, resultados, 2)
+		assert.Equal(t, models.RetencaoClientesInativos, resultados[0].Regra)
+		assert.Equal(t, int64(2), resultados[0].Afetados)
+		assert.Equal(t, models.RetencaoJobs, resultados[1].Regra)
+		assert.Equal(t, int64(1), resultados[1].Afetados)
+		assert.Equal(t, resultados[0].Execucao, resultados[1].Execucao)
+
+		assert.Equal(t, int64(3), contar(db, &models.Cliente{}))
+		assert.Equal(t, int64(1), contar(db, &models.Job{}))
+		var auditoria []models.ExecucaoRetencao
+		require.NoError(t, db.Find(&auditoria).Error)
+		require.Len(t, auditoria, 2)
+		assert.True(t, auditoria[0].Simulacao)
+	})
+
+	// Caso de sucesso: A rodada anonimiza os inativos e apaga os jobs em todos os tenants
+	t.Run("Aplica a política em todos os tenants", func(t *testing.T) {
+		executor, db := novo(t, "sal-dos-testes-lgpd")
+		resultados := executor.Executar(context.Background(), false)
+		require.Len(t, resultados, 4)
+		for _, resultado := range resultados {
+			assert.Empty(t, resultado.Erro)
+		}
+
+		assert.Zero(t, contar(db, &models.Cliente{}))
+		assert.Zero(t, contar(db, &models.Job{}))
+		hash, _ := lgpd.NewPseudonimizador("sal-dos-testes-lgpd").Hash("a", "52998224725")
+		anonimizado, err := repository.NewClienteRepository(db).FindAnonimizado(ctx, hash)
+		require.NoError(t, err)
+		assert.True(t, anonimizado.Blocklist, "A blocklist continua valendo pelo hash")
+		assert.Equal(t, int64(4), contar(db, &models.ExecucaoRetencao{}))
+	})
+
+	// Caso de sucesso: O cliente removido antes da anonimização não é contado
+	t.Run("Conta somente os clientes anonimizados", func(t *testing.T) {
+		executor, db := novo(t, "sal-dos-testes-lgpd")
+		executor.clientes = removidoNoIntervalo{executor.clientes}
+		resultados := executor.ExecutarTenant(context.Background(), "", "a", false)
+		require.Len(t, resultados, 2)
+		assert.Empty(t, resultados[0].Erro)
+		assert.Equal(t, int64(2), resultados[0].Afetados)
+		assert.Equal(t, int64(2), contar(db, &models.ClienteAnonimizado{}))
+	})
+
+	// Caso de erro: Sem o sal a anonimização falha e as demais regras são aplicadas
+	t.Run("Registra a falha de uma regra", func(t *testing.T) {
+		executor, db := novo(t, "")
+		resultados := executor.ExecutarTenant(context.Background(), "", "a", false)
+		require.Len(t, resultados, 2)
+		assert.Contains(t, resultados[0].Erro, lgpd.ErrSemSal.Error())
+		assert.Empty(t, resultados[1].Erro)
+
+		assert.Equal(t, int64(3), contar(db, &models.Cliente{}))
+		assert.Zero(t, contar(db, &models.Job{}))
+		var auditoria models.ExecucaoRetencao
+		require.NoError(t, db.Where("regra = ?", models.RetencaoClientesInativos).First(&auditoria).Error)
+		assert.NotEmpty(t, auditoria.Erro)
+	})
+}
@@ -0,0 +1,142 @@
+package servidor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gileno29/clientes-API/config"
+	"github.com/Gileno29/clientes-API/health"
+)
+
+// Finalizador libera um recurso no encerramento, depois que as requisições foram drenadas
+type Finalizador struct {
+	Nome string
+	Fn   func(ctx context.Context) error
+}
+
+// Servidor atende as requisições HTTP com timeouts e coordena o encerramento gracioso
+type Servidor struct {
+	// HTTP é o servidor subjacente; RegisterOnShutdown pode ser usado para finalizar conexões longas
+	HTTP *http.Server
+
+	// TempoEncerramento é o prazo para drenar as requisições
+	TempoEncerramento time.Duration
+	// TempoFinalizador é o prazo de cada finalizador. Cada um recebe um contexto próprio, para que
+	// uma drenagem longa ou um finalizador preso não impeçam os seguintes de liberar os recursos.
+	TempoFinalizador time.Duration
+	// AtrasoEncerramento é a espera, com a prontidão já falhando, antes de parar de aceitar conexões
+	AtrasoEncerramento time.Duration
+
+	registro      *health.Registro
+	finalizadores []Finalizador
+}
+
+func NewServidor(cfg config.Servidor, handler http.Handler, registro *health.Registro) *Servidor {
+	return &Servidor{
+		HTTP: &http.Server{
+			Addr:              ":" + strconv.Itoa(cfg.Porta),
+			Handler:           handler,
+			ReadHeaderTimeout: time.Duration(cfg.TempoLeituraCabecalho),
+			ReadTimeout:       time.Duration(cfg.TempoLeitura),
+			WriteTimeout:      time.Duration(cfg.TempoEscrita),
+			IdleTimeout:       time.Duration(cfg.TempoOcioso),
+		},
+		TempoEncerramento:  time.Duration(cfg.TempoEncerramento),
+		TempoFinalizador:   time.Duration(cfg.TempoFinalizador),
+		AtrasoEncerramento: time.Duration(cfg.AtrasoEncerramento),
+		registro:           registro,
+	}
+}
+
+// AoEncerrar registra um finalizador. Os finalizadores são executados na ordem de registro,
+// depois que o servidor deixa de aceitar conexões e as requisições em andamento terminam.
+func (s *Servidor) AoEncerrar(nome string, fn func(ctx context.Context) error) {
+	s.finalizadores = append(s.finalizadores, Finalizador{Nome: nome, Fn: fn})
+}
+
+// Executar escuta na porta configurada até que ctx seja cancelado e então encerra o servidor
+func (s *Servidor) Executar(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.HTTP.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Servir(ctx, listener)
+}
+
+// Servir atende as conexões do listener até que ctx seja cancelado e então encerra o servidor
+func (s *Servidor) Servir(ctx context.Context, listener net.Listener) error {
+	erros := make(chan error, 1)
+	go func() {
+		erros <- s.HTTP.Serve(listener)
+	}()
+	slog.Info("Servidor iniciado", "endereco", listener.Addr().String())
+
+	select {
+	case err := <-erros:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	return s.Encerrar()
+}
+
+// Encerrar marca a aplicação como não pronta, para de aceitar conexões, aguarda as requisições
+// em andamento dentro do TempoEncerramento e executa os finalizadores, cada um dentro do
+// TempoFinalizador
+func (s *Servidor) Encerrar() error {
+	inicio := time.Now()
+	slog.Info("Encerramento iniciado", "prazo", s.TempoEncerramento.String())
+	if s.registro != nil {
+		s.registro.IniciarEncerramento()
+	}
+	if s.AtrasoEncerramento > 0 {
+		time.Sleep(s.AtrasoEncerramento)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.TempoEncerramento)
+	defer cancel()
+
+	var erros []error
+	if err := s.HTTP.Shutdown(ctx); err != nil {
+		// Prazo esgotado: as conexões restantes são fechadas à força
+		s.HTTP.Close()
+		erros = append(erros, fmt.Errorf("requisições não concluídas no prazo: %w", err))
+	}
+
+	for _, finalizador := range s.finalizadores {
+		if err := s.executar(finalizador); err != nil {
+			slog.Error("Erro ao finalizar", "recurso", finalizador.Nome, "erro", err)
+			erros = append(erros, fmt.Errorf("%s: %w", finalizador.Nome, err))
+		}
+	}
+
+	slog.Info("Encerramento concluído", "duracao_ms", time.Since(inicio).Milliseconds())
+	return errors.Join(erros...)
+}
+
+// executar roda o finalizador com um prazo próprio, respeitado mesmo que ele ignore o contexto
+func (s *Servidor) executar(finalizador Finalizador) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.TempoFinalizador)
+	defer cancel()
+
+	resultado := make(chan error, 1)
+	go func() {
+		resultado <- finalizador.Fn(ctx)
+	}()
+
+	select {
+	case err := <-resultado:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
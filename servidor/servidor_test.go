@@ -0,0 +1,164 @@
+package servidor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/config"
+	"github.com/Gileno29/clientes-API/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func novoServidor(handler http.Handler, registro *health.Registro, prazo time.Duration) (*Servidor, net.Listener) {
+	cfg := config.Padrao().Servidor
+	cfg.TempoEncerramento = config.Duracao(prazo)
+	cfg.TempoFinalizador = config.Duracao(prazo)
+	srv := NewServidor(cfg, handler, registro)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	return srv, listener
+}
+
+func TestEncerramento(t *testing.T) {
+	// Caso de sucesso: Requisições em andamento terminam antes dos finalizadores
+	t.Run("Drena as requisições e executa os finalizadores", func(t *testing.T) {
+		var mu sync.Mutex
+		var ordem []string
+		registrar := func(etapa string) {
+			mu.Lock()
+			defer mu.Unlock()
+			ordem = append(ordem, etapa)
+		}
+
+		iniciada := make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/lento", func(w http.ResponseWriter, r *http.Request) {
+			close(iniciada)
+			time.Sleep(300 * time.Millisecond)
+			registrar("requisicao")
+			w.WriteHeader(http.StatusOK)
+		})
+
+		registro := health.NewRegistro(time.Second)
+		srv, listener := novoServidor(mux, registro, 5*time.Second)
+		srv.AoEncerrar("jobs", func(context.Context) error {
+			registrar("jobs")
+			return nil
+		})
+		srv.AoEncerrar("banco de dados", func(context.Context) error {
+			registrar("banco de dados")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		encerrado := make(chan error, 1)
+		go func() { encerrado <- srv.Servir(ctx, listener) }()
+
+		url := "http://" + listener.Addr().String()
+		status := make(chan int, 1)
+		go func() {
+			resp, err := http.Get(url + "/lento")
+			if err != nil {
+				status <- 0
+				return
+			}
+			resp.Body.Close()
+			status <- resp.StatusCode
+		}()
+
+		<-iniciada
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			return registro.Verificar(context.Background()).Status == health.StatusFalha
+		}, time.Second, 10*time.Millisecond, "A prontidão deve falhar durante o encerramento")
+
+		assert.Equal(t, http.StatusOK, <-status, "A requisição em andamento deve ser concluída")
+		assert.NoError(t, <-encerrado)
+		assert.Equal(t, []string{"requisicao", "jobs", "banco de dados"}, ordem)
+
+		_, err := http.Get(url + "/lento")
+		assert.Error(t, err, "Novas conexões devem ser recusadas")
+	})
+
+	// Caso de erro: Prazo esgotado com requisição e finalizador presos
+	t.Run("Respeita o prazo de encerramento", func(t *testing.T) {
+		iniciada := make(chan struct{})
+		liberar := make(chan struct{})
+		defer close(liberar)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(iniciada)
+			<-liberar
+		})
+
+		srv, listener := novoServidor(handler, nil, 200*time.Millisecond)
+		srv.AoEncerrar("webhooks", func(context.Context) error {
+			<-liberar
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		encerrado := make(chan error, 1)
+		go func() { encerrado <- srv.Servir(ctx, listener) }()
+		go http.Get("http://" + listener.Addr().String())
+
+		<-iniciada
+		inicio := time.Now()
+		cancel()
+
+		err := <-encerrado
+		assert.Less(t, time.Since(inicio), 2*time.Second)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "requisições não concluídas no prazo")
+			assert.Contains(t, err.Error(), "webhooks")
+		}
+	})
+
+	// Caso de erro: A drenagem e um finalizador esgotam os prazos, mas o banco ainda é fechado
+	t.Run("Executa os finalizadores após a drenagem esgotar o prazo", func(t *testing.T) {
+		iniciada := make(chan struct{})
+		liberar := make(chan struct{})
+		defer close(liberar)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(iniciada)
+			<-liberar
+		})
+
+		srv, listener := novoServidor(handler, nil, 200*time.Millisecond)
+		srv.AoEncerrar("jobs", func(context.Context) error {
+			<-liberar
+			return nil
+		})
+		var contexto error
+		fechado := false
+		srv.AoEncerrar("banco de dados", func(ctx context.Context) error {
+			contexto = ctx.Err()
+			fechado = true
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		encerrado := make(chan error, 1)
+		go func() { encerrado <- srv.Servir(ctx, listener) }()
+		go http.Get("http://" + listener.Addr().String())
+
+		<-iniciada
+		cancel()
+
+		err := <-encerrado
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "requisições não concluídas no prazo")
+			assert.Contains(t, err.Error(), "jobs")
+			assert.NotContains(t, err.Error(), "banco de dados")
+		}
+		assert.True(t, fechado, "O pool deve ser fechado mesmo após os prazos anteriores")
+		assert.NoError(t, contexto, "O finalizador deve receber um prazo próprio")
+	})
+}
@@ -0,0 +1,152 @@
+package tenant
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// CabecalhoAPIKey identifica o tenant pela chave de API cadastrada para ele
+	CabecalhoAPIKey = "X-API-Key"
+	// CabecalhoTenant informa o tenant diretamente; só é aceito atrás de um gateway confiável
+	CabecalhoTenant = "X-Tenant-ID"
+	// ClaimTenant é a claim do JWT que contém o tenant
+	ClaimTenant = "tenant_id"
+)
+
+var (
+	ErrNaoIdentificado = errors.New("tenant não identificado")
+	ErrCredencial      = errors.New("credencial inválida")
+	ErrDesconhecido    = errors.New("tenant desconhecido")
+)
+
+// Resolvedor identifica o tenant de uma requisição pela chave de API, pelo JWT no cabeçalho
+// Authorization ou, quando permitido, pelo cabeçalho X-Tenant-ID, nessa ordem
+type Resolvedor struct {
+	tenants map[string]Tenant
+	// chaves associa o hash SHA-256 de cada chave de API ao tenant
+	chaves           map[[sha256.Size]byte]string
+	segredoJWT       []byte
+	aceitarCabecalho bool
+}
+
+// NewResolvedor cria o resolvedor dos tenants informados. Sem tenants a multi-tenancy fica
+// desligada e todas as requisições pertencem ao tenant Padrao. chaves associa as chaves de API
+// ao id do tenant; segredoJWT vazio desativa o JWT.
+func NewResolvedor(tenants []Tenant, chaves map[string]string, segredoJWT string, aceitarCabecalho bool) *Resolvedor {
+	r := &Resolvedor{
+		tenants:          map[string]Tenant{},
+		chaves:           map[[sha256.Size]byte]string{},
+		segredoJWT:       []byte(segredoJWT),
+		aceitarCabecalho: aceitarCabecalho,
+	}
+	for _, t := range tenants {
+		r.tenants[t.ID] = t
+	}
+	for chave, id := range chaves {
+		r.chaves[sha256.Sum256([]byte(chave))] = id
+	}
+	return r
+}
+
+// Habilitado indica se há tenants configurados
+func (r *Resolvedor) Habilitado() bool {
+	return len(r.tenants) > 0
+}
+
+// IDs retorna os tenants conhecidos, ou somente Padrao com a multi-tenancy desligada
+func (r *Resolvedor) IDs() []string {
+	if !r.Habilitado() {
+		return []string{Padrao}
+	}
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Resolver retorna o tenant da requisição. Uma credencial informada e inválida é recusada mesmo
+// que outra forma de identificação esteja presente.
+func (r *Resolvedor) Resolver(req *http.Request) (Tenant, error) {
+	return r.ResolverCabecalhos(req.Header.Get)
+}
+
+// ResolverCabecalhos é o Resolver de outros protocolos, como os metadados do gRPC. cabecalho
+// retorna o valor do cabecalho pelo nome, vazio quando ausente.
+func (r *Resolvedor) ResolverCabecalhos(cabecalho func(nome string) string) (Tenant, error) {
+	if !r.Habilitado() {
+		return Tenant{ID: Padrao}, nil
+	}
+
+	if chave := cabecalho(CabecalhoAPIKey); chave != "" {
+		id, ok := r.chaves[sha256.Sum256([]byte(chave))]
+		if !ok {
+			return Tenant{}, ErrCredencial
+		}
+		t, err := r.buscar(id)
+		if err != nil {
+			return Tenant{}, err
+		}
+		t.Sujeito = IDChave(chave)
+		return t, nil
+	}
+
+	if autorizacao := cabecalho("Authorization"); len(r.segredoJWT) > 0 && autorizacao != "" {
+		token, ok := strings.CutPrefix(autorizacao, "Bearer ")
+		if !ok {
+			return Tenant{}, ErrCredencial
+		}
+		id, sujeito, err := r.lerJWT(token)
+		if err != nil {
+			return Tenant{}, err
+		}
+		t, err := r.buscar(id)
+		if err != nil {
+			return Tenant{}, err
+		}
+		t.Sujeito = sujeito
+		return t, nil
+	}
+
+	if id := cabecalho(CabecalhoTenant); r.aceitarCabecalho && id != "" {
+		return r.buscar(id)
+	}
+	return Tenant{}, ErrNaoIdentificado
+}
+
+// lerJWT valida a assinatura HS256 e a expiração do token e retorna as claims do tenant e do sujeito
+func (r *Resolvedor) lerJWT(token string) (string, string, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return r.segredoJWT, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return "", "", ErrCredencial
+	}
+	id, _ := claims[ClaimTenant].(string)
+	if id == "" {
+		return "", "", ErrCredencial
+	}
+	sujeito, _ := claims.GetSubject()
+	return id, sujeito, nil
+}
+
+// IDChave identifica a chave de API nos logs pelo início do hash SHA-256 dela, sem expor a chave
+func IDChave(chave string) string {
+	hash := sha256.Sum256([]byte(chave))
+	return "chave:" + hex.EncodeToString(hash[:4])
+}
+
+func (r *Resolvedor) buscar(id string) (Tenant, error) {
+	t, ok := r.tenants[id]
+	if !ok {
+		return Tenant{}, ErrDesconhecido
+	}
+	return t, nil
+}
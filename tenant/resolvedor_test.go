@@ -0,0 +1,113 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assinar(t *testing.T, metodo jwt.SigningMethod, segredo string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(metodo, claims).SignedString([]byte(segredo))
+	require.NoError(t, err)
+	return token
+}
+
+func TestResolvedor(t *testing.T) {
+	tenants := []Tenant{{ID: "a", TimeoutConsulta: time.Second}, {ID: "b"}}
+	resolvedor := NewResolvedor(tenants, map[string]string{"chave-a": "a", "chave-c": "c"}, "segredo", false)
+
+	requisicao := func(cabecalhos map[string]string) *http.Request {
+		req, _ := http.NewRequest("GET", "/clientes", nil)
+		for chave, valor := range cabecalhos {
+			req.Header.Set(chave, valor)
+		}
+		return req
+	}
+
+	// Caso de sucesso: Multi-tenancy desligada atribui o tenant padrão
+	t.Run("Usa o tenant padrão sem tenants configurados", func(t *testing.T) {
+		desligado := NewResolvedor(nil, nil, "", false)
+		tenant, err := desligado.Resolver(requisicao(map[string]string{CabecalhoTenant: "a"}))
+		require.NoError(t, err)
+		assert.Equal(t, Padrao, tenant.ID)
+		assert.Equal(t, []string{Padrao}, desligado.IDs())
+		assert.ElementsMatch(t, []string{"a", "b"}, resolvedor.IDs())
+	})
+
+	// Caso de sucesso: Chave de API com a configuração do tenant
+	t.Run("Identifica pela chave de API", func(t *testing.T) {
+		tenant, err := resolvedor.Resolver(requisicao(map[string]string{CabecalhoAPIKey: "chave-a"}))
+		require.NoError(t, err)
+		assert.Equal(t, "a", tenant.ID)
+		assert.Equal(t, time.Second, tenant.TimeoutConsulta)
+		assert.Equal(t, IDChave("chave-a"), tenant.Sujeito)
+
+		_, err = resolvedor.Resolver(requisicao(map[string]string{CabecalhoAPIKey: "chave-x"}))
+		assert.ErrorIs(t, err, ErrCredencial)
+		_, err = resolvedor.Resolver(requisicao(map[string]string{CabecalhoAPIKey: "chave-c"}))
+		assert.ErrorIs(t, err, ErrDesconhecido, "A chave aponta para um tenant não configurado")
+	})
+
+	// Caso de sucesso: JWT assinado com o segredo configurado
+	t.Run("Identifica pelo JWT", func(t *testing.T) {
+		token := assinar(t, jwt.SigningMethodHS256, "segredo", jwt.MapClaims{ClaimTenant: "b", "sub": "usuario", "exp": time.Now().Add(time.Minute).Unix()})
+		tenant, err := resolvedor.Resolver(requisicao(map[string]string{"Authorization": "Bearer " + token}))
+		require.NoError(t, err)
+		assert.Equal(t, "b", tenant.ID)
+		assert.Equal(t, "usuario", tenant.Sujeito)
+	})
+
+	// Caso de erro: JWT com assinatura, algoritmo, validade ou claim inválidos
+	t.Run("Recusa JWT inválido", func(t *testing.T) {
+		tokens := map[string]string{
+			"segredo errado": assinar(t, jwt.SigningMethodHS256, "outro", jwt.MapClaims{ClaimTenant: "a"}),
+			"algoritmo":      assinar(t, jwt.SigningMethodHS512, "segredo", jwt.MapClaims{ClaimTenant: "a"}),
+			"expirado":       assinar(t, jwt.SigningMethodHS256, "segredo", jwt.MapClaims{ClaimTenant: "a", "exp": time.Now().Add(-time.Minute).Unix()}),
+			"sem claim":      assinar(t, jwt.SigningMethodHS256, "segredo", jwt.MapClaims{"sub": "usuario"}),
+		}
+		for caso, token := range tokens {
+			_, err := resolvedor.Resolver(requisicao(map[string]string{"Authorization": "Bearer " + token}))
+			assert.ErrorIs(t, err, ErrCredencial, caso)
+		}
+		_, err := resolvedor.Resolver(requisicao(map[string]string{"Authorization": "Basic dXNlcjpzZW5oYQ=="}))
+		assert.ErrorIs(t, err, ErrCredencial)
+	})
+
+	// Caso de erro: Credencial inválida não cai para o cabeçalho X-Tenant-ID
+	t.Run("Aceita o cabeçalho somente quando configurado", func(t *testing.T) {
+		_, err := resolvedor.Resolver(requisicao(map[string]string{CabecalhoTenant: "a"}))
+		assert.ErrorIs(t, err, ErrNaoIdentificado)
+
+		confiavel := NewResolvedor(tenants, map[string]string{"chave-a": "a"}, "", true)
+		tenant, err := confiavel.Resolver(requisicao(map[string]string{CabecalhoTenant: "b"}))
+		require.NoError(t, err)
+		assert.Equal(t, "b", tenant.ID)
+		_, err = confiavel.Resolver(requisicao(map[string]string{CabecalhoTenant: "c"}))
+		assert.ErrorIs(t, err, ErrDesconhecido)
+		_, err = confiavel.Resolver(requisicao(map[string]string{CabecalhoAPIKey: "chave-x", CabecalhoTenant: "b"}))
+		assert.ErrorIs(t, err, ErrCredencial)
+	})
+}
+
+func TestContexto(t *testing.T) {
+	// Caso de erro: Contexto sem tenant
+	_, err := ID(context.Background())
+	assert.ErrorIs(t, err, ErrSemTenant)
+	_, err = ID(ComID(context.Background(), ""))
+	assert.ErrorIs(t, err, ErrSemTenant)
+
+	// Caso de sucesso: Tenant associado ao contexto
+	id, err := ID(ComID(context.Background(), "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", id)
+
+	assert.True(t, IDValido("empresa-1"))
+	assert.False(t, IDValido("Empresa"))
+	assert.False(t, IDValido(""))
+}
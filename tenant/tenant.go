@@ -0,0 +1,64 @@
+// Package tenant identifica a unidade de negócio dona de cada requisição. Os repositórios leem o
+// tenant do contexto e recusam operações sem ele, de forma que um tenant nunca enxerga ou altera
+// os dados de outro.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+)
+
+// Padrao é o tenant de todas as requisições quando a multi-tenancy não está configurada e o
+// dono dos dados existentes antes dela
+const Padrao = "padrao"
+
+// ErrSemTenant é retornado pelos repositórios quando o contexto não identifica o tenant
+var ErrSemTenant = errors.New("tenant não informado no contexto")
+
+// formatoID restringe os identificadores ao que pode ir em cabeçalhos, chaves de cache e logs
+var formatoID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Tenant é a unidade de negócio e a configuração própria dela. Prazos zerados usam os padrões da API.
+type Tenant struct {
+	ID              string
+	TimeoutConsulta time.Duration
+	TimeoutEscrita  time.Duration
+	TimeoutLote     time.Duration
+	// Sujeito identifica a credencial que resolveu o tenant da requisição: a claim sub do JWT ou o
+	// id da chave de API. Fica vazio quando o tenant vem do cabeçalho X-Tenant-ID.
+	Sujeito string
+}
+
+type chaveContexto struct{}
+
+// ComTenant retorna um contexto que identifica o tenant
+func ComTenant(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, chaveContexto{}, t)
+}
+
+// ComID é um atalho para ComTenant quando só o identificador é conhecido, como nos jobs
+func ComID(ctx context.Context, id string) context.Context {
+	return ComTenant(ctx, Tenant{ID: id})
+}
+
+// DoContexto retorna o tenant associado ao contexto
+func DoContexto(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(chaveContexto{}).(Tenant)
+	return t, ok && t.ID != ""
+}
+
+// ID retorna o identificador do tenant do contexto ou ErrSemTenant
+func ID(ctx context.Context) (string, error) {
+	t, ok := DoContexto(ctx)
+	if !ok {
+		return "", ErrSemTenant
+	}
+	return t.ID, nil
+}
+
+// IDValido indica se o identificador pode ser usado como tenant
+func IDValido(id string) bool {
+	return formatoID.MatchString(id)
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const chaveSpan = "tracing:span"
+
+// spanConsulta guarda o contexto anterior para restaurá-lo ao fim do comando, já que o mesmo
+// statement pode ser reutilizado, como no COUNT seguido da consulta da página
+type spanConsulta struct {
+	span     trace.Span
+	anterior context.Context
+}
+
+// GormPlugin cria um span para cada comando executado pelo GORM, filho do span presente no
+// contexto da consulta. O SQL é registrado com os placeholders, sem os valores dos parâmetros.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:antes_create", iniciarSpan("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:depois_create", finalizarSpan),
+		callbacks.Query().Before("gorm:query").Register("tracing:antes_query", iniciarSpan("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:depois_query", finalizarSpan),
+		callbacks.Update().Before("gorm:update").Register("tracing:antes_update", iniciarSpan("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:depois_update", finalizarSpan),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:antes_delete", iniciarSpan("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:depois_delete", finalizarSpan),
+		callbacks.Row().Before("gorm:row").Register("tracing:antes_row", iniciarSpan("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:depois_row", finalizarSpan),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:antes_raw", iniciarSpan("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:depois_raw", finalizarSpan),
+	)
+}
+
+func iniciarSpan(operacao string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := Tracer().Start(db.Statement.Context, "gorm."+operacao,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(db.Dialector.Name()),
+				semconv.DBOperationName(operacao),
+			),
+		)
+		db.InstanceSet(chaveSpan, spanConsulta{span: span, anterior: db.Statement.Context})
+		db.Statement.Context = ctx
+	}
+}
+
+func finalizarSpan(db *gorm.DB) {
+	valor, ok := db.InstanceGet(chaveSpan)
+	if !ok {
+		return
+	}
+	consulta := valor.(spanConsulta)
+	db.Statement.Context = consulta.anterior
+	span := consulta.span
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+// FinalizarSpan registra o erro da operação no span, exceto registro não encontrado, e o encerra
+func FinalizarSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExportadorNenhum = "none"
+	ExportadorStdout = "stdout"
+	ExportadorOTLP   = "otlp"
+
+	// NomeServico é o service.name usado quando OTEL_SERVICE_NAME não é definido
+	NomeServico = "clientes-api"
+
+	nomeTracer = "github.com/Gileno29/clientes-API"
+)
+
+// Configurar instala o provedor de traces com o exportador informado e a propagação W3C
+// (traceparent e baggage). O endpoint do OTLP segue as variáveis OTEL_EXPORTER_OTLP_*.
+// A função retornada descarrega os spans pendentes e deve ser chamada no encerramento.
+func Configurar(ctx context.Context, exportador string, saida io.Writer) (func(context.Context) error, error) {
+	// A propagação é instalada mesmo sem exportador para que o traceparent recebido seja repassado
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(exportador) {
+	case "", ExportadorNenhum:
+		return func(context.Context) error { return nil }, nil
+	case ExportadorStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(saida))
+	case ExportadorOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("exportador de traces inválido: %s", exportador)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	recurso, err := resource.Merge(
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(NomeServico)),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(recurso),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer retorna o tracer da aplicação a partir do provedor global
+func Tracer() trace.Tracer {
+	return otel.Tracer(nomeTracer)
+}
+
+// TraceID retorna o id do trace ativo no contexto ou vazio quando não há trace
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
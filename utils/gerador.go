@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// GerarCPF gera um CPF válido, sem formatação, para uso em testes
+func GerarCPF(r *rand.Rand) string {
+	base := digitosAleatorios(r, 9)
+	for todosDigitosIguais(base) {
+		base = digitosAleatorios(r, 9)
+	}
+	base += strconv.Itoa(calcularDigitoVerificador(base, 10))
+	return base + strconv.Itoa(calcularDigitoVerificador(base, 11))
+}
+
+// GerarCNPJ gera o CNPJ válido de uma matriz (filial 0001), sem formatação, para uso em testes
+func GerarCNPJ(r *rand.Rand) string {
+	base := digitosAleatorios(r, 8) + "0001"
+	base += strconv.Itoa(calcularDigitoVerificadorCNPJ(base, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}))
+	return base + strconv.Itoa(calcularDigitoVerificadorCNPJ(base, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}))
+}
+
+// FormatarDocumento aplica a máscara do CPF (000.000.000-00) ou do CNPJ (00.000.000/0000-00).
+// Documentos com outra quantidade de dígitos são retornados sem alteração.
+func FormatarDocumento(documento string) string {
+	numero := ClearNumber(documento)
+	switch len(numero) {
+	case 11:
+		return numero[:3] + "." + numero[3:6] + "." + numero[6:9] + "-" + numero[9:]
+	case 14:
+		return numero[:2] + "." + numero[2:5] + "." + numero[5:8] + "/" + numero[8:12] + "-" + numero[12:]
+	}
+	return documento
+}
+
+func digitosAleatorios(r *rand.Rand, quantidade int) string {
+	var digitos strings.Builder
+	for i := 0; i < quantidade; i++ {
+		digitos.WriteByte(byte('0' + r.IntN(10)))
+	}
+	return digitos.String()
+}
@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrTesteFalhou indica que uma operação "test" de um JSON Patch não confere com o documento
+var ErrTesteFalhou = errors.New("operação test não confere com o documento")
+
+// referencias:
+// JSON Merge Patch - https://www.rfc-editor.org/rfc/rfc7396
+// JSON Patch       - https://www.rfc-editor.org/rfc/rfc6902
+// JSON Pointer     - https://www.rfc-editor.org/rfc/rfc6901
+
+// AplicarMergePatch aplica um JSON Merge Patch (RFC 7396) ao documento informado
+func AplicarMergePatch(documento, patch []byte) ([]byte, error) {
+	var alvo interface{}
+	if err := json.Unmarshal(documento, &alvo); err != nil {
+		return nil, fmt.Errorf("documento inválido: %w", err)
+	}
+
+	var mudancas interface{}
+	if err := json.Unmarshal(patch, &mudancas); err != nil {
+		return nil, fmt.Errorf("patch inválido: %w", err)
+	}
+
+	return json.Marshal(mesclar(alvo, mudancas))
+}
+
+func mesclar(alvo, patch interface{}) interface{} {
+	patchObjeto, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	alvoObjeto, ok := alvo.(map[string]interface{})
+	if !ok {
+		alvoObjeto = map[string]interface{}{}
+	}
+
+	for chave, valor := range patchObjeto {
+		if valor == nil {
+			delete(alvoObjeto, chave)
+			continue
+		}
+		alvoObjeto[chave] = mesclar(alvoObjeto[chave], valor)
+	}
+	return alvoObjeto
+}
+
+type operacaoPatch struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// AplicarJSONPatch aplica uma lista de operações JSON Patch (RFC 6902) ao documento informado.
+// As operações são aplicadas em sequência e, se alguma falhar, nenhuma alteração é retornada.
+func AplicarJSONPatch(documento, patch []byte) ([]byte, error) {
+	var alvo interface{}
+	if err := json.Unmarshal(documento, &alvo); err != nil {
+		return nil, fmt.Errorf("documento inválido: %w", err)
+	}
+
+	var operacoes []operacaoPatch
+	if err := json.Unmarshal(patch, &operacoes); err != nil {
+		return nil, fmt.Errorf("patch inválido: %w", err)
+	}
+
+	for i, op := range operacoes {
+		var err error
+		alvo, err = aplicarOperacao(alvo, op)
+		if err != nil {
+			return nil, fmt.Errorf("operação %d (%s): %w", i, op.Op, err)
+		}
+	}
+
+	return json.Marshal(alvo)
+}
+
+func aplicarOperacao(alvo interface{}, op operacaoPatch) (interface{}, error) {
+	if op.Path == nil {
+		return nil, errors.New("campo 'path' obrigatório")
+	}
+	caminho, err := parsePonteiro(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("campo 'value' obrigatório")
+		}
+		var valor interface{}
+		if err := json.Unmarshal(*op.Value, &valor); err != nil {
+			return nil, fmt.Errorf("valor inválido: %w", err)
+		}
+		switch op.Op {
+		case "add":
+			return adicionar(alvo, caminho, valor)
+		case "replace":
+			if _, err := obter(alvo, caminho); err != nil {
+				return nil, err
+			}
+			if alvo, err = remover(alvo, caminho); err != nil {
+				return nil, err
+			}
+			return adicionar(alvo, caminho, valor)
+		default:
+			atual, err := obter(alvo, caminho)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(atual, valor) {
+				return nil, ErrTesteFalhou
+			}
+			return alvo, nil
+		}
+	case "remove":
+		return remover(alvo, caminho)
+	case "move", "copy":
+		if op.From == nil {
+			return nil, errors.New("campo 'from' obrigatório")
+		}
+		origem, err := parsePonteiro(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		valor, err := obter(alvo, origem)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(*op.Path+"/", *op.From+"/") && *op.Path != *op.From {
+				return nil, errors.New("não é possível mover um valor para dentro dele mesmo")
+			}
+			if alvo, err = remover(alvo, origem); err != nil {
+				return nil, err
+			}
+		} else {
+			valor = copiarValor(valor)
+		}
+		return adicionar(alvo, caminho, valor)
+	default:
+		return nil, fmt.Errorf("operação desconhecida: %q", op.Op)
+	}
+}
+
+func parsePonteiro(ponteiro string) ([]string, error) {
+	if ponteiro == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ponteiro, "/") {
+		return nil, fmt.Errorf("caminho inválido: %q", ponteiro)
+	}
+	partes := strings.Split(ponteiro[1:], "/")
+	for i, parte := range partes {
+		parte = strings.ReplaceAll(parte, "~1", "/")
+		partes[i] = strings.ReplaceAll(parte, "~0", "~")
+	}
+	return partes, nil
+}
+
+func indiceArray(token string, tamanho int, permitirFim bool) (int, error) {
+	if permitirFim && token == "-" {
+		return tamanho, nil
+	}
+	indice, err := strconv.Atoi(token)
+	if err != nil || indice < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("índice inválido: %q", token)
+	}
+	limite := tamanho - 1
+	if permitirFim {
+		limite = tamanho
+	}
+	if indice > limite {
+		return 0, fmt.Errorf("índice fora do intervalo: %d", indice)
+	}
+	return indice, nil
+}
+
+func obter(alvo interface{}, caminho []string) (interface{}, error) {
+	atual := alvo
+	for _, token := range caminho {
+		switch no := atual.(type) {
+		case map[string]interface{}:
+			valor, ok := no[token]
+			if !ok {
+				return nil, fmt.Errorf("caminho inexistente: %q", token)
+			}
+			atual = valor
+		case []interface{}:
+			indice, err := indiceArray(token, len(no), false)
+			if err != nil {
+				return nil, err
+			}
+			atual = no[indice]
+		default:
+			return nil, fmt.Errorf("caminho inexistente: %q", token)
+		}
+	}
+	return atual, nil
+}
+
+func adicionar(alvo interface{}, caminho []string, valor interface{}) (interface{}, error) {
+	if len(caminho) == 0 {
+		return valor, nil
+	}
+
+	pai, err := obter(alvo, caminho[:len(caminho)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := caminho[len(caminho)-1]
+
+	switch no := pai.(type) {
+	case map[string]interface{}:
+		no[token] = valor
+		return alvo, nil
+	case []interface{}:
+		indice, err := indiceArray(token, len(no), true)
+		if err != nil {
+			return nil, err
+		}
+		novo := append(no[:indice:indice], append([]interface{}{valor}, no[indice:]...)...)
+		return substituir(alvo, caminho[:len(caminho)-1], novo)
+	default:
+		return nil, fmt.Errorf("caminho inexistente: %q", token)
+	}
+}
+
+func remover(alvo interface{}, caminho []string) (interface{}, error) {
+	if len(caminho) == 0 {
+		return nil, errors.New("não é possível remover a raiz do documento")
+	}
+
+	pai, err := obter(alvo, caminho[:len(caminho)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := caminho[len(caminho)-1]
+
+	switch no := pai.(type) {
+	case map[string]interface{}:
+		if _, ok := no[token]; !ok {
+			return nil, fmt.Errorf("caminho inexistente: %q", token)
+		}
+		delete(no, token)
+		return alvo, nil
+	case []interface{}:
+		indice, err := indiceArray(token, len(no), false)
+		if err != nil {
+			return nil, err
+		}
+		novo := append(no[:indice:indice], no[indice+1:]...)
+		return substituir(alvo, caminho[:len(caminho)-1], novo)
+	default:
+		return nil, fmt.Errorf("caminho inexistente: %q", token)
+	}
+}
+
+// substituir troca o valor apontado pelo caminho, necessário para arrays que mudam de tamanho
+func substituir(alvo interface{}, caminho []string, valor interface{}) (interface{}, error) {
+	if len(caminho) == 0 {
+		return valor, nil
+	}
+
+	pai, err := obter(alvo, caminho[:len(caminho)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := caminho[len(caminho)-1]
+
+	switch no := pai.(type) {
+	case map[string]interface{}:
+		no[token] = valor
+	case []interface{}:
+		indice, err := indiceArray(token, len(no), false)
+		if err != nil {
+			return nil, err
+		}
+		no[indice] = valor
+	}
+	return alvo, nil
+}
+
+func copiarValor(valor interface{}) interface{} {
+	switch v := valor.(type) {
+	case map[string]interface{}:
+		copia := make(map[string]interface{}, len(v))
+		for chave, item := range v {
+			copia[chave] = copiarValor(item)
+		}
+		return copia
+	case []interface{}:
+		copia := make([]interface{}, len(v))
+		for i, item := range v {
+			copia[i] = copiarValor(item)
+		}
+		return copia
+	default:
+		return v
+	}
+}
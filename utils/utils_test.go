@@ -1,13 +1,10 @@
 package utils
 
 import (
-	"log"
+	"math/rand/v2"
 	"testing"
 
-	"github.com/Gileno29/clientes-API/models"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 func TestValidaDocumento(t *testing.T) {
@@ -62,41 +59,6 @@ func TestTodosDigitosIguais(t *testing.T) {
 	assert.False(t, todosDigitosIguais("12345678901"), "Dígitos diferentes")
 }
 
-// setupDB inicializa um banco de dados SQLite em memória para testes
-func setupDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
-	}
-	return db
-}
-
-// TestVerificarTabelaClientes testa a função VerificarTabelaClientes
-func TestVerificarTabelaClientes(t *testing.T) {
-	// Configura o banco de dados em memória
-	db := setupDB()
-
-	// Testa a criação da tabela quando ela não existe
-	t.Run("Cria tabela quando não existe", func(t *testing.T) {
-		err := VerificarTabelaClientes(db)
-		assert.NoError(t, err, "Erro ao verificar/criar tabela de clientes")
-
-		// Verifica se a tabela foi criada
-		hasTable := db.Migrator().HasTable(&models.Cliente{})
-		assert.True(t, hasTable, "A tabela de clientes deve existir após a criação")
-	})
-
-	// Testa o comportamento quando a tabela já existe
-	t.Run("Não recria tabela quando já existe", func(t *testing.T) {
-		err := VerificarTabelaClientes(db)
-		assert.NoError(t, err, "Erro ao verificar tabela de clientes")
-
-		// Verifica se a tabela ainda existe
-		hasTable := db.Migrator().HasTable(&models.Cliente{})
-		assert.True(t, hasTable, "A tabela de clientes deve continuar existindo")
-	})
-}
-
 func TestClearNumber(t *testing.T) {
 	// Casos de teste
 	tests := []struct {
@@ -151,3 +113,86 @@ func TestClearNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestGerarDocumentos(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 100; i++ {
+		cpf := GerarCPF(r)
+		assert.Len(t, cpf, 11)
+		assert.True(t, ValidarCPF(cpf), "CPF gerado deve ser válido: %s", cpf)
+
+		cnpj := GerarCNPJ(r)
+		assert.Len(t, cnpj, 14)
+		assert.True(t, ValidarCNPJ(cnpj), "CNPJ gerado deve ser válido: %s", cnpj)
+	}
+}
+
+func TestFormatarDocumento(t *testing.T) {
+	assert.Equal(t, "529.982.247-25", FormatarDocumento("52998224725"), "CPF")
+	assert.Equal(t, "33.000.167/0001-01", FormatarDocumento("33000167000101"), "CNPJ")
+	assert.Equal(t, "33.000.167/0001-01", FormatarDocumento("33.000.167/0001-01"), "CNPJ já formatado")
+	assert.Equal(t, "123", FormatarDocumento("123"), "Documento com tamanho inválido")
+}
+
+func TestAplicarMergePatch(t *testing.T) {
+	documento := []byte(`{"documento": "52998224725", "razaosocial": "João Silva", "blocklist": false}`)
+
+	resultado, err := AplicarMergePatch(documento, []byte(`{"blocklist": true, "razaosocial": null}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"documento": "52998224725", "blocklist": true}`, string(resultado))
+
+	_, err = AplicarMergePatch(documento, []byte(`{"blocklist":`))
+	assert.Error(t, err, "Patch malformado deve retornar erro")
+}
+
+func TestAplicarJSONPatch(t *testing.T) {
+	documento := []byte(`{"razaosocial": "João Silva", "blocklist": false, "tags": ["a"]}`)
+
+	tests := []struct {
+		name     string
+		patch    string
+		expected string
+		erro     bool
+	}{
+		{
+			name:     "Replace",
+			patch:    `[{"op": "replace", "path": "/blocklist", "value": true}]`,
+			expected: `{"razaosocial": "João Silva", "blocklist": true, "tags": ["a"]}`,
+		},
+		{
+			name:     "Add e remove",
+			patch:    `[{"op": "add", "path": "/tags/-", "value": "b"}, {"op": "remove", "path": "/razaosocial"}]`,
+			expected: `{"blocklist": false, "tags": ["a", "b"]}`,
+		},
+		{
+			name:     "Move e copy",
+			patch:    `[{"op": "copy", "from": "/razaosocial", "path": "/nome"}, {"op": "move", "from": "/tags/0", "path": "/tag"}]`,
+			expected: `{"razaosocial": "João Silva", "nome": "João Silva", "blocklist": false, "tags": [], "tag": "a"}`,
+		},
+		{
+			name:  "Replace em caminho inexistente",
+			patch: `[{"op": "replace", "path": "/inexistente", "value": 1}]`,
+			erro:  true,
+		},
+		{
+			name:  "Operação desconhecida",
+			patch: `[{"op": "merge", "path": "/blocklist", "value": true}]`,
+			erro:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resultado, err := AplicarJSONPatch(documento, []byte(tt.patch))
+			if tt.erro {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(resultado))
+		})
+	}
+
+	_, err := AplicarJSONPatch(documento, []byte(`[{"op": "test", "path": "/blocklist", "value": true}]`))
+	assert.ErrorIs(t, err, ErrTesteFalhou, "Operação test divergente deve retornar ErrTesteFalhou")
+}
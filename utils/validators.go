@@ -1,13 +1,9 @@
 package utils
 
 import (
-	"log"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/Gileno29/clientes-API/models"
-	"gorm.io/gorm"
 )
 
 var StartTime time.Time
@@ -40,6 +36,11 @@ func ValidaDocumento(documento string) bool {
 	return false
 }
 
+// ValidaRazaoSocial verifica se a razão social possui algum conteúdo além de espaços
+func ValidaRazaoSocial(razaoSocial string) bool {
+	return strings.TrimSpace(razaoSocial) != ""
+}
+
 func todosDigitosIguais(cpf string) bool {
 	for i := 1; i < len(cpf); i++ {
 		if cpf[i] != cpf[0] {
@@ -118,22 +119,3 @@ func ValidarCNPJ(cnpj string) bool {
 	// Verifica se os dígitos calculados são iguais aos informados
 	return cnpj[12:14] == strconv.Itoa(primeiroDigito)+strconv.Itoa(segundoDigito)
 }
-
-func VerificarTabelaClientes(db *gorm.DB) error {
-
-	if !db.Migrator().HasTable(&models.Cliente{}) {
-		log.Println("Tabela de clientes não encontrada. Criando tabela...")
-
-		err := db.AutoMigrate(&models.Cliente{})
-		if err != nil {
-			log.Fatalf("Erro ao criar tabela de clientes: %v", err)
-			return err
-		}
-
-		log.Println("Tabela de clientes criada com sucesso!")
-	} else {
-		log.Println("Tabela de clientes já existe.")
-	}
-
-	return nil
-}
@@ -0,0 +1,262 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+)
+
+const (
+	HeaderEvento     = "X-Webhook-Evento"
+	HeaderEntrega    = "X-Webhook-Entrega"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderAssinatura = "X-Webhook-Assinatura"
+
+	tamanhoLote = 100
+)
+
+// Assinar calcula a assinatura HMAC-SHA256 de uma entrega. O conteúdo assinado é o timestamp
+// seguido de um ponto e do corpo da requisição, permitindo ao receptor recusar entregas antigas.
+func Assinar(segredo, timestamp string, corpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(corpo)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher distribui os eventos do outbox para as assinaturas e realiza as entregas
+type Dispatcher struct {
+	eventos  repository.EventoRepository
+	webhooks repository.WebhookRepository
+	client   *http.Client
+
+	// Intervalo é a frequência com que o outbox e as entregas pendentes são verificados
+	Intervalo time.Duration
+	// MaxTentativas é o número de tentativas após o qual a entrega é considerada falha
+	MaxTentativas int
+	// BackoffInicial é a espera antes da segunda tentativa, dobrada a cada nova falha
+	BackoffInicial time.Duration
+	// BackoffMaximo limita a espera entre tentativas
+	BackoffMaximo time.Duration
+	// Concorrencia é o número de entregas feitas em paralelo
+	Concorrencia int
+
+	parar context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+func NewDispatcher(eventos repository.EventoRepository, webhooks repository.WebhookRepository, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Dispatcher{
+		eventos:        eventos,
+		webhooks:       webhooks,
+		client:         client,
+		Intervalo:      time.Second,
+		MaxTentativas:  10,
+		BackoffInicial: 5 * time.Second,
+		BackoffMaximo:  time.Hour,
+		Concorrencia:   4,
+	}
+}
+
+func (d *Dispatcher) Iniciar(ctx context.Context) {
+	ctx, d.parar = context.WithCancel(ctx)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.Intervalo)
+		defer ticker.Stop()
+		for {
+			d.Processar(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Parar interrompe o dispatcher e aguarda as entregas em andamento
+func (d *Dispatcher) Parar() {
+	if d.parar != nil {
+		d.parar()
+	}
+	d.wg.Wait()
+}
+
+// Processar executa um ciclo de distribuição do outbox e de entrega das pendências
+func (d *Dispatcher) Processar(ctx context.Context) {
+	if err := d.distribuir(); err != nil {
+		slog.Error("Erro ao distribuir eventos para os webhooks", "erro", err)
+	}
+	if err := d.entregar(ctx); err != nil {
+		slog.Error("Erro ao entregar webhooks", "erro", err)
+	}
+}
+
+func (d *Dispatcher) distribuir() error {
+	for {
+		eventos, err := d.eventos.ListarNaoPublicados(tamanhoLote)
+		if err != nil || len(eventos) == 0 {
+			return err
+		}
+
+		assinaturas, err := d.webhooks.ListarAtivas()
+		if err != nil {
+			return err
+		}
+
+		for i := range eventos {
+			if _, err := d.webhooks.DistribuirEvento(&eventos[i], assinaturas); err != nil {
+				return err
+			}
+		}
+
+		if len(eventos) < tamanhoLote {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) entregar(ctx context.Context) error {
+	// A reserva cobre o timeout da requisição para que outra instância não repita a entrega
+	reserva := d.client.Timeout + 30*time.Second
+	entregas, err := d.webhooks.ReservarEntregas(tamanhoLote, reserva)
+	if err != nil {
+		return err
+	}
+
+	semaforo := make(chan struct{}, max(d.Concorrencia, 1))
+	var wg sync.WaitGroup
+	for i := range entregas {
+		semaforo <- struct{}{}
+		wg.Add(1)
+		go func(entrega *models.WebhookEntrega) {
+			defer wg.Done()
+			defer func() { <-semaforo }()
+			d.entregarUma(ctx, entrega)
+		}(&entregas[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+func (d *Dispatcher) entregarUma(ctx context.Context, entrega *models.WebhookEntrega) {
+	evento, err := d.webhooks.FindEvento(entrega.EventoID)
+	if err != nil {
+		entrega.Status = models.EntregaFalhou
+		d.registrar(entrega, &models.WebhookTentativa{Erro: "evento não encontrado"})
+		return
+	}
+	// A assinatura é buscada no tenant do evento, que nunca é entregue a outro tenant
+	assinatura, err := d.webhooks.FindByID(evento.TenantID, entrega.AssinaturaID)
+	if err != nil {
+		entrega.Status = models.EntregaFalhou
+		d.registrar(entrega, &models.WebhookTentativa{Erro: "assinatura não encontrada"})
+		return
+	}
+
+	corpo, err := json.Marshal(dtos.EventoResponse{
+		ID:        evento.ID,
+		Tipo:      evento.Tipo,
+		Documento: evento.Documento,
+		CriadoEm:  evento.CreatedAt,
+		Dados:     json.RawMessage(evento.Payload),
+	})
+	if err != nil {
+		entrega.Status = models.EntregaFalhou
+		d.registrar(entrega, &models.WebhookTentativa{Erro: err.Error()})
+		return
+	}
+
+	tentativa := &models.WebhookTentativa{}
+	inicio := time.Now()
+	statusHTTP, err := d.enviar(ctx, assinatura, evento, entrega, corpo)
+	tentativa.DuracaoMs = time.Since(inicio).Milliseconds()
+	tentativa.StatusHTTP = statusHTTP
+
+	if ctx.Err() != nil {
+		// Interrompida pelo encerramento da aplicação, a entrega volta a ser tentada quando a reserva vencer
+		return
+	}
+
+	if err == nil && statusHTTP >= 200 && statusHTTP < 300 {
+		agora := time.Now()
+		entrega.Status = models.EntregaEntregue
+		entrega.EntregueEm = &agora
+	} else {
+		if err != nil {
+			tentativa.Erro = err.Error()
+		} else {
+			tentativa.Erro = fmt.Sprintf("resposta inesperada: %d", statusHTTP)
+		}
+		if entrega.Tentativas+1 >= d.MaxTentativas {
+			entrega.Status = models.EntregaFalhou
+		} else {
+			entrega.ProximaTentativaEm = time.Now().Add(d.Backoff(entrega.Tentativas + 1))
+		}
+	}
+	d.registrar(entrega, tentativa)
+}
+
+func (d *Dispatcher) enviar(ctx context.Context, assinatura *models.WebhookAssinatura, evento *models.Evento, entrega *models.WebhookEntrega, corpo []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, assinatura.URL, bytes.NewReader(corpo))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvento, evento.Tipo)
+	req.Header.Set(HeaderEntrega, strconv.FormatUint(uint64(entrega.ID), 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderAssinatura, Assinar(assinatura.Segredo, timestamp, corpo))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) registrar(entrega *models.WebhookEntrega, tentativa *models.WebhookTentativa) {
+	entrega.Tentativas++
+	tentativa.EntregaID = entrega.ID
+	tentativa.Numero = entrega.Tentativas
+	if err := d.webhooks.RegistrarTentativa(entrega, tentativa); err != nil {
+		slog.Error("Erro ao registrar a tentativa de entrega", "entrega_id", entrega.ID, "erro", err)
+	}
+}
+
+// Backoff retorna a espera antes da próxima tentativa após o número de falhas informado
+func (d *Dispatcher) Backoff(falhas int) time.Duration {
+	espera := d.BackoffInicial
+	for i := 1; i < falhas; i++ {
+		espera *= 2
+		if espera >= d.BackoffMaximo {
+			return d.BackoffMaximo
+		}
+	}
+	return espera
+}
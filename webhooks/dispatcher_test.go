@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gileno29/clientes-API/dtos"
+	"github.com/Gileno29/clientes-API/models"
+	"github.com/Gileno29/clientes-API/repository"
+	"github.com/Gileno29/clientes-API/tenant"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupDB inicializa um banco de dados SQLite em memória com as tabelas de clientes, eventos e webhooks
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	db.AutoMigrate(&models.Cliente{}, &models.Evento{}, &models.WebhookAssinatura{}, &models.WebhookEntrega{}, &models.WebhookTentativa{})
+	for _, tabela := range []string{"clientes", "eventos", "webhook_assinaturas", "webhook_entregas", "webhook_tentativas"} {
+		db.Exec("DELETE FROM " + tabela)
+	}
+	return db
+}
+
+type receptor struct {
+	mu        sync.Mutex
+	status    int
+	recebidos []*http.Request
+	corpos    [][]byte
+}
+
+func (r *receptor) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	corpo, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recebidos = append(r.recebidos, req)
+	r.corpos = append(r.corpos, corpo)
+	w.WriteHeader(r.status)
+}
+
+func TestAssinar(t *testing.T) {
+	assinatura := Assinar("segredo", "1700000000", []byte(`{"id":1}`))
+	assert.Equal(t, "sha256=", assinatura[:7])
+	assert.Equal(t, assinatura, Assinar("segredo", "1700000000", []byte(`{"id":1}`)))
+	assert.NotEqual(t, assinatura, Assinar("outro", "1700000000", []byte(`{"id":1}`)))
+	assert.NotEqual(t, assinatura, Assinar("segredo", "1700000001", []byte(`{"id":1}`)))
+}
+
+func TestDispatcherEntregaEventos(t *testing.T) {
+	db := setupDB(t)
+	clientes := repository.NewClienteRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	servidor := &receptor{status: http.StatusOK}
+	ts := httptest.NewServer(servidor)
+	defer ts.Close()
+
+	webhookRepo.Create(&models.WebhookAssinatura{ID: "blocklist", TenantID: "a", URL: ts.URL, Eventos: models.EventoBlocklistAlterado, Segredo: "segredo", Ativo: true})
+	// A assinatura de outro tenant não recebe os eventos do tenant a
+	webhookRepo.Create(&models.WebhookAssinatura{ID: "outro-tenant", TenantID: "b", URL: ts.URL, Segredo: "segredo", Ativo: true})
+
+	ctx := tenant.ComID(context.Background(), "a")
+	cliente := &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"}
+	assert.NoError(t, clientes.Create(ctx, cliente))
+	blocklist := true
+	_, err := clientes.UpdateByDocumento(ctx, cliente, &dtos.AtualizaClienteRequest{Blocklist: &blocklist})
+	assert.NoError(t, err)
+
+	dispatcher := NewDispatcher(repository.NewEventoRepository(db), webhookRepo, ts.Client())
+	dispatcher.Processar(context.Background())
+
+	// Somente o evento assinado é entregue
+	assert.Len(t, servidor.recebidos, 1)
+	req := servidor.recebidos[0]
+	assert.Equal(t, models.EventoBlocklistAlterado, req.Header.Get(HeaderEvento))
+	assert.Equal(t, Assinar("segredo", req.Header.Get(HeaderTimestamp), servidor.corpos[0]), req.Header.Get(HeaderAssinatura))
+
+	var evento dtos.EventoResponse
+	assert.NoError(t, json.Unmarshal(servidor.corpos[0], &evento))
+	var payload dtos.BlocklistAlteradoPayload
+	json.Unmarshal(evento.Dados, &payload)
+	assert.True(t, payload.Blocklist)
+	assert.False(t, payload.BlocklistAnterior)
+
+	// Os eventos já distribuídos não são entregues novamente
+	dispatcher.Processar(context.Background())
+	assert.Len(t, servidor.recebidos, 1)
+
+	entregas, _ := webhookRepo.ListarEntregas("blocklist", 10)
+	assert.Len(t, entregas, 1)
+	assert.Equal(t, models.EntregaEntregue, entregas[0].Status)
+	entregas, _ = webhookRepo.ListarEntregas("outro-tenant", 10)
+	assert.Empty(t, entregas)
+}
+
+func TestDispatcherRetentaComBackoff(t *testing.T) {
+	db := setupDB(t)
+	clientes := repository.NewClienteRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	servidor := &receptor{status: http.StatusInternalServerError}
+	ts := httptest.NewServer(servidor)
+	defer ts.Close()
+
+	webhookRepo.Create(&models.WebhookAssinatura{ID: "todos", TenantID: "a", URL: ts.URL, Segredo: "segredo", Ativo: true})
+	clientes.Create(tenant.ComID(context.Background(), "a"), &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"})
+
+	dispatcher := NewDispatcher(repository.NewEventoRepository(db), webhookRepo, ts.Client())
+	dispatcher.BackoffInicial = time.Hour
+	dispatcher.MaxTentativas = 2
+
+	dispatcher.Processar(context.Background())
+	entregas, _ := webhookRepo.ListarEntregas("todos", 10)
+	assert.Len(t, entregas, 1)
+	assert.Equal(t, models.EntregaPendente, entregas[0].Status)
+	assert.Equal(t, 1, entregas[0].Tentativas)
+	assert.True(t, entregas[0].ProximaTentativaEm.After(time.Now().Add(50*time.Minute)), "Próxima tentativa deve respeitar o backoff")
+
+	// Antes do backoff vencer a entrega não é repetida
+	dispatcher.Processar(context.Background())
+	assert.Len(t, servidor.recebidos, 1)
+
+	// Após a última tentativa permitida a entrega é marcada como falha
+	db.Model(&models.WebhookEntrega{}).Where("id = ?", entregas[0].ID).Update("proxima_tentativa_em", time.Now().Add(-time.Second))
+	dispatcher.Processar(context.Background())
+	entregas, _ = webhookRepo.ListarEntregas("todos", 10)
+	assert.Equal(t, models.EntregaFalhou, entregas[0].Status)
+
+	tentativas, _ := webhookRepo.ListarTentativas([]uint{entregas[0].ID})
+	assert.Len(t, tentativas, 2)
+	assert.Equal(t, http.StatusInternalServerError, tentativas[1].StatusHTTP)
+}
+
+func TestDispatcherNaoEntregaAuditorias(t *testing.T) {
+	db := setupDB(t)
+	eventoRepo := repository.NewEventoRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	servidor := &receptor{status: http.StatusOK}
+	ts := httptest.NewServer(servidor)
+	defer ts.Close()
+
+	// A assinatura sem filtro recebe todos os eventos distribuídos
+	webhookRepo.Create(&models.WebhookAssinatura{ID: "todos", TenantID: "a", URL: ts.URL, Segredo: "segredo", Ativo: true})
+	assert.NoError(t, eventoRepo.Registrar("a", models.EventoRelatorioTitular, "52998224725", dtos.RelatorioTitularPayload{Formato: "json"}))
+
+	dispatcher := NewDispatcher(eventoRepo, webhookRepo, ts.Client())
+	dispatcher.Processar(context.Background())
+
+	assert.Empty(t, servidor.recebidos, "A auditoria do titular não é enviada aos webhooks")
+	entregas, _ := webhookRepo.ListarEntregas("todos", 10)
+	assert.Empty(t, entregas)
+	pendentes, err := eventoRepo.ListarNaoPublicados(10)
+	assert.NoError(t, err)
+	assert.Empty(t, pendentes, "A auditoria sai do outbox para poder ser expurgada")
+}
+
+func TestOutboxTransacional(t *testing.T) {
+	db := setupDB(t)
+	clientes := repository.NewClienteRepository(db)
+	ctx := tenant.ComID(context.Background(), "a")
+
+	// Uma transação desfeita não deixa eventos no outbox
+	clientes.Transaction(ctx, func(repo repository.ClienteRepository) error {
+		repo.Create(ctx, &models.Cliente{Documento: "52998224725", RazaoSocial: "João Silva"})
+		return errors.New("desfaz")
+	})
+
+	eventos, err := repository.NewEventoRepository(db).ListarNaoPublicados(10)
+	assert.NoError(t, err)
+	assert.Empty(t, eventos)
+
+	assert.NoError(t, clientes.DeleteByDocumento(ctx, "52998224725"))
+	eventos, _ = repository.NewEventoRepository(db).ListarNaoPublicados(10)
+	assert.Empty(t, eventos, "Remover um cliente inexistente não gera evento")
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher := NewDispatcher(nil, nil, nil)
+	dispatcher.BackoffInicial = time.Second
+	dispatcher.BackoffMaximo = 10 * time.Second
+
+	assert.Equal(t, time.Second, dispatcher.Backoff(1))
+	assert.Equal(t, 2*time.Second, dispatcher.Backoff(2))
+	assert.Equal(t, 8*time.Second, dispatcher.Backoff(4))
+	assert.Equal(t, 10*time.Second, dispatcher.Backoff(5))
+}